@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"sort"
+
+	"tf-engine/internal/models"
+)
+
+// HeatReport is the risk of the active trades as a fraction of equity,
+// overall and per sector
+type HeatReport struct {
+	Equity       float64      `json:"equity"`
+	OpenRisk     float64      `json:"open_risk"`
+	Heat         float64      `json:"heat"`     // OpenRisk / Equity
+	HeatCap      float64      `json:"heat_cap"` // Policy portfolio cap (0.04 = 4%)
+	ActiveTrades int          `json:"active_trades"`
+	Sectors      []SectorHeat `json:"sectors"`
+}
+
+// SectorHeat is one sector's share of the heat
+type SectorHeat struct {
+	Sector       string  `json:"sector"`
+	OpenRisk     float64 `json:"open_risk"`
+	Heat         float64 `json:"heat"`
+	HeatCap      float64 `json:"heat_cap"`
+	ActiveTrades int     `json:"active_trades"`
+}
+
+// OverCap reports whether the heat exceeds its cap (a zero cap is no cap)
+func (h HeatReport) OverCap() bool {
+	return h.HeatCap > 0 && h.Heat > h.HeatCap
+}
+
+// OverCap reports whether the sector's heat exceeds its cap
+func (s SectorHeat) OverCap() bool {
+	return s.HeatCap > 0 && s.Heat > s.HeatCap
+}
+
+// CalculateHeat sums the max loss of active trades against equity. Sector
+// caps come from the policy's heat_cap_percent, falling back to the bucket
+// cap; policy may be nil.
+func CalculateHeat(trades []models.Trade, equity float64, policy *models.Policy) HeatReport {
+	report := HeatReport{Equity: equity, Sectors: []SectorHeat{}}
+	if policy != nil {
+		report.HeatCap = policy.Defaults.PortfolioHeatCap
+	}
+
+	sectors := map[string]*SectorHeat{}
+	for i := range trades {
+		trade := &trades[i]
+		if trade.GetStatus() != "active" {
+			continue
+		}
+		risk := trade.MaxLoss
+		if risk == 0 {
+			risk = trade.Risk
+		}
+
+		report.ActiveTrades++
+		report.OpenRisk += risk
+
+		sector, ok := sectors[trade.Sector]
+		if !ok {
+			sector = &SectorHeat{Sector: trade.Sector, HeatCap: sectorHeatCap(policy, trade.Sector)}
+			sectors[trade.Sector] = sector
+		}
+		sector.ActiveTrades++
+		sector.OpenRisk += risk
+	}
+
+	for _, sector := range sectors {
+		if equity > 0 {
+			sector.Heat = sector.OpenRisk / equity
+		}
+		report.Sectors = append(report.Sectors, *sector)
+	}
+	if equity > 0 {
+		report.Heat = report.OpenRisk / equity
+	}
+
+	sort.Slice(report.Sectors, func(i, j int) bool {
+		if report.Sectors[i].Heat != report.Sectors[j].Heat {
+			return report.Sectors[i].Heat > report.Sectors[j].Heat
+		}
+		return report.Sectors[i].Sector < report.Sectors[j].Sector
+	})
+	return report
+}
+
+func sectorHeatCap(policy *models.Policy, name string) float64 {
+	if policy == nil {
+		return 0
+	}
+	for _, sector := range policy.Sectors {
+		if sector.Name == name && sector.HeatCapPercent > 0 {
+			return sector.HeatCapPercent
+		}
+	}
+	return policy.Defaults.BucketHeatCap
+}
@@ -0,0 +1,48 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	"tf-engine/internal/models"
+)
+
+func TestCalculateHeat(t *testing.T) {
+	policy := &models.Policy{
+		Defaults: models.PolicyDefaults{PortfolioHeatCap: 0.04, BucketHeatCap: 0.03},
+		Sectors:  []models.Sector{{Name: "Technology", HeatCapPercent: 0.01}},
+	}
+	trades := []models.Trade{
+		{Sector: "Technology", MaxLoss: 400, Status: "active"},
+		{Sector: "Healthcare", Risk: 200, Status: "active"},
+		{Sector: "Healthcare", MaxLoss: 100, Status: "active"},
+		{Sector: "Healthcare", MaxLoss: 5000, Status: "closed"},
+	}
+
+	heat := CalculateHeat(trades, 20000, policy)
+
+	if heat.ActiveTrades != 3 || heat.OpenRisk != 700 || math.Abs(heat.Heat-0.035) > 1e-9 {
+		t.Errorf("Unexpected portfolio heat %+v", heat)
+	}
+	if heat.OverCap() {
+		t.Error("3.5% is under the 4% portfolio cap")
+	}
+	if len(heat.Sectors) != 2 {
+		t.Fatalf("Expected 2 sectors, got %+v", heat.Sectors)
+	}
+
+	tech, health := heat.Sectors[0], heat.Sectors[1]
+	if tech.Sector != "Technology" || tech.HeatCap != 0.01 || !tech.OverCap() {
+		t.Errorf("Technology should use its own 1%% cap and exceed it: %+v", tech)
+	}
+	if health.Sector != "Healthcare" || health.HeatCap != 0.03 || health.ActiveTrades != 2 || health.OverCap() {
+		t.Errorf("Healthcare should use the bucket cap: %+v", health)
+	}
+}
+
+func TestCalculateHeat_NoEquityOrPolicy(t *testing.T) {
+	heat := CalculateHeat([]models.Trade{{Sector: "Energy", MaxLoss: 100, Status: "active"}}, 0, nil)
+	if heat.Heat != 0 || heat.OverCap() || heat.Sectors[0].OverCap() {
+		t.Errorf("Without equity or caps nothing is over: %+v", heat)
+	}
+}
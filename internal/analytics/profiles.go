@@ -0,0 +1,48 @@
+package analytics
+
+import (
+	"sort"
+
+	"tf-engine/internal/models"
+)
+
+// ProfileSummary holds performance and equity for one account profile
+type ProfileSummary struct {
+	Profile   models.Profile
+	Stats     TradeStats
+	Equity    float64
+	HasLedger bool
+}
+
+// SummarizeProfile computes the summary for one profile's trades and ledger
+func SummarizeProfile(profile models.Profile, trades []models.Trade, ledger *models.Ledger) ProfileSummary {
+	summary := ProfileSummary{
+		Profile: profile,
+		Stats:   CalculateTradeStats(SortByClose(trades)),
+	}
+	if ledger != nil {
+		summary.Equity = ledger.Equity()
+		summary.HasLedger = true
+	}
+	return summary
+}
+
+// ConsolidateTrades merges trades from several profiles in close order so
+// streaks and drawdown reflect the combined account activity
+func ConsolidateTrades(tradeSets ...[]models.Trade) []models.Trade {
+	all := []models.Trade{}
+	for _, trades := range tradeSets {
+		all = append(all, trades...)
+	}
+	return SortByClose(all)
+}
+
+// SortByClose returns a copy of trades ordered by last update (close time)
+func SortByClose(trades []models.Trade) []models.Trade {
+	sorted := make([]models.Trade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UpdatedAt.Before(sorted[j].UpdatedAt)
+	})
+	return sorted
+}
@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func TestConsolidateTrades_MergesInCloseOrder(t *testing.T) {
+	day := time.Date(2025, 3, 3, 16, 0, 0, 0, time.UTC)
+	win, loss := 300.0, -100.0
+
+	personal := []models.Trade{
+		{ID: "p2", ProfitLoss: &win, UpdatedAt: day.AddDate(0, 0, 2)},
+		{ID: "p1", ProfitLoss: &loss, UpdatedAt: day},
+	}
+	prop := []models.Trade{
+		{ID: "f1", ProfitLoss: &loss, UpdatedAt: day.AddDate(0, 0, 1)},
+	}
+
+	merged := ConsolidateTrades(personal, prop)
+	if len(merged) != 3 {
+		t.Fatalf("Expected 3 trades, got %d", len(merged))
+	}
+	order := merged[0].ID + merged[1].ID + merged[2].ID
+	if order != "p1f1p2" {
+		t.Errorf("Expected close order p1 f1 p2, got %s", order)
+	}
+
+	stats := CalculateTradeStats(merged)
+	if stats.TotalPnL != 100 {
+		t.Errorf("Expected combined P&L 100, got %.2f", stats.TotalPnL)
+	}
+	if stats.LongestLossStreak != 2 {
+		t.Errorf("Expected combined loss streak 2, got %d", stats.LongestLossStreak)
+	}
+
+	// Inputs are not reordered
+	if personal[0].ID != "p2" {
+		t.Error("ConsolidateTrades should not modify its inputs")
+	}
+}
+
+func TestSummarizeProfile_UsesLedgerEquity(t *testing.T) {
+	profile := models.Profile{ID: "prop", Name: "Prop"}
+	ledger := models.NewLedger(50000, time.Now())
+
+	summary := SummarizeProfile(profile, nil, ledger)
+	if !summary.HasLedger || summary.Equity != 50000 {
+		t.Errorf("Expected ledger equity 50000, got %.2f (ledger=%v)", summary.Equity, summary.HasLedger)
+	}
+
+	summary = SummarizeProfile(profile, nil, nil)
+	if summary.HasLedger {
+		t.Error("Profile without ledger should report HasLedger=false")
+	}
+}
@@ -9,41 +9,71 @@ import (
 
 // TradeStats holds performance statistics for trades
 type TradeStats struct {
-	TotalTrades       int
-	WinningTrades     int
-	LosingTrades      int
-	WinRate           float64
-	TotalPnL          float64
-	AveragePnL        float64
-	AverageWin        float64
-	AverageLoss       float64
-	LargestWin        float64
-	LargestLoss       float64
-	ProfitFactor      float64
-	SharpeRatio       float64
-	MaxDrawdown       float64
-	MaxDrawdownPct    float64
-	CurrentStreak     int
-	LongestWinStreak  int
-	LongestLossStreak int
+	TotalTrades       int     `json:"total_trades"`
+	WinningTrades     int     `json:"winning_trades"`
+	LosingTrades      int     `json:"losing_trades"`
+	WinRate           float64 `json:"win_rate"`
+	TotalPnL          float64 `json:"total_pnl"`
+	AveragePnL        float64 `json:"average_pnl"`
+	AverageWin        float64 `json:"average_win"`
+	AverageLoss       float64 `json:"average_loss"`
+	LargestWin        float64 `json:"largest_win"`
+	LargestLoss       float64 `json:"largest_loss"`
+	ProfitFactor      float64 `json:"profit_factor"`
+	SharpeRatio       float64 `json:"sharpe_ratio"`
+	MaxDrawdown       float64 `json:"max_drawdown"`
+	MaxDrawdownPct    float64 `json:"max_drawdown_pct"`
+	CurrentStreak     int     `json:"current_streak"`
+	LongestWinStreak  int     `json:"longest_win_streak"`
+	LongestLossStreak int     `json:"longest_loss_streak"`
 }
 
 // SectorStats holds performance statistics by sector
 type SectorStats struct {
-	Sector      string
-	TotalTrades int
-	WinRate     float64
-	TotalPnL    float64
-	AveragePnL  float64
+	Sector      string  `json:"sector"`
+	TotalTrades int     `json:"total_trades"`
+	WinRate     float64 `json:"win_rate"`
+	TotalPnL    float64 `json:"total_pnl"`
+	AveragePnL  float64 `json:"average_pnl"`
 }
 
 // StrategyStats holds performance statistics by strategy
 type StrategyStats struct {
-	Strategy    string
-	TotalTrades int
-	WinRate     float64
-	TotalPnL    float64
-	AveragePnL  float64
+	Strategy    string  `json:"strategy"`
+	TotalTrades int     `json:"total_trades"`
+	WinRate     float64 `json:"win_rate"`
+	TotalPnL    float64 `json:"total_pnl"`
+	AveragePnL  float64 `json:"average_pnl"`
+}
+
+// ConvictionStats holds performance statistics by conviction (5-8)
+type ConvictionStats struct {
+	Conviction  int     `json:"conviction"`
+	TotalTrades int     `json:"total_trades"`
+	WinRate     float64 `json:"win_rate"`
+	TotalPnL    float64 `json:"total_pnl"`
+	AveragePnL  float64 `json:"average_pnl"`
+}
+
+// StatsReport combines the overall, sector, strategy and conviction
+// statistics
+type StatsReport struct {
+	Overall     TradeStats        `json:"overall"`
+	Sectors     []SectorStats     `json:"sectors"`
+	Strategies  []StrategyStats   `json:"strategies"`
+	Convictions []ConvictionStats `json:"convictions"`
+}
+
+// CalculateStatsReport computes every statistics table, taking trades in the
+// order they closed
+func CalculateStatsReport(trades []models.Trade) StatsReport {
+	trades = SortByClose(trades)
+	return StatsReport{
+		Overall:     CalculateTradeStats(trades),
+		Sectors:     CalculateSectorStats(trades),
+		Strategies:  CalculateStrategyStats(trades),
+		Convictions: CalculateConvictionStats(trades),
+	}
 }
 
 // CalculateTradeStats computes overall performance statistics
@@ -264,6 +294,42 @@ func CalculateStrategyStats(trades []models.Trade) []StrategyStats {
 	return result
 }
 
+// CalculateConvictionStats computes performance statistics by conviction,
+// lowest conviction first. Trades without a conviction are grouped under 0.
+func CalculateConvictionStats(trades []models.Trade) []ConvictionStats {
+	byConviction := make(map[int]*ConvictionStats)
+	wins := make(map[int]int)
+
+	for _, trade := range trades {
+		if trade.ProfitLoss == nil {
+			continue
+		}
+		stats, exists := byConviction[trade.Conviction]
+		if !exists {
+			stats = &ConvictionStats{Conviction: trade.Conviction}
+			byConviction[trade.Conviction] = stats
+		}
+		stats.TotalTrades++
+		stats.TotalPnL += *trade.ProfitLoss
+		if *trade.ProfitLoss > 0 {
+			wins[trade.Conviction]++
+		}
+	}
+
+	result := []ConvictionStats{}
+	for conviction, stats := range byConviction {
+		stats.AveragePnL = stats.TotalPnL / float64(stats.TotalTrades)
+		stats.WinRate = float64(wins[conviction]) / float64(stats.TotalTrades) * 100
+		result = append(result, *stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Conviction < result[j].Conviction
+	})
+
+	return result
+}
+
 // EquityCurvePoint represents a point on the equity curve
 type EquityCurvePoint struct {
 	Date   time.Time
@@ -309,3 +375,21 @@ func CalculateEquityCurve(trades []models.Trade) []EquityCurvePoint {
 
 	return curve
 }
+
+// CalculateLedgerEquityCurve computes the account equity curve from the
+// ledger, including the starting balance, deposits and withdrawals
+func CalculateLedgerEquityCurve(ledger *models.Ledger) []EquityCurvePoint {
+	if ledger == nil {
+		return []EquityCurvePoint{}
+	}
+
+	curve := []EquityCurvePoint{}
+	for _, point := range ledger.History() {
+		curve = append(curve, EquityCurvePoint{
+			Date:   point.Entry.Date,
+			Equity: point.Balance,
+		})
+	}
+
+	return curve
+}
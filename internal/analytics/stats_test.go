@@ -217,6 +217,31 @@ func TestCalculateStrategyStats(t *testing.T) {
 	}
 }
 
+func TestCalculateConvictionStats(t *testing.T) {
+	pnl1 := 100.0
+	pnl2 := -50.0
+	pnl3 := 300.0
+
+	trades := []models.Trade{
+		{Conviction: 8, ProfitLoss: &pnl3},
+		{Conviction: 5, ProfitLoss: &pnl1},
+		{Conviction: 5, ProfitLoss: &pnl2},
+		{Conviction: 7}, // Still open
+	}
+
+	stats := CalculateConvictionStats(trades)
+
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 convictions, got %d", len(stats))
+	}
+	if stats[0].Conviction != 5 || stats[0].TotalTrades != 2 || stats[0].WinRate != 50 || stats[0].AveragePnL != 25 {
+		t.Errorf("Unexpected conviction 5 stats: %+v", stats[0])
+	}
+	if stats[1].Conviction != 8 || stats[1].TotalPnL != 300 {
+		t.Errorf("Unexpected conviction 8 stats: %+v", stats[1])
+	}
+}
+
 func TestCalculateEquityCurve(t *testing.T) {
 	now := time.Now()
 	pnl1 := 100.0
@@ -265,3 +290,35 @@ func TestCalculateEquityCurve_Empty(t *testing.T) {
 		t.Errorf("Expected empty equity curve, got %d points", len(curve))
 	}
 }
+
+func TestCalculateLedgerEquityCurve(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ledger := models.NewLedger(25000, start)
+	ledger.Deposit(5000, start.AddDate(0, 0, 10), "Top-up")
+
+	pnl := -750.0
+	exit := start.AddDate(0, 0, 5)
+	ledger.SyncRealizedPnL([]models.Trade{
+		{ID: "t1", Ticker: "UNH", ProfitLoss: &pnl, ExitDate: &exit, Status: "closed"},
+	}, start.AddDate(0, 0, 20))
+
+	curve := CalculateLedgerEquityCurve(ledger)
+
+	if len(curve) != 3 {
+		t.Fatalf("Expected 3 curve points, got %d", len(curve))
+	}
+	// Points are in date order: start, loss, deposit
+	expected := []float64{25000, 24250, 29250}
+	for i, want := range expected {
+		if curve[i].Equity != want {
+			t.Errorf("Point %d: expected $%.2f, got $%.2f", i, want, curve[i].Equity)
+		}
+	}
+}
+
+func TestCalculateLedgerEquityCurve_NilLedger(t *testing.T) {
+	curve := CalculateLedgerEquityCurve(nil)
+	if len(curve) != 0 {
+		t.Errorf("Expected empty curve, got %d points", len(curve))
+	}
+}
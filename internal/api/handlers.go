@@ -0,0 +1,220 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/models"
+)
+
+// dateLayout is the format of dates in requests
+const dateLayout = "2006-01-02"
+
+func (s *Server) listTrades(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.TradeFilter{
+		Status:   q.Get("status"),
+		Sector:   q.Get("sector"),
+		Strategy: q.Get("strategy"),
+		Ticker:   q.Get("ticker"),
+	}
+
+	var trades []models.Trade
+	s.opts.Do(func() { trades = filter.Apply(s.state.AllTrades) })
+	writeJSON(w, http.StatusOK, trades)
+}
+
+func (s *Server) getTrade(w http.ResponseWriter, r *http.Request) {
+	trade, ok := s.find(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("trade not found: %s", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, trade)
+}
+
+func (s *Server) find(id string) (models.Trade, bool) {
+	var trade models.Trade
+	found := false
+	s.opts.Do(func() {
+		for _, t := range s.state.AllTrades {
+			if t.ID == id {
+				trade, found = t, true
+				return
+			}
+		}
+	})
+	return trade, found
+}
+
+// tradePatch holds the fields the trade management edit dialog can change
+type tradePatch struct {
+	Ticker     *string  `json:"ticker"`
+	Status     *string  `json:"status"`
+	ProfitLoss *float64 `json:"profit_loss"`
+	OpenPnL    *float64 `json:"open_pnl"`
+}
+
+func (s *Server) patchTrade(w http.ResponseWriter, r *http.Request) {
+	var patch tradePatch
+	if err := decode(w, r, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Patch the stored trade so a change made meanwhile (e.g. by the CLI)
+	// to other fields isn't overwritten with this instance's copy
+	now := time.Now()
+	var trade models.Trade
+	err := s.write(func() error {
+		var err error
+		trade, err = s.state.PatchTrade(r.PathValue("id"), func(t *models.Trade) {
+			if patch.Ticker != nil {
+				t.Ticker = *patch.Ticker
+			}
+			if patch.Status != nil {
+				t.Status = *patch.Status
+			}
+			if patch.ProfitLoss != nil {
+				t.ProfitLoss = patch.ProfitLoss
+			}
+			if patch.OpenPnL != nil {
+				t.MarkOpenPnL(*patch.OpenPnL, now)
+			}
+			t.UpdatedAt = now
+		})
+		return err
+	})
+	if err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trade)
+}
+
+// closeRequest closes a trade; ExitDate defaults to today and ProfitLoss to
+// the exit price minus the premium
+type closeRequest struct {
+	ExitPrice  *float64 `json:"exit_price"`
+	ExitDate   string   `json:"exit_date"`
+	ProfitLoss *float64 `json:"profit_loss"`
+}
+
+func (s *Server) closeTrade(w http.ResponseWriter, r *http.Request) {
+	var req closeRequest
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.ExitPrice == nil {
+		writeError(w, http.StatusBadRequest, errors.New("exit_price is required"))
+		return
+	}
+	exitDate := time.Now()
+	if req.ExitDate != "" {
+		var err error
+		if exitDate, err = time.ParseInLocation(dateLayout, req.ExitDate, time.Local); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("exit_date: want YYYY-MM-DD, got %q", req.ExitDate))
+			return
+		}
+	}
+
+	var trade models.Trade
+	err := s.write(func() (err error) {
+		trade, err = s.state.CloseTrade(r.PathValue("id"), *req.ExitPrice, exitDate, req.ProfitLoss)
+		return err
+	})
+	if err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trade)
+}
+
+// write runs fn on the app state and reports the change if it succeeded
+func (s *Server) write(fn func() error) error {
+	var err error
+	s.opts.Do(func() {
+		if err = fn(); err == nil && s.opts.OnChange != nil {
+			s.opts.OnChange()
+		}
+	})
+	return err
+}
+
+func (s *Server) heat(w http.ResponseWriter, r *http.Request) {
+	var report analytics.HeatReport
+	s.opts.Do(func() {
+		report = analytics.CalculateHeat(s.state.AllTrades, s.state.CurrentEquity(), s.state.Policy)
+	})
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
+	var report analytics.StatsReport
+	s.opts.Do(func() { report = analytics.CalculateStatsReport(s.state.AllTrades) })
+	writeJSON(w, http.StatusOK, report)
+}
+
+// policyView is the part of the policy scripts need: sectors with their
+// allowed strategies and suitability, the strategies and the defaults
+type policyView struct {
+	PolicyID   string                     `json:"policy_id"`
+	Version    string                     `json:"version"`
+	SafeMode   bool                       `json:"safe_mode"`
+	Sectors    []models.Sector            `json:"sectors"`
+	Strategies map[string]models.Strategy `json:"strategies"`
+	Defaults   models.PolicyDefaults      `json:"defaults"`
+}
+
+func (s *Server) policy(w http.ResponseWriter, r *http.Request) {
+	var view policyView
+	var loaded bool
+	s.opts.Do(func() {
+		if p := s.state.Policy; p != nil {
+			loaded = true
+			view = policyView{
+				PolicyID:   p.PolicyID,
+				Version:    p.Version,
+				SafeMode:   s.state.SafeModeActive,
+				Sectors:    p.Sectors,
+				Strategies: p.Strategies,
+				Defaults:   p.Defaults,
+			}
+		}
+	})
+	if !loaded {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no policy loaded"))
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+func (s *Server) getSettings(w http.ResponseWriter, r *http.Request) {
+	var settings models.Settings
+	s.opts.Do(func() {
+		if s.state.Settings != nil {
+			settings = *s.state.Settings
+		}
+	})
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// putSettings replaces the settings. Changes to the API itself apply after
+// a restart.
+func (s *Server) putSettings(w http.ResponseWriter, r *http.Request) {
+	settings := models.DefaultSettings()
+	if err := decode(w, r, settings); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.write(func() error { return s.state.SaveSettings(settings) }); err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,171 @@
+// Package api serves journal state as JSON on localhost for spreadsheets
+// and scripts. Every request except the OpenAPI description needs the token.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/logging"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+var logger = logging.For(logging.App)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Options configure the server
+type Options struct {
+	Token string
+	// Do runs fn where the app state may be used (the UI goroutine);
+	// defaults to calling fn directly
+	Do func(fn func())
+	// OnChange is called (inside Do) after a write, e.g. to refresh the UI
+	OnChange func()
+}
+
+// Server handles API requests against the app state
+type Server struct {
+	state *appcore.AppState
+	opts  Options
+	mux   *http.ServeMux
+}
+
+// New creates the API handler
+func New(state *appcore.AppState, opts Options) *Server {
+	if opts.Do == nil {
+		opts.Do = func(fn func()) { fn() }
+	}
+	s := &Server{state: state, opts: opts, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /api/v1/openapi.yaml", s.openAPI)
+	s.handle("GET /api/v1/trades", s.listTrades)
+	s.handle("GET /api/v1/trades/{id}", s.getTrade)
+	s.handle("PATCH /api/v1/trades/{id}", s.patchTrade)
+	s.handle("POST /api/v1/trades/{id}/close", s.closeTrade)
+	s.handle("GET /api/v1/heat", s.heat)
+	s.handle("GET /api/v1/stats", s.stats)
+	s.handle("GET /api/v1/policy", s.policy)
+	s.handle("GET /api/v1/settings", s.getSettings)
+	s.handle("PUT /api/v1/settings", s.putSettings)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Addr is the listen address for port: localhost only
+func Addr(port int) string {
+	return fmt.Sprintf("127.0.0.1:%d", port)
+}
+
+// Start listens on localhost:port and serves in the background. Stop it
+// with Shutdown.
+func (s *Server) Start(port int) (*http.Server, error) {
+	return Listen("Local API", s, port)
+}
+
+// Listen serves h on localhost:port in the background; name labels its log
+// entries and errors. Stop it with Shutdown.
+func Listen(name string, h http.Handler, port int) (*http.Server, error) {
+	listener, err := net.Listen("tcp", Addr(port))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(name+" stopped", "err", err)
+		}
+	}()
+	logger.Info(name+" listening", "addr", listener.Addr().String())
+	return srv, nil
+}
+
+// Shutdown stops srv, waiting briefly for requests in flight
+func Shutdown(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	srv.Shutdown(ctx)
+}
+
+// handle registers an authenticated handler
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tf-engine"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong bearer token"))
+			return
+		}
+		h(w, r)
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+// errorBody is the JSON of every error response
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorBody{Error: err.Error()})
+}
+
+// writeWriteError maps an error from a write to its status
+func writeWriteError(w http.ResponseWriter, err error) {
+	var invalid models.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		writeError(w, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, appcore.ErrTradeNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, appcore.ErrTradeNotActive), errors.Is(err, storage.ErrReadOnly):
+		writeError(w, http.StatusConflict, err)
+	default:
+		logger.Error("Local API write failed", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// decode reads a JSON body, rejecting unknown fields
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openAPISpec)
+}
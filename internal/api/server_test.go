@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/storage"
+)
+
+const testToken = "secret-token"
+
+func setupTestServer(t *testing.T) (*httptest.Server, *appcore.AppState, *int) {
+	t.Helper()
+	old := paths.Current()
+	saved := storage.TakeSnapshot()
+	paths.Set(paths.Under(t.TempDir(), paths.ModeFlag))
+	if _, err := storage.InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
+	t.Cleanup(func() {
+		paths.Set(old)
+		saved.Restore()
+	})
+
+	pnl := 300.0
+	trades := []models.Trade{
+		{ID: "T1", Ticker: "NVDA", Sector: "Technology", Strategy: "Alt10", Status: "active", MaxLoss: 100, Premium: 250,
+			CreatedAt: time.Now().AddDate(0, 0, -10)},
+		{ID: "T2", Ticker: "UNH", Sector: "Healthcare", Strategy: "Alt26", Status: "closed", ProfitLoss: &pnl},
+	}
+	if err := storage.SaveAllTrades(trades); err != nil {
+		t.Fatal(err)
+	}
+
+	state := appcore.NewAppState()
+	state.UseSafeMode()
+	if err := state.LoadProfileData(); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := 0
+	server := httptest.NewServer(New(state, Options{Token: testToken, OnChange: func() { changes++ }}))
+	t.Cleanup(server.Close)
+	return server, state, &changes
+}
+
+func request(t *testing.T, server *httptest.Server, method, path, body string) (*http.Response, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest(method, server.URL+"/api/v1"+path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	buf := &bytes.Buffer{}
+	buf.ReadFrom(resp.Body)
+	return resp, buf.Bytes()
+}
+
+func TestAuth(t *testing.T) {
+	server, _, _ := setupTestServer(t)
+
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req, _ := http.NewRequest("GET", server.URL+"/api/v1/trades", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: got %d, want 401", header, resp.StatusCode)
+		}
+	}
+
+	// The description is public
+	resp, err := server.Client().Get(server.URL + "/api/v1/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("openapi.yaml: got %d", resp.StatusCode)
+	}
+}
+
+func TestListAndGetTrades(t *testing.T) {
+	server, _, _ := setupTestServer(t)
+
+	resp, body := request(t, server, "GET", "/trades?status=closed", "")
+	var trades []models.Trade
+	if err := json.Unmarshal(body, &trades); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Status %d (%v): %s", resp.StatusCode, err, body)
+	}
+	if len(trades) != 1 || trades[0].ID != "T2" {
+		t.Errorf("Expected only T2, got %+v", trades)
+	}
+
+	if resp, body := request(t, server, "GET", "/trades/T1", ""); resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "NVDA") {
+		t.Errorf("Get T1: %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := request(t, server, "GET", "/trades/T9", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Get T9: got %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestPatchTrade_Validates(t *testing.T) {
+	server, state, changes := setupTestServer(t)
+
+	if resp, body := request(t, server, "PATCH", "/trades/T1", `{"status": "pending"}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Bad status: got %d %s", resp.StatusCode, body)
+	}
+	if resp, _ := request(t, server, "PATCH", "/trades/T1", `{"premium": 1}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unknown field: got %d, want 400", resp.StatusCode)
+	}
+
+	resp, body := request(t, server, "PATCH", "/trades/T1", `{"open_pnl": 42.5}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Patch: %d %s", resp.StatusCode, body)
+	}
+	if state.AllTrades[0].GetOpenPnL() != 42.5 || *changes != 1 {
+		t.Errorf("State not updated: %+v, %d changes", state.AllTrades[0], *changes)
+	}
+	if _, err := state.UndoTradeCommand(); err != nil || state.AllTrades[0].OpenPnL != nil {
+		t.Errorf("API edits should be undoable (%v): %+v", err, state.AllTrades[0])
+	}
+}
+
+func TestPatchTrade_KeepsChangesMadeElsewhere(t *testing.T) {
+	server, _, _ := setupTestServer(t)
+
+	// Another process (the CLI) changes the trade after this instance loaded it
+	_, err := storage.TradeStore{}.Update(func(trades []models.Trade) ([]models.Trade, error) {
+		edited := append([]models.Trade{}, trades...)
+		edited[0].Premium = 275
+		return edited, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, body := request(t, server, "PATCH", "/trades/T1", `{"open_pnl": 42.5}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Patch: %d %s", resp.StatusCode, body)
+	}
+	trades, _ := storage.LoadAllTrades()
+	if trades[0].Premium != 275 || trades[0].GetOpenPnL() != 42.5 {
+		t.Errorf("Expected both changes kept, got premium %.2f open P&L %.2f", trades[0].Premium, trades[0].GetOpenPnL())
+	}
+
+	if resp, _ := request(t, server, "PATCH", "/trades/T9", `{"open_pnl": 1}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Unknown trade: got %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestCloseTrade(t *testing.T) {
+	server, state, _ := setupTestServer(t)
+
+	if resp, _ := request(t, server, "POST", "/trades/T1/close", `{}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Missing exit_price: got %d, want 400", resp.StatusCode)
+	}
+
+	resp, body := request(t, server, "POST", "/trades/T1/close", `{"exit_price": 400}`)
+	var closed models.Trade
+	if err := json.Unmarshal(body, &closed); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Close: %d (%v) %s", resp.StatusCode, err, body)
+	}
+	if closed.Status != "closed" || closed.GetPnL() != 150 || state.AllTrades[0].Status != "closed" {
+		t.Errorf("Unexpected closed trade %+v", closed)
+	}
+	if state.Ledger.Equity() != state.Settings.AccountEquity+450 {
+		t.Errorf("Realized P&L not posted: equity %.2f", state.Ledger.Equity())
+	}
+
+	if resp, _ := request(t, server, "POST", "/trades/T1/close", `{"exit_price": 1}`); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Closing twice: got %d, want 409", resp.StatusCode)
+	}
+}
+
+func TestReports(t *testing.T) {
+	server, _, _ := setupTestServer(t)
+
+	_, body := request(t, server, "GET", "/heat", "")
+	var heat analytics.HeatReport
+	if err := json.Unmarshal(body, &heat); err != nil || heat.ActiveTrades != 1 || heat.OpenRisk != 100 {
+		t.Errorf("Unexpected heat (%v): %s", err, body)
+	}
+
+	_, body = request(t, server, "GET", "/stats", "")
+	var stats analytics.StatsReport
+	if err := json.Unmarshal(body, &stats); err != nil || stats.Overall.TotalTrades != 1 || stats.Overall.TotalPnL != 300 {
+		t.Errorf("Unexpected stats (%v): %s", err, body)
+	}
+
+	resp, body := request(t, server, "GET", "/policy", "")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"safe_mode": true`) {
+		t.Errorf("Unexpected policy: %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	server, state, _ := setupTestServer(t)
+
+	if resp, _ := request(t, server, "PUT", "/settings", `{"account_equity": -1}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Invalid settings: got %d, want 422", resp.StatusCode)
+	}
+
+	resp, body := request(t, server, "PUT", "/settings", `{"account_equity": 30000, "risk_per_trade": 0.01, "theme_mode": "night"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Put: %d %s", resp.StatusCode, body)
+	}
+	saved, err := storage.LoadSettings()
+	if err != nil || saved.AccountEquity != 30000 || state.Settings.ThemeMode != "night" {
+		t.Errorf("Settings not saved (%v): %+v", err, saved)
+	}
+
+	_, body = request(t, server, "GET", "/settings", "")
+	if !strings.Contains(string(body), `"risk_per_trade": 0.01`) {
+		t.Errorf("Unexpected settings: %s", body)
+	}
+}
+
+// TestOpenAPI_CoversRoutes keeps the description in step with the handlers
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	documented := regexp.MustCompile(`(?m)^  (/\S*):$`).FindAllStringSubmatch(string(openAPISpec), -1)
+	got := []string{}
+	for _, m := range documented {
+		got = append(got, m[1])
+	}
+	sort.Strings(got)
+
+	src, err := os.ReadFile(filepath.Join(".", "server.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := regexp.MustCompile(`"[A-Z]+ /api/v1(/[^"]*)"`).FindAllStringSubmatch(string(src), -1)
+	seen := map[string]bool{}
+	want := []string{}
+	for _, m := range routes {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			want = append(want, m[1])
+		}
+	}
+	sort.Strings(want)
+
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("openapi.yaml paths %v, handlers %v", got, want)
+	}
+}
+
+func TestToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", TokenFile)
+
+	first, err := LoadOrCreateToken(path)
+	if err != nil || len(first) != 64 {
+		t.Fatalf("Unexpected token %q (%v)", first, err)
+	}
+	if again, _ := LoadOrCreateToken(path); again != first {
+		t.Error("Token should persist")
+	}
+	if next, _ := RegenerateToken(path); next == first {
+		t.Error("Regenerated token should differ")
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("Token file mode %v, want 0600", info.Mode().Perm())
+	}
+}
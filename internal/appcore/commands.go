@@ -0,0 +1,63 @@
+package appcore
+
+import (
+	"fmt"
+
+	"tf-engine/internal/backup"
+	"tf-engine/internal/commands"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+// ExecuteTradeCommand applies a trade command through the undo stack, then
+// refreshes AllTrades and posts realized P&L to the ledger
+func (s *AppState) ExecuteTradeCommand(cmd commands.Command) error {
+	trades, err := s.commandStack().Execute(cmd, storage.TradeStore{})
+	if err != nil {
+		return err
+	}
+	return s.syncTrades(trades)
+}
+
+// UndoTradeCommand reverts the most recent trade command
+func (s *AppState) UndoTradeCommand() (commands.Command, error) {
+	cmd, trades, err := s.commandStack().Undo(storage.TradeStore{})
+	if err != nil {
+		return cmd, err
+	}
+	return cmd, s.syncTrades(trades)
+}
+
+// RedoTradeCommand re-applies the most recently undone trade command
+func (s *AppState) RedoTradeCommand() (commands.Command, error) {
+	cmd, trades, err := s.commandStack().Redo(storage.TradeStore{})
+	if err != nil {
+		return cmd, err
+	}
+	return cmd, s.syncTrades(trades)
+}
+
+// RestoreBackup replaces the trade history with a verified backup. The
+// replaced history is journaled and can be brought back with undo.
+func (s *AppState) RestoreBackup(info backup.Info) error {
+	trades, err := storage.LoadBackupTrades(info)
+	if err != nil {
+		return fmt.Errorf("cannot restore %s: %w", info.Name, err)
+	}
+	return s.ExecuteTradeCommand(commands.NewRestoreCommand(info.Name, trades))
+}
+
+// syncTrades keeps in-memory trades and the ledger in step with storage
+func (s *AppState) syncTrades(trades []models.Trade) error {
+	s.AllTrades = trades
+	return storage.PostRealizedPnL(s.Ledger, trades)
+}
+
+// commandStack returns the undo stack, creating it for states built without
+// NewAppState
+func (s *AppState) commandStack() *commands.Stack {
+	if s.Commands == nil {
+		s.Commands = commands.NewStack()
+	}
+	return s.Commands
+}
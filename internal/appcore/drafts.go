@@ -0,0 +1,89 @@
+package appcore
+
+import (
+	"fmt"
+	"time"
+
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+// SaveDraft saves the current trade as a draft left on the given workflow
+// step, with the screens Back returns through. An empty step keeps the
+// position the draft was last saved at.
+func (s *AppState) SaveDraft(step string, history []string) error {
+	if s.CurrentTrade == nil {
+		return nil // Nothing to save
+	}
+	if step == "" {
+		step, history = s.draftStep, s.draftHistory
+	}
+
+	draft := &models.Draft{Trade: *s.CurrentTrade, Step: step, History: history}
+	if err := storage.SaveDraft(draft); err != nil {
+		return err
+	}
+
+	s.CurrentTrade.ID = draft.Trade.ID
+	s.CurrentTrade.UpdatedAt = draft.Trade.UpdatedAt
+	s.draftStep = step
+	s.draftHistory = history
+	return nil
+}
+
+// ResumeDraft makes a saved draft the current trade and restores its
+// cooldown. The caller saves the previous current trade first.
+func (s *AppState) ResumeDraft(id string) (*models.Draft, error) {
+	draft, err := storage.LoadDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, fmt.Errorf("draft %s not found", id)
+	}
+
+	trade := draft.Trade
+	s.CurrentTrade = &trade
+	s.draftStep = draft.Step
+	s.draftHistory = draft.History
+	s.restoreCooldown()
+	return draft, nil
+}
+
+// StartNewDraft clears the current trade so the workflow starts a new one.
+// Other drafts are left as they are.
+func (s *AppState) StartNewDraft() {
+	s.CurrentTrade = nil
+	s.draftStep = ""
+	s.draftHistory = nil
+	s.restoreCooldown()
+}
+
+// DiscardDraft deletes a draft. Discarding the current trade clears it.
+func (s *AppState) DiscardDraft(id string) error {
+	if err := storage.DeleteDraft(id); err != nil {
+		return err
+	}
+	if s.CurrentTrade != nil && s.CurrentTrade.ID == id {
+		s.StartNewDraft()
+	}
+	return nil
+}
+
+// restoreCooldown sets the cooldown state from the current trade's own
+// cooldown start, so switching drafts can't skip or restart a cooldown
+func (s *AppState) restoreCooldown() {
+	s.CooldownActive = false
+	s.CooldownStart = nil
+	s.CooldownCompleted = false
+
+	if s.CurrentTrade == nil || s.CurrentTrade.CooldownStartTime.IsZero() {
+		return
+	}
+
+	start := s.CurrentTrade.CooldownStartTime
+	s.CooldownStart = &start
+	s.CooldownDuration = s.CooldownPeriod()
+	s.CooldownActive = time.Since(start) < s.CooldownDuration
+	s.CooldownCompleted = !s.CooldownActive
+}
@@ -0,0 +1,101 @@
+package appcore
+
+import (
+	"errors"
+	"fmt"
+
+	"tf-engine/internal/logging"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+var policyLogger = logging.For(logging.Policy)
+
+// LoadProfilePolicy loads the active profile's policy override, or the shared
+// policy at defaultPath. Activates safe mode if the policy cannot be loaded.
+// Returns the path that was used.
+func (s *AppState) LoadProfilePolicy(defaultPath string) (string, error) {
+	s.defaultPolicyPath = defaultPath
+
+	path := defaultPath
+	if s.Profile.PolicyFile != "" {
+		path = s.Profile.PolicyFile
+	}
+	if path == "" {
+		return "", nil // No policy location known; keep the current policy
+	}
+
+	s.SafeModeActive = false
+	s.policyPath = path
+	if err := s.LoadPolicy(path); err != nil {
+		policyLogger.Error("Failed to load policy, activating safe mode", "path", path, "err", err)
+		s.UseSafeMode()
+		return path, err
+	}
+	policyLogger.Info("Policy loaded", "path", path, "profile", s.Profile.ID)
+	return path, nil
+}
+
+// LoadProfileData loads the active profile's settings, trades and ledger.
+// Drafts stay on disk until one is resumed. Anything that fails to load falls back to defaults and
+// is reported in the returned error.
+func (s *AppState) LoadProfileData() error {
+	var errs []error
+
+	settings, err := storage.LoadSettings()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("settings: %w", err))
+		settings = models.DefaultSettings()
+	}
+	s.Settings = settings
+	logging.SetRedact(settings.PrivateLogs)
+
+	trades, err := storage.LoadAllTrades()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("trades: %w", err))
+		trades = []models.Trade{}
+	}
+	s.AllTrades = trades
+
+	// Ledger is created from the Settings equity on first use
+	s.Ledger = nil
+	ledger, err := storage.LoadOrCreateLedger(s.Settings.AccountEquity, s.AllTrades)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("ledger: %w", err))
+	} else {
+		if err := storage.PostRealizedPnL(ledger, s.AllTrades); err != nil {
+			errs = append(errs, fmt.Errorf("ledger: %w", err))
+		}
+		s.Ledger = ledger
+	}
+
+	// Move a trade left in the old single in-progress file into the drafts
+	if _, err := storage.LoadDrafts(); err != nil {
+		errs = append(errs, fmt.Errorf("drafts: %w", err))
+	}
+	s.StartNewDraft()
+
+	return errors.Join(errs...)
+}
+
+// SwitchProfile makes another profile active and reloads its policy and data.
+// The caller is responsible for saving the current in-progress trade first.
+func (s *AppState) SwitchProfile(id string) error {
+	profile, err := storage.SetActiveProfile(id)
+	if err != nil {
+		return err
+	}
+	s.Profile = profile
+
+	// Undo history applies to the previous profile's trades
+	s.commandStack().Clear()
+
+	var errs []error
+	if path, err := s.LoadProfilePolicy(s.defaultPolicyPath); err != nil {
+		errs = append(errs, fmt.Errorf("policy %s: %w", path, err))
+	}
+	if err := s.LoadProfileData(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -1,6 +1,7 @@
 package appcore
 
 import (
+	"tf-engine/internal/commands"
 	"tf-engine/internal/config"
 	"tf-engine/internal/models"
 	"time"
@@ -11,21 +12,33 @@ type AppState struct {
 	Policy            *models.Policy
 	FeatureFlags      *config.FeatureFlags
 	Settings          *models.Settings
+	Profile           models.Profile
+	Ledger            *models.Ledger
 	CurrentTrade      *models.Trade
 	CurrentScreen     string
 	AllTrades         []models.Trade
+	Commands          *commands.Stack // Undo/redo for trade edits (session only)
 	CooldownActive    bool
 	CooldownStart     *time.Time
 	CooldownDuration  time.Duration
 	CooldownCompleted bool
 	SafeModeActive    bool
+	AppName           string // Reported in diagnostics
+	AppVersion        string
+
+	defaultPolicyPath string   // Shared policy used when a profile has no override
+	policyPath        string   // Policy file last loaded (or attempted)
+	draftStep         string   // Workflow step the current trade was last saved on
+	draftHistory      []string // Back history saved with draftStep
 }
 
 // NewAppState creates a new application state
 func NewAppState() *AppState {
 	return &AppState{
 		Settings:      models.DefaultSettings(),
+		Profile:       models.DefaultProfileList().ActiveProfile(),
 		AllTrades:     []models.Trade{},
+		Commands:      commands.NewStack(),
 		CurrentScreen: "dashboard",
 	}
 }
@@ -40,25 +53,44 @@ func (s *AppState) LoadPolicy(path string) error {
 	return nil
 }
 
+// PolicyPath returns the policy file last loaded, or "" if none was
+func (s *AppState) PolicyPath() string {
+	return s.policyPath
+}
+
 // UseSafeMode activates safe mode with minimal policy
 func (s *AppState) UseSafeMode() {
 	s.Policy = models.SafeModePolicy()
 	s.SafeModeActive = true
 }
 
-// StartCooldown begins the anti-impulsivity timer
+// CurrentEquity returns account equity derived from the ledger, falling back
+// to the static Settings value when no ledger is loaded
+func (s *AppState) CurrentEquity() float64 {
+	if s.Ledger != nil {
+		return s.Ledger.Equity()
+	}
+	if s.Settings != nil {
+		return s.Settings.AccountEquity
+	}
+	return 0
+}
+
+// StartCooldown begins the anti-impulsivity timer. A trade's cooldown runs
+// once: if it already started (before going back, or before a restart) it
+// continues from the saved start instead of resetting.
 func (s *AppState) StartCooldown() {
+	if s.CurrentTrade != nil && !s.CurrentTrade.CooldownStartTime.IsZero() {
+		s.restoreCooldown()
+		return
+	}
+
 	now := time.Now()
 	s.CooldownStart = &now
 	s.CooldownActive = true
 	s.CooldownCompleted = false
 
-	// Get duration from policy, default to 300 seconds (5 minutes)
-	if s.Policy != nil && s.Policy.Defaults.CooldownSeconds > 0 {
-		s.CooldownDuration = time.Duration(s.Policy.Defaults.CooldownSeconds) * time.Second
-	} else {
-		s.CooldownDuration = 300 * time.Second
-	}
+	s.CooldownDuration = s.CooldownPeriod()
 
 	// Also set cooldown start time in current trade for persistence
 	if s.CurrentTrade != nil {
@@ -66,6 +98,15 @@ func (s *AppState) StartCooldown() {
 	}
 }
 
+// CooldownPeriod returns the cooldown from the policy, defaulting to 300
+// seconds (5 minutes)
+func (s *AppState) CooldownPeriod() time.Duration {
+	if s.Policy != nil && s.Policy.Defaults.CooldownSeconds > 0 {
+		return time.Duration(s.Policy.Defaults.CooldownSeconds) * time.Second
+	}
+	return 300 * time.Second
+}
+
 // IsCooldownComplete checks if cooldown has expired
 func (s *AppState) IsCooldownComplete() bool {
 	if s.CooldownStart == nil {
@@ -0,0 +1,98 @@
+package appcore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tf-engine/internal/commands"
+	"tf-engine/internal/logging"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+// Errors from trade updates
+var (
+	ErrTradeNotFound  = errors.New("trade not found")
+	ErrTradeNotActive = errors.New("trade is not active")
+)
+
+// UpdateTrade validates an edited trade and saves it through the undo stack
+func (s *AppState) UpdateTrade(after models.Trade) error {
+	if err := after.Validate(); err != nil {
+		return err
+	}
+	before, err := findTrade(after.ID)
+	if err != nil {
+		return err
+	}
+	return s.ExecuteTradeCommand(commands.NewEditCommand(before, after))
+}
+
+// PatchTrade applies patch to the stored version of a trade through the undo
+// stack and returns the result
+func (s *AppState) PatchTrade(id string, patch func(*models.Trade)) (models.Trade, error) {
+	cmd := commands.NewPatchCommand(id, patch)
+	if err := s.ExecuteTradeCommand(cmd); err != nil {
+		if errors.Is(err, commands.ErrTradeNotFound) {
+			return models.Trade{}, fmt.Errorf("%w: %s", ErrTradeNotFound, id)
+		}
+		return models.Trade{}, err
+	}
+	return cmd.After, nil
+}
+
+// CloseTrade closes an active trade at exitPrice (total dollars). The
+// realized P&L defaults to the exit price minus the premium.
+func (s *AppState) CloseTrade(id string, exitPrice float64, exitDate time.Time, pnl *float64) (models.Trade, error) {
+	before, err := findTrade(id)
+	if err != nil {
+		return models.Trade{}, err
+	}
+	if status := before.GetStatus(); status != "active" {
+		return models.Trade{}, fmt.Errorf("%w: %s is already %s", ErrTradeNotActive, id, status)
+	}
+
+	realized := exitPrice - before.Premium
+	if pnl != nil {
+		realized = *pnl
+	}
+	after := before
+	after.ExitDate = &exitDate
+	after.ExitPrice = &exitPrice
+	after.ProfitLoss = &realized
+	after.OpenPnL = nil
+	after.Status = "closed"
+	after.UpdatedAt = time.Now()
+	if err := s.UpdateTrade(after); err != nil {
+		return models.Trade{}, err
+	}
+	return after, nil
+}
+
+// SaveSettings validates settings, saves them and makes them current
+func (s *AppState) SaveSettings(settings *models.Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if err := storage.SaveSettings(settings); err != nil {
+		return err
+	}
+	s.Settings = settings
+	logging.SetRedact(settings.PrivateLogs)
+	return nil
+}
+
+// findTrade returns the saved version of a trade
+func findTrade(id string) (models.Trade, error) {
+	trades, err := storage.LoadAllTrades()
+	if err != nil {
+		return models.Trade{}, fmt.Errorf("failed to load trades: %w", err)
+	}
+	for _, t := range trades {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return models.Trade{}, fmt.Errorf("%w: %s", ErrTradeNotFound, id)
+}
@@ -0,0 +1,487 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"tf-engine/internal/filelock"
+	"tf-engine/internal/models"
+)
+
+// EventType identifies what happened to a trade
+type EventType string
+
+// Journaled trade mutations
+const (
+	EventCreated   EventType = "trade_created"
+	EventChecklist EventType = "checklist"
+	EventSizing    EventType = "sizing"
+	EventEdited    EventType = "trade_edited"
+	EventClosed    EventType = "trade_closed"
+	EventDeleted   EventType = "trade_deleted"
+	EventReverted  EventType = "write_reverted" // A journaled change that never reached the trade file
+)
+
+// ErrChainBroken is returned when a journal entry's hash does not match
+var ErrChainBroken = errors.New("audit journal hash chain broken")
+
+// FieldChange records one trade field before and after a mutation. Values
+// are the field's JSON encoding; an empty value means the field was absent.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Event is one entry in the hash-chained journal
+type Event struct {
+	Seq      int           `json:"seq"`
+	Time     time.Time     `json:"time"`
+	Type     EventType     `json:"type"`
+	TradeID  string        `json:"trade_id"`
+	Actor    string        `json:"actor"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+	PrevHash string        `json:"prev_hash"`
+	Hash     string        `json:"hash"`
+}
+
+// journalMu serializes appends so sequence numbers and hashes stay chained.
+// The journal's lock file does the same across processes.
+var journalMu sync.Mutex
+
+// lockPath is the lock file guarding the journal at path
+func lockPath(path string) string {
+	return path + ".lock"
+}
+
+// Codec encrypts journal lines at rest. Decode must reverse Encode.
+type Codec interface {
+	Encode(data []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+var (
+	codecMu sync.RWMutex
+	codec   Codec
+)
+
+// SetCodec sets how new journal lines are encoded. Nil writes plaintext JSON.
+// Lines are read back by their format, so a journal may mix both.
+func SetCodec(c Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codec = c
+}
+
+func currentCodec() Codec {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	return codec
+}
+
+// Append adds an event to the journal at path, filling in its sequence
+// number, time, actor (if empty) and hashes. Returns the stored event.
+func Append(path string, e Event) (Event, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	fl, err := filelock.Exclusive(lockPath(path))
+	if err != nil {
+		return Event{}, err
+	}
+	defer fl.Unlock()
+
+	events, err := load(path)
+	if err != nil {
+		return Event{}, err
+	}
+
+	e.Seq = 1
+	e.PrevHash = ""
+	if n := len(events); n > 0 {
+		e.Seq = events[n-1].Seq + 1
+		e.PrevHash = events[n-1].Hash
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	if e.Actor == "" {
+		e.Actor = CurrentActor()
+	}
+
+	e.Hash, err = hashEvent(e)
+	if err != nil {
+		return Event{}, err
+	}
+
+	line, err := encodeLine(e, currentCodec())
+	if err != nil {
+		return Event{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Event{}, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return Event{}, fmt.Errorf("open error: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return Event{}, fmt.Errorf("write error: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return Event{}, fmt.Errorf("sync error: %w", err)
+	}
+
+	return e, nil
+}
+
+// Load reads every event from the journal. A missing journal is empty.
+func Load(path string) ([]Event, error) {
+	fl, err := filelock.Shared(lockPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer fl.Unlock()
+	return load(path)
+}
+
+// load reads the journal without locking (caller holds the lock)
+func load(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open error: %w", err)
+	}
+	defer f.Close()
+
+	events := []Event{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		e, err := decodeLine(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+
+	return events, nil
+}
+
+// Write replaces the journal with events, encoding every line with the
+// current codec (used when encryption is turned on, off or re-keyed). Hashes
+// are kept as they are.
+func Write(path string, events []Event) error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	fl, err := filelock.Exclusive(lockPath(path))
+	if err != nil {
+		return err
+	}
+	defer fl.Unlock()
+
+	data, err := Encode(events, currentCodec())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename error: %w", err)
+	}
+	return nil
+}
+
+// Encode returns events as journal lines encoded with c (nil for plaintext),
+// the way Write stores them
+func Encode(events []Event, c Codec) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, e := range events {
+		line, err := encodeLine(e, c)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeLine marshals an event, then encodes it as base64 when a codec is set
+func encodeLine(e Event, c Codec) ([]byte, error) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+
+	if c == nil {
+		return line, nil
+	}
+	sealed, err := c.Encode(line)
+	if err != nil {
+		return nil, fmt.Errorf("encode error: %w", err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decodeLine parses a plaintext JSON line or a base64 encoded one
+func decodeLine(line []byte) (Event, error) {
+	var e Event
+
+	line = bytes.TrimSpace(line)
+	if !bytes.HasPrefix(line, []byte("{")) {
+		c := currentCodec()
+		if c == nil {
+			return e, errors.New("line is encrypted")
+		}
+		sealed, err := base64.StdEncoding.DecodeString(string(line))
+		if err != nil {
+			return e, err
+		}
+		if line, err = c.Decode(sealed); err != nil {
+			return e, err
+		}
+	}
+
+	err := json.Unmarshal(line, &e)
+	return e, err
+}
+
+// Verify checks sequence numbers and the hash chain. The error identifies
+// the first entry that was altered, removed or reordered.
+func Verify(events []Event) error {
+	prevHash := ""
+	for i, e := range events {
+		if e.Seq != i+1 {
+			return fmt.Errorf("%w: entry %d has sequence %d", ErrChainBroken, i+1, e.Seq)
+		}
+		if e.PrevHash != prevHash {
+			return fmt.Errorf("%w: entry %d does not follow entry %d", ErrChainBroken, e.Seq, i)
+		}
+		hash, err := hashEvent(e)
+		if err != nil {
+			return err
+		}
+		if hash != e.Hash {
+			return fmt.Errorf("%w: entry %d was modified", ErrChainBroken, e.Seq)
+		}
+		prevHash = e.Hash
+	}
+	return nil
+}
+
+// History returns the events for one trade, oldest first
+func History(events []Event, tradeID string) []Event {
+	history := []Event{}
+	for _, e := range events {
+		if e.TradeID == tradeID {
+			history = append(history, e)
+		}
+	}
+	return history
+}
+
+// Rebuild replays the journal and returns the trades that were created and
+// not deleted, in creation order. Checklist and sizing events for trades
+// that were never saved are ignored; a reverted creation or deletion is
+// undone.
+func Rebuild(events []Event) ([]models.Trade, error) {
+	fields := map[string]map[string]json.RawMessage{}
+	order := []string{}
+	ordered := map[string]bool{}
+	create := func(e Event) {
+		if !ordered[e.TradeID] {
+			ordered[e.TradeID] = true
+			order = append(order, e.TradeID)
+		}
+		fields[e.TradeID] = map[string]json.RawMessage{}
+		applyChanges(fields[e.TradeID], e.Changes)
+	}
+
+	for _, e := range events {
+		switch e.Type {
+		case EventCreated:
+			create(e)
+		case EventDeleted:
+			delete(fields, e.TradeID)
+		case EventReverted:
+			switch {
+			case every(e.Changes, func(c FieldChange) bool { return len(c.After) == 0 }):
+				// Undoes a creation
+				delete(fields, e.TradeID)
+			case every(e.Changes, func(c FieldChange) bool { return len(c.Before) == 0 }):
+				// Undoes a deletion
+				create(e)
+			default:
+				if trade, exists := fields[e.TradeID]; exists {
+					applyChanges(trade, e.Changes)
+				}
+			}
+		default:
+			if trade, exists := fields[e.TradeID]; exists {
+				applyChanges(trade, e.Changes)
+			}
+		}
+	}
+
+	trades := []models.Trade{}
+	for _, id := range order {
+		trade, exists := fields[id]
+		if !exists {
+			continue
+		}
+		data, err := json.Marshal(trade)
+		if err != nil {
+			return nil, fmt.Errorf("trade %s: %w", id, err)
+		}
+		var t models.Trade
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("trade %s: %w", id, err)
+		}
+		trades = append(trades, t)
+	}
+
+	return trades, nil
+}
+
+// Diff returns the field-level changes from before to after. A nil before
+// records every field of a new trade; a nil after records a deletion.
+func Diff(before, after *models.Trade) ([]FieldChange, error) {
+	b, err := tradeFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := tradeFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range b {
+		names[name] = true
+	}
+	for name := range a {
+		names[name] = true
+	}
+
+	changes := []FieldChange{}
+	for name := range names {
+		if bytes.Equal(b[name], a[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: b[name], After: a[name]})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// Fields records the current values of selected trade fields (by JSON name)
+func Fields(trade *models.Trade, names ...string) ([]FieldChange, error) {
+	values, err := tradeFields(trade)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			changes = append(changes, FieldChange{Field: name, After: value})
+		}
+	}
+	return changes, nil
+}
+
+// CurrentActor identifies who is making changes (the OS user)
+func CurrentActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// hashEvent hashes the event with its own hash cleared
+func hashEvent(e Event) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("marshal error: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// tradeFields returns a trade's JSON fields. A nil trade has no fields.
+func tradeFields(trade *models.Trade) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if trade == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(trade)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return fields, nil
+}
+
+// every reports whether there are changes and all of them satisfy ok
+func every(changes []FieldChange, ok func(FieldChange) bool) bool {
+	for _, c := range changes {
+		if !ok(c) {
+			return false
+		}
+	}
+	return len(changes) > 0
+}
+
+func applyChanges(fields map[string]json.RawMessage, changes []FieldChange) {
+	for _, c := range changes {
+		if len(c.After) == 0 {
+			delete(fields, c.Field)
+		} else {
+			fields[c.Field] = c.After
+		}
+	}
+}
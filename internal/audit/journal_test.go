@@ -0,0 +1,237 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func appendDiff(t *testing.T, path string, eventType EventType, before, after *models.Trade) Event {
+	t.Helper()
+
+	id := ""
+	if after != nil {
+		id = after.ID
+	} else {
+		id = before.ID
+	}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	e, err := Append(path, Event{Type: eventType, TradeID: id, Actor: "tester", Changes: changes})
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	return e
+}
+
+func TestAppend_ChainsEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	trade := &models.Trade{ID: "T1", Ticker: "AAPL", Status: "active"}
+
+	first := appendDiff(t, path, EventCreated, nil, trade)
+	edited := *trade
+	edited.Ticker = "MSFT"
+	second := appendDiff(t, path, EventEdited, trade, &edited)
+
+	if first.Seq != 1 || second.Seq != 2 {
+		t.Errorf("Expected sequences 1,2, got %d,%d", first.Seq, second.Seq)
+	}
+	if second.PrevHash != first.Hash {
+		t.Error("Second event should chain to the first")
+	}
+
+	events, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := Verify(events); err != nil {
+		t.Errorf("Untouched journal should verify: %v", err)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	pnl := 250.0
+	trade := &models.Trade{ID: "T1", Ticker: "AAPL", ProfitLoss: &pnl}
+	appendDiff(t, path, EventCreated, nil, trade)
+
+	edited := *trade
+	better := 900.0
+	edited.ProfitLoss = &better
+	appendDiff(t, path, EventEdited, trade, &edited)
+
+	// Rewrite the P&L in the first entry
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), `"after":250`, `"after":2500`, 1)
+	os.WriteFile(path, []byte(tampered), 0644)
+
+	events, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	err = Verify(events)
+	if !errors.Is(err, ErrChainBroken) {
+		t.Fatalf("Expected ErrChainBroken, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "entry 1") {
+		t.Errorf("Error should name the altered entry, got: %v", err)
+	}
+
+	// Dropping an entry is also detected
+	if err := Verify(events[1:]); !errors.Is(err, ErrChainBroken) {
+		t.Errorf("Expected ErrChainBroken for missing entry, got %v", err)
+	}
+}
+
+func TestRebuild_ReplaysEditsAndDeletes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	created := time.Date(2025, 4, 1, 14, 30, 0, 0, time.UTC)
+
+	a := &models.Trade{ID: "A", Ticker: "AAPL", CreatedAt: created, Status: "active"}
+	b := &models.Trade{ID: "B", Ticker: "XOM", CreatedAt: created, Status: "active"}
+
+	// Sizing for a trade that was never saved is ignored
+	sizing, _ := Fields(&models.Trade{ID: "draft", MaxLoss: 500}, "max_loss")
+	Append(path, Event{Type: EventSizing, TradeID: "draft", Changes: sizing})
+
+	appendDiff(t, path, EventCreated, nil, a)
+	appendDiff(t, path, EventCreated, nil, b)
+
+	closed := *a
+	pnl := -120.0
+	exit := created.AddDate(0, 0, 5)
+	closed.ProfitLoss = &pnl
+	closed.ExitDate = &exit
+	closed.Status = "closed"
+	appendDiff(t, path, EventClosed, a, &closed)
+	appendDiff(t, path, EventDeleted, b, nil)
+
+	events, _ := Load(path)
+	trades, err := Rebuild(events)
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	if len(trades) != 1 {
+		t.Fatalf("Expected 1 trade after delete, got %d", len(trades))
+	}
+	got := trades[0]
+	if got.ID != "A" || got.Status != "closed" || got.GetPnL() != -120 {
+		t.Errorf("Unexpected rebuilt trade: %+v", got)
+	}
+	if got.ExitDate == nil || !got.ExitDate.Equal(exit) {
+		t.Errorf("Expected exit date %v, got %v", exit, got.ExitDate)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("Expected created_at %v, got %v", created, got.CreatedAt)
+	}
+}
+
+func TestRebuild_UndoesRevertedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a := &models.Trade{ID: "A", Ticker: "AAPL", Status: "active"}
+	b := &models.Trade{ID: "B", Ticker: "XOM", Status: "active"}
+	edited := *a
+	edited.Premium = 4.5
+
+	appendDiff(t, path, EventCreated, nil, a)
+	// A creation, a deletion and an edit that never reached the trade file
+	appendDiff(t, path, EventCreated, nil, b)
+	appendDiff(t, path, EventReverted, b, nil)
+	appendDiff(t, path, EventDeleted, a, nil)
+	appendDiff(t, path, EventReverted, nil, a)
+	appendDiff(t, path, EventEdited, a, &edited)
+	appendDiff(t, path, EventReverted, &edited, a)
+
+	events, _ := Load(path)
+	trades, err := Rebuild(events)
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if len(trades) != 1 || trades[0].ID != "A" || trades[0].Ticker != "AAPL" || trades[0].Premium != 0 {
+		t.Errorf("Expected only the original trade A, got %+v", trades)
+	}
+}
+
+func TestDiff_RecordsBeforeAndAfter(t *testing.T) {
+	before := &models.Trade{ID: "A", Ticker: "AAPL"}
+	pnl := 75.0
+	after := &models.Trade{ID: "A", Ticker: "AAPL", ProfitLoss: &pnl}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Field != "profit_loss" {
+		t.Fatalf("Expected only profit_loss to change, got %+v", changes)
+	}
+	if len(changes[0].Before) != 0 || string(changes[0].After) != "75" {
+		t.Errorf("Expected absent → 75, got %s → %s", changes[0].Before, changes[0].After)
+	}
+
+	history := History([]Event{{TradeID: "A"}, {TradeID: "B"}, {TradeID: "A"}}, "A")
+	if len(history) != 2 {
+		t.Errorf("Expected 2 events for trade A, got %d", len(history))
+	}
+}
+
+// xorCodec is a stand-in for encryption in tests
+type xorCodec struct{}
+
+func (xorCodec) Encode(data []byte) ([]byte, error) { return xor(data), nil }
+func (xorCodec) Decode(data []byte) ([]byte, error) { return xor(data), nil }
+
+func xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0x5A
+	}
+	return out
+}
+
+func TestCodec_MixedJournalAndRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	trade := &models.Trade{ID: "T1", Ticker: "AAPL", Status: "active"}
+
+	// One plaintext line, then encoded lines
+	appendDiff(t, path, EventCreated, nil, trade)
+	SetCodec(xorCodec{})
+	defer SetCodec(nil)
+	edited := *trade
+	edited.Ticker = "MSFT"
+	appendDiff(t, path, EventEdited, trade, &edited)
+
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), "MSFT") != 0 {
+		t.Error("Encoded lines should not contain plaintext")
+	}
+
+	events, err := Load(path)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Load of mixed journal failed: %d events, %v", len(events), err)
+	}
+	if err := Verify(events); err != nil {
+		t.Errorf("Mixed journal should verify: %v", err)
+	}
+
+	// Rewriting encodes every line; without the codec it can't be read
+	if err := Write(path, events); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "AAPL") {
+		t.Error("Rewritten journal should be fully encoded")
+	}
+	SetCodec(nil)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error loading an encoded journal without a codec")
+	}
+}
@@ -0,0 +1,408 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File names inside a backup archive
+const (
+	manifestName = "manifest.json"
+	payloadName  = "trades.json"
+	timeLayout   = "20060102_150405"
+	filePrefix   = "trades_"
+)
+
+// ErrChecksumMismatch is returned when a backup's contents don't match its manifest
+var ErrChecksumMismatch = errors.New("backup checksum mismatch")
+
+// Retention is how many backups to keep per bucket. The newest backup in each
+// of the last Hourly hours, Daily days and Weekly weeks is kept. A zero
+// Retention keeps everything.
+type Retention struct {
+	Hourly int
+	Daily  int
+	Weekly int
+}
+
+// DefaultRetention keeps a day of hourly, a week of daily and two months of
+// weekly backups
+var DefaultRetention = Retention{Hourly: 24, Daily: 7, Weekly: 8}
+
+// Manifest describes the trade file stored in an archive
+type Manifest struct {
+	Created time.Time `json:"created"`
+	File    string    `json:"file"`
+	SHA256  string    `json:"sha256"`
+	Trades  int       `json:"trades"`
+}
+
+// Info describes one backup file
+type Info struct {
+	Name   string
+	Path   string
+	Time   time.Time
+	Size   int64
+	Legacy bool // Plain .json copy written by older versions
+}
+
+// Result is the outcome of verifying one backup
+type Result struct {
+	Info   Info
+	Trades int
+	Err    error
+}
+
+// Codec encodes trade files for storage (e.g. encryption). Decode must
+// accept data Encode produced as well as plaintext.
+type Codec interface {
+	Encode(data []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// Manager creates, lists, verifies and prunes backups in a directory
+type Manager struct {
+	Dir       string
+	Retention Retention
+	Now       func() time.Time // Injectable clock for tests
+	Codec     Codec            // Nil stores plaintext
+}
+
+// New creates a manager for dir with the default retention policy
+func New(dir string) *Manager {
+	return &Manager{Dir: dir, Retention: DefaultRetention, Now: time.Now}
+}
+
+// Create archives src with a checksum manifest and verifies the archive. It
+// does not prune; see Prune.
+func (m *Manager) Create(src string) (Info, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return Info{}, fmt.Errorf("read error: %w", err)
+	}
+
+	plaintext, err := m.decode(data)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info, err := m.write(m.now(), plaintext)
+	if err != nil {
+		return Info{}, err
+	}
+	if _, err := m.Verify(info); err != nil {
+		return Info{}, fmt.Errorf("verify error: %w", err)
+	}
+	return info, nil
+}
+
+// Rewrite re-archives a backup's trade file with the manager's codec,
+// keeping its timestamp. Legacy .json backups are converted to archives.
+func (m *Manager) Rewrite(info Info, plaintext []byte) (Info, error) {
+	rewritten, err := m.write(info.Time, plaintext)
+	if err != nil {
+		return Info{}, err
+	}
+	if info.Legacy {
+		if err := os.Remove(info.Path); err != nil {
+			return rewritten, fmt.Errorf("failed to remove %s: %w", info.Name, err)
+		}
+	}
+	return rewritten, nil
+}
+
+// write stores a trade file as trades_<created>.zip
+func (m *Manager) write(created time.Time, plaintext []byte) (Info, error) {
+	count, err := countTrades(plaintext)
+	if err != nil {
+		return Info{}, fmt.Errorf("refusing to back up unparseable file: %w", err)
+	}
+
+	payload, err := m.encode(plaintext)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return Info{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	sum := sha256.Sum256(payload)
+	manifest, err := json.MarshalIndent(Manifest{
+		Created: created,
+		File:    payloadName,
+		SHA256:  hex.EncodeToString(sum[:]),
+		Trades:  count,
+	}, "", "  ")
+	if err != nil {
+		return Info{}, fmt.Errorf("marshal error: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{manifestName, manifest}, {payloadName, payload}} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: created})
+		if err != nil {
+			return Info{}, fmt.Errorf("zip error: %w", err)
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return Info{}, fmt.Errorf("zip error: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return Info{}, fmt.Errorf("zip error: %w", err)
+	}
+
+	name := filePrefix + created.Format(timeLayout) + ".zip"
+	path := filepath.Join(m.Dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return Info{}, fmt.Errorf("write error: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Info{}, fmt.Errorf("rename error: %w", err)
+	}
+
+	return Info{Name: name, Path: path, Time: created, Size: int64(buf.Len())}, nil
+}
+
+// List returns all backups, newest first. Files that don't look like backups
+// are ignored.
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if os.IsNotExist(err) {
+		return []Info{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+
+	backups := []Info{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, ok := parseName(e.Name())
+		if !ok {
+			continue
+		}
+		if fi, err := e.Info(); err == nil {
+			info.Size = fi.Size()
+		}
+		info.Path = filepath.Join(m.Dir, e.Name())
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// Read returns the trade file stored in a backup after verifying it
+func (m *Manager) Read(info Info) ([]byte, error) {
+	data, _, err := m.read(info)
+	return data, err
+}
+
+// Verify checks one backup and returns its trade count
+func (m *Manager) Verify(info Info) (int, error) {
+	_, count, err := m.read(info)
+	return count, err
+}
+
+// VerifyAll checks every backup, newest first
+func (m *Manager) VerifyAll() ([]Result, error) {
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(backups))
+	for _, b := range backups {
+		count, err := m.Verify(b)
+		results = append(results, Result{Info: b, Trades: count, Err: err})
+	}
+	return results, nil
+}
+
+// Prune deletes backups not kept by the retention policy and returns their names
+func (m *Manager) Prune() ([]string, error) {
+	r := m.Retention
+	if r.Hourly <= 0 && r.Daily <= 0 && r.Weekly <= 0 {
+		return nil, nil
+	}
+
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	hours, days, weeks := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	removed := []string{}
+	for _, b := range backups {
+		keep := false
+
+		t := b.Time.Local()
+		year, week := t.ISOWeek()
+		buckets := []struct {
+			seen  map[string]bool
+			key   string
+			limit int
+		}{
+			{hours, t.Format("2006-01-02T15"), r.Hourly},
+			{days, t.Format("2006-01-02"), r.Daily},
+			{weeks, fmt.Sprintf("%d-W%02d", year, week), r.Weekly},
+		}
+		for _, bucket := range buckets {
+			if !bucket.seen[bucket.key] && len(bucket.seen) < bucket.limit {
+				bucket.seen[bucket.key] = true
+				keep = true
+			}
+		}
+
+		if keep {
+			continue
+		}
+		if err := os.Remove(b.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", b.Name, err)
+		}
+		removed = append(removed, b.Name)
+	}
+
+	return removed, nil
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+func (m *Manager) encode(data []byte) ([]byte, error) {
+	if m.Codec == nil {
+		return data, nil
+	}
+	return m.Codec.Encode(data)
+}
+
+func (m *Manager) decode(data []byte) ([]byte, error) {
+	if m.Codec == nil {
+		return data, nil
+	}
+	return m.Codec.Decode(data)
+}
+
+// read loads and verifies a backup's trade file
+func (m *Manager) read(info Info) ([]byte, int, error) {
+	if info.Legacy {
+		raw, err := os.ReadFile(info.Path)
+		if err != nil {
+			return nil, 0, fmt.Errorf("read error: %w", err)
+		}
+		data, err := m.decode(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+		count, err := countTrades(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("unparseable backup: %w", err)
+		}
+		return data, count, nil
+	}
+
+	zr, err := zip.OpenReader(info.Path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("corrupt archive: %w", err)
+	}
+	defer zr.Close()
+
+	var manifest Manifest
+	manifestData, err := readZipFile(&zr.Reader, manifestName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, 0, fmt.Errorf("unparseable manifest: %w", err)
+	}
+
+	payload, err := readZipFile(&zr.Reader, manifest.File)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sum := sha256.Sum256(payload)
+	if hex.EncodeToString(sum[:]) != manifest.SHA256 {
+		return nil, 0, ErrChecksumMismatch
+	}
+
+	data, err := m.decode(payload)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := countTrades(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unparseable backup: %w", err)
+	}
+	if count != manifest.Trades {
+		return nil, 0, fmt.Errorf("%w: manifest lists %d trades, file has %d", ErrChecksumMismatch, manifest.Trades, count)
+	}
+
+	return data, count, nil
+}
+
+// readZipFile reads one file from an archive (the zip reader checks its CRC)
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt archive: missing %s", name)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt archive: %w", err)
+	}
+	return data, nil
+}
+
+// countTrades checks that data is a JSON array and returns its length
+func countTrades(data []byte) (int, error) {
+	var trades []json.RawMessage
+	if err := json.Unmarshal(data, &trades); err != nil {
+		return 0, err
+	}
+	return len(trades), nil
+}
+
+// parseName recognizes trades_YYYYMMDD_HHMMSS.zip and legacy .json backups
+func parseName(name string) (Info, bool) {
+	ext := filepath.Ext(name)
+	if (ext != ".zip" && ext != ".json") || !strings.HasPrefix(name, filePrefix) {
+		return Info{}, false
+	}
+
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), ext)
+	t, err := time.ParseInLocation(timeLayout, stamp, time.Local)
+	if err != nil {
+		return Info{}, false
+	}
+
+	return Info{Name: name, Time: t, Legacy: ext == ".json"}, true
+}
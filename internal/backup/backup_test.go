@@ -0,0 +1,226 @@
+package backup
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTrades(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "trades.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCreateListVerify(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTrades(t, dir, `[{"id":"1"},{"id":"2"}]`)
+
+	m := New(filepath.Join(dir, "backups"))
+	m.Now = func() time.Time { return time.Date(2026, 3, 2, 10, 30, 0, 0, time.Local) }
+
+	info, err := m.Create(src)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if info.Name != "trades_20260302_103000.zip" {
+		t.Errorf("Unexpected name %s", info.Name)
+	}
+
+	backups, err := m.List()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %d (%v)", len(backups), err)
+	}
+
+	count, err := m.Verify(backups[0])
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 trades, got %d", count)
+	}
+
+	data, err := m.Read(backups[0])
+	if err != nil || string(data) != `[{"id":"1"},{"id":"2"}]` {
+		t.Errorf("Read returned %q, %v", data, err)
+	}
+}
+
+func TestCreate_RefusesUnparseableSource(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTrades(t, dir, `{not json`)
+
+	if _, err := New(dir).Create(src); err == nil {
+		t.Error("Expected error backing up a corrupt file")
+	}
+}
+
+func TestVerifyAll_ReportsCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTrades(t, dir, `[{"id":"1"}]`)
+	backupDir := filepath.Join(dir, "backups")
+
+	m := New(backupDir)
+	m.Retention = Retention{}
+	m.Now = func() time.Time { return time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local) }
+	good, _ := m.Create(src)
+
+	// Same payload with a tampered manifest checksum
+	m.Now = func() time.Time { return time.Date(2026, 3, 2, 11, 0, 0, 0, time.Local) }
+	writeTrades(t, dir, `[{"id":"1"},{"id":"2"}]`)
+	tampered, _ := m.Create(src)
+	swapPayload(t, tampered.Path)
+	data, _ := os.ReadFile(good.Path)
+
+	// Truncated zip and an unparseable legacy copy
+	os.WriteFile(filepath.Join(backupDir, "trades_20260302_120000.zip"), data[:len(data)/2], 0644)
+	os.WriteFile(filepath.Join(backupDir, "trades_20260302_130000.json"), []byte(`[{`), 0644)
+	os.WriteFile(filepath.Join(backupDir, "trades_20260302_140000.json"), []byte(`[{"id":"1"}]`), 0644)
+	os.WriteFile(filepath.Join(backupDir, "notes.txt"), []byte(`ignored`), 0644)
+
+	results, err := m.VerifyAll()
+	if err != nil {
+		t.Fatalf("VerifyAll failed: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Expected 5 backups, got %d", len(results))
+	}
+
+	byName := map[string]Result{}
+	for _, r := range results {
+		byName[r.Info.Name] = r
+	}
+	if r := byName["trades_20260302_140000.json"]; r.Err != nil || r.Trades != 1 || !r.Info.Legacy {
+		t.Errorf("Legacy backup should verify: %+v", r)
+	}
+	if r := byName["trades_20260302_130000.json"]; r.Err == nil {
+		t.Error("Unparseable legacy backup should fail")
+	}
+	if r := byName["trades_20260302_120000.zip"]; r.Err == nil {
+		t.Error("Truncated archive should fail")
+	}
+	if r := byName[tampered.Name]; !errors.Is(r.Err, ErrChecksumMismatch) {
+		t.Errorf("Tampered archive should fail checksum, got %v", r.Err)
+	}
+	if r := byName[good.Name]; r.Err != nil {
+		t.Errorf("Good archive should verify: %v", r.Err)
+	}
+}
+
+// swapPayload rewrites an archive keeping its manifest but changing the payload
+func swapPayload(t *testing.T, path string) {
+	t.Helper()
+	dir := t.TempDir()
+	src := writeTrades(t, dir, `[{"id":"other"}]`)
+
+	m := &Manager{Dir: dir, Now: func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local) }}
+	other, err := m.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep the original manifest, take the other archive's payload
+	manifest := readEntry(t, path, manifestName)
+	payload := readEntry(t, other.Path, payloadName)
+	writeArchive(t, path, manifest, payload)
+}
+
+func TestPrune_RetentionBuckets(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTrades(t, dir, `[]`)
+
+	m := New(filepath.Join(dir, "backups"))
+	m.Retention = Retention{}
+
+	// Four backups an hour apart today, one on each of the previous 10 days
+	base := time.Date(2026, 3, 20, 12, 30, 0, 0, time.Local)
+	stamps := []time.Time{}
+	for h := 0; h < 4; h++ {
+		stamps = append(stamps, base.Add(-time.Duration(h)*time.Hour))
+		stamps = append(stamps, base.Add(-time.Duration(h)*time.Hour-10*time.Minute)) // Same hour, older
+	}
+	for d := 1; d <= 10; d++ {
+		stamps = append(stamps, base.AddDate(0, 0, -d))
+	}
+	for _, ts := range stamps {
+		ts := ts
+		m.Now = func() time.Time { return ts }
+		if _, err := m.Create(src); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.Retention = Retention{Hourly: 3, Daily: 4, Weekly: 2}
+	removed, err := m.Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	backups, _ := m.List()
+	kept := map[string]bool{}
+	for _, b := range backups {
+		kept[b.Name] = true
+	}
+
+	name := func(ts time.Time) string { return "trades_" + ts.Format(timeLayout) + ".zip" }
+	for h := 0; h < 3; h++ {
+		if !kept[name(base.Add(-time.Duration(h)*time.Hour))] {
+			t.Errorf("Newest backup of hour -%d should be kept", h)
+		}
+		if kept[name(base.Add(-time.Duration(h)*time.Hour-10*time.Minute))] {
+			t.Errorf("Older backup within hour -%d should be pruned", h)
+		}
+	}
+	for d := 1; d <= 3; d++ {
+		if !kept[name(base.AddDate(0, 0, -d))] {
+			t.Errorf("Daily backup -%d should be kept", d)
+		}
+	}
+	// 2026-03-20 is a Friday: the previous ISO week's newest backup is Sunday the 15th
+	for d := 4; d <= 10; d++ {
+		if want := d == 5; kept[name(base.AddDate(0, 0, -d))] != want {
+			t.Errorf("Backup -%d days: kept=%v, want %v", d, !want, want)
+		}
+	}
+	if len(removed)+len(backups) != len(stamps) {
+		t.Errorf("Removed %d + kept %d should equal %d", len(removed), len(backups), len(stamps))
+	}
+}
+
+func readEntry(t *testing.T, path, name string) []byte {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	data, err := readZipFile(&zr.Reader, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func writeArchive(t *testing.T, path string, manifest, payload []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, data := range map[string][]byte{manifestName: manifest, payloadName: payload} {
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
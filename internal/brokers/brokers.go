@@ -0,0 +1,179 @@
+// Package brokers reads broker statements into fills and reconciles them
+// with the trade journal: fills are grouped into positions, matched to
+// trades by ticker, contract and date, and turned into a plan of closes,
+// corrections and new trades that is previewed before anything is written.
+package brokers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tf-engine/internal/options"
+)
+
+// Fill is one execution from a statement
+type Fill struct {
+	Time       time.Time `json:"time"`
+	Underlying string    `json:"underlying"`           // e.g. "NVDA"
+	Symbol     string    `json:"symbol"`               // As the broker wrote it
+	Expiration time.Time `json:"expiration,omitempty"` // Options only
+	Strike     float64   `json:"strike,omitempty"`
+	Right      string    `json:"right,omitempty"` // "C" or "P"; empty for stock
+	Quantity   float64   `json:"quantity"`        // Contracts or shares; negative when sold
+	Price      float64   `json:"price"`           // Per share
+	Multiplier float64   `json:"multiplier"`      // 100 for standard options, 1 for stock
+	Fees       float64   `json:"fees"`            // Commissions and fees paid
+}
+
+// IsOption reports whether the fill is for an option contract
+func (f Fill) IsOption() bool {
+	return f.Right != ""
+}
+
+// Amount is the cash the fill moved: negative when paying, after fees
+func (f Fill) Amount() float64 {
+	return -f.Quantity*f.Price*f.Multiplier - f.Fees
+}
+
+// Option returns the fill's option contract
+func (f Fill) Option() options.Contract {
+	return options.Contract{Root: f.Underlying, Expiry: f.Expiration, Right: f.Right, Strike: f.Strike}
+}
+
+// Contract identifies what was traded: the OCC symbol for options, e.g.
+// "NVDA  250221C00900000", or the ticker for stock
+func (f Fill) Contract() string {
+	if !f.IsOption() {
+		return f.Underlying
+	}
+	return f.Option().Symbol()
+}
+
+// setOption fills in the contract from an OCC symbol, reporting whether
+// symbol was one
+func (f *Fill) setOption(symbol string) bool {
+	c, err := options.Parse(symbol)
+	if err != nil {
+		return false
+	}
+	f.Underlying, f.Expiration, f.Right, f.Strike = c.Root, c.Expiry, c.Right, c.Strike
+	return true
+}
+
+// Parser reads one statement format
+type Parser interface {
+	// Name is the format's flag value, e.g. "thinkorswim"
+	Name() string
+	// Detect reports whether data looks like this format
+	Detect(data []byte) bool
+	// Parse returns the statement's fills, oldest first
+	Parse(data []byte) ([]Fill, error)
+}
+
+// Parsers returns every format; generic files are read with mapping
+func Parsers(mapping Mapping) []Parser {
+	return []Parser{thinkorswim{}, ibkrFlex{}, generic{mapping}}
+}
+
+// ParserFor returns the named format
+func ParserFor(name string, mapping Mapping) (Parser, error) {
+	names := []string{}
+	for _, p := range Parsers(mapping) {
+		if strings.EqualFold(p.Name(), name) {
+			return p, nil
+		}
+		names = append(names, p.Name())
+	}
+	return nil, fmt.Errorf("unknown statement format %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// DetectParser returns the first format data looks like
+func DetectParser(data []byte, mapping Mapping) (Parser, error) {
+	for _, p := range Parsers(mapping) {
+		if p.Detect(data) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized statement: not a thinkorswim statement, IBKR Flex query or CSV with the mapped columns")
+}
+
+// dateLayout is the format of contract expirations
+const dateLayout = "2006-01-02"
+
+// readRecords reads CSV rows of any width, skipping a byte order mark
+func readRecords(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV: %w", err)
+	}
+	return records, nil
+}
+
+// columnIndex maps header names (case-insensitive) to positions
+func columnIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return index
+}
+
+// cell returns row's value in the named column, or ""
+func cell(row []string, index map[string]int, name string) string {
+	i, ok := index[strings.ToLower(name)]
+	if !ok || name == "" || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// parseNumber reads amounts like "+1", "1,250.50", "$3.20" or "(45.00)"
+func parseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.NewReplacer("(", "", ")", "", "$", "", ",", "", "+", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %q", s)
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// normalizeRight maps CALL/PUT/C/P to "C" or "P"; anything else is stock
+func normalizeRight(s string) string {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "C", "CALL":
+		return "C"
+	case "P", "PUT":
+		return "P"
+	}
+	return ""
+}
+
+// sortFills orders fills oldest first, keeping statement order for ties
+func sortFills(fills []Fill) []Fill {
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].Time.Before(fills[j].Time) })
+	return fills
+}
+
+// round2 rounds to cents so sums of fills compare cleanly
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,160 @@
+package brokers
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return data
+}
+
+func fixtureMapping(t *testing.T) Mapping {
+	t.Helper()
+	mapping, err := LoadMapping(filepath.Join("testdata", "generic_mapping.json"))
+	if err != nil {
+		t.Fatalf("LoadMapping failed: %v", err)
+	}
+	return mapping
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func TestDetectParser(t *testing.T) {
+	mapping := fixtureMapping(t)
+	for file, want := range map[string]string{
+		"tos_statement.csv": "thinkorswim",
+		"ibkr_flex.csv":     "ibkr",
+		"generic.csv":       "generic",
+	} {
+		p, err := DetectParser(readFixture(t, file), mapping)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if p.Name() != want {
+			t.Errorf("%s detected as %s, want %s", file, p.Name(), want)
+		}
+	}
+
+	// Without the mapping the generic file's columns are unknown
+	if _, err := DetectParser(readFixture(t, "generic.csv"), DefaultMapping()); err == nil {
+		t.Error("Expected the generic file to need its mapping")
+	}
+	if _, err := ParserFor("schwab", mapping); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+}
+
+func TestThinkorswim(t *testing.T) {
+	fills, err := thinkorswim{}.Parse(readFixture(t, "tos_statement.csv"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(fills) != 5 {
+		t.Fatalf("Expected 5 fills, got %d", len(fills))
+	}
+
+	open := fills[0]
+	if open.Contract() != "NVDA  250221C00900000" || open.Quantity != 2 || open.Price != 12.40 {
+		t.Errorf("First fill: %+v", open)
+	}
+	if !near(open.Fees, 2.70) || !near(open.Amount(), -2482.70) {
+		t.Errorf("Order fees belong to its first leg: fees %.2f, amount %.2f", open.Fees, open.Amount())
+	}
+
+	// The spread's second leg inherits the order's time and pays no fee
+	short := fills[1]
+	if !short.Time.Equal(open.Time) || short.Quantity != -2 || short.Fees != 0 || short.Strike != 920 {
+		t.Errorf("Second leg: %+v", short)
+	}
+	if fills[2].Underlying != "AAPL" || fills[2].Right != "P" || !near(fills[2].Fees, 0.65) {
+		t.Errorf("AAPL fill: %+v", fills[2])
+	}
+}
+
+func TestIBKRFlex(t *testing.T) {
+	fills, err := ibkrFlex{}.Parse(readFixture(t, "ibkr_flex.csv"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(fills) != 5 {
+		t.Fatalf("Expected 5 fills (cash skipped), got %d", len(fills))
+	}
+	if f := fills[0]; f.Underlying != "MSFT" || f.Contract() != "MSFT  250321P00400000" || f.Quantity != -3 || !near(f.Fees, 3.15) {
+		t.Errorf("First fill: %+v", f)
+	}
+	if f := fills[2]; f.Underlying != "XOM" || f.IsOption() || f.Multiplier != 1 || !near(f.Amount(), -5413.50) {
+		t.Errorf("Stock fill: %+v (amount %.2f)", f, f.Amount())
+	}
+
+	// Flex files with the row type column and a repeated header
+	flex := "HEADER,Symbol,DateTime,Quantity,TradePrice,IBCommission\n" +
+		"DATA,XOM,20250210;100000,50,108.25,-1\n" +
+		"HEADER,Symbol,DateTime,Quantity,TradePrice,IBCommission\n" +
+		"DATA,XOM,20250212;100000,-50,110,-1\n"
+	fills, err = ibkrFlex{}.Parse([]byte(flex))
+	if err != nil || len(fills) != 2 || fills[1].Quantity != -50 {
+		t.Errorf("Flex with row types: %+v, %v", fills, err)
+	}
+
+	// Without the option columns the OCC symbol describes the contract
+	flex = "Symbol,DateTime,Quantity,TradePrice\n" +
+		"MSFT  250321P00400000,20250203;093512,-3,5.2\n"
+	fills, err = ibkrFlex{}.Parse([]byte(flex))
+	if err != nil || len(fills) != 1 || fills[0].Underlying != "MSFT" || fills[0].Strike != 400 || fills[0].Multiplier != 100 {
+		t.Errorf("Flex with OCC symbols only: %+v, %v", fills, err)
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	fills, err := generic{fixtureMapping(t)}.Parse(readFixture(t, "generic.csv"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(fills) != 2 {
+		t.Fatalf("Expected 2 fills, got %d", len(fills))
+	}
+	if f := fills[0]; f.Contract() != "SPY   250131C00600000" || f.Quantity != 1 || f.Multiplier != 100 {
+		t.Errorf("Opening fill: %+v", f)
+	}
+	if f := fills[1]; f.Quantity != -1 || !near(f.Amount(), 1124.35) {
+		t.Errorf("STC should be a sale: %+v (amount %.2f)", f, f.Amount())
+	}
+
+	// An OCC symbol stands in for the option columns
+	occ := "Date,Symbol,Side,Quantity,Price\n2025-01-06,SPY250131C00600000,buy,1,8.50\n"
+	fills, err = generic{DefaultMapping()}.Parse([]byte(occ))
+	if err != nil || len(fills) != 1 || fills[0].Contract() != "SPY   250131C00600000" || fills[0].Underlying != "SPY" {
+		t.Errorf("OCC symbol: %+v, %v", fills, err)
+	}
+
+	// A bad value names its line
+	bad := "Trade Date,Ticker,Qty,Fill Price\n01/06/2025,SPY,one,8.50\n"
+	if _, err := (generic{fixtureMapping(t)}).Parse([]byte(bad)); err == nil {
+		t.Error("Expected a bad quantity to fail")
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for text, want := range map[string]float64{
+		"+2": 2, "-0.10": -0.10, "$3.20": 3.20, "(45.00)": -45, "1,250.50": 1250.50, "": 0,
+	} {
+		got, err := parseNumber(text)
+		if err != nil || got != want {
+			t.Errorf("parseNumber(%q) = %v, %v; want %v", text, got, err, want)
+		}
+	}
+	if _, err := parseNumber("n/a"); err == nil {
+		t.Error("Expected n/a to fail")
+	}
+}
@@ -0,0 +1,170 @@
+package brokers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// Mapping names the columns of a generic CSV export. Only Date, Symbol,
+// Quantity and Price are required. Options need Expiration, Strike and Right
+// columns unless the symbol is an OCC symbol; other rows are stock.
+type Mapping struct {
+	Date       string `json:"date"`
+	Time       string `json:"time,omitempty"` // When the time is in its own column
+	Symbol     string `json:"symbol"`
+	Underlying string `json:"underlying,omitempty"` // Defaults to Symbol
+	Side       string `json:"side,omitempty"`       // buy/sell (or BTO, STC...) for unsigned quantities
+	Quantity   string `json:"quantity"`
+	Price      string `json:"price"`
+	Fees       string `json:"fees,omitempty"`
+	Expiration string `json:"expiration,omitempty"`
+	Strike     string `json:"strike,omitempty"`
+	Right      string `json:"right,omitempty"`
+	Multiplier string `json:"multiplier,omitempty"`
+
+	// DateLayouts are Go time layouts tried for dates and expirations;
+	// defaults to ISO and US formats
+	DateLayouts []string `json:"date_layouts,omitempty"`
+}
+
+// DefaultMapping reads a CSV with plainly named columns
+func DefaultMapping() Mapping {
+	return Mapping{
+		Date:       "Date",
+		Time:       "Time",
+		Symbol:     "Symbol",
+		Underlying: "Underlying",
+		Side:       "Side",
+		Quantity:   "Quantity",
+		Price:      "Price",
+		Fees:       "Fees",
+		Expiration: "Expiration",
+		Strike:     "Strike",
+		Right:      "Right",
+		Multiplier: "Multiplier",
+	}
+}
+
+// LoadMapping reads a mapping from a JSON file. Columns it leaves out keep
+// their DefaultMapping names.
+func LoadMapping(path string) (Mapping, error) {
+	mapping := DefaultMapping()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Mapping{}, fmt.Errorf("read mapping: %w", err)
+	}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return Mapping{}, fmt.Errorf("mapping %s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+var defaultDateLayouts = []string{
+	"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02",
+	"01/02/2006 15:04:05", "01/02/2006 15:04", "01/02/2006", "1/2/2006", "1/2/06",
+}
+
+// generic reads a CSV whose header has the mapped columns
+type generic struct {
+	mapping Mapping
+}
+
+func (generic) Name() string { return "generic" }
+
+func (g generic) Detect(data []byte) bool {
+	records, err := readRecords(data)
+	if err != nil || len(records) == 0 {
+		return false
+	}
+	m := g.mapping
+	return hasColumns(columnIndex(records[0]),
+		strings.ToLower(m.Date), strings.ToLower(m.Symbol), strings.ToLower(m.Quantity), strings.ToLower(m.Price))
+}
+
+func (g generic) Parse(data []byte) ([]Fill, error) {
+	records, err := readRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	if !g.Detect(data) {
+		return nil, fmt.Errorf("generic: the header needs the %q, %q, %q and %q columns",
+			g.mapping.Date, g.mapping.Symbol, g.mapping.Quantity, g.mapping.Price)
+	}
+
+	layouts := g.mapping.DateLayouts
+	if len(layouts) == 0 {
+		layouts = defaultDateLayouts
+	}
+	index := columnIndex(records[0])
+	fills := []Fill{}
+	for n, row := range records[1:] {
+		if blank(row) {
+			continue
+		}
+		fill, err := g.fill(row, index, layouts)
+		if err != nil {
+			return nil, fmt.Errorf("generic line %d: %w", n+2, err)
+		}
+		fills = append(fills, fill)
+	}
+	return sortFills(fills), nil
+}
+
+func (g generic) fill(row []string, index map[string]int, layouts []string) (Fill, error) {
+	m := g.mapping
+	get := func(column string) string { return cell(row, index, column) }
+	var err error
+
+	fill := Fill{Symbol: get(m.Symbol), Multiplier: 1}
+	if fill.Underlying = strings.ToUpper(get(m.Underlying)); fill.Underlying == "" {
+		fill.Underlying = strings.ToUpper(fill.Symbol)
+	}
+	when := strings.TrimSpace(get(m.Date) + " " + get(m.Time))
+	if fill.Time, err = parseDate(when, layouts); err != nil {
+		return Fill{}, fmt.Errorf("date: %w", err)
+	}
+	if fill.Quantity, err = parseNumber(get(m.Quantity)); err != nil {
+		return Fill{}, fmt.Errorf("quantity: %w", err)
+	}
+	if sold(get(m.Side)) && fill.Quantity > 0 {
+		fill.Quantity = -fill.Quantity
+	}
+	if fill.Price, err = parseNumber(get(m.Price)); err != nil {
+		return Fill{}, fmt.Errorf("price: %w", err)
+	}
+	fees, err := parseNumber(get(m.Fees))
+	if err != nil {
+		return Fill{}, fmt.Errorf("fees: %w", err)
+	}
+	fill.Fees = math.Abs(fees)
+
+	if fill.Right = normalizeRight(get(m.Right)); fill.IsOption() {
+		if fill.Strike, err = parseNumber(get(m.Strike)); err != nil {
+			return Fill{}, fmt.Errorf("strike: %w", err)
+		}
+		if fill.Expiration, err = parseDate(get(m.Expiration), layouts); err != nil {
+			return Fill{}, fmt.Errorf("expiration: %w", err)
+		}
+		fill.Multiplier = 100
+	} else if fill.setOption(fill.Symbol) {
+		fill.Multiplier = 100 // An OCC symbol needs no option columns
+	}
+	if text := get(m.Multiplier); text != "" {
+		if fill.Multiplier, err = parseNumber(text); err != nil {
+			return Fill{}, fmt.Errorf("multiplier: %w", err)
+		}
+	}
+	return fill, nil
+}
+
+// sold reports whether a side column means a sale
+func sold(side string) bool {
+	switch strings.ToUpper(strings.TrimSpace(side)) {
+	case "SELL", "SOLD", "S", "STO", "STC", "SELL TO OPEN", "SELL TO CLOSE":
+		return true
+	}
+	return false
+}
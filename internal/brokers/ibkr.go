@@ -0,0 +1,122 @@
+package brokers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ibkrFlex reads the Trades section of an Interactive Brokers Flex query
+// saved as CSV. Header rows may repeat (one per account or section) and may
+// carry the "HEADER"/"DATA" row type column Flex adds.
+type ibkrFlex struct{}
+
+func (ibkrFlex) Name() string { return "ibkr" }
+
+func (ibkrFlex) Detect(data []byte) bool {
+	return bytes.Contains(data, []byte("TradePrice")) && bytes.Contains(data, []byte("Symbol"))
+}
+
+// ibkrTimeLayouts are the DateTime and TradeDate formats Flex can produce
+var ibkrTimeLayouts = []string{"20060102;150405", "20060102 150405", "2006-01-02, 15:04:05", "2006-01-02 15:04:05", "20060102", "2006-01-02", "01/02/2006"}
+
+func (ibkrFlex) Parse(data []byte) ([]Fill, error) {
+	records, err := readRecords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fills := []Fill{}
+	var index map[string]int
+	for n, row := range records {
+		line := fmt.Sprintf("ibkr line %d", n+1)
+		if len(row) > 0 {
+			switch strings.ToUpper(strings.TrimSpace(row[0])) {
+			case "HEADER", "DATA":
+				row = row[1:]
+			}
+		}
+		if blank(row) {
+			continue
+		}
+		if header := columnIndex(row); hasColumns(header, "symbol", "tradeprice", "quantity") {
+			index = header
+			continue
+		}
+		if index == nil {
+			continue // Preamble before the first header
+		}
+		if class := strings.ToUpper(cell(row, index, "assetclass")); class != "" && class != "OPT" && class != "STK" {
+			continue // Cash, futures and the like have no journal trades
+		}
+
+		fill, err := ibkrFill(row, index)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", line, err)
+		}
+		fills = append(fills, fill)
+	}
+	if index == nil {
+		return nil, fmt.Errorf("ibkr: no header with Symbol, TradePrice and Quantity")
+	}
+	return sortFills(fills), nil
+}
+
+func ibkrFill(row []string, index map[string]int) (Fill, error) {
+	var err error
+	fill := Fill{Symbol: cell(row, index, "symbol"), Multiplier: 1}
+	fill.Underlying = strings.ToUpper(cell(row, index, "underlyingsymbol"))
+	if fill.Underlying == "" {
+		fill.Underlying = strings.ToUpper(strings.Fields(fill.Symbol + " ")[0])
+	}
+
+	when := cell(row, index, "datetime")
+	if when == "" {
+		when = cell(row, index, "tradedate")
+	}
+	if fill.Time, err = parseDate(when, ibkrTimeLayouts); err != nil {
+		return Fill{}, fmt.Errorf("date: %w", err)
+	}
+	if fill.Quantity, err = parseNumber(cell(row, index, "quantity")); err != nil {
+		return Fill{}, fmt.Errorf("quantity: %w", err)
+	}
+	if strings.EqualFold(cell(row, index, "buy/sell"), "SELL") && fill.Quantity > 0 {
+		fill.Quantity = -fill.Quantity
+	}
+	if fill.Price, err = parseNumber(cell(row, index, "tradeprice")); err != nil {
+		return Fill{}, fmt.Errorf("trade price: %w", err)
+	}
+	commission, err := parseNumber(cell(row, index, "ibcommission"))
+	if err != nil {
+		return Fill{}, fmt.Errorf("commission: %w", err)
+	}
+	fill.Fees = -commission // Flex reports commissions as negative amounts
+
+	if fill.Right = normalizeRight(cell(row, index, "put/call")); fill.IsOption() {
+		if fill.Strike, err = parseNumber(cell(row, index, "strike")); err != nil {
+			return Fill{}, fmt.Errorf("strike: %w", err)
+		}
+		if fill.Expiration, err = parseDate(cell(row, index, "expiry"), ibkrTimeLayouts); err != nil {
+			return Fill{}, fmt.Errorf("expiry: %w", err)
+		}
+		fill.Multiplier = 100
+	} else if fill.setOption(fill.Symbol) {
+		fill.Multiplier = 100 // Flex writes option symbols in OCC form
+	}
+	if text := cell(row, index, "multiplier"); text != "" {
+		if fill.Multiplier, err = parseNumber(text); err != nil {
+			return Fill{}, fmt.Errorf("multiplier: %w", err)
+		}
+	}
+	return fill, nil
+}
+
+// hasColumns reports whether a header has every named column
+func hasColumns(index map[string]int, names ...string) bool {
+	for _, name := range names {
+		if _, ok := index[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
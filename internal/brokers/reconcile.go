@@ -0,0 +1,421 @@
+package brokers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"tf-engine/internal/commands"
+	"tf-engine/internal/models"
+	"tf-engine/internal/options"
+)
+
+// matchWindow is how far a stock trade's entry date may be from the first
+// fill and still match it
+const matchWindow = 3 * 24 * time.Hour
+
+// Position is a group of fills in one underlying and expiration, from the
+// first opening fill until every contract nets back to zero
+type Position struct {
+	Underlying string    `json:"underlying"`
+	Expiration time.Time `json:"expiration,omitempty"` // Zero for stock
+	Fills      []Fill    `json:"fills"`
+	Opened     time.Time `json:"opened"`
+	Closed     time.Time `json:"closed,omitempty"` // Zero while any contract is still held
+	OpenCash   float64   `json:"open_cash"`        // Cash from opening fills (negative for a debit)
+	CloseCash  float64   `json:"close_cash"`       // Cash from closing fills
+	legs       map[string]Fill
+}
+
+// Leg is one contract of a position and the quantity opened in it
+type Leg struct {
+	Contract string  `json:"contract"` // OCC symbol, or the ticker for stock
+	Strike   float64 `json:"strike,omitempty"`
+	Right    string  `json:"right,omitempty"`
+	Quantity float64 `json:"quantity"` // Negative when sold to open
+}
+
+// IsOpen reports whether any contract is still held
+func (p Position) IsOpen() bool {
+	return p.Closed.IsZero()
+}
+
+// PnL is the realized profit or loss after fees, once closed
+func (p Position) PnL() float64 {
+	return round2(p.OpenCash + p.CloseCash)
+}
+
+// Legs returns the opened contracts, lowest strike first
+func (p Position) Legs() []Leg {
+	legs := make([]Leg, 0, len(p.legs))
+	for contract, f := range p.legs {
+		legs = append(legs, Leg{Contract: contract, Strike: f.Strike, Right: f.Right, Quantity: f.Quantity})
+	}
+	sort.Slice(legs, func(i, j int) bool {
+		if legs[i].Strike != legs[j].Strike {
+			return legs[i].Strike < legs[j].Strike
+		}
+		return legs[i].Right < legs[j].Right
+	})
+	return legs
+}
+
+// Strikes returns the distinct strikes, lowest first
+func (p Position) Strikes() []float64 {
+	strikes := []float64{}
+	for _, leg := range p.Legs() {
+		if leg.Right != "" && (len(strikes) == 0 || strikes[len(strikes)-1] != leg.Strike) {
+			strikes = append(strikes, leg.Strike)
+		}
+	}
+	return strikes
+}
+
+// Size is the largest quantity opened in any one contract
+func (p Position) Size() int {
+	size := 0.0
+	for _, leg := range p.legs {
+		size = math.Max(size, math.Abs(leg.Quantity))
+	}
+	return int(size)
+}
+
+// Strategy names the options strategy the legs form, or "" if unrecognized
+func (p Position) Strategy() string {
+	legs := p.Legs()
+	calls, puts := []Leg{}, []Leg{}
+	for _, leg := range legs {
+		switch leg.Right {
+		case "C":
+			calls = append(calls, leg)
+		case "P":
+			puts = append(puts, leg)
+		}
+	}
+
+	switch {
+	case len(legs) == 1 && len(calls) == 1:
+		return pick(calls[0].Quantity > 0, "Long call", "Covered call")
+	case len(legs) == 1 && len(puts) == 1:
+		return pick(puts[0].Quantity > 0, "Long put", "Cash-secured put")
+	case len(calls) == 2 && len(puts) == 0:
+		// Calls are ordered by strike: buying the lower one is a debit spread
+		return pick(calls[0].Quantity > 0, "Bull call spread", "Bear call credit spread")
+	case len(puts) == 2 && len(calls) == 0:
+		return pick(puts[1].Quantity > 0, "Bear put spread", "Bull put credit spread")
+	case len(calls) == 1 && len(puts) == 1 && calls[0].Quantity > 0 && puts[0].Quantity > 0:
+		return pick(calls[0].Strike == puts[0].Strike, "Straddle", "Strangle")
+	case len(calls) == 2 && len(puts) == 2 && puts[0].Quantity > 0 && puts[1].Quantity < 0 &&
+		calls[0].Quantity < 0 && calls[1].Quantity > 0:
+		return pick(puts[1].Strike == calls[0].Strike, "Iron butterfly", "Iron condor")
+	}
+	return ""
+}
+
+func pick(cond bool, yes, no string) string {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+// GroupPositions groups fills (oldest first) into positions. A fill opens
+// when it grows its contract's net quantity and closes otherwise.
+func GroupPositions(fills []Fill) []Position {
+	positions := []Position{}
+	open := map[string]*Position{}
+	nets := map[string]map[string]float64{}
+	order := []string{}
+
+	for _, f := range fills {
+		key := f.Underlying
+		if f.IsOption() {
+			key += " " + f.Expiration.Format(dateLayout)
+		}
+		p, ok := open[key]
+		if !ok {
+			p = &Position{Underlying: f.Underlying, Expiration: f.Expiration, Opened: f.Time, legs: map[string]Fill{}}
+			open[key] = p
+			nets[key] = map[string]float64{}
+			order = append(order, key)
+		}
+		p.Fills = append(p.Fills, f)
+
+		contract := f.Contract()
+		before := nets[key][contract]
+		after := before + f.Quantity
+		nets[key][contract] = after
+		if math.Abs(after) > math.Abs(before) {
+			p.OpenCash = round2(p.OpenCash + f.Amount())
+			leg, ok := p.legs[contract]
+			if !ok {
+				leg = f
+				leg.Quantity = 0
+			}
+			leg.Quantity += f.Quantity
+			p.legs[contract] = leg
+		} else {
+			p.CloseCash = round2(p.CloseCash + f.Amount())
+		}
+
+		if flat(nets[key]) {
+			p.Closed = f.Time
+			positions = append(positions, *p)
+			delete(open, key)
+		}
+	}
+
+	// Positions still held, in the order they were opened
+	for _, key := range order {
+		if p, ok := open[key]; ok {
+			positions = append(positions, *p)
+			delete(open, key)
+		}
+	}
+	sort.SliceStable(positions, func(i, j int) bool { return positions[i].Opened.Before(positions[j].Opened) })
+	return positions
+}
+
+func flat(nets map[string]float64) bool {
+	for _, n := range nets {
+		if math.Abs(n) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reconciliation actions
+const (
+	ActionNew       = "new"       // Unmatched position: add a trade
+	ActionClose     = "close"     // Matched active trade the statement shows closed
+	ActionCorrect   = "correct"   // Matched closed trade whose P&L differs from the statement
+	ActionUnchanged = "unchanged" // Matched and already in agreement
+)
+
+// Item is the plan for one position
+type Item struct {
+	Action   string        `json:"action"`
+	Position Position      `json:"position"`
+	Before   *models.Trade `json:"before,omitempty"` // The matched trade
+	After    models.Trade  `json:"after"`            // The trade as it would be saved
+	Note     string        `json:"note,omitempty"`
+}
+
+// Changes reports whether applying the item writes anything
+func (i Item) Changes() bool {
+	return i.Action != ActionUnchanged
+}
+
+// Plan is a reconciliation preview. Nothing is written until its command is
+// executed.
+type Plan struct {
+	Source string `json:"source"`
+	Items  []Item `json:"items"`
+}
+
+// Count returns how many items have the action
+func (p Plan) Count(action string) int {
+	n := 0
+	for _, item := range p.Items {
+		if item.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Changes returns how many items would write something
+func (p Plan) Changes() int {
+	return len(p.Items) - p.Count(ActionUnchanged)
+}
+
+// Summary describes the plan, e.g. "2 new, 1 close, 0 correct, 3 unchanged"
+func (p Plan) Summary() string {
+	return fmt.Sprintf("%d new, %d close, %d correct, %d unchanged",
+		p.Count(ActionNew), p.Count(ActionClose), p.Count(ActionCorrect), p.Count(ActionUnchanged))
+}
+
+// Command returns the plan's changes as one undoable command
+func (p Plan) Command() *commands.ReconcileCommand {
+	edits := []*commands.EditCommand{}
+	added := []models.Trade{}
+	for _, item := range p.Items {
+		switch {
+		case !item.Changes():
+		case item.Before == nil:
+			added = append(added, item.After)
+		default:
+			edits = append(edits, commands.NewEditCommand(*item.Before, item.After))
+		}
+	}
+	return commands.NewReconcileCommand(p.Source, edits, added)
+}
+
+// Reconcile matches the fills' positions to trades and plans the changes
+// that bring the journal in line with the statement
+func Reconcile(source string, fills []Fill, trades []models.Trade, policy *models.Policy, now time.Time) Plan {
+	plan := Plan{Source: source, Items: []Item{}}
+	used := map[string]bool{}
+	ids := map[string]bool{}
+	for _, t := range trades {
+		ids[t.ID] = true
+	}
+
+	for _, pos := range GroupPositions(fills) {
+		i := match(pos, trades, used)
+		if i < 0 {
+			plan.Items = append(plan.Items, newItem(pos, policy, ids, now))
+			continue
+		}
+		used[trades[i].ID] = true
+		plan.Items = append(plan.Items, matchedItem(pos, trades[i], now))
+	}
+	return plan
+}
+
+// match returns the index of the unused trade that best fits pos, or -1.
+// Options must have the same expiration and share a contract; stock must have
+// been entered within matchWindow of the first fill. Ties go to the closest
+// entry date.
+func match(pos Position, trades []models.Trade, used map[string]bool) int {
+	best, bestGap := -1, time.Duration(0)
+	for i, t := range trades {
+		if used[t.ID] || !strings.EqualFold(t.Ticker, pos.Underlying) {
+			continue
+		}
+		entered := t.EntryDate
+		if entered.IsZero() {
+			entered = t.CreatedAt
+		}
+		gap := pos.Opened.Sub(entered)
+		if gap < 0 {
+			gap = -gap
+		}
+
+		if pos.Expiration.IsZero() {
+			if t.OptionsStrategy != "" || gap > matchWindow {
+				continue
+			}
+		} else if t.ExpirationDate.Format(dateLayout) != pos.Expiration.Format(dateLayout) || !sharesContract(t, pos) {
+			continue
+		}
+		if best < 0 || gap < bestGap {
+			best, bestGap = i, gap
+		}
+	}
+	return best
+}
+
+// sharesContract reports whether the trade holds one of the position's
+// contracts. Trades whose contracts can't be derived from their strategy
+// need a shared strike, or match on expiration alone when they record none.
+func sharesContract(t models.Trade, pos Position) bool {
+	if legs, err := options.Legs(t); err == nil {
+		for _, leg := range legs {
+			if _, ok := pos.legs[leg.Contract.Symbol()]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	recorded := false
+	for _, s := range []float64{t.Strike1, t.Strike2, t.Strike3, t.Strike4} {
+		if s == 0 {
+			continue
+		}
+		recorded = true
+		for _, strike := range pos.Strikes() {
+			if math.Abs(s-strike) < 0.001 {
+				return true
+			}
+		}
+	}
+	return !recorded
+}
+
+// matchedItem compares a matched trade with its position
+func matchedItem(pos Position, trade models.Trade, now time.Time) Item {
+	before := trade
+	item := Item{Action: ActionUnchanged, Position: pos, Before: &before, After: trade}
+	status := trade.GetStatus()
+
+	switch {
+	case pos.IsOpen() && status == "active":
+		item.Note = "Still open"
+	case pos.IsOpen():
+		item.Note = fmt.Sprintf("Journal says %s but the statement shows the position open", status)
+	case status == "active":
+		item.Action = ActionClose
+		item.After = closed(trade, pos, now)
+		item.Note = fmt.Sprintf("Closed %s, P&L $%.2f", pos.Closed.Format(dateLayout), pos.PnL())
+	case math.Abs(trade.GetPnL()-pos.PnL()) > 0.01:
+		item.Action = ActionCorrect
+		item.After = closed(trade, pos, now)
+		item.Note = fmt.Sprintf("Journal P&L $%.2f, statement $%.2f", trade.GetPnL(), pos.PnL())
+	default:
+		item.Note = "P&L agrees"
+	}
+	return item
+}
+
+// closed returns trade closed with the position's exit. Exit price is the
+// cash value of the exit, and P&L includes fees.
+func closed(trade models.Trade, pos Position, now time.Time) models.Trade {
+	exitDate := pos.Closed
+	exitPrice := math.Abs(pos.CloseCash)
+	pnl := pos.PnL()
+	if trade.Premium == 0 {
+		trade.Premium = math.Abs(pos.OpenCash)
+	}
+	trade.ExitDate = &exitDate
+	trade.ExitPrice = &exitPrice
+	trade.ProfitLoss = &pnl
+	trade.OpenPnL = nil
+	trade.Status = "closed"
+	trade.UpdatedAt = now
+	return trade
+}
+
+// newItem proposes a trade for an unmatched position
+func newItem(pos Position, policy *models.Policy, ids map[string]bool, now time.Time) Item {
+	id := models.NewTradeID()
+	for n := 1; ids[id]; n++ {
+		id = fmt.Sprintf("%s-%d", models.NewTradeID(), n)
+	}
+	ids[id] = true
+
+	trade := models.Trade{
+		ID:              id,
+		CreatedAt:       pos.Opened,
+		UpdatedAt:       now,
+		Ticker:          pos.Underlying,
+		OptionsStrategy: pos.Strategy(),
+		EntryDate:       pos.Opened,
+		ExpirationDate:  pos.Expiration,
+		Premium:         math.Abs(pos.OpenCash),
+		PositionSize:    pos.Size(),
+		Status:          "active",
+	}
+	if policy != nil {
+		if sector := policy.SectorForTicker(pos.Underlying); sector != nil {
+			trade.Sector = sector.Name
+		}
+	}
+	strikes := []*float64{&trade.Strike1, &trade.Strike2, &trade.Strike3, &trade.Strike4}
+	for i, s := range pos.Strikes() {
+		if i < len(strikes) {
+			*strikes[i] = s
+		}
+	}
+
+	note := "Open position not in the journal"
+	if !pos.IsOpen() {
+		trade = closed(trade, pos, now)
+		note = fmt.Sprintf("Closed position not in the journal, P&L $%.2f", pos.PnL())
+	}
+	return Item{Action: ActionNew, Position: pos, After: trade, Note: note}
+}
@@ -0,0 +1,160 @@
+package brokers
+
+import (
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func date(s string) time.Time {
+	t, _ := time.ParseInLocation(dateLayout, s, time.Local)
+	return t
+}
+
+func pnl(v float64) *float64 { return &v }
+
+func parseFixture(t *testing.T, name string) []Fill {
+	t.Helper()
+	data := readFixture(t, name)
+	p, err := DetectParser(data, fixtureMapping(t))
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	fills, err := p.Parse(data)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return fills
+}
+
+func TestGroupPositions(t *testing.T) {
+	positions := GroupPositions(parseFixture(t, "tos_statement.csv"))
+	if len(positions) != 2 {
+		t.Fatalf("Expected 2 positions, got %d", len(positions))
+	}
+
+	nvda := positions[0]
+	if nvda.Underlying != "NVDA" || nvda.IsOpen() || nvda.Strategy() != "Bull call spread" || nvda.Size() != 2 {
+		t.Errorf("NVDA: %s open=%v strategy=%q size=%d", nvda.Underlying, nvda.IsOpen(), nvda.Strategy(), nvda.Size())
+	}
+	if !near(nvda.OpenCash, -1302.70) || !near(nvda.CloseCash, 2197.30) || !near(nvda.PnL(), 894.60) {
+		t.Errorf("NVDA cash: open %.2f close %.2f P&L %.2f", nvda.OpenCash, nvda.CloseCash, nvda.PnL())
+	}
+	if aapl := positions[1]; !aapl.IsOpen() || aapl.Strategy() != "Long put" {
+		t.Errorf("AAPL: open=%v strategy=%q", aapl.IsOpen(), aapl.Strategy())
+	}
+
+	// Reopening after going flat starts a new position
+	fills := []Fill{
+		{Time: date("2025-01-02"), Underlying: "XOM", Quantity: 10, Price: 100, Multiplier: 1},
+		{Time: date("2025-01-03"), Underlying: "XOM", Quantity: -10, Price: 101, Multiplier: 1},
+		{Time: date("2025-01-06"), Underlying: "XOM", Quantity: 5, Price: 99, Multiplier: 1},
+	}
+	if positions := GroupPositions(fills); len(positions) != 2 || !near(positions[0].PnL(), 10) || !positions[1].IsOpen() {
+		t.Errorf("Expected a closed and a reopened XOM position, got %+v", positions)
+	}
+}
+
+func TestStrategyGuess(t *testing.T) {
+	leg := func(strike float64, right string, qty float64) Fill {
+		return Fill{Underlying: "SPY", Expiration: date("2025-03-21"), Strike: strike, Right: right, Quantity: qty, Multiplier: 100}
+	}
+	for want, fills := range map[string][]Fill{
+		"Bear put spread":         {leg(500, "P", 1), leg(490, "P", -1)},
+		"Bear call credit spread": {leg(600, "C", -1), leg(610, "C", 1)},
+		"Iron condor":             {leg(480, "P", 1), leg(490, "P", -1), leg(600, "C", -1), leg(610, "C", 1)},
+		"Straddle":                {leg(550, "C", 1), leg(550, "P", 1)},
+		"":                        {leg(550, "C", 1), leg(560, "C", -2), leg(570, "C", 1), leg(500, "P", 1), leg(490, "P", 1)},
+	} {
+		positions := GroupPositions(fills)
+		if got := positions[0].Strategy(); got != want {
+			t.Errorf("Strategy() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	policy := models.SafeModePolicy()
+	policy.Sectors[0].Tickers = []string{"AAPL"}
+	now := date("2025-03-10")
+
+	trades := []models.Trade{
+		// Same strikes and expiration but puts: not the statement's contracts
+		{ID: "nvda-puts", Ticker: "NVDA", OptionsStrategy: "Bull put credit spread", Strike1: 900, Strike2: 920,
+			EntryDate: date("2025-01-15"), ExpirationDate: date("2025-02-21"), Status: "active"},
+		{ID: "nvda", Ticker: "NVDA", OptionsStrategy: "Bull call spread", Strike1: 900, Strike2: 920,
+			EntryDate: date("2025-01-15"), ExpirationDate: date("2025-02-21"), Premium: 1300, Status: "active"},
+		// Different strikes: not the statement's contract
+		{ID: "nvda-other", Ticker: "NVDA", Strike1: 700, ExpirationDate: date("2025-02-21"), Status: "active"},
+		{ID: "msft", Ticker: "MSFT", OptionsStrategy: "Bull put credit spread", Strike1: 390, Strike2: 400,
+			EntryDate: date("2025-02-03"), ExpirationDate: date("2025-03-21"), Status: "closed", ProfitLoss: pnl(560)},
+		{ID: "spy", Ticker: "SPY", OptionsStrategy: "Long call", Strike1: 600,
+			EntryDate: date("2025-01-06"), ExpirationDate: date("2025-01-31"), Status: "closed", ProfitLoss: pnl(273.70)},
+	}
+
+	fills := parseFixture(t, "tos_statement.csv")
+	fills = append(fills, parseFixture(t, "ibkr_flex.csv")...)
+	fills = append(fills, parseFixture(t, "generic.csv")...)
+	plan := Reconcile("statements", sortFills(fills), trades, policy, now)
+
+	if got := plan.Summary(); got != "2 new, 1 close, 1 correct, 1 unchanged" {
+		t.Fatalf("Summary() = %q", got)
+	}
+	byTicker := map[string]Item{}
+	for _, item := range plan.Items {
+		byTicker[item.Position.Underlying] = item
+	}
+
+	nvda := byTicker["NVDA"]
+	if nvda.Action != ActionClose || nvda.Before.ID != "nvda" || nvda.After.Status != "closed" ||
+		!near(nvda.After.GetPnL(), 894.60) || !near(*nvda.After.ExitPrice, 2197.30) || nvda.After.Premium != 1300 {
+		t.Errorf("NVDA: %+v", nvda)
+	}
+	if msft := byTicker["MSFT"]; msft.Action != ActionCorrect || !near(msft.After.GetPnL(), 557.40) {
+		t.Errorf("MSFT: %s P&L %.2f (%s)", msft.Action, msft.After.GetPnL(), msft.Note)
+	}
+	if spy := byTicker["SPY"]; spy.Action != ActionUnchanged || spy.Changes() {
+		t.Errorf("SPY: %s (%s)", spy.Action, spy.Note)
+	}
+
+	aapl := byTicker["AAPL"]
+	if aapl.Action != ActionNew || aapl.Before != nil || aapl.After.Status != "active" ||
+		aapl.After.OptionsStrategy != "Long put" || aapl.After.Strike1 != 220 || aapl.After.Sector != policy.Sectors[0].Name ||
+		!aapl.After.ExpirationDate.Equal(date("2025-03-21")) || !near(aapl.After.Premium, 410.65) {
+		t.Errorf("AAPL: %+v", aapl.After)
+	}
+	if xom := byTicker["XOM"]; xom.Action != ActionNew || xom.After.PositionSize != 50 || xom.After.Sector != "" {
+		t.Errorf("XOM: %+v", xom.After)
+	}
+	if aapl.After.ID == byTicker["XOM"].After.ID {
+		t.Error("New trades need distinct IDs")
+	}
+
+	// The command writes the changes and nothing else
+	after, err := plan.Command().Apply(trades)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if len(after) != 7 || after[0].Status != "active" || after[1].Status != "closed" || after[2].Status != "active" || !near(after[3].GetPnL(), 557.40) {
+		t.Errorf("After apply: %d trades, %+v", len(after), after[:4])
+	}
+	for _, trade := range after {
+		if err := trade.Validate(); err != nil {
+			t.Errorf("%s: %v", trade.Ticker, err)
+		}
+	}
+}
+
+func TestReconcileStockNeedsNearbyEntry(t *testing.T) {
+	fills := []Fill{{Time: date("2025-02-10"), Underlying: "XOM", Quantity: 50, Price: 108.25, Multiplier: 1}}
+	trades := []models.Trade{{ID: "old", Ticker: "XOM", EntryDate: date("2025-01-02"), Status: "active"}}
+	if plan := Reconcile("", fills, trades, nil, date("2025-03-01")); plan.Items[0].Action != ActionNew {
+		t.Errorf("An entry five weeks earlier should not match, got %s", plan.Items[0].Action)
+	}
+
+	trades[0].EntryDate = date("2025-02-09")
+	if plan := Reconcile("", fills, trades, nil, date("2025-03-01")); plan.Items[0].Action != ActionUnchanged {
+		t.Errorf("An entry the day before should match, got %s", plan.Items[0].Action)
+	}
+}
@@ -0,0 +1,157 @@
+package brokers
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// thinkorswim reads the "Account Trade History" section of a thinkorswim
+// account statement export. Fees come from the "Cash Balance" rows with the
+// same date and time as the order.
+type thinkorswim struct{}
+
+func (thinkorswim) Name() string { return "thinkorswim" }
+
+func (thinkorswim) Detect(data []byte) bool {
+	return bytes.Contains(data, []byte("Account Trade History"))
+}
+
+// tosTimeLayout is the format of Exec Time and of Cash Balance DATE + TIME
+const tosTimeLayout = "1/2/06 15:04:05"
+
+// tosExpLayouts are the expiration formats thinkorswim uses
+var tosExpLayouts = []string{"2 Jan 06", "2 Jan 2006", "1/2/06", "1/2/2006"}
+
+func (thinkorswim) Parse(data []byte) ([]Fill, error) {
+	records, err := readRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := tosFees(section(records, "Cash Balance"))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := section(records, "Account Trade History")
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("thinkorswim: no Account Trade History section")
+	}
+	index := columnIndex(rows[0])
+	for _, name := range []string{"exec time", "qty", "symbol", "price", "type"} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("thinkorswim: Account Trade History has no %q column", name)
+		}
+	}
+
+	fills := []Fill{}
+	var execTime time.Time
+	for n, row := range rows[1:] {
+		if blank(row) {
+			continue
+		}
+		fee := 0.0
+		line := fmt.Sprintf("thinkorswim trade %d", n+1)
+		// Later legs of a spread leave Exec Time empty
+		if text := cell(row, index, "exec time"); text != "" {
+			if execTime, err = time.ParseInLocation(tosTimeLayout, text, time.Local); err != nil {
+				return nil, fmt.Errorf("%s: exec time %q: %w", line, text, err)
+			}
+			fee = fees[text] // Charged once per order, on its first leg
+			delete(fees, text)
+		}
+		if execTime.IsZero() {
+			return nil, fmt.Errorf("%s: no exec time", line)
+		}
+
+		fill := Fill{Time: execTime, Underlying: strings.ToUpper(cell(row, index, "symbol")), Multiplier: 1, Fees: fee}
+		fill.Symbol = fill.Underlying
+		if fill.Quantity, err = parseNumber(cell(row, index, "qty")); err != nil {
+			return nil, fmt.Errorf("%s: qty: %w", line, err)
+		}
+		if fill.Price, err = parseNumber(cell(row, index, "price")); err != nil {
+			return nil, fmt.Errorf("%s: price: %w", line, err)
+		}
+		if side := strings.ToUpper(cell(row, index, "side")); side == "SELL" && fill.Quantity > 0 {
+			fill.Quantity = -fill.Quantity
+		}
+
+		if fill.Right = normalizeRight(cell(row, index, "type")); fill.IsOption() {
+			fill.Multiplier = 100
+			if fill.Strike, err = parseNumber(cell(row, index, "strike")); err != nil {
+				return nil, fmt.Errorf("%s: strike: %w", line, err)
+			}
+			if fill.Expiration, err = parseDate(cell(row, index, "exp"), tosExpLayouts); err != nil {
+				return nil, fmt.Errorf("%s: exp: %w", line, err)
+			}
+			fill.Symbol = fill.Contract()
+		}
+		fills = append(fills, fill)
+	}
+	return sortFills(fills), nil
+}
+
+// section returns the rows after a title row, up to the next title. Titles
+// are one-field rows; the blank lines around them never reach us because
+// the CSV reader skips empty lines.
+func section(records [][]string, title string) [][]string {
+	for i, row := range records {
+		if len(row) == 1 && strings.TrimSpace(row[0]) == title {
+			rows := [][]string{}
+			for _, r := range records[i+1:] {
+				if len(r) == 1 {
+					break
+				}
+				rows = append(rows, r)
+			}
+			return rows
+		}
+	}
+	return nil
+}
+
+func blank(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// tosFees sums the commissions and fees of each trade row in the Cash
+// Balance section, keyed by "DATE TIME"
+func tosFees(rows [][]string) (map[string]float64, error) {
+	fees := map[string]float64{}
+	if len(rows) == 0 {
+		return fees, nil
+	}
+	index := columnIndex(rows[0])
+	for _, row := range rows[1:] {
+		if cell(row, index, "type") != "TRD" {
+			continue
+		}
+		total := 0.0
+		for _, column := range []string{"misc fees", "commissions & fees"} {
+			v, err := parseNumber(cell(row, index, column))
+			if err != nil {
+				return nil, fmt.Errorf("thinkorswim cash balance: %s: %w", column, err)
+			}
+			total += math.Abs(v)
+		}
+		fees[cell(row, index, "date")+" "+cell(row, index, "time")] += total
+	}
+	return fees, nil
+}
+
+// parseDate tries each layout in turn
+func parseDate(text string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(text), time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", text)
+}
@@ -0,0 +1,243 @@
+// Package cli implements the headless subcommands (trades, stats, heat,
+// policy) that run instead of the GUI when a command is given
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/storage"
+)
+
+// EnvPassphrase unlocks encrypted data for commands run without a terminal
+const EnvPassphrase = "TF_ENGINE_PASSPHRASE"
+
+// Exit codes
+const (
+	ExitOK    = 0
+	ExitFail  = 1 // Error, or a check (heat, policy) failed
+	ExitUsage = 2
+)
+
+// Options connect the commands to the app
+type Options struct {
+	Stdout, Stderr io.Writer
+	Getenv         func(string) string      // Defaults to os.Getenv
+	FindConfigFile func(name string) string // Locates policy.v1.json
+}
+
+// command is one subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+func subcommands() []command {
+	return []command{
+		{"trades list", "List trades (--status, --sector, --strategy, --ticker, --since, --until)", tradesList},
+		{"trades show", "Show one trade: trades show <id>", tradesShow},
+		{"trades close", "Close an active trade: trades close <id> --price <total> --date <YYYY-MM-DD> [--pnl <amount>]", tradesClose},
+		{"trades import", "Add trades from a JSON file: trades import <file> [--dry-run]", tradesImport},
+		{"trades reconcile", "Match a broker statement to the journal: trades reconcile <file> [--broker thinkorswim|ibkr|generic] [--mapping <file>] [--apply]", tradesReconcile},
+		{"trades export", "Write trades as JSON: trades export [--out <file>] [filters]", tradesExport},
+		{"export", "Write a CSV table, the JSON archive or an .ics calendar: export <trades|legs|sectors|strategies|convictions|equity|archive|calendar> [--columns a,b] [--since] [--until] [--review-pct] [--exit-dte] [--out <file>]", exportData},
+		{"stats", "Performance statistics with sector, strategy and conviction tables", stats},
+		{"heat", "Portfolio and sector heat of active trades; exits 1 over a cap", heat},
+		{"policy verify", "Check the policy signature: policy verify [file]; exits 1 on mismatch", policyVerify},
+	}
+}
+
+// IsCommand reports whether args start with a subcommand rather than
+// GUI flags
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "help" {
+		return true
+	}
+	for _, c := range subcommands() {
+		if strings.Fields(c.name)[0] == args[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the subcommand in args against the active profile and returns
+// the exit code. Paths must already be set.
+func Run(args []string, opts Options) int {
+	if opts.Getenv == nil {
+		opts.Getenv = os.Getenv
+	}
+	if opts.FindConfigFile == nil {
+		opts.FindConfigFile = func(name string) string { return name }
+	}
+
+	cmd, rest, ok := lookup(args)
+	if !ok {
+		usage(opts.Stderr)
+		if len(args) > 0 && args[0] == "help" {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	e := &env{opts: opts, out: opts.Stdout}
+	if err := cmd.run(e, rest); err != nil {
+		var usageErr usageError
+		var failed checkFailed
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return ExitUsage
+		case errors.As(err, &usageErr):
+			fmt.Fprintf(opts.Stderr, "%s: %v\nusage: %s\n", cmd.name, err, cmd.summary)
+			return ExitUsage
+		case errors.As(err, &failed):
+			return ExitFail
+		default:
+			fmt.Fprintf(opts.Stderr, "%s: %v\n", cmd.name, err)
+			return ExitFail
+		}
+	}
+	return ExitOK
+}
+
+func lookup(args []string) (command, []string, bool) {
+	for _, c := range subcommands() {
+		words := strings.Fields(c.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == c.name {
+			return c, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: tf-engine [--data-dir <dir>] [--portable] <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range subcommands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nEvery command takes --format table|json. Encrypted data is unlocked with "+EnvPassphrase+".")
+}
+
+// usageError is a bad argument; Run prints the command's usage
+type usageError struct{ error }
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// checkFailed is a check that ran and failed; its output is already printed
+type checkFailed struct{}
+
+func (checkFailed) Error() string { return "check failed" }
+
+// env is what a command runs with
+type env struct {
+	opts   Options
+	out    io.Writer
+	format string
+	state  *appcore.AppState
+}
+
+// flags creates a command's flag set with the shared --format flag
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.opts.Stderr)
+	fs.StringVar(&e.format, "format", "table", "output format: table or json")
+	return fs
+}
+
+// parse parses args, allowing flags after positional arguments, and
+// checks the format
+func (e *env) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if e.format != "table" && e.format != "json" {
+		return nil, usageErrorf("unknown format %q", e.format)
+	}
+	return positional, nil
+}
+
+// load opens the active profile: settings, trades, ledger and, if wanted,
+// the policy
+func (e *env) load(withPolicy bool) error {
+	profile, err := storage.InitProfiles()
+	if err != nil {
+		return fmt.Errorf("load profiles: %w", err)
+	}
+	if storage.Locked() {
+		passphrase := e.opts.Getenv(EnvPassphrase)
+		if passphrase == "" {
+			return fmt.Errorf("data is encrypted: set %s", EnvPassphrase)
+		}
+		if err := storage.Unlock(passphrase); err != nil {
+			return err
+		}
+	}
+
+	e.state = appcore.NewAppState()
+	e.state.Profile = profile
+	if withPolicy {
+		if path, err := e.state.LoadProfilePolicy(e.opts.FindConfigFile("policy.v1.json")); err != nil {
+			fmt.Fprintf(e.opts.Stderr, "warning: policy %s: %v (using safe mode)\n", path, err)
+		}
+	}
+	return e.state.LoadProfileData()
+}
+
+// json writes v as indented JSON
+func (e *env) json(v interface{}) error {
+	enc := json.NewEncoder(e.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// table writes aligned columns
+func (e *env) table(headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fields writes label: value lines in order
+func (e *env) fields(pairs [][2]string) error {
+	tw := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+	for _, p := range pairs {
+		fmt.Fprintf(tw, "%s:\t%s\n", p[0], p[1])
+	}
+	return tw.Flush()
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.2f%%", v*100)
+}
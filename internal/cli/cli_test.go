@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/storage"
+)
+
+func setupTestDataDir(t *testing.T, trades []models.Trade) string {
+	t.Helper()
+	old := paths.Current()
+	saved := storage.TakeSnapshot()
+	root := t.TempDir()
+	paths.Set(paths.Under(root, paths.ModeFlag))
+	if _, err := storage.InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
+	t.Cleanup(func() {
+		paths.Set(old)
+		saved.Restore()
+	})
+	if trades != nil {
+		if _, err := (storage.TradeStore{}).Update(func([]models.Trade) ([]models.Trade, error) {
+			return trades, nil
+		}); err != nil {
+			t.Fatalf("Saving trades failed: %v", err)
+		}
+	}
+	return root
+}
+
+func testTrades() []models.Trade {
+	win, loss := 300.0, -150.0
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 10, 0, 0, 0, time.Local) }
+	return []models.Trade{
+		{ID: "T1", Ticker: "NVDA", Sector: "Technology", Strategy: "Alt10", Status: "active", MaxLoss: 400, Premium: 250, CreatedAt: day(1)},
+		{ID: "T2", Ticker: "UNH", Sector: "Healthcare", Strategy: "Alt26", Status: "closed", MaxLoss: 200, ProfitLoss: &win, CreatedAt: day(5)},
+		{ID: "T3", Ticker: "AAPL", Sector: "Technology", Strategy: "Alt10", Status: "closed", MaxLoss: 200, ProfitLoss: &loss, CreatedAt: day(9)},
+	}
+}
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := Run(args, Options{
+		Stdout: stdout,
+		Stderr: stderr,
+		Getenv: func(string) string { return "" },
+	})
+	return code, stdout.String(), stderr.String()
+}
+
+func TestIsCommand(t *testing.T) {
+	for args, want := range map[string]bool{
+		"trades list": true,
+		"stats":       true,
+		"help":        true,
+		"":            false,
+		"-portable":   false,
+		"nonsense":    false,
+	} {
+		if got := IsCommand(strings.Fields(args)); got != want {
+			t.Errorf("IsCommand(%q) = %v, want %v", args, got, want)
+		}
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	if code, _, stderr := run(t, "trades", "frobnicate"); code != ExitUsage || !strings.Contains(stderr, "trades close") {
+		t.Errorf("Unknown command: code %d, stderr %q", code, stderr)
+	}
+	if code, _, stderr := run(t, "trades", "list", "--format", "yaml"); code != ExitUsage || !strings.Contains(stderr, "unknown format") {
+		t.Errorf("Bad format: code %d, stderr %q", code, stderr)
+	}
+}
+
+func TestTradesList_Filters(t *testing.T) {
+	setupTestDataDir(t, testTrades())
+
+	code, stdout, stderr := run(t, "trades", "list", "--sector", "technology", "--status", "closed", "--format", "json")
+	if code != ExitOK {
+		t.Fatalf("Exit %d: %s", code, stderr)
+	}
+	var trades []models.Trade
+	if err := json.Unmarshal([]byte(stdout), &trades); err != nil {
+		t.Fatalf("Bad JSON %v: %s", err, stdout)
+	}
+	if len(trades) != 1 || trades[0].ID != "T3" {
+		t.Errorf("Expected T3, got %+v", trades)
+	}
+
+	_, stdout, _ = run(t, "trades", "list", "--since", "2026-09-05", "--until", "2026-09-05")
+	if !strings.Contains(stdout, "UNH") || strings.Contains(stdout, "NVDA") || strings.Contains(stdout, "AAPL") {
+		t.Errorf("Date filter should keep only UNH:\n%s", stdout)
+	}
+}
+
+func TestTradesShow(t *testing.T) {
+	setupTestDataDir(t, testTrades())
+
+	if code, stdout, _ := run(t, "trades", "show", "T2"); code != ExitOK || !strings.Contains(stdout, "300.00") {
+		t.Errorf("Exit %d:\n%s", code, stdout)
+	}
+	if code, _, stderr := run(t, "trades", "show", "T9"); code != ExitFail || !strings.Contains(stderr, "no trade") {
+		t.Errorf("Missing trade: exit %d, %q", code, stderr)
+	}
+}
+
+func TestTradesClose(t *testing.T) {
+	setupTestDataDir(t, testTrades())
+
+	if code, _, stderr := run(t, "trades", "close", "T1", "--date", "2026-10-01"); code != ExitUsage {
+		t.Errorf("Missing --price should be a usage error, got %d: %s", code, stderr)
+	}
+	code, stdout, stderr := run(t, "trades", "close", "T1", "--price", "400", "--date", "2026-10-01")
+	if code != ExitOK || !strings.Contains(stdout, "150.00") {
+		t.Fatalf("Exit %d: %s %s", code, stdout, stderr)
+	}
+
+	trades, err := storage.LoadAllTrades()
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := trades[0]
+	if closed.Status != "closed" || closed.ProfitLoss == nil || *closed.ProfitLoss != 150 ||
+		closed.ExitDate == nil || closed.ExitDate.Format(dateLayout) != "2026-10-01" {
+		t.Errorf("Trade not closed as expected: %+v", closed)
+	}
+
+	if code, _, stderr := run(t, "trades", "close", "T1", "--price", "1"); code != ExitFail || !strings.Contains(stderr, "already closed") {
+		t.Errorf("Closing twice: exit %d, %q", code, stderr)
+	}
+}
+
+func TestTradesImportExport(t *testing.T) {
+	root := setupTestDataDir(t, testTrades()[:1])
+
+	file := filepath.Join(root, "incoming.json")
+	data, _ := json.Marshal(testTrades()) // T1 already exists
+	os.WriteFile(file, data, 0644)
+
+	code, stdout, _ := run(t, "trades", "import", file, "--dry-run", "--format", "json")
+	var result importResult
+	json.Unmarshal([]byte(stdout), &result)
+	if code != ExitOK || len(result.Imported) != 2 || len(result.Skipped) != 1 || !result.DryRun {
+		t.Errorf("Dry run: exit %d, %+v", code, result)
+	}
+	if trades, _ := storage.LoadAllTrades(); len(trades) != 1 {
+		t.Errorf("Dry run saved trades: %d", len(trades))
+	}
+
+	if code, _, stderr := run(t, "trades", "import", file); code != ExitOK {
+		t.Fatalf("Import exit %d: %s", code, stderr)
+	}
+	out := filepath.Join(root, "export.json")
+	if code, _, stderr := run(t, "trades", "export", "--out", out); code != ExitOK {
+		t.Fatalf("Export exit %d: %s", code, stderr)
+	}
+	var exported []models.Trade
+	data, _ = os.ReadFile(out)
+	if err := json.Unmarshal(data, &exported); err != nil || len(exported) != 3 {
+		t.Errorf("Expected 3 exported trades (%v): %s", err, data)
+	}
+}
+
+func TestTradesReconcile(t *testing.T) {
+	spy := models.Trade{ID: "S1", Ticker: "SPY", Strike1: 600, Status: "active",
+		ExpirationDate: time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)}
+	setupTestDataDir(t, []models.Trade{spy})
+	statement := filepath.Join("..", "brokers", "testdata", "generic.csv")
+	mapping := filepath.Join("..", "brokers", "testdata", "generic_mapping.json")
+
+	code, stdout, stderr := run(t, "trades", "reconcile", statement, "--mapping", mapping)
+	if code != ExitOK || !strings.Contains(stdout, "1 close") || !strings.Contains(stdout, "--apply") {
+		t.Fatalf("Preview exit %d: %s%s", code, stdout, stderr)
+	}
+	if trades, _ := storage.LoadAllTrades(); trades[0].Status != "active" {
+		t.Error("Preview should not write")
+	}
+
+	code, stdout, _ = run(t, "trades", "reconcile", statement, "--mapping", mapping, "--broker", "generic", "--apply", "--format", "json")
+	var result reconcileResult
+	json.Unmarshal([]byte(stdout), &result)
+	if code != ExitOK || !result.Applied || result.Format != "generic" {
+		t.Errorf("Apply exit %d: %s", code, stdout)
+	}
+	if trades, _ := storage.LoadAllTrades(); trades[0].Status != "closed" || trades[0].GetPnL() != 273.70 {
+		t.Errorf("Expected SPY closed with P&L 273.70, got %s %.2f", trades[0].Status, trades[0].GetPnL())
+	}
+
+	if code, _, _ := run(t, "trades", "reconcile", statement, "--broker", "schwab"); code != ExitUsage {
+		t.Errorf("Unknown broker should be a usage error, got exit %d", code)
+	}
+}
+
+func TestExport(t *testing.T) {
+	root := setupTestDataDir(t, testTrades())
+
+	code, stdout, stderr := run(t, "export", "trades", "--columns", "id,ticker", "--since", "2026-09-05")
+	if code != ExitOK || stdout != "id,ticker\nT2,UNH\nT3,AAPL\n" {
+		t.Errorf("Exit %d (%s): %q", code, stderr, stdout)
+	}
+
+	code, stdout, _ = run(t, "export", "strategies", "--columns", "strategy,total_pnl")
+	if code != ExitOK || !strings.Contains(stdout, "Alt26,300.00") {
+		t.Errorf("Strategies: exit %d: %s", code, stdout)
+	}
+
+	out := filepath.Join(root, "archive.json")
+	if code, _, stderr := run(t, "export", "archive", "--until", "2026-09-05", "--out", out); code != ExitOK {
+		t.Fatalf("Archive exit %d: %s", code, stderr)
+	}
+	var archive struct {
+		Trades   []models.Trade   `json:"trades"`
+		Policy   *models.Policy   `json:"policy"`
+		Settings *models.Settings `json:"settings"`
+	}
+	data, _ := os.ReadFile(out)
+	if err := json.Unmarshal(data, &archive); err != nil || len(archive.Trades) != 2 || archive.Policy == nil || archive.Settings == nil {
+		t.Errorf("Unexpected archive (%v): %.200s", err, data)
+	}
+
+	for _, args := range [][]string{{"export"}, {"export", "positions"}, {"export", "trades", "--columns", "pnl"}, {"export", "trades", "--since", "May"},
+		{"export", "calendar", "--review-pct", "150"}} {
+		if code, _, _ := run(t, args...); code == ExitOK {
+			t.Errorf("%v: expected a failure", args)
+		}
+	}
+}
+
+func TestExportCalendar(t *testing.T) {
+	trades := testTrades()
+	trades[0].EntryDate = time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+	trades[0].ExpirationDate = time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	setupTestDataDir(t, trades)
+
+	code, stdout, stderr := run(t, "export", "calendar", "--since", "2026-09-01", "--exit-dte", "30")
+	if code != ExitOK {
+		t.Fatalf("Exit %d: %s", code, stderr)
+	}
+	for _, want := range []string{"UID:T1-expiration@tf-engine", "DTSTART;VALUE=DATE:20261016", "UID:T1-exit@tf-engine", "DTSTART;VALUE=DATE:20260916"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Missing %q in:\n%s", want, stdout)
+		}
+	}
+
+	if _, again, _ := run(t, "export", "calendar", "--since", "2026-09-01", "--until", "2026-09-30", "--review-pct", "0"); strings.Contains(again, "expiration") || strings.Contains(again, "review@") {
+		t.Errorf("Expected only September events without reviews:\n%s", again)
+	}
+}
+
+func TestStats_JSON(t *testing.T) {
+	setupTestDataDir(t, testTrades())
+
+	code, stdout, _ := run(t, "stats", "--format", "json")
+	var report analytics.StatsReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || code != ExitOK {
+		t.Fatalf("Exit %d (%v): %s", code, err, stdout)
+	}
+	if report.Overall.TotalTrades != 2 || report.Overall.TotalPnL != 150 || len(report.Sectors) != 2 || len(report.Strategies) != 2 {
+		t.Errorf("Unexpected stats %+v", report)
+	}
+}
+
+func TestHeat_ExitsOneOverCap(t *testing.T) {
+	// Without a policy file safe mode applies its caps
+	trades := testTrades()
+	trades[0].MaxLoss = 100
+	setupTestDataDir(t, trades)
+
+	code, stdout, _ := run(t, "heat", "--format", "json")
+	var report analytics.HeatReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Bad JSON %v: %s", err, stdout)
+	}
+	if code != ExitOK || report.ActiveTrades != 1 || report.OpenRisk != 100 || report.HeatCap == 0 {
+		t.Errorf("Exit %d, heat %+v", code, report)
+	}
+
+	trades[0].MaxLoss = 1e9
+	setupTestDataDir(t, trades)
+	if code, stdout, _ := run(t, "heat"); code != ExitFail || !strings.Contains(stdout, "OVER CAP") {
+		t.Errorf("Expected exit 1 over cap, got %d:\n%s", code, stdout)
+	}
+}
+
+func TestPolicyVerify(t *testing.T) {
+	root := setupTestDataDir(t, nil)
+
+	signed := filepath.Join(root, "signed.json")
+	os.WriteFile(signed, []byte(`{"version": "1.0", "security": {"signature": ""}}`), 0644)
+	v, _ := models.VerifyPolicyFile(signed)
+	os.WriteFile(signed, []byte(`{"version": "1.0", "security": {"signature": "`+v.Hash+`"}}`), 0644)
+	if code, stdout, _ := run(t, "policy", "verify", signed); code != ExitOK || !strings.Contains(stdout, models.PolicyVerified) {
+		t.Errorf("Signed policy: exit %d\n%s", code, stdout)
+	}
+
+	edited := filepath.Join(root, "edited.json")
+	os.WriteFile(edited, []byte(`{"version": "1.1", "security": {"signature": "`+v.Hash+`"}}`), 0644)
+	if code, _, _ := run(t, "policy", "verify", edited); code != ExitFail {
+		t.Errorf("Edited policy should exit 1, got %d", code)
+	}
+}
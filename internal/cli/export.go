@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"tf-engine/internal/export"
+)
+
+// exportKinds lists what export can write: the CSV tables, the archive and
+// the calendar
+func exportKinds() string {
+	return strings.Join(append(export.Kinds(), "archive", "calendar"), ", ")
+}
+
+func exportData(e *env, args []string) error {
+	fs := e.flags("export")
+	out := fs.String("out", "", "file to write (default stdout)")
+	columns := fs.String("columns", "", "comma-separated columns to keep, in order (CSV only)")
+	since := fs.String("since", "", "trades created (calendar: events dated) on or after YYYY-MM-DD")
+	until := fs.String("until", "", "trades created (calendar: events dated) on or before YYYY-MM-DD")
+	reviewPct := fs.Float64("review-pct", 50, "calendar: review once this percent of the entry DTE has passed (0 for none)")
+	exitDTE := fs.Int("exit-dte", 21, "calendar: planned exit at this many days to expiration (0 for none)")
+	rest, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageErrorf("want one of %s", exportKinds())
+	}
+	kind := rest[0]
+	if e.format == "json" {
+		return usageErrorf("export writes CSV tables; use \"export archive\" for JSON")
+	}
+	if *reviewPct < 0 || *reviewPct > 100 || *exitDTE < 0 {
+		return usageErrorf("--review-pct must be 0 to 100 and --exit-dte at least 0")
+	}
+	r, err := export.ParseRange(*since, *until)
+	if err != nil {
+		return usageError{err}
+	}
+	var selected []string
+	if *columns != "" {
+		selected = strings.Split(*columns, ",")
+	}
+	if kind != "archive" && kind != "calendar" {
+		all, err := export.Columns(kind)
+		if err != nil {
+			return usageError{err}
+		}
+		if _, err := (export.Table{Columns: all}).Select(selected); err != nil {
+			return usageError{err}
+		}
+	}
+
+	if err := e.load(kind == "archive" || kind == "calendar"); err != nil {
+		return err
+	}
+	src := export.Source{
+		Trades:   e.state.AllTrades,
+		Ledger:   e.state.Ledger,
+		Policy:   e.state.Policy,
+		Settings: e.state.Settings,
+	}
+
+	write := func(w io.Writer) error {
+		switch kind {
+		case "archive":
+			return export.WriteArchive(w, export.NewArchive(src, r, time.Now()))
+		case "calendar":
+			// The range selects event dates, from the calendar screen's look-back by default
+			if *since == "" {
+				r.Since = export.DefaultCalendarRange(src.Policy, time.Now()).Since
+			}
+			plan := export.CalendarPlan{ReviewFraction: *reviewPct / 100, ExitDTE: *exitDTE}
+			return export.WriteCalendar(w, src, r, plan, time.Now())
+		}
+		return export.WriteCSV(w, src, export.Options{Kind: kind, Columns: selected, Range: r})
+	}
+	if *out == "" {
+		return write(e.out)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.opts.Stderr, "Exported %s to %s\n", kind, *out)
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"tf-engine/internal/models"
+)
+
+// policyResult is the JSON output of policy verify
+type policyResult struct {
+	File string `json:"file"`
+	models.PolicyVerification
+}
+
+// policyVerify checks the given policy file, or the one the active profile
+// uses. Unsigned policies pass unless the policy enforces its hash.
+func policyVerify(e *env, args []string) error {
+	fs := e.flags("policy verify")
+	rest, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		return usageErrorf("want at most one policy file")
+	}
+
+	path := ""
+	if len(rest) == 1 {
+		path = rest[0]
+	} else {
+		if err := e.load(true); err != nil {
+			return err
+		}
+		path = e.state.PolicyPath()
+	}
+
+	v, err := models.VerifyPolicyFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if e.format == "json" {
+		if err := e.json(policyResult{File: path, PolicyVerification: v}); err != nil {
+			return err
+		}
+	} else {
+		if err := e.fields([][2]string{
+			{"File", path},
+			{"Status", v.Status},
+			{"Hash", v.Hash},
+			{"Signature", v.Signature},
+			{"Enforced", fmt.Sprint(v.EnforceHash)},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if v.Status == models.PolicyMismatch || (v.Status == models.PolicyUnsigned && v.EnforceHash) {
+		return checkFailed{}
+	}
+	return nil
+}
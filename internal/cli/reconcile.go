@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tf-engine/internal/brokers"
+)
+
+// reconcileResult reports what trades reconcile planned or did
+type reconcileResult struct {
+	Format  string       `json:"format"` // Statement format read
+	Plan    brokers.Plan `json:"plan"`
+	Applied bool         `json:"applied"`
+}
+
+func tradesReconcile(e *env, args []string) error {
+	fs := e.flags("trades reconcile")
+	broker := fs.String("broker", "", "statement format: thinkorswim, ibkr or generic (default: detect)")
+	mappingFile := fs.String("mapping", "", "JSON column mapping for generic CSV files")
+	apply := fs.Bool("apply", false, "write the planned changes (default: preview only)")
+	rest, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageErrorf("want one statement file")
+	}
+
+	mapping := brokers.DefaultMapping()
+	if *mappingFile != "" {
+		if mapping, err = brokers.LoadMapping(*mappingFile); err != nil {
+			return err
+		}
+	}
+	var parser brokers.Parser
+	if *broker != "" {
+		if parser, err = brokers.ParserFor(*broker, mapping); err != nil {
+			return usageError{err}
+		}
+	}
+
+	data, err := os.ReadFile(rest[0])
+	if err != nil {
+		return err
+	}
+	if parser == nil {
+		if parser, err = brokers.DetectParser(data, mapping); err != nil {
+			return fmt.Errorf("%s: %w", rest[0], err)
+		}
+	}
+	fills, err := parser.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", rest[0], err)
+	}
+	if err := e.load(true); err != nil {
+		return err
+	}
+
+	source := filepath.Base(rest[0])
+	plan := brokers.Reconcile(source, fills, e.state.AllTrades, e.state.Policy, time.Now())
+	result := reconcileResult{Format: parser.Name(), Plan: plan}
+	if *apply && plan.Changes() > 0 {
+		if err := e.state.ExecuteTradeCommand(plan.Command()); err != nil {
+			return fmt.Errorf("reconcile: %w", err)
+		}
+		result.Applied = true
+	}
+
+	if e.format == "json" {
+		return e.json(result)
+	}
+	rows := [][]string{}
+	for _, item := range plan.Items {
+		trade := "(new)"
+		if item.Before != nil {
+			trade = item.Before.ID
+		}
+		closed := "open"
+		if !item.Position.IsOpen() {
+			closed = item.Position.Closed.Format(dateLayout)
+		}
+		rows = append(rows, []string{item.Action, item.Position.Underlying, positionExpiration(item.Position),
+			item.Position.Opened.Format(dateLayout), closed, money(item.Position.PnL()), trade, item.Note})
+	}
+	if err := e.table([]string{"ACTION", "TICKER", "EXPIRATION", "OPENED", "CLOSED", "CASH", "TRADE", "NOTE"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.out, "\n%s %s: %d fill(s), %s\n", parser.Name(), source, len(fills), plan.Summary())
+	switch {
+	case result.Applied:
+		fmt.Fprintln(e.out, "Applied")
+	case plan.Changes() > 0:
+		fmt.Fprintln(e.out, "Preview only: run again with --apply to write these changes")
+	}
+	return nil
+}
+
+func positionExpiration(p brokers.Position) string {
+	if p.Expiration.IsZero() {
+		return "stock"
+	}
+	return p.Expiration.Format(dateLayout)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+
+	"tf-engine/internal/analytics"
+)
+
+func stats(e *env, args []string) error {
+	fs := e.flags("stats")
+	if rest, err := e.parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageErrorf("unexpected argument %q", rest[0])
+	}
+	if err := e.load(false); err != nil {
+		return err
+	}
+
+	report := analytics.CalculateStatsReport(e.state.AllTrades)
+	if e.format == "json" {
+		return e.json(report)
+	}
+
+	s := report.Overall
+	if err := e.fields([][2]string{
+		{"Closed trades", fmt.Sprintf("%d (%d won, %d lost)", s.TotalTrades, s.WinningTrades, s.LosingTrades)},
+		{"Win rate", fmt.Sprintf("%.1f%%", s.WinRate)},
+		{"Total P&L", money(s.TotalPnL)},
+		{"Average P&L", money(s.AveragePnL)},
+		{"Average win / loss", money(s.AverageWin) + " / " + money(s.AverageLoss)},
+		{"Largest win / loss", money(s.LargestWin) + " / " + money(s.LargestLoss)},
+		{"Profit factor", fmt.Sprintf("%.2f", s.ProfitFactor)},
+		{"Sharpe ratio", fmt.Sprintf("%.2f", s.SharpeRatio)},
+		{"Max drawdown", fmt.Sprintf("%s (%.1f%%)", money(s.MaxDrawdown), s.MaxDrawdownPct)},
+		{"Current streak", fmt.Sprint(s.CurrentStreak)},
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.out)
+	rows := make([][]string, len(report.Sectors))
+	for i, r := range report.Sectors {
+		rows[i] = []string{r.Sector, fmt.Sprint(r.TotalTrades), fmt.Sprintf("%.1f%%", r.WinRate), money(r.TotalPnL), money(r.AveragePnL)}
+	}
+	if err := e.table([]string{"SECTOR", "TRADES", "WIN RATE", "TOTAL P&L", "AVG P&L"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.out)
+	rows = make([][]string, len(report.Strategies))
+	for i, r := range report.Strategies {
+		rows[i] = []string{r.Strategy, fmt.Sprint(r.TotalTrades), fmt.Sprintf("%.1f%%", r.WinRate), money(r.TotalPnL), money(r.AveragePnL)}
+	}
+	if err := e.table([]string{"STRATEGY", "TRADES", "WIN RATE", "TOTAL P&L", "AVG P&L"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.out)
+	rows = make([][]string, len(report.Convictions))
+	for i, r := range report.Convictions {
+		rows[i] = []string{fmt.Sprint(r.Conviction), fmt.Sprint(r.TotalTrades), fmt.Sprintf("%.1f%%", r.WinRate), money(r.TotalPnL), money(r.AveragePnL)}
+	}
+	return e.table([]string{"CONVICTION", "TRADES", "WIN RATE", "TOTAL P&L", "AVG P&L"}, rows)
+}
+
+func heat(e *env, args []string) error {
+	fs := e.flags("heat")
+	if rest, err := e.parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageErrorf("unexpected argument %q", rest[0])
+	}
+	if err := e.load(true); err != nil {
+		return err
+	}
+
+	report := analytics.CalculateHeat(e.state.AllTrades, e.state.CurrentEquity(), e.state.Policy)
+	over := report.OverCap()
+	for _, s := range report.Sectors {
+		over = over || s.OverCap()
+	}
+
+	if e.format == "json" {
+		if err := e.json(report); err != nil {
+			return err
+		}
+	} else {
+		rows := [][]string{heatRow("PORTFOLIO", report.ActiveTrades, report.OpenRisk, report.Heat, report.HeatCap, report.OverCap())}
+		for _, s := range report.Sectors {
+			rows = append(rows, heatRow(s.Sector, s.ActiveTrades, s.OpenRisk, s.Heat, s.HeatCap, s.OverCap()))
+		}
+		fmt.Fprintf(e.out, "Equity: %s\n\n", money(report.Equity))
+		if err := e.table([]string{"SCOPE", "TRADES", "OPEN RISK", "HEAT", "CAP", ""}, rows); err != nil {
+			return err
+		}
+	}
+
+	if over {
+		return checkFailed{}
+	}
+	return nil
+}
+
+func heatRow(scope string, trades int, risk, heat, heatCap float64, over bool) []string {
+	capText, note := "—", ""
+	if heatCap > 0 {
+		capText = percent(heatCap)
+	}
+	if over {
+		note = "OVER CAP"
+	}
+	return []string{scope, fmt.Sprint(trades), money(risk), percent(heat), capText, note}
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tf-engine/internal/commands"
+	"tf-engine/internal/models"
+)
+
+// dateLayout is the date format of every date flag
+const dateLayout = "2006-01-02"
+
+// tradeFilter selects trades for list and export
+type tradeFilter struct {
+	status, sector, strategy, ticker string
+	since, until                     string
+}
+
+func (f *tradeFilter) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.status, "status", "", "active, closed or expired")
+	fs.StringVar(&f.sector, "sector", "", "sector name (case-insensitive)")
+	fs.StringVar(&f.strategy, "strategy", "", "strategy name (case-insensitive)")
+	fs.StringVar(&f.ticker, "ticker", "", "ticker symbol (case-insensitive)")
+	fs.StringVar(&f.since, "since", "", "created on or after YYYY-MM-DD")
+	fs.StringVar(&f.until, "until", "", "created on or before YYYY-MM-DD")
+}
+
+// apply returns the trades that match every filter that is set
+func (f *tradeFilter) apply(trades []models.Trade) ([]models.Trade, error) {
+	filter := models.TradeFilter{Status: f.status, Sector: f.sector, Strategy: f.strategy, Ticker: f.ticker}
+	var err error
+	if f.since != "" {
+		if filter.Since, err = time.ParseInLocation(dateLayout, f.since, time.Local); err != nil {
+			return nil, usageErrorf("--since: want YYYY-MM-DD, got %q", f.since)
+		}
+	}
+	if f.until != "" {
+		if filter.Until, err = time.ParseInLocation(dateLayout, f.until, time.Local); err != nil {
+			return nil, usageErrorf("--until: want YYYY-MM-DD, got %q", f.until)
+		}
+		filter.Until = filter.Until.AddDate(0, 0, 1) // Inclusive
+	}
+	return filter.Apply(trades), nil
+}
+
+func tradesList(e *env, args []string) error {
+	fs := e.flags("trades list")
+	var filter tradeFilter
+	filter.register(fs)
+	if rest, err := e.parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageErrorf("unexpected argument %q", rest[0])
+	}
+	if err := e.load(false); err != nil {
+		return err
+	}
+
+	trades, err := filter.apply(e.state.AllTrades)
+	if err != nil {
+		return err
+	}
+	if e.format == "json" {
+		return e.json(trades)
+	}
+
+	rows := make([][]string, len(trades))
+	for i := range trades {
+		t := &trades[i]
+		pnl := "—"
+		if t.ProfitLoss != nil {
+			pnl = money(*t.ProfitLoss)
+		}
+		rows[i] = []string{t.ID, t.CreatedAt.Format(dateLayout), t.Ticker, t.Sector, t.Strategy,
+			t.GetStatus(), money(t.MaxLoss), pnl}
+	}
+	return e.table([]string{"ID", "CREATED", "TICKER", "SECTOR", "STRATEGY", "STATUS", "MAX LOSS", "P&L"}, rows)
+}
+
+func tradesShow(e *env, args []string) error {
+	fs := e.flags("trades show")
+	rest, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageErrorf("want one trade ID")
+	}
+	if err := e.load(false); err != nil {
+		return err
+	}
+
+	trade, err := e.find(rest[0])
+	if err != nil {
+		return err
+	}
+	if e.format == "json" {
+		return e.json(trade)
+	}
+	return e.fields(tradeFields(trade))
+}
+
+// tradeFields lists a trade's main fields for table output
+func tradeFields(t models.Trade) [][2]string {
+	pairs := [][2]string{
+		{"ID", t.ID},
+		{"Ticker", t.Ticker},
+		{"Sector", t.Sector},
+		{"Strategy", t.Strategy},
+		{"Options", t.OptionsStrategy},
+		{"Status", t.GetStatus()},
+		{"Created", t.CreatedAt.Format(time.RFC3339)},
+		{"Expiration", t.ExpirationDate.Format(dateLayout)},
+		{"Position size", fmt.Sprint(t.PositionSize)},
+		{"Max loss", money(t.MaxLoss)},
+		{"Premium", money(t.Premium)},
+	}
+	if t.ExitDate != nil {
+		pairs = append(pairs, [2]string{"Exit date", t.ExitDate.Format(dateLayout)})
+	}
+	if t.ExitPrice != nil {
+		pairs = append(pairs, [2]string{"Exit price", money(*t.ExitPrice)})
+	}
+	if t.ProfitLoss != nil {
+		pairs = append(pairs, [2]string{"P&L", money(*t.ProfitLoss)})
+	}
+	if t.OpenPnL != nil {
+		pairs = append(pairs, [2]string{"Open P&L", money(*t.OpenPnL)})
+	}
+	return pairs
+}
+
+// find returns the loaded trade with id
+func (e *env) find(id string) (models.Trade, error) {
+	for _, t := range e.state.AllTrades {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return models.Trade{}, fmt.Errorf("no trade with ID %s", id)
+}
+
+func tradesClose(e *env, args []string) error {
+	fs := e.flags("trades close")
+	price := fs.Float64("price", 0, "total exit value in dollars (required)")
+	date := fs.String("date", "", "exit date YYYY-MM-DD (default today)")
+	pnl := fs.String("pnl", "", "realized P&L in dollars (default exit value minus premium)")
+	rest, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageErrorf("want one trade ID")
+	}
+	priceSet := false
+	fs.Visit(func(f *flag.Flag) { priceSet = priceSet || f.Name == "price" })
+	if !priceSet {
+		return usageErrorf("--price is required")
+	}
+	exitDate := time.Now()
+	if *date != "" {
+		if exitDate, err = time.ParseInLocation(dateLayout, *date, time.Local); err != nil {
+			return usageErrorf("--date: want YYYY-MM-DD, got %q", *date)
+		}
+	}
+	if err := e.load(false); err != nil {
+		return err
+	}
+
+	var realized *float64
+	if *pnl != "" {
+		realized = new(float64)
+		if _, err := fmt.Sscanf(*pnl, "%f", realized); err != nil {
+			return usageErrorf("--pnl: want a number, got %q", *pnl)
+		}
+	}
+	after, err := e.state.CloseTrade(rest[0], *price, exitDate, realized)
+	if err != nil {
+		return err
+	}
+
+	if e.format == "json" {
+		return e.json(after)
+	}
+	fmt.Fprintf(e.out, "Closed %s %s on %s: P&L %s\n", after.ID, after.Ticker, exitDate.Format(dateLayout), money(after.GetPnL()))
+	return nil
+}
+
+// importResult reports what trades import did
+type importResult struct {
+	Imported []string `json:"imported"`
+	Skipped  []string `json:"skipped"` // IDs already in the history
+	DryRun   bool     `json:"dry_run"`
+}
+
+func tradesImport(e *env, args []string) error {
+	fs := e.flags("trades import")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without saving")
+	rest, err := e.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usageErrorf("want one JSON file")
+	}
+
+	data, err := os.ReadFile(rest[0])
+	if err != nil {
+		return err
+	}
+	var incoming []models.Trade
+	if err := json.Unmarshal(data, &incoming); err != nil {
+		return fmt.Errorf("%s: want a JSON array of trades: %w", rest[0], err)
+	}
+	if err := e.load(false); err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	for _, t := range e.state.AllTrades {
+		existing[t.ID] = true
+	}
+	result := importResult{Imported: []string{}, Skipped: []string{}, DryRun: *dryRun}
+	trades := []models.Trade{}
+	now := time.Now()
+	for _, t := range incoming {
+		if t.ID == "" {
+			t.ID = models.NewTradeID()
+			for existing[t.ID] {
+				t.ID = models.NewTradeID()
+			}
+		} else if existing[t.ID] {
+			result.Skipped = append(result.Skipped, t.ID)
+			continue
+		}
+		if t.CreatedAt.IsZero() {
+			t.CreatedAt = now
+		}
+		t.UpdatedAt = now
+		existing[t.ID] = true
+		trades = append(trades, t)
+		result.Imported = append(result.Imported, t.ID)
+	}
+
+	if !*dryRun && len(trades) > 0 {
+		cmd := commands.NewImportCommand(filepath.Base(rest[0]), trades)
+		if err := e.state.ExecuteTradeCommand(cmd); err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+	}
+
+	if e.format == "json" {
+		return e.json(result)
+	}
+	verb := "Imported"
+	if *dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(e.out, "%s %d trade(s), skipped %d already present\n", verb, len(result.Imported), len(result.Skipped))
+	for _, id := range result.Skipped {
+		fmt.Fprintf(e.out, "  skipped %s\n", id)
+	}
+	return nil
+}
+
+func tradesExport(e *env, args []string) error {
+	fs := e.flags("trades export")
+	out := fs.String("out", "", "file to write (default stdout)")
+	var filter tradeFilter
+	filter.register(fs)
+	if rest, err := e.parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageErrorf("unexpected argument %q", rest[0])
+	}
+	if err := e.load(false); err != nil {
+		return err
+	}
+
+	trades, err := filter.apply(e.state.AllTrades)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return e.json(trades) // Export is always JSON, readable by trades import
+	}
+
+	data, err := json.MarshalIndent(trades, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.opts.Stderr, "Exported %d trade(s) to %s\n", len(trades), *out)
+	return nil
+}
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"tf-engine/internal/models"
+)
+
+// ErrTradeNotFound is returned when a command's trade no longer exists
+var ErrTradeNotFound = errors.New("trade not found")
+
+// Command is a reversible operation on the trade history
+type Command interface {
+	// Name describes the command for undo/redo prompts ("Delete AAPL")
+	Name() string
+	// Apply returns the trade history with the command applied
+	Apply(trades []models.Trade) ([]models.Trade, error)
+	// Revert returns the trade history with the command undone
+	Revert(trades []models.Trade) ([]models.Trade, error)
+}
+
+// EditCommand replaces a trade with an edited version
+type EditCommand struct {
+	Before models.Trade
+	After  models.Trade
+}
+
+// NewEditCommand creates a command that edits a trade
+func NewEditCommand(before, after models.Trade) *EditCommand {
+	return &EditCommand{Before: before, After: after}
+}
+
+// Name describes the edit
+func (c *EditCommand) Name() string {
+	if c.Before.GetStatus() != "closed" && c.After.GetStatus() == "closed" {
+		return "Close " + c.After.Ticker
+	}
+	return "Edit " + c.After.Ticker
+}
+
+// Apply writes the edited trade
+func (c *EditCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	return replaceTrade(trades, c.After)
+}
+
+// Revert restores the trade as it was before the edit
+func (c *EditCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	return replaceTrade(trades, c.Before)
+}
+
+// PatchCommand changes some fields of a trade. The patch is applied to the
+// stored version, so fields another process changed meanwhile are kept.
+type PatchCommand struct {
+	ID     string
+	Patch  func(*models.Trade)
+	Before models.Trade // Stored version the patch was applied to
+	After  models.Trade
+}
+
+// NewPatchCommand creates a command that patches a trade
+func NewPatchCommand(id string, patch func(*models.Trade)) *PatchCommand {
+	return &PatchCommand{ID: id, Patch: patch}
+}
+
+// Name describes the patch
+func (c *PatchCommand) Name() string {
+	return "Edit " + c.After.Ticker
+}
+
+// Apply patches the stored trade, remembering the version it replaced
+func (c *PatchCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	i := indexOf(trades, c.ID)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, c.ID)
+	}
+	after := trades[i]
+	c.Patch(&after)
+	if err := after.Validate(); err != nil {
+		return nil, err
+	}
+	c.Before, c.After = trades[i], after
+
+	result := append([]models.Trade{}, trades...)
+	result[i] = after
+	return result, nil
+}
+
+// Revert restores the trade as it was before the patch
+func (c *PatchCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	return replaceTrade(trades, c.Before)
+}
+
+// DeleteCommand removes a trade, remembering its position for undo
+type DeleteCommand struct {
+	Trade models.Trade
+	index int
+}
+
+// NewDeleteCommand creates a command that deletes a trade
+func NewDeleteCommand(trade models.Trade) *DeleteCommand {
+	return &DeleteCommand{Trade: trade}
+}
+
+// Name describes the delete
+func (c *DeleteCommand) Name() string {
+	return "Delete " + c.Trade.Ticker
+}
+
+// Apply removes the trade, remembering the stored version and its position
+func (c *DeleteCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	i := indexOf(trades, c.Trade.ID)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, c.Trade.ID)
+	}
+	c.index = i
+	c.Trade = trades[i] // Restore the stored version, not the caller's copy
+
+	result := make([]models.Trade, 0, len(trades)-1)
+	result = append(result, trades[:i]...)
+	return append(result, trades[i+1:]...), nil
+}
+
+// Revert puts the trade back where it was
+func (c *DeleteCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	if indexOf(trades, c.Trade.ID) >= 0 {
+		return nil, fmt.Errorf("trade %s already exists", c.Trade.ID)
+	}
+
+	i := c.index
+	if i > len(trades) {
+		i = len(trades)
+	}
+
+	result := make([]models.Trade, 0, len(trades)+1)
+	result = append(result, trades[:i]...)
+	result = append(result, c.Trade)
+	return append(result, trades[i:]...), nil
+}
+
+// BulkStatusCommand sets the status of several trades at once
+type BulkStatusCommand struct {
+	IDs      []string
+	Status   string
+	previous map[string]string
+}
+
+// NewBulkStatusCommand creates a command that sets the status of trades
+func NewBulkStatusCommand(ids []string, status string) *BulkStatusCommand {
+	return &BulkStatusCommand{IDs: ids, Status: status}
+}
+
+// Name describes the status change
+func (c *BulkStatusCommand) Name() string {
+	return fmt.Sprintf("Mark %d trades %s", len(c.IDs), c.Status)
+}
+
+// Apply sets the new status, remembering each trade's previous status
+func (c *BulkStatusCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	result := append([]models.Trade{}, trades...)
+	c.previous = map[string]string{}
+
+	for _, id := range c.IDs {
+		i := indexOf(result, id)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, id)
+		}
+		c.previous[id] = result[i].Status
+		result[i].Status = c.Status
+	}
+	return result, nil
+}
+
+// Revert restores each trade's previous status
+func (c *BulkStatusCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	result := append([]models.Trade{}, trades...)
+
+	for id, status := range c.previous {
+		i := indexOf(result, id)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, id)
+		}
+		result[i].Status = status
+	}
+	return result, nil
+}
+
+// ImportCommand appends imported trades
+type ImportCommand struct {
+	Source string
+	Trades []models.Trade
+}
+
+// NewImportCommand creates a command that adds imported trades
+func NewImportCommand(source string, trades []models.Trade) *ImportCommand {
+	return &ImportCommand{Source: source, Trades: trades}
+}
+
+// Name describes the import
+func (c *ImportCommand) Name() string {
+	if c.Source == "" {
+		return fmt.Sprintf("Import %d trades", len(c.Trades))
+	}
+	return fmt.Sprintf("Import %d trades from %s", len(c.Trades), c.Source)
+}
+
+// Apply appends the imported trades. Fails if any ID already exists.
+func (c *ImportCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	for _, t := range c.Trades {
+		if indexOf(trades, t.ID) >= 0 {
+			return nil, fmt.Errorf("trade %s already exists", t.ID)
+		}
+	}
+	result := append([]models.Trade{}, trades...)
+	return append(result, c.Trades...), nil
+}
+
+// Revert removes the imported trades
+func (c *ImportCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	imported := map[string]bool{}
+	for _, t := range c.Trades {
+		imported[t.ID] = true
+	}
+
+	result := []models.Trade{}
+	for _, t := range trades {
+		if !imported[t.ID] {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// RestoreCommand replaces the whole trade history (e.g. from a backup)
+type RestoreCommand struct {
+	Source   string
+	Trades   []models.Trade
+	previous []models.Trade
+}
+
+// NewRestoreCommand creates a command that restores a trade history
+func NewRestoreCommand(source string, trades []models.Trade) *RestoreCommand {
+	return &RestoreCommand{Source: source, Trades: trades}
+}
+
+// Name describes the restore
+func (c *RestoreCommand) Name() string {
+	return "Restore " + c.Source
+}
+
+// Apply replaces the history, remembering the one it replaced
+func (c *RestoreCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	c.previous = append([]models.Trade{}, trades...)
+	return append([]models.Trade{}, c.Trades...), nil
+}
+
+// Revert puts back the history that was replaced
+func (c *RestoreCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	return append([]models.Trade{}, c.previous...), nil
+}
+
+// ReconcileCommand applies a broker statement reconciliation as one undo
+// step: edits to matched trades plus new trades for unmatched positions
+type ReconcileCommand struct {
+	Source string
+	Edits  []*EditCommand
+	Added  []models.Trade
+}
+
+// NewReconcileCommand creates a command that edits and adds trades together
+func NewReconcileCommand(source string, edits []*EditCommand, added []models.Trade) *ReconcileCommand {
+	return &ReconcileCommand{Source: source, Edits: edits, Added: added}
+}
+
+// Name describes the reconciliation
+func (c *ReconcileCommand) Name() string {
+	return fmt.Sprintf("Reconcile %s (%d updated, %d added)", c.Source, len(c.Edits), len(c.Added))
+}
+
+// Apply writes each edit, then appends the new trades
+func (c *ReconcileCommand) Apply(trades []models.Trade) ([]models.Trade, error) {
+	var err error
+	for _, edit := range c.Edits {
+		if trades, err = edit.Apply(trades); err != nil {
+			return nil, err
+		}
+	}
+	return NewImportCommand(c.Source, c.Added).Apply(trades)
+}
+
+// Revert removes the new trades and undoes the edits
+func (c *ReconcileCommand) Revert(trades []models.Trade) ([]models.Trade, error) {
+	trades, err := NewImportCommand(c.Source, c.Added).Revert(trades)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(c.Edits) - 1; i >= 0; i-- {
+		if trades, err = c.Edits[i].Revert(trades); err != nil {
+			return nil, err
+		}
+	}
+	return trades, nil
+}
+
+func indexOf(trades []models.Trade, id string) int {
+	for i := range trades {
+		if trades[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func replaceTrade(trades []models.Trade, trade models.Trade) ([]models.Trade, error) {
+	i := indexOf(trades, trade.ID)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, trade.ID)
+	}
+	result := append([]models.Trade{}, trades...)
+	result[i] = trade
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"errors"
+	"sync"
+
+	"tf-engine/internal/models"
+)
+
+// DefaultLimit is how many commands the stack remembers
+const DefaultLimit = 100
+
+// ErrNothingToUndo and ErrNothingToRedo are returned by an empty stack
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Store holds the trade history a command operates on. Update loads the
+// history, transforms it and saves the result as one step, so no other
+// writer can change the file in between.
+type Store interface {
+	Update(transform func([]models.Trade) ([]models.Trade, error)) ([]models.Trade, error)
+}
+
+// Stack records executed commands for undo and redo
+type Stack struct {
+	mu     sync.Mutex
+	done   []Command
+	undone []Command
+	limit  int
+}
+
+// NewStack creates an empty command stack
+func NewStack() *Stack {
+	return &Stack{limit: DefaultLimit}
+}
+
+// Execute applies a command and records it for undo. Clears the redo stack.
+func (s *Stack) Execute(cmd Command, store Store) ([]models.Trade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trades, err := store.Update(cmd.Apply)
+	if err != nil {
+		return nil, err
+	}
+
+	s.done = append(s.done, cmd)
+	if s.limit > 0 && len(s.done) > s.limit {
+		s.done = s.done[len(s.done)-s.limit:]
+	}
+	s.undone = nil
+	return trades, nil
+}
+
+// Undo reverts the most recent command
+func (s *Stack) Undo(store Store) (Command, []models.Trade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.done) == 0 {
+		return nil, nil, ErrNothingToUndo
+	}
+	cmd := s.done[len(s.done)-1]
+
+	trades, err := store.Update(cmd.Revert)
+	if err != nil {
+		return cmd, nil, err
+	}
+
+	s.done = s.done[:len(s.done)-1]
+	s.undone = append(s.undone, cmd)
+	return cmd, trades, nil
+}
+
+// Redo re-applies the most recently undone command
+func (s *Stack) Redo(store Store) (Command, []models.Trade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.undone) == 0 {
+		return nil, nil, ErrNothingToRedo
+	}
+	cmd := s.undone[len(s.undone)-1]
+
+	trades, err := store.Update(cmd.Apply)
+	if err != nil {
+		return cmd, nil, err
+	}
+
+	s.undone = s.undone[:len(s.undone)-1]
+	s.done = append(s.done, cmd)
+	return cmd, trades, nil
+}
+
+// UndoName returns the name of the command Undo would revert
+func (s *Stack) UndoName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.done) == 0 {
+		return ""
+	}
+	return s.done[len(s.done)-1].Name()
+}
+
+// RedoName returns the name of the command Redo would re-apply
+func (s *Stack) RedoName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.undone) == 0 {
+		return ""
+	}
+	return s.undone[len(s.undone)-1].Name()
+}
+
+// Clear forgets all commands (e.g. after switching profiles)
+func (s *Stack) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = nil
+	s.undone = nil
+}
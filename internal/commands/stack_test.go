@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"tf-engine/internal/models"
+)
+
+// memoryStore keeps trades in memory and counts saves
+type memoryStore struct {
+	trades []models.Trade
+	saves  int
+	fail   error
+}
+
+func (m *memoryStore) Update(transform func([]models.Trade) ([]models.Trade, error)) ([]models.Trade, error) {
+	after, err := transform(append([]models.Trade{}, m.trades...))
+	if err != nil {
+		return nil, err
+	}
+	if m.fail != nil {
+		return nil, m.fail
+	}
+	m.saves++
+	m.trades = after
+	return after, nil
+}
+
+func sampleStore() *memoryStore {
+	return &memoryStore{trades: []models.Trade{
+		{ID: "1", Ticker: "AAPL", Status: "active"},
+		{ID: "2", Ticker: "MSFT", Status: "active"},
+		{ID: "3", Ticker: "XOM", Status: "active"},
+	}}
+}
+
+func tickers(trades []models.Trade) string {
+	s := ""
+	for _, t := range trades {
+		s += t.Ticker + " "
+	}
+	return s
+}
+
+func TestStack_DeleteUndoRedo(t *testing.T) {
+	store := sampleStore()
+	stack := NewStack()
+
+	if _, err := stack.Execute(NewDeleteCommand(store.trades[1]), store); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := tickers(store.trades); got != "AAPL XOM " {
+		t.Errorf("After delete: %q", got)
+	}
+	if stack.UndoName() != "Delete MSFT" {
+		t.Errorf("Unexpected undo name %q", stack.UndoName())
+	}
+
+	cmd, _, err := stack.Undo(store)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if cmd.Name() != "Delete MSFT" {
+		t.Errorf("Undid wrong command %q", cmd.Name())
+	}
+	if got := tickers(store.trades); got != "AAPL MSFT XOM " {
+		t.Errorf("Undo should restore the trade in place, got %q", got)
+	}
+
+	if _, _, err := stack.Redo(store); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if got := tickers(store.trades); got != "AAPL XOM " {
+		t.Errorf("After redo: %q", got)
+	}
+
+	if _, _, err := stack.Redo(store); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestStack_EditAndBulkStatus(t *testing.T) {
+	store := sampleStore()
+	stack := NewStack()
+
+	edited := store.trades[0]
+	pnl := 150.0
+	edited.ProfitLoss = &pnl
+	edited.Status = "closed"
+	stack.Execute(NewEditCommand(store.trades[0], edited), store)
+
+	if stack.UndoName() != "Close AAPL" {
+		t.Errorf("Expected close name, got %q", stack.UndoName())
+	}
+
+	stack.Execute(NewBulkStatusCommand([]string{"2", "3"}, "expired"), store)
+	if store.trades[1].Status != "expired" || store.trades[2].Status != "expired" {
+		t.Errorf("Bulk status not applied: %+v", store.trades)
+	}
+
+	// Undo both, newest first
+	stack.Undo(store)
+	if store.trades[1].Status != "active" {
+		t.Errorf("Bulk undo should restore status, got %s", store.trades[1].Status)
+	}
+	stack.Undo(store)
+	if store.trades[0].ProfitLoss != nil || store.trades[0].Status != "active" {
+		t.Errorf("Edit undo should restore the original trade, got %+v", store.trades[0])
+	}
+
+	if _, _, err := stack.Undo(store); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+}
+
+func TestStack_ImportAndNewCommandClearsRedo(t *testing.T) {
+	store := sampleStore()
+	stack := NewStack()
+
+	imported := []models.Trade{{ID: "9", Ticker: "NVDA"}}
+	if _, err := stack.Execute(NewImportCommand("broker.csv", imported), store); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(store.trades) != 4 {
+		t.Fatalf("Expected 4 trades after import, got %d", len(store.trades))
+	}
+
+	stack.Undo(store)
+	if len(store.trades) != 3 {
+		t.Errorf("Undo import should remove the trades, got %d", len(store.trades))
+	}
+
+	// A new command discards the redo history
+	stack.Execute(NewDeleteCommand(store.trades[0]), store)
+	if stack.RedoName() != "" {
+		t.Errorf("Redo stack should be cleared, got %q", stack.RedoName())
+	}
+
+	// Re-importing an existing ID is refused
+	if _, err := stack.Execute(NewImportCommand("", []models.Trade{{ID: "2"}}), store); err == nil {
+		t.Error("Expected duplicate import to fail")
+	}
+}
+
+func TestStack_FailedSaveIsNotRecorded(t *testing.T) {
+	store := sampleStore()
+	store.fail = errors.New("disk full")
+	stack := NewStack()
+
+	if _, err := stack.Execute(NewDeleteCommand(store.trades[0]), store); err == nil {
+		t.Fatal("Expected save error")
+	}
+	if stack.UndoName() != "" {
+		t.Error("Failed command should not be undoable")
+	}
+
+	// Deleting a trade that is already gone fails cleanly
+	store.fail = nil
+	_, err := stack.Execute(NewDeleteCommand(models.Trade{ID: "missing"}), store)
+	if !errors.Is(err, ErrTradeNotFound) {
+		t.Errorf("Expected ErrTradeNotFound, got %v", err)
+	}
+}
+
+func TestStack_RestoreUndo(t *testing.T) {
+	store := sampleStore()
+	stack := NewStack()
+
+	restored := []models.Trade{{ID: "0", Ticker: "OLD"}}
+	stack.Execute(NewRestoreCommand("trades_20260301_090000.zip", restored), store)
+	if got := tickers(store.trades); got != "OLD " {
+		t.Errorf("After restore: %q", got)
+	}
+
+	stack.Undo(store)
+	if got := tickers(store.trades); got != "AAPL MSFT XOM " {
+		t.Errorf("Undo restore should bring back the replaced history, got %q", got)
+	}
+}
+
+func TestStack_ReconcileIsOneStep(t *testing.T) {
+	store := sampleStore()
+	stack := NewStack()
+
+	closed := store.trades[1]
+	closed.Status = "closed"
+	cmd := NewReconcileCommand("statement.csv",
+		[]*EditCommand{NewEditCommand(store.trades[1], closed)},
+		[]models.Trade{{ID: "9", Ticker: "NVDA", Status: "closed"}})
+	if _, err := stack.Execute(cmd, store); err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if got := tickers(store.trades); got != "AAPL MSFT XOM NVDA " || store.trades[1].Status != "closed" {
+		t.Errorf("After reconcile: %q, MSFT %s", got, store.trades[1].Status)
+	}
+	if store.saves != 1 {
+		t.Errorf("Reconcile should save once, saved %d times", store.saves)
+	}
+
+	stack.Undo(store)
+	if got := tickers(store.trades); got != "AAPL MSFT XOM " || store.trades[1].Status != "active" {
+		t.Errorf("Undo reconcile: %q, MSFT %s", got, store.trades[1].Status)
+	}
+}
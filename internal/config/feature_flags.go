@@ -8,16 +8,19 @@ import (
 
 // FeatureFlag represents a single feature flag configuration
 type FeatureFlag struct {
-	Enabled      bool   `json:"enabled"`
-	Description  string `json:"description"`
-	Phase        int    `json:"phase"`
-	SinceVersion string `json:"since_version"`
+	Enabled      bool     `json:"enabled"`
+	Description  string   `json:"description"`
+	Phase        int      `json:"phase"`
+	SinceVersion string   `json:"since_version"`      // Minimum app version
+	Requires     []string `json:"requires,omitempty"` // Flags that must also be on
 }
 
 // FeatureFlags represents the complete feature flags configuration
 type FeatureFlags struct {
 	Version string                 `json:"version"`
 	Flags   map[string]FeatureFlag `json:"flags"`
+
+	Sources map[string]FlagSource `json:"-"` // Set by ResolveFeatureFlags
 }
 
 // LoadFeatureFlags loads feature flags from the specified path
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Layers a flag's value can come from, lowest precedence first
+const (
+	LayerDefault = "default"
+	LayerFile    = "file"
+	LayerUser    = "user"
+	LayerEnv     = "env"
+	LayerCLI     = "cli"
+)
+
+// UserFlagsFile is the per-user override file, kept in the config directory
+const UserFlagsFile = "feature.flags.user.json"
+
+// FlagsVersion is the feature level of the flags this build ships. Flags
+// with a later SinceVersion stay off. It moves with the flag definitions, not
+// with the app's release version.
+const FlagsVersion = "2.3.0"
+
+// EnvFlagPrefix starts the environment variables that override flags, e.g.
+// TF_ENGINE_FLAG_ADVANCED_ANALYTICS=1
+const EnvFlagPrefix = "TF_ENGINE_FLAG_"
+
+// FlagSource explains a flag's effective value
+type FlagSource struct {
+	Layer   string // Layer that set the value
+	Detail  string // File, variable or argument the value came from
+	Blocked string // Why the flag was forced off despite being set on
+}
+
+// LayerOptions are the inputs to ResolveFeatureFlags
+type LayerOptions struct {
+	File     string              // feature.flags.json; a missing file is skipped
+	UserFile string              // Per-user overrides; a missing file is skipped
+	Getenv   func(string) string // Defaults to os.Getenv
+	CLI      map[string]bool     // --flag arguments
+	Version  string              // Flags with a later SinceVersion stay off; defaults to FlagsVersion
+}
+
+// DefaultFeatureFlags returns the built-in flags, all off
+func DefaultFeatureFlags() *FeatureFlags {
+	return &FeatureFlags{
+		Version: "1.0.0",
+		Flags: map[string]FeatureFlag{
+			"trade_management": {
+				Description:  "Screen 9: Edit/delete trades",
+				Phase:        2,
+				SinceVersion: "2.1.0",
+			},
+			"sample_data_generator": {
+				Description:  "Generate sample trades for testing",
+				Phase:        2,
+				SinceVersion: "2.1.0",
+			},
+			"vimium_mode": {
+				Description:  "Keyboard navigation shortcuts",
+				Phase:        2,
+				SinceVersion: "2.2.0",
+			},
+			"advanced_analytics": {
+				Description:  "Win rate tracking, equity curves",
+				Phase:        2,
+				SinceVersion: "2.3.0",
+				Requires:     []string{"trade_management"},
+			},
+		},
+	}
+}
+
+// ResolveFeatureFlags layers built-in defaults, feature.flags.json, the
+// per-user file, environment variables and command-line flags, each
+// overriding the one before. Flags whose SinceVersion is newer than the
+// flags version or whose required flags are off are then forced off.
+//
+// The returned flags are always usable: a layer that fails to load is
+// skipped and reported in the error.
+func ResolveFeatureFlags(opts LayerOptions) (*FeatureFlags, error) {
+	if opts.Getenv == nil {
+		opts.Getenv = os.Getenv
+	}
+	if opts.Version == "" {
+		opts.Version = FlagsVersion
+	}
+
+	ff := DefaultFeatureFlags()
+	ff.Sources = map[string]FlagSource{}
+	for name := range ff.Flags {
+		ff.Sources[name] = FlagSource{Layer: LayerDefault}
+	}
+
+	var errs []error
+	for _, file := range []struct{ layer, path string }{
+		{LayerFile, opts.File},
+		{LayerUser, opts.UserFile},
+	} {
+		if err := ff.mergeFile(file.layer, file.path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range ff.Names() {
+		variable := EnvFlagPrefix + strings.ToUpper(name)
+		value := opts.Getenv(variable)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", variable, err))
+			continue
+		}
+		ff.set(name, enabled, FlagSource{Layer: LayerEnv, Detail: variable})
+	}
+
+	for name, enabled := range opts.CLI {
+		if _, ok := ff.Flags[name]; !ok {
+			errs = append(errs, fmt.Errorf("--flag %s: unknown feature flag", name))
+			continue
+		}
+		ff.set(name, enabled, FlagSource{Layer: LayerCLI, Detail: "--flag " + name})
+	}
+
+	ff.applyGates(opts.Version)
+	return ff, errors.Join(errs...)
+}
+
+// mergeFile applies a flags file. Flags it defines are added; for known
+// flags it sets the value and any metadata it gives.
+func (ff *FeatureFlags) mergeFile(layer, path string) error {
+	if path == "" {
+		return nil
+	}
+	file, err := LoadFeatureFlags(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s layer %s: %w", layer, path, err)
+	}
+
+	if layer == LayerFile && file.Version != "" {
+		ff.Version = file.Version
+	}
+	for name, flag := range file.Flags {
+		merged, ok := ff.Flags[name]
+		if !ok {
+			merged = flag
+		}
+		if flag.Description != "" {
+			merged.Description = flag.Description
+		}
+		if flag.Phase != 0 {
+			merged.Phase = flag.Phase
+		}
+		if flag.SinceVersion != "" {
+			merged.SinceVersion = flag.SinceVersion
+		}
+		if flag.Requires != nil {
+			merged.Requires = flag.Requires
+		}
+		ff.Flags[name] = merged
+		ff.set(name, flag.Enabled, FlagSource{Layer: layer, Detail: path})
+	}
+	return nil
+}
+
+// set records a layer's value for a flag
+func (ff *FeatureFlags) set(name string, enabled bool, source FlagSource) {
+	flag := ff.Flags[name]
+	flag.Enabled = enabled
+	ff.Flags[name] = flag
+	ff.Sources[name] = source
+}
+
+// applyGates forces off flags that need a newer app version or a flag that
+// is off, until nothing changes (dependencies can chain)
+func (ff *FeatureFlags) applyGates(version string) {
+	block := func(name, reason string) {
+		flag := ff.Flags[name]
+		flag.Enabled = false
+		ff.Flags[name] = flag
+		source := ff.Sources[name]
+		source.Blocked = reason
+		ff.Sources[name] = source
+	}
+
+	names := ff.Names()
+	for _, name := range names {
+		flag := ff.Flags[name]
+		if flag.Enabled && flag.SinceVersion != "" && CompareVersions(version, flag.SinceVersion) < 0 {
+			block(name, fmt.Sprintf("needs flags version %s (this build has %s)", flag.SinceVersion, version))
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, name := range names {
+			if !ff.Flags[name].Enabled {
+				continue
+			}
+			for _, required := range ff.Flags[name].Requires {
+				if !ff.IsEnabled(required) {
+					block(name, "requires "+required)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// Names returns the flag names in order
+func (ff *FeatureFlags) Names() []string {
+	names := make([]string, 0, len(ff.Flags))
+	for name := range ff.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns where a flag's value came from. Flags not built by
+// ResolveFeatureFlags report the file layer.
+func (ff *FeatureFlags) Source(name string) FlagSource {
+	if source, ok := ff.Sources[name]; ok {
+		return source
+	}
+	return FlagSource{Layer: LayerFile}
+}
+
+// CompareVersions compares dotted versions such as "2.1.0" or "v2.3",
+// returning -1, 0 or 1. Missing parts count as 0 and anything after a "-"
+// (pre-release tags) is ignored.
+func CompareVersions(a, b string) int {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if i := strings.Index(v, "-"); i >= 0 {
+			v = v[:i]
+		}
+		parts := []int{}
+		for _, p := range strings.Split(v, ".") {
+			n, _ := strconv.Atoi(p)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := parse(a), parse(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// FlagOverrides collects repeated --flag name[=bool] arguments
+type FlagOverrides map[string]bool
+
+func (o FlagOverrides) String() string {
+	parts := []string{}
+	for name, enabled := range o {
+		parts = append(parts, fmt.Sprintf("%s=%t", name, enabled))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Set parses "name" (on) or "name=false"
+func (o FlagOverrides) Set(value string) error {
+	name, raw, hasValue := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("flag name is empty")
+	}
+	enabled := true
+	if hasValue {
+		var err error
+		if enabled, err = strconv.ParseBool(raw); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	o[name] = enabled
+	return nil
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFlags(t *testing.T, dir, name, json string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(json), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveFeatureFlags_LayerPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFlags(t, dir, "feature.flags.json", `{"version": "1.2.0", "flags": {
+		"trade_management": {"enabled": true},
+		"sample_data_generator": {"enabled": true},
+		"vimium_mode": {"enabled": true},
+		"advanced_analytics": {"enabled": false}
+	}}`)
+	user := writeFlags(t, dir, UserFlagsFile, `{"flags": {
+		"sample_data_generator": {"enabled": false},
+		"advanced_analytics": {"enabled": true}
+	}}`)
+	env := map[string]string{EnvFlagPrefix + "VIMIUM_MODE": "false"}
+
+	ff, err := ResolveFeatureFlags(LayerOptions{
+		File:     file,
+		UserFile: user,
+		Getenv:   func(k string) string { return env[k] },
+		CLI:      map[string]bool{"sample_data_generator": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		layer   string
+	}{
+		{"trade_management", true, LayerFile},
+		{"advanced_analytics", true, LayerUser},
+		{"vimium_mode", false, LayerEnv},
+		{"sample_data_generator", true, LayerCLI},
+	}
+	for _, tt := range tests {
+		if got := ff.IsEnabled(tt.name); got != tt.enabled {
+			t.Errorf("%s: enabled=%v, want %v", tt.name, got, tt.enabled)
+		}
+		if got := ff.Source(tt.name).Layer; got != tt.layer {
+			t.Errorf("%s: set by %s, want %s", tt.name, got, tt.layer)
+		}
+	}
+	if ff.Version != "1.2.0" {
+		t.Errorf("Expected the file's version, got %s", ff.Version)
+	}
+}
+
+func TestResolveFeatureFlags_DefaultsWithoutFiles(t *testing.T) {
+	dir := t.TempDir()
+	ff, err := ResolveFeatureFlags(LayerOptions{
+		File:     filepath.Join(dir, "missing.json"),
+		UserFile: filepath.Join(dir, "missing.user.json"),
+		Getenv:   func(string) string { return "" },
+	})
+	if err != nil {
+		t.Fatalf("Missing files should be skipped: %v", err)
+	}
+	if len(ff.ListEnabledFlags()) != 0 {
+		t.Errorf("Built-in defaults should be off, got %v", ff.ListEnabledFlags())
+	}
+	if ff.GetFlag("advanced_analytics") == nil || ff.Source("advanced_analytics").Layer != LayerDefault {
+		t.Error("Known flags should exist with the default layer")
+	}
+}
+
+func TestResolveFeatureFlags_Dependencies(t *testing.T) {
+	ff, _ := ResolveFeatureFlags(LayerOptions{
+		Getenv: func(string) string { return "" },
+		CLI:    map[string]bool{"advanced_analytics": true},
+	})
+	if ff.IsEnabled("advanced_analytics") {
+		t.Error("advanced_analytics requires trade_management")
+	}
+	if got := ff.Source("advanced_analytics"); got.Layer != LayerCLI || got.Blocked != "requires trade_management" {
+		t.Errorf("Expected the CLI value blocked by its dependency, got %+v", got)
+	}
+
+	ff, _ = ResolveFeatureFlags(LayerOptions{
+		Getenv: func(string) string { return "" },
+		CLI:    map[string]bool{"advanced_analytics": true, "trade_management": true},
+	})
+	if !ff.IsEnabled("advanced_analytics") || ff.Source("advanced_analytics").Blocked != "" {
+		t.Errorf("advanced_analytics should be on with its dependency, got %+v", ff.Source("advanced_analytics"))
+	}
+}
+
+func TestResolveFeatureFlags_ChainedDependencies(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFlags(t, dir, "feature.flags.json", `{"flags": {
+		"trade_management": {"enabled": false},
+		"advanced_analytics": {"enabled": true},
+		"forecasts": {"enabled": true, "requires": ["advanced_analytics"]}
+	}}`)
+
+	ff, _ := ResolveFeatureFlags(LayerOptions{File: file, Getenv: func(string) string { return "" }})
+	if ff.IsEnabled("forecasts") || ff.Source("forecasts").Blocked != "requires advanced_analytics" {
+		t.Errorf("A flag needing a blocked flag should be blocked too, got %+v", ff.Source("forecasts"))
+	}
+}
+
+func TestResolveFeatureFlags_MinVersion(t *testing.T) {
+	cli := map[string]bool{"trade_management": true, "vimium_mode": true, "advanced_analytics": true}
+
+	// Every built-in flag is available at this build's flags version
+	ff, _ := ResolveFeatureFlags(LayerOptions{Getenv: func(string) string { return "" }, CLI: cli})
+	for name := range cli {
+		if !ff.IsEnabled(name) {
+			t.Errorf("%s should be on at flags version %s: %s", name, FlagsVersion, ff.Source(name).Blocked)
+		}
+	}
+
+	ff, _ = ResolveFeatureFlags(LayerOptions{Getenv: func(string) string { return "" }, CLI: cli, Version: "2.2.5"})
+	if !ff.IsEnabled("trade_management") {
+		t.Error("trade_management (2.1.0) should be on in 2.2.5")
+	}
+	if ff.IsEnabled("advanced_analytics") {
+		t.Error("advanced_analytics (2.3.0) should be off in 2.2.5")
+	}
+	if got := ff.Source("advanced_analytics").Blocked; got != "needs flags version 2.3.0 (this build has 2.2.5)" {
+		t.Errorf("Unexpected reason %q", got)
+	}
+}
+
+func TestResolveFeatureFlags_BadLayersReported(t *testing.T) {
+	dir := t.TempDir()
+	user := writeFlags(t, dir, UserFlagsFile, `{not json`)
+	env := map[string]string{EnvFlagPrefix + "TRADE_MANAGEMENT": "maybe"}
+
+	ff, err := ResolveFeatureFlags(LayerOptions{
+		UserFile: user,
+		Getenv:   func(k string) string { return env[k] },
+		CLI:      map[string]bool{"no_such_flag": true, "vimium_mode": true},
+	})
+	if err == nil {
+		t.Fatal("Expected errors for the bad user file, env value and unknown flag")
+	}
+	if ff == nil || !ff.IsEnabled("vimium_mode") {
+		t.Error("Good layers should still apply")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.3.0", "2.3.0", 0},
+		{"2.3", "2.3.0", 0},
+		{"v2.10.0", "2.9.1", 1},
+		{"1.0.0", "2.1.0", -1},
+		{"2.3.0-beta", "2.3.0", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFlagOverrides_Set(t *testing.T) {
+	overrides := FlagOverrides{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(overrides, "flag", "")
+
+	if err := fs.Parse([]string{"--flag", "vimium_mode", "--flag", "trade_management=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if !overrides["vimium_mode"] || overrides["trade_management"] {
+		t.Errorf("Unexpected overrides %v", overrides)
+	}
+	if err := overrides.Set("x=sometimes"); err == nil {
+		t.Error("Expected an error for a bad value")
+	}
+}
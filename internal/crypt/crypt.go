@@ -0,0 +1,198 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Magic prefixes every encrypted file so plaintext files can still be read
+var Magic = []byte("TFENC1\n")
+
+const (
+	keyLen   = 32 // AES-256
+	saltLen  = 16
+	checkMsg = "tf-engine key check"
+)
+
+// Errors returned when data can't be decrypted
+var (
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	ErrLocked          = errors.New("data is encrypted; unlock with the passphrase first")
+	ErrCorrupt         = errors.New("encrypted data is corrupt or was encrypted with another key")
+)
+
+// Params are the scrypt cost parameters
+type Params struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// DefaultParams take roughly 100ms to derive a key on a desktop machine
+var DefaultParams = Params{N: 1 << 15, R: 8, P: 1}
+
+// KeyFile stores what's needed to re-derive the key from the passphrase. It
+// never contains the key itself.
+type KeyFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Params  Params `json:"params"`
+	Salt    []byte `json:"salt"`
+	Check   []byte `json:"check"` // A known message sealed with the key
+}
+
+// Key encrypts and decrypts data with AES-GCM
+type Key struct {
+	aead cipher.AEAD
+}
+
+// NewKey derives a key from a new passphrase with a fresh salt
+func NewKey(passphrase string, params Params) (*KeyFile, *Key, error) {
+	if passphrase == "" {
+		return nil, nil, errors.New("passphrase cannot be empty")
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("random error: %w", err)
+	}
+
+	kf := &KeyFile{Version: 1, KDF: "scrypt", Params: params, Salt: salt}
+	key, err := kf.derive(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kf.Check, err = key.Seal([]byte(checkMsg))
+	if err != nil {
+		return nil, nil, err
+	}
+	return kf, key, nil
+}
+
+// Unlock derives the key from the passphrase and checks it
+func (kf *KeyFile) Unlock(passphrase string) (*Key, error) {
+	key, err := kf.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := key.Open(kf.Check)
+	if err != nil || string(check) != checkMsg {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
+
+func (kf *KeyFile) derive(passphrase string) (*Key, error) {
+	if kf.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation %q", kf.KDF)
+	}
+
+	raw, err := scrypt.Key([]byte(passphrase), kf.Salt, kf.Params.N, kf.Params.R, kf.Params.P, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation error: %w", err)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead}, nil
+}
+
+// Seal encrypts data: Magic, then a random nonce, then the ciphertext
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("random error: %w", err)
+	}
+
+	out := make([]byte, 0, len(Magic)+len(nonce)+len(plaintext)+k.aead.Overhead())
+	out = append(out, Magic...)
+	out = append(out, nonce...)
+	return k.aead.Seal(out, nonce, plaintext, Magic), nil
+}
+
+// Open decrypts data produced by Seal
+func (k *Key) Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, ErrCorrupt
+	}
+	data = data[len(Magic):]
+
+	n := k.aead.NonceSize()
+	if len(data) < n {
+		return nil, ErrCorrupt
+	}
+
+	plaintext, err := k.aead.Open(nil, data[:n], data[n:], Magic)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether data was produced by Seal
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, Magic)
+}
+
+// Decode returns plaintext as-is and decrypts encrypted data. A nil key
+// returns ErrLocked for encrypted data.
+func Decode(key *Key, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if key == nil {
+		return nil, ErrLocked
+	}
+	return key.Open(data)
+}
+
+// Encode encrypts data, or returns it as-is when key is nil
+func Encode(key *Key, data []byte) ([]byte, error) {
+	if key == nil {
+		return data, nil
+	}
+	return key.Seal(data)
+}
+
+// LoadKeyFile reads a key file. Returns nil if it doesn't exist.
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+
+	var kf KeyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &kf, nil
+}
+
+// Marshal encodes the key file. Storage writes it with the same durable
+// path as the data files it protects.
+func (kf *KeyFile) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	return data, nil
+}
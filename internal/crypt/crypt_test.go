@@ -0,0 +1,98 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testParams keep key derivation fast in tests
+var testParams = Params{N: 1 << 10, R: 8, P: 1}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	_, key, err := NewKey("correct horse", testParams)
+	if err != nil {
+		t.Fatalf("NewKey failed: %v", err)
+	}
+
+	plaintext := []byte(`[{"ticker":"AAPL","profit_loss":340}]`)
+	sealed, err := key.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, []byte("AAPL")) {
+		t.Error("Sealed data should be marked encrypted and not contain plaintext")
+	}
+
+	opened, err := key.Open(sealed)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Fatalf("Open returned %q, %v", opened, err)
+	}
+
+	// Tampering is detected
+	sealed[len(sealed)-1] ^= 0xFF
+	if _, err := key.Open(sealed); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for tampered data, got %v", err)
+	}
+}
+
+func TestKeyFileUnlock(t *testing.T) {
+	kf, key, err := NewKey("correct horse", testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "encryption.json")
+	data, err := kf.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadKeyFile(path)
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadKeyFile failed: %v", err)
+	}
+
+	if _, err := loaded.Unlock("battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	unlocked, err := loaded.Unlock("correct horse")
+	if err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	sealed, _ := key.Seal([]byte("secret"))
+	if opened, err := unlocked.Open(sealed); err != nil || string(opened) != "secret" {
+		t.Errorf("Unlocked key should open data sealed by the original key: %q, %v", opened, err)
+	}
+
+	if missing, err := LoadKeyFile(filepath.Join(t.TempDir(), "none.json")); missing != nil || err != nil {
+		t.Errorf("Missing key file should return nil, nil; got %v, %v", missing, err)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	_, key, _ := NewKey("pw", testParams)
+	plain := []byte(`{"account_equity":25000}`)
+
+	// Without a key, data passes through and encrypted data is locked
+	if out, _ := Encode(nil, plain); !bytes.Equal(out, plain) {
+		t.Error("Encode without a key should return plaintext")
+	}
+	sealed, _ := Encode(key, plain)
+	if _, err := Decode(nil, sealed); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked, got %v", err)
+	}
+
+	// With a key, plaintext files are still readable
+	if out, err := Decode(key, plain); err != nil || !bytes.Equal(out, plain) {
+		t.Errorf("Decode should pass plaintext through: %q, %v", out, err)
+	}
+	if out, err := Decode(key, sealed); err != nil || !bytes.Equal(out, plain) {
+		t.Errorf("Decode failed: %q, %v", out, err)
+	}
+}
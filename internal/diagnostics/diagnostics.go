@@ -0,0 +1,325 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/storage"
+)
+
+// Report is everything a bug report needs except the logs, with no
+// tickers, prices or account values
+type Report struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	App         AppInfo      `json:"app"`
+	Build       BuildInfo    `json:"build"`
+	System      SystemInfo   `json:"system"`
+	Policy      PolicyInfo   `json:"policy"`
+	Flags       []FlagInfo   `json:"flags"`
+	Trades      TradeSummary `json:"trades"`
+	Errors      []string     `json:"errors,omitempty"` // Anything that couldn't be collected
+}
+
+// AppInfo identifies the running app
+type AppInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// BuildInfo comes from runtime/debug.ReadBuildInfo
+type BuildInfo struct {
+	GoVersion   string            `json:"go_version"`
+	Module      string            `json:"module,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	VCSRevision string            `json:"vcs_revision,omitempty"`
+	VCSTime     string            `json:"vcs_time,omitempty"`
+	VCSModified bool              `json:"vcs_modified,omitempty"`
+	Settings    map[string]string `json:"settings,omitempty"` // Other build settings (-tags, CGO_ENABLED...)
+}
+
+// SystemInfo describes the machine and data directory
+type SystemInfo struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	NumCPU    int    `json:"num_cpu"`
+	DataMode  string `json:"data_mode"` // How the data directory was chosen
+	ReadOnly  bool   `json:"read_only"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+// PolicyInfo is the loaded policy and its signature check
+type PolicyInfo struct {
+	File     string `json:"file"` // Base name only
+	Version  string `json:"version"`
+	SafeMode bool   `json:"safe_mode"`
+	models.PolicyVerification
+	Error string `json:"error,omitempty"`
+}
+
+// FlagInfo is a feature flag's effective value and where it came from
+type FlagInfo struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Layer   string `json:"layer"`
+	Blocked string `json:"blocked,omitempty"`
+}
+
+// TradeSummary counts the active profile's trades without identifying them
+type TradeSummary struct {
+	Total      int            `json:"total"`
+	ByStatus   map[string]int `json:"by_status"`
+	BySector   map[string]int `json:"by_sector"`
+	ByStrategy map[string]int `json:"by_strategy"`
+	Drafts     int            `json:"drafts"`
+	Profiles   int            `json:"profiles"`
+	FirstMonth string         `json:"first_month,omitempty"` // YYYY-MM of the oldest trade
+	LastMonth  string         `json:"last_month,omitempty"`
+	MissingIDs int            `json:"missing_ids,omitempty"` // Trades without an ID (data problem)
+}
+
+// Options name the app being diagnosed
+type Options struct {
+	AppName    string
+	AppVersion string
+	Now        time.Time // Defaults to time.Now()
+}
+
+// Collect gathers a report for the running app
+func Collect(state *appcore.AppState, opts Options) Report {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	r := Report{
+		GeneratedAt: opts.Now,
+		App:         AppInfo{Name: opts.AppName, Version: opts.AppVersion},
+		Build:       ReadBuild(),
+		System: SystemInfo{
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			NumCPU:    runtime.NumCPU(),
+			DataMode:  string(paths.Current().Mode),
+			ReadOnly:  storage.ReadOnly(),
+			Encrypted: storage.EncryptionEnabled(),
+		},
+	}
+
+	r.Policy = PolicyInfo{SafeMode: state.SafeModeActive}
+	if state.Policy != nil {
+		r.Policy.Version = state.Policy.Version
+	}
+	if path := state.PolicyPath(); path != "" {
+		r.Policy.File = filepath.Base(path)
+		v, err := models.VerifyPolicyFile(path)
+		if err != nil {
+			r.Policy.Error = err.Error()
+		}
+		r.Policy.PolicyVerification = v
+	}
+
+	if state.FeatureFlags != nil {
+		for _, name := range state.FeatureFlags.Names() {
+			source := state.FeatureFlags.Source(name)
+			r.Flags = append(r.Flags, FlagInfo{
+				Name:    name,
+				Enabled: state.FeatureFlags.IsEnabled(name),
+				Layer:   source.Layer,
+				Blocked: source.Blocked,
+			})
+		}
+	}
+
+	r.Trades = SummarizeTrades(state.AllTrades)
+	if drafts, err := storage.LoadDrafts(); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("drafts: %v", err))
+	} else {
+		r.Trades.Drafts = len(drafts)
+	}
+	if list, err := storage.LoadProfiles(); err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("profiles: %v", err))
+	} else {
+		r.Trades.Profiles = len(list.Profiles)
+	}
+	return r
+}
+
+// ReadBuild reports the Go version, module and VCS stamp of the binary
+func ReadBuild() BuildInfo {
+	b := BuildInfo{GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	b.GoVersion = info.GoVersion
+	b.Module = info.Main.Path
+	b.Version = info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.VCSRevision = s.Value
+		case "vcs.time":
+			b.VCSTime = s.Value
+		case "vcs.modified":
+			b.VCSModified = s.Value == "true"
+		default:
+			if b.Settings == nil {
+				b.Settings = map[string]string{}
+			}
+			b.Settings[s.Key] = s.Value
+		}
+	}
+	return b
+}
+
+// SummarizeTrades counts trades by status, sector and strategy. Tickers,
+// strikes, prices and P&L are left out.
+func SummarizeTrades(trades []models.Trade) TradeSummary {
+	s := TradeSummary{
+		Total:      len(trades),
+		ByStatus:   map[string]int{},
+		BySector:   map[string]int{},
+		ByStrategy: map[string]int{},
+	}
+	var first, last time.Time
+	for i := range trades {
+		t := &trades[i]
+		s.ByStatus[t.GetStatus()]++
+		s.BySector[label(t.Sector)]++
+		s.ByStrategy[label(t.Strategy)]++
+		if t.ID == "" {
+			s.MissingIDs++
+		}
+		if t.CreatedAt.IsZero() {
+			continue
+		}
+		if first.IsZero() || t.CreatedAt.Before(first) {
+			first = t.CreatedAt
+		}
+		if t.CreatedAt.After(last) {
+			last = t.CreatedAt
+		}
+	}
+	if !first.IsZero() {
+		s.FirstMonth = first.Format("2006-01")
+		s.LastMonth = last.Format("2006-01")
+	}
+	return s
+}
+
+func label(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// FileName suggests a name for a diagnostics bundle made at now
+func FileName(now time.Time) string {
+	return "tf-engine-diagnostics-" + now.Format("20060102-150405") + ".zip"
+}
+
+// MaxLogBytes caps the logs added to a bundle, newest files first
+const MaxLogBytes = 8 << 20
+
+// WriteZip writes report.json and the most recent .log files in logDir
+// (up to MaxLogBytes) to w. It returns the log files included.
+func WriteZip(w io.Writer, r Report, logDir string) ([]string, error) {
+	zw := zip.NewWriter(w)
+
+	report, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := addFile(zw, "report.json", r.GeneratedAt, bytes.NewReader(report)); err != nil {
+		return nil, err
+	}
+
+	logs, err := recentLogs(logDir, MaxLogBytes)
+	if err != nil {
+		return nil, fmt.Errorf("list logs: %w", err)
+	}
+	included := []string{}
+	for _, log := range logs {
+		f, err := os.Open(filepath.Join(logDir, log.Name()))
+		if err != nil {
+			continue // Rotated away while we were collecting
+		}
+		err = addFile(zw, "logs/"+log.Name(), log.ModTime(), io.LimitReader(f, log.Size()))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		included = append(included, log.Name())
+	}
+
+	return included, zw.Close()
+}
+
+// Export writes a diagnostics bundle to path
+func Export(path string, r Report, logDir string) ([]string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	included, err := WriteZip(f, r, logDir)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("export diagnostics: %w", err)
+	}
+	return included, nil
+}
+
+func addFile(zw *zip.Writer, name string, modified time.Time, content io.Reader) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// recentLogs returns the newest .log files whose sizes fit in limit
+func recentLogs(dir string, limit int64) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logs := []os.FileInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".log" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			logs = append(logs, info)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].ModTime().After(logs[j].ModTime()) })
+
+	var total int64
+	for i, log := range logs {
+		if total+log.Size() > limit {
+			return logs[:i], nil
+		}
+		total += log.Size()
+	}
+	return logs, nil
+}
@@ -0,0 +1,142 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/config"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/storage"
+)
+
+func setupTestDataDir(t *testing.T) string {
+	t.Helper()
+	old := paths.Current()
+	saved := storage.TakeSnapshot()
+	root := t.TempDir()
+	paths.Set(paths.Under(root, paths.ModeFlag))
+	if _, err := storage.InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
+	t.Cleanup(func() {
+		paths.Set(old)
+		saved.Restore()
+	})
+	return root
+}
+
+func testTrades() []models.Trade {
+	pnl := 412.5
+	return []models.Trade{
+		{ID: "T1", Ticker: "NVDA", Sector: "Technology", Strategy: "Alt10", Status: "active", Premium: 3.2,
+			CreatedAt: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{ID: "T2", Ticker: "UNH", Sector: "Healthcare", Strategy: "Alt10", Status: "closed", ProfitLoss: &pnl,
+			CreatedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{Ticker: "XLU", Status: "closed"},
+	}
+}
+
+func TestSummarizeTrades_Anonymized(t *testing.T) {
+	s := SummarizeTrades(testTrades())
+
+	if s.Total != 3 || s.ByStatus["closed"] != 2 || s.ByStrategy["Alt10"] != 2 || s.BySector["(none)"] != 1 {
+		t.Errorf("Unexpected counts %+v", s)
+	}
+	if s.FirstMonth != "2026-03" || s.LastMonth != "2026-09" || s.MissingIDs != 1 {
+		t.Errorf("Unexpected range or missing IDs %+v", s)
+	}
+
+	data, _ := json.Marshal(s)
+	for _, secret := range []string{"NVDA", "UNH", "XLU", "412.5", "3.2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Summary leaks %q: %s", secret, data)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	root := setupTestDataDir(t)
+
+	policyPath := filepath.Join(root, "policy.v1.json")
+	os.WriteFile(policyPath, []byte(`{"version": "1.0", "security": {"signature": "abc"}}`), 0644)
+
+	state := appcore.NewAppState()
+	state.LoadProfilePolicy(policyPath)
+	state.AllTrades = testTrades()
+	state.FeatureFlags, _ = config.ResolveFeatureFlags(config.LayerOptions{
+		Getenv: func(string) string { return "" },
+		CLI:    map[string]bool{"vimium_mode": true},
+	})
+
+	r := Collect(state, Options{AppName: "TF-Engine", AppVersion: "2.3.0"})
+
+	if r.System.OS != runtime.GOOS || r.System.Arch != runtime.GOARCH || r.Build.GoVersion == "" {
+		t.Errorf("Unexpected system/build info %+v %+v", r.System, r.Build)
+	}
+	if r.Policy.File != "policy.v1.json" || r.Policy.Status != models.PolicyMismatch || len(r.Policy.Hash) != 64 {
+		t.Errorf("Unexpected policy info %+v", r.Policy)
+	}
+	if len(r.Flags) != 4 {
+		t.Fatalf("Expected every flag, got %+v", r.Flags)
+	}
+	for _, f := range r.Flags {
+		if f.Name == "vimium_mode" && (!f.Enabled || f.Layer != config.LayerCLI) {
+			t.Errorf("Unexpected vimium_mode %+v", f)
+		}
+	}
+	if r.Trades.Total != 3 || r.Trades.Profiles != 1 || len(r.Errors) != 0 {
+		t.Errorf("Unexpected trades %+v, errors %v", r.Trades, r.Errors)
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	logDir := t.TempDir()
+	os.WriteFile(filepath.Join(logDir, "tf-engine.log"), []byte("newest\n"), 0644)
+	os.WriteFile(filepath.Join(logDir, "tf-engine.1.log"), []byte("older\n"), 0644)
+	os.WriteFile(filepath.Join(logDir, "notes.txt"), []byte("not a log"), 0644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(logDir, "tf-engine.1.log"), old, old)
+
+	buf := &bytes.Buffer{}
+	r := Report{GeneratedAt: time.Now(), App: AppInfo{Name: "TF-Engine", Version: "2.3.0"}}
+	included, err := WriteZip(buf, r, logDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(included, ",") != "tf-engine.log,tf-engine.1.log" {
+		t.Errorf("Expected logs newest first, got %v", included)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	if files["logs/tf-engine.log"] != "newest\n" || files["logs/tf-engine.1.log"] != "older\n" {
+		t.Errorf("Unexpected logs in bundle: %v", files)
+	}
+	if _, ok := files["logs/notes.txt"]; ok {
+		t.Error("Only .log files belong in the bundle")
+	}
+
+	var report Report
+	if err := json.Unmarshal([]byte(files["report.json"]), &report); err != nil || report.App.Version != "2.3.0" {
+		t.Errorf("Bad report.json (%v): %s", err, files["report.json"])
+	}
+}
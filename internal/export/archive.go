@@ -0,0 +1,55 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/models"
+)
+
+// ArchiveFormat identifies the layout of an archive
+const ArchiveFormat = "tf-engine-archive/1"
+
+// Archive is the full JSON export: the trades in range with their
+// statistics, the ledger, and snapshots of the policy and settings in use
+type Archive struct {
+	Format     string                `json:"format"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Since      *time.Time            `json:"since,omitempty"`
+	Until      *time.Time            `json:"until,omitempty"` // Exclusive
+	Trades     []models.Trade        `json:"trades"`
+	Stats      analytics.StatsReport `json:"stats"`
+	Ledger     *models.Ledger        `json:"ledger,omitempty"`
+	Policy     *models.Policy        `json:"policy,omitempty"`
+	Settings   *models.Settings      `json:"settings,omitempty"`
+}
+
+// NewArchive builds the archive of the trades in r
+func NewArchive(src Source, r Range, now time.Time) Archive {
+	trades := r.Trades(src.Trades)
+	archive := Archive{
+		Format:     ArchiveFormat,
+		ExportedAt: now,
+		Trades:     trades,
+		Stats:      analytics.CalculateStatsReport(trades),
+		Ledger:     src.Ledger,
+		Policy:     src.Policy,
+		Settings:   src.Settings,
+	}
+	if !r.Since.IsZero() {
+		archive.Since = &r.Since
+	}
+	if !r.Until.IsZero() {
+		archive.Until = &r.Until
+	}
+	return archive
+}
+
+// WriteArchive writes the archive as indented JSON
+func WriteArchive(w io.Writer, archive Archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(archive)
+}
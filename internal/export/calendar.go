@@ -0,0 +1,155 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"tf-engine/internal/models"
+	"tf-engine/internal/options"
+)
+
+// CalendarName is the name calendar apps show for the exported calendar
+const CalendarName = "TF-Engine Trades"
+
+// Calendar event kinds; each is the UID suffix of a trade's event
+const (
+	EventExpiration = "expiration"
+	EventReview     = "review"
+	EventExit       = "exit"
+	EventTimeStop   = "time-stop"
+)
+
+// defaultPastDays matches the calendar screen's default look-back
+const defaultPastDays = 14
+
+// CalendarPlan sets the planned dates derived from each trade
+type CalendarPlan struct {
+	ReviewFraction float64 // Review once this fraction of the entry DTE has passed; 0 for none
+	ExitDTE        int     // Plan to roll or close at this many days to expiration; 0 for none
+}
+
+// DefaultCalendarPlan reviews at 50% of DTE and plans the exit at 21 DTE
+func DefaultCalendarPlan() CalendarPlan {
+	return CalendarPlan{ReviewFraction: 0.5, ExitDTE: 21}
+}
+
+// DefaultCalendarRange starts at the calendar screen's look-back from today
+// and is open-ended
+func DefaultCalendarRange(policy *models.Policy, now time.Time) Range {
+	days := defaultPastDays
+	if policy != nil && policy.Calendar.PastDays > 0 {
+		days = policy.Calendar.PastDays
+	}
+	return Range{Since: day(now).AddDate(0, 0, -days)}
+}
+
+// TradeEvents returns a trade's expiration, review, planned exit and time
+// stop events. The time stop is the upper end of the strategy's HoldWeeks
+// after entry. Events of a closed or expired trade are cancelled from its
+// exit on, so re-importing removes plans that no longer apply.
+func TradeEvents(t models.Trade, policy *models.Policy, plan CalendarPlan) []Event {
+	entry := t.EntryDate
+	if entry.IsZero() {
+		entry = t.CreatedAt
+	}
+	entry = day(entry)
+
+	description := []string{}
+	if contracts, err := options.Legs(t); err == nil {
+		for _, leg := range contracts {
+			description = append(description, leg.Name())
+		}
+	}
+	description = append(description, fmt.Sprintf("%s | %s | %s", t.Sector, t.Strategy, t.OptionsStrategy),
+		"Trade "+t.ID+", entered "+entry.Format(dateLayout))
+	label := t.Ticker
+	if t.OptionsStrategy != "" {
+		label += " " + t.OptionsStrategy
+	}
+
+	events := []Event{}
+	add := func(kind string, date time.Time, summary string) {
+		events = append(events, Event{
+			UID:         t.ID + "-" + kind + "@tf-engine",
+			Date:        date,
+			Summary:     summary,
+			Description: strings.Join(description, "\n"),
+			Categories:  []string{"TF-Engine", kind},
+		})
+	}
+
+	if !t.ExpirationDate.IsZero() {
+		expiration := day(t.ExpirationDate)
+		dte := int(math.Round(expiration.Sub(entry).Hours() / 24))
+		add(EventExpiration, expiration, "Expiration: "+label)
+		if plan.ReviewFraction > 0 && dte >= 2 {
+			offset := int(math.Round(float64(dte) * plan.ReviewFraction))
+			add(EventReview, entry.AddDate(0, 0, offset),
+				fmt.Sprintf("Review: %s (%.0f%% of %d DTE)", label, plan.ReviewFraction*100, dte))
+		}
+		if plan.ExitDTE > 0 && dte > plan.ExitDTE {
+			add(EventExit, expiration.AddDate(0, 0, -plan.ExitDTE),
+				fmt.Sprintf("Planned exit: %s (%d DTE)", label, plan.ExitDTE))
+		}
+	}
+	if policy != nil {
+		if id, ok := policy.FindStrategy(t.Strategy); ok {
+			weeks, ok := policy.Strategies[id].MaxHoldWeeks()
+			stop := entry.AddDate(0, 0, 7*weeks)
+			if ok && (t.ExpirationDate.IsZero() || !stop.After(day(t.ExpirationDate))) {
+				add(EventTimeStop, stop, fmt.Sprintf("Time stop: %s (%s holds up to %d weeks)", label, id, weeks))
+			}
+		}
+	}
+
+	sequence := 0
+	if t.UpdatedAt.After(t.CreatedAt) {
+		sequence = int(t.UpdatedAt.Sub(t.CreatedAt) / time.Second)
+	}
+	status := t.GetStatus()
+	done := status == "closed" || status == "expired"
+	for i := range events {
+		events[i].Sequence = sequence
+		events[i].Modified = t.UpdatedAt
+		events[i].Status = EventConfirmed
+		if done && (t.ExitDate == nil || events[i].Date.After(day(*t.ExitDate))) {
+			events[i].Status = EventCancelled
+		}
+	}
+	return events
+}
+
+// CalendarEvents returns the events of every trade dated in r, soonest
+// first
+func CalendarEvents(src Source, r Range, plan CalendarPlan) []Event {
+	events := []Event{}
+	for _, t := range src.Trades {
+		for _, e := range TradeEvents(t, src.Policy, plan) {
+			if r.Contains(e.Date) {
+				events = append(events, e)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Date.Equal(events[j].Date) {
+			return events[i].Date.Before(events[j].Date)
+		}
+		return events[i].UID < events[j].UID
+	})
+	return events
+}
+
+// WriteCalendar writes the events of src dated in r as an .ics file
+func WriteCalendar(w io.Writer, src Source, r Range, plan CalendarPlan, now time.Time) error {
+	return WriteICS(w, CalendarName, CalendarEvents(src, r, plan), now)
+}
+
+// day returns midnight local time of t's date
+func day(t time.Time) time.Time {
+	y, m, d := t.In(time.Local).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
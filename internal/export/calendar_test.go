@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"tf-engine/internal/models"
+)
+
+func calendarSource() Source {
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 10, 0, 0, 0, time.Local) }
+	policy := models.SafeModePolicy()
+	alt10 := policy.Strategies["Alt10"]
+	alt10.HoldWeeks = "3-6"
+	policy.Strategies["Alt10"] = alt10
+	exit := day(3, 20)
+	return Source{
+		Trades: []models.Trade{
+			{ID: "T1", CreatedAt: day(3, 1), UpdatedAt: day(3, 1), Ticker: "NVDA", Strategy: "Alt10",
+				OptionsStrategy: "Bull call spread", Strike1: 900, Strike2: 950,
+				EntryDate: day(3, 3), ExpirationDate: day(5, 16), Status: "active"},
+			{ID: "T2", CreatedAt: day(3, 1), UpdatedAt: exit, Ticker: "UNH", Strategy: "Alt10",
+				EntryDate: day(3, 3), ExpirationDate: day(4, 17), Status: "closed", ExitDate: &exit},
+		},
+		Policy: policy,
+	}
+}
+
+func TestTradeEvents(t *testing.T) {
+	src := calendarSource()
+	byUID := map[string]Event{}
+	for _, e := range TradeEvents(src.Trades[0], src.Policy, DefaultCalendarPlan()) {
+		byUID[e.UID] = e
+	}
+
+	// Entered 2025-03-03 with 74 DTE
+	for uid, want := range map[string]string{
+		"T1-expiration@tf-engine": "2025-05-16",
+		"T1-review@tf-engine":     "2025-04-09",
+		"T1-exit@tf-engine":       "2025-04-25",
+		"T1-time-stop@tf-engine":  "2025-04-14",
+	} {
+		e, ok := byUID[uid]
+		if !ok {
+			t.Errorf("Missing %s", uid)
+			continue
+		}
+		if got := e.Date.Format(dateLayout); got != want || e.Status != EventConfirmed {
+			t.Errorf("%s: %s %s, want %s", uid, got, e.Status, want)
+		}
+	}
+	if e := byUID["T1-expiration@tf-engine"]; !strings.Contains(e.Description, "+1 NVDA  250516C00900000") {
+		t.Errorf("Expected the contracts in the description, got %q", e.Description)
+	}
+
+	// A time stop after expiration is left out; a short-dated trade has no planned exit
+	short := src.Trades[0]
+	short.ExpirationDate = time.Date(2025, 3, 21, 0, 0, 0, 0, time.Local)
+	for _, e := range TradeEvents(short, src.Policy, DefaultCalendarPlan()) {
+		if strings.Contains(e.UID, EventTimeStop) || strings.Contains(e.UID, EventExit) {
+			t.Errorf("Unexpected %s on %s", e.UID, e.Date.Format(dateLayout))
+		}
+	}
+
+	// A closed trade's plans after its exit are cancelled, and revised
+	for _, e := range TradeEvents(src.Trades[1], src.Policy, DefaultCalendarPlan()) {
+		if e.Status != EventCancelled || e.Sequence == 0 {
+			t.Errorf("%s: %s sequence %d", e.UID, e.Status, e.Sequence)
+		}
+	}
+}
+
+func TestWriteCalendar(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	r := Range{Since: time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local)}
+
+	write := func() string {
+		buf := &bytes.Buffer{}
+		if err := WriteCalendar(buf, calendarSource(), r, DefaultCalendarPlan(), now); err != nil {
+			t.Fatalf("WriteCalendar failed: %v", err)
+		}
+		return buf.String()
+	}
+	ics := write()
+	if ics != write() {
+		t.Error("Re-exporting should produce the same UIDs and content")
+	}
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n", "X-WR-CALNAME:TF-Engine Trades\r\n",
+		"UID:T1-exit@tf-engine\r\n", "DTSTART;VALUE=DATE:20250425\r\n", "DTEND;VALUE=DATE:20250426\r\n",
+		"DTSTAMP:20250310T120000Z\r\n", "STATUS:CANCELLED\r\n", "END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Missing %q", strings.TrimSpace(want))
+		}
+	}
+	// Events before the range are left out
+	if strings.Contains(ics, "UID:T2-review@tf-engine") {
+		t.Error("Expected the March review to be outside the range")
+	}
+	if first := strings.Index(ics, "UID:T2-expiration"); first < 0 || first > strings.Index(ics, "UID:T1-exit") {
+		t.Error("Expected events ordered by date")
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 octets: %q", line)
+		}
+	}
+}
+
+func TestICSText(t *testing.T) {
+	if got := escapeText("a, b; c\\d\ne"); got != `a\, b\; c\\d\ne` {
+		t.Errorf("escapeText = %q", got)
+	}
+
+	long := "DESCRIPTION:" + strings.Repeat("é", 60)
+	folded := fold(long)
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n ")
+	if strings.Join(lines, "") != long {
+		t.Errorf("Unfolding should restore the line, got %q", folded)
+	}
+	for _, line := range lines {
+		if len(line) > 75 || !utf8.ValidString(line) {
+			t.Errorf("Bad fold %q", line)
+		}
+	}
+}
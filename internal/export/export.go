@@ -0,0 +1,179 @@
+// Package export writes the trade journal and its analytics as CSV tables,
+// or everything as one JSON archive, for spreadsheets and safekeeping.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/models"
+)
+
+// Table kinds
+const (
+	Trades      = "trades"      // One row per trade
+	Legs        = "legs"        // One row per option contract (or strike)
+	Sectors     = "sectors"     // Sector statistics
+	Strategies  = "strategies"  // Strategy statistics
+	Convictions = "convictions" // Conviction statistics
+	Equity      = "equity"      // Equity curve
+)
+
+// Kinds lists the table kinds in menu order
+func Kinds() []string {
+	return []string{Trades, Legs, Sectors, Strategies, Convictions, Equity}
+}
+
+// dateLayout is the format of date ranges; timestampLayout of exported
+// times, which spreadsheets read as dates
+const (
+	dateLayout      = "2006-01-02"
+	timestampLayout = "2006-01-02 15:04:05"
+)
+
+// Source is the data exports are built from
+type Source struct {
+	Trades   []models.Trade
+	Ledger   *models.Ledger
+	Policy   *models.Policy
+	Settings *models.Settings
+}
+
+// Range limits an export to trades created in [Since, Until). A zero end is
+// open.
+type Range struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ParseRange parses YYYY-MM-DD dates, either of which may be empty. Until is
+// inclusive.
+func ParseRange(since, until string) (Range, error) {
+	var r Range
+	var err error
+	if since != "" {
+		if r.Since, err = time.ParseInLocation(dateLayout, since, time.Local); err != nil {
+			return Range{}, fmt.Errorf("since: want YYYY-MM-DD, got %q", since)
+		}
+	}
+	if until != "" {
+		if r.Until, err = time.ParseInLocation(dateLayout, until, time.Local); err != nil {
+			return Range{}, fmt.Errorf("until: want YYYY-MM-DD, got %q", until)
+		}
+		r.Until = r.Until.AddDate(0, 0, 1)
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && !r.Since.Before(r.Until) {
+		return Range{}, fmt.Errorf("since %s is after until %s", since, until)
+	}
+	return r, nil
+}
+
+// Contains reports whether t falls in the range
+func (r Range) Contains(t time.Time) bool {
+	return (r.Since.IsZero() || !t.Before(r.Since)) && (r.Until.IsZero() || t.Before(r.Until))
+}
+
+// Trades returns the trades created in the range
+func (r Range) Trades(trades []models.Trade) []models.Trade {
+	return models.TradeFilter{Since: r.Since, Until: r.Until}.Apply(trades)
+}
+
+// Options select what a CSV export contains
+type Options struct {
+	Kind    string
+	Columns []string // Empty means every column, in table order
+	Range   Range
+}
+
+// WriteCSV writes the table opts select
+func WriteCSV(w io.Writer, src Source, opts Options) error {
+	table, err := BuildTable(opts.Kind, src, opts.Range)
+	if err != nil {
+		return err
+	}
+	if table, err = table.Select(opts.Columns); err != nil {
+		return err
+	}
+	return table.WriteCSV(w)
+}
+
+// FileName is the suggested name of an export, e.g.
+// tf-engine-trades-20250102.csv
+func FileName(kind, ext string, now time.Time) string {
+	return fmt.Sprintf("tf-engine-%s-%s.%s", kind, now.Format("20060102"), ext)
+}
+
+// Table is a header row and rows of cells
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Columns returns the columns of a table kind
+func Columns(kind string) ([]string, error) {
+	table, err := BuildTable(kind, Source{}, Range{})
+	if err != nil {
+		return nil, err
+	}
+	return table.Columns, nil
+}
+
+// BuildTable builds the table of the given kind from the trades in r
+func BuildTable(kind string, src Source, r Range) (Table, error) {
+	trades := r.Trades(src.Trades)
+	switch kind {
+	case Trades:
+		return tradeTable(trades, false), nil
+	case Legs:
+		return tradeTable(trades, true), nil
+	case Sectors, Strategies, Convictions:
+		return statsTable(kind, analytics.SortByClose(trades)), nil
+	case Equity:
+		return equityTable(src, r), nil
+	}
+	return Table{}, fmt.Errorf("unknown export %q (want one of %s)", kind, strings.Join(Kinds(), ", "))
+}
+
+// Select keeps the named columns, in the order given. Unknown names are an
+// error; no names keeps every column.
+func (t Table) Select(columns []string) (Table, error) {
+	if len(columns) == 0 {
+		return t, nil
+	}
+	index := make(map[string]int, len(t.Columns))
+	for i, name := range t.Columns {
+		index[name] = i
+	}
+	picks := make([]int, len(columns))
+	for i, name := range columns {
+		pick, ok := index[strings.TrimSpace(name)]
+		if !ok {
+			return Table{}, fmt.Errorf("unknown column %q (want one of %s)", name, strings.Join(t.Columns, ", "))
+		}
+		picks[i] = pick
+	}
+
+	selected := Table{Columns: make([]string, len(picks)), Rows: make([][]string, len(t.Rows))}
+	for i, pick := range picks {
+		selected.Columns[i] = t.Columns[pick]
+	}
+	for r, row := range t.Rows {
+		selected.Rows[r] = make([]string, len(picks))
+		for i, pick := range picks {
+			selected.Rows[r][i] = row[pick]
+		}
+	}
+	return selected, nil
+}
+
+// WriteCSV writes the header and rows
+func (t Table) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write(t.Columns)
+	out.WriteAll(t.Rows) // Flushes
+	return out.Error()
+}
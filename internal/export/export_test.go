@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func testSource() Source {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 10, 0, 0, 0, time.Local) }
+	win, loss := 300.0, -100.0
+	exit := day(20)
+	return Source{
+		Trades: []models.Trade{
+			{ID: "T1", CreatedAt: day(1), Ticker: "NVDA", Sector: "Technology", Strategy: "Alt10", Conviction: 7,
+				OptionsStrategy: "Bull call spread", Strike1: 900, Strike2: 950, Status: "closed",
+				ProfitLoss: &win, ExitDate: &exit, UpdatedAt: exit},
+			{ID: "T2", CreatedAt: day(10), Ticker: "UNH", Sector: "Healthcare", Strategy: "Alt26", Conviction: 5,
+				Status: "closed", ProfitLoss: &loss, UpdatedAt: day(12)},
+			{ID: "T3", CreatedAt: day(25), Ticker: "MSFT", Sector: "Technology", Strategy: "Alt10", Conviction: 7,
+				Strike1: 400, Status: "active"},
+		},
+		Settings: models.DefaultSettings(),
+		Policy:   models.SafeModePolicy(),
+	}
+}
+
+func readCSV(t *testing.T, src Source, opts Options) [][]string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := WriteCSV(buf, src, opts); err != nil {
+		t.Fatalf("WriteCSV(%s) failed: %v", opts.Kind, err)
+	}
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestWriteCSV_Trades(t *testing.T) {
+	records := readCSV(t, testSource(), Options{Kind: Trades})
+	if len(records) != 4 || records[0][0] != "id" || records[1][0] != "T1" {
+		t.Fatalf("Unexpected trades CSV: %v", records)
+	}
+
+	records = readCSV(t, testSource(), Options{Kind: Trades, Columns: []string{"ticker", "profit_loss"}})
+	want := [][]string{{"ticker", "profit_loss"}, {"NVDA", "300.00"}, {"UNH", "-100.00"}, {"MSFT", ""}}
+	if !equal(records, want) {
+		t.Errorf("Selected columns: got %v, want %v", records, want)
+	}
+
+	if err := WriteCSV(&bytes.Buffer{}, testSource(), Options{Kind: Trades, Columns: []string{"pnl"}}); err == nil {
+		t.Error("Expected an unknown column to fail")
+	}
+}
+
+func TestWriteCSV_Legs(t *testing.T) {
+	records := readCSV(t, testSource(), Options{Kind: Legs, Columns: []string{"id", "leg", "strike"}})
+	want := [][]string{{"id", "leg", "strike"}, {"T1", "1", "900.00"}, {"T1", "2", "950.00"}, {"T2", "", ""}, {"T3", "1", "400.00"}}
+	if !equal(records, want) {
+		t.Errorf("Got %v, want %v", records, want)
+	}
+
+	// With an expiration the legs are the strategy's contracts
+	src := testSource()
+	src.Trades[0].ExpirationDate = time.Date(2025, 4, 17, 0, 0, 0, 0, time.Local)
+	records = readCSV(t, src, Options{Kind: Legs, Columns: []string{"id", "strike", "right", "ratio", "contract"}})
+	want = [][]string{{"id", "strike", "right", "ratio", "contract"},
+		{"T1", "900.00", "C", "1", "NVDA  250417C00900000"}, {"T1", "950.00", "C", "-1", "NVDA  250417C00950000"},
+		{"T2", "", "", "", ""}, {"T3", "400.00", "", "", ""}}
+	if !equal(records, want) {
+		t.Errorf("Got %v, want %v", records, want)
+	}
+}
+
+func TestWriteCSV_Range(t *testing.T) {
+	r, err := ParseRange("2025-03-05", "2025-03-25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := readCSV(t, testSource(), Options{Kind: Trades, Columns: []string{"id"}, Range: r})
+	if !equal(records, [][]string{{"id"}, {"T2"}, {"T3"}}) {
+		t.Errorf("Until should be inclusive: %v", records)
+	}
+
+	if _, err := ParseRange("2025-03-25", "2025-03-05"); err == nil {
+		t.Error("Expected a reversed range to fail")
+	}
+	if _, err := ParseRange("March", ""); err == nil {
+		t.Error("Expected a bad date to fail")
+	}
+}
+
+func TestWriteCSV_Analytics(t *testing.T) {
+	records := readCSV(t, testSource(), Options{Kind: Convictions})
+	want := [][]string{
+		{"conviction", "total_trades", "win_rate", "total_pnl", "average_pnl"},
+		{"5", "1", "0.0", "-100.00", "-100.00"},
+		{"7", "1", "100.0", "300.00", "300.00"},
+	}
+	if !equal(records, want) {
+		t.Errorf("Got %v, want %v", records, want)
+	}
+
+	records = readCSV(t, testSource(), Options{Kind: Sectors, Columns: []string{"sector", "total_pnl"}})
+	if !equal(records, [][]string{{"sector", "total_pnl"}, {"Technology", "300.00"}, {"Healthcare", "-100.00"}}) {
+		t.Errorf("Unexpected sectors: %v", records)
+	}
+
+	records = readCSV(t, testSource(), Options{Kind: Equity})
+	if len(records) != 4 || records[len(records)-1][1] != "200.00" {
+		t.Errorf("Unexpected equity curve: %v", records)
+	}
+
+	if _, err := Columns("positions"); err == nil {
+		t.Error("Expected an unknown kind to fail")
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	r, _ := ParseRange("", "2025-03-15")
+	buf := &bytes.Buffer{}
+	if err := WriteArchive(buf, NewArchive(testSource(), r, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive Archive
+	if err := json.Unmarshal(buf.Bytes(), &archive); err != nil {
+		t.Fatal(err)
+	}
+	if archive.Format != ArchiveFormat || len(archive.Trades) != 2 || archive.Stats.Overall.TotalPnL != 200 {
+		t.Errorf("Unexpected archive: %+v", archive)
+	}
+	if archive.Since != nil || archive.Until == nil || archive.Policy == nil || archive.Settings == nil {
+		t.Errorf("Archive missing range or snapshots: %s", buf.String()[:200])
+	}
+	if !strings.Contains(buf.String(), `"account_equity"`) {
+		t.Error("Settings snapshot not written")
+	}
+}
+
+func equal(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.Join(a[i], "|") != strings.Join(b[i], "|") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses
+const (
+	EventConfirmed = "CONFIRMED"
+	EventCancelled = "CANCELLED" // Tells calendars to drop an event imported earlier
+)
+
+// Event is an all-day iCalendar event
+type Event struct {
+	UID         string // Stable across exports so calendars update the event
+	Date        time.Time
+	Summary     string
+	Description string
+	Categories  []string
+	Status      string    // EventConfirmed or EventCancelled
+	Sequence    int       // Grows with each revision of the event
+	Modified    time.Time // When the underlying trade last changed
+}
+
+// icsDateLayout and icsTimeLayout are the iCalendar DATE and UTC DATE-TIME
+// formats
+const (
+	icsDateLayout = "20060102"
+	icsTimeLayout = "20060102T150405Z"
+)
+
+// WriteICS writes events as an iCalendar (RFC 5545) file named name
+func WriteICS(w io.Writer, name string, events []Event, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(text string) { bw.WriteString(fold(text)) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//tf-engine//Trade Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeText(name))
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + now.UTC().Format(icsTimeLayout))
+		if !e.Modified.IsZero() {
+			line("LAST-MODIFIED:" + e.Modified.UTC().Format(icsTimeLayout))
+		}
+		line("SEQUENCE:" + fmt.Sprint(e.Sequence))
+		line("DTSTART;VALUE=DATE:" + e.Date.Format(icsDateLayout))
+		line("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format(icsDateLayout))
+		line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				categories[i] = escapeText(c)
+			}
+			line("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		status := e.Status
+		if status == "" {
+			status = EventConfirmed
+		}
+		line("STATUS:" + status)
+		line("TRANSP:TRANSPARENT") // Reminders, not busy time
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// escapeText escapes a TEXT value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// fold ends a content line with CRLF, folding it so no line exceeds 75
+// octets and no UTF-8 character is split
+func fold(text string) string {
+	var b strings.Builder
+	limit := 75
+	for len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		b.WriteString(text[:cut])
+		b.WriteString("\r\n ")
+		text = text[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	b.WriteString(text)
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"strconv"
+	"time"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/models"
+	"tf-engine/internal/options"
+)
+
+// tradeColumn is a trade table column and how to fill it
+type tradeColumn struct {
+	name  string
+	value func(t *models.Trade) string
+}
+
+var tradeColumns = []tradeColumn{
+	{"id", func(t *models.Trade) string { return t.ID }},
+	{"created_at", func(t *models.Trade) string { return timestamp(t.CreatedAt) }},
+	{"status", func(t *models.Trade) string { return t.GetStatus() }},
+	{"sector", func(t *models.Trade) string { return t.Sector }},
+	{"direction", func(t *models.Trade) string { return t.Direction }},
+	{"ticker", func(t *models.Trade) string { return t.Ticker }},
+	{"strategy", func(t *models.Trade) string { return t.Strategy }},
+	{"conviction", func(t *models.Trade) string { return strconv.Itoa(t.Conviction) }},
+	{"position_size", func(t *models.Trade) string { return strconv.Itoa(t.PositionSize) }},
+	{"max_loss", func(t *models.Trade) string { return amount(t.MaxLoss) }},
+	{"options_strategy", func(t *models.Trade) string { return t.OptionsStrategy }},
+	{"entry_date", func(t *models.Trade) string { return timestamp(t.EntryDate) }},
+	{"expiration_date", func(t *models.Trade) string { return date(t.ExpirationDate) }},
+	{"premium", func(t *models.Trade) string { return amount(t.Premium) }},
+	{"exit_date", func(t *models.Trade) string { return optionalTime(t.ExitDate) }},
+	{"exit_price", func(t *models.Trade) string { return optionalAmount(t.ExitPrice) }},
+	{"profit_loss", func(t *models.Trade) string { return optionalAmount(t.ProfitLoss) }},
+	{"open_pnl", func(t *models.Trade) string { return optionalAmount(t.OpenPnL) }},
+}
+
+// tradeTable has a row per trade, or with perLeg a row per contract keyed by
+// its OCC symbol. Trades whose contracts can't be derived get a row per
+// strike, and trades without strikes keep one row with an empty leg.
+func tradeTable(trades []models.Trade, perLeg bool) Table {
+	table := Table{Rows: [][]string{}}
+	for _, c := range tradeColumns {
+		table.Columns = append(table.Columns, c.name)
+	}
+	if perLeg {
+		table.Columns = append(table.Columns, "leg", "strike", "right", "ratio", "contract")
+	}
+
+	for i := range trades {
+		t := &trades[i]
+		row := make([]string, 0, len(table.Columns))
+		for _, c := range tradeColumns {
+			row = append(row, c.value(t))
+		}
+		if !perLeg {
+			table.Rows = append(table.Rows, row)
+			continue
+		}
+
+		if legs, err := options.Legs(*t); err == nil {
+			for n, leg := range legs {
+				c := leg.Contract
+				table.Rows = append(table.Rows, append(row[:len(row):len(row)],
+					strconv.Itoa(n+1), amount(c.Strike), c.Right, strconv.Itoa(leg.Ratio), c.Symbol()))
+			}
+			continue
+		}
+
+		legs := 0
+		for n, strike := range []float64{t.Strike1, t.Strike2, t.Strike3, t.Strike4} {
+			if strike == 0 {
+				continue
+			}
+			legs++
+			table.Rows = append(table.Rows, append(row[:len(row):len(row)], strconv.Itoa(n+1), amount(strike), "", "", ""))
+		}
+		if legs == 0 {
+			table.Rows = append(table.Rows, append(row, "", "", "", "", ""))
+		}
+	}
+	return table
+}
+
+// statsTable has a row per sector, strategy or conviction
+func statsTable(kind string, trades []models.Trade) Table {
+	table := Table{Rows: [][]string{}}
+	add := func(group string, totalTrades int, winRate, totalPnL, averagePnL float64) {
+		table.Rows = append(table.Rows, []string{group, strconv.Itoa(totalTrades),
+			strconv.FormatFloat(winRate, 'f', 1, 64), amount(totalPnL), amount(averagePnL)})
+	}
+
+	switch kind {
+	case Sectors:
+		for _, s := range analytics.CalculateSectorStats(trades) {
+			add(s.Sector, s.TotalTrades, s.WinRate, s.TotalPnL, s.AveragePnL)
+		}
+	case Strategies:
+		for _, s := range analytics.CalculateStrategyStats(trades) {
+			add(s.Strategy, s.TotalTrades, s.WinRate, s.TotalPnL, s.AveragePnL)
+		}
+	case Convictions:
+		for _, s := range analytics.CalculateConvictionStats(trades) {
+			add(strconv.Itoa(s.Conviction), s.TotalTrades, s.WinRate, s.TotalPnL, s.AveragePnL)
+		}
+	}
+
+	group := map[string]string{Sectors: "sector", Strategies: "strategy", Convictions: "conviction"}[kind]
+	table.Columns = []string{group, "total_trades", "win_rate", "total_pnl", "average_pnl"}
+	return table
+}
+
+// equityTable is the account equity from the ledger, or the cumulative P&L
+// of the trades when there is no ledger, limited to points in r
+func equityTable(src Source, r Range) Table {
+	table := Table{Columns: []string{"date", "equity"}, Rows: [][]string{}}
+
+	var curve []analytics.EquityCurvePoint
+	if src.Ledger != nil && len(src.Ledger.Entries) > 0 {
+		curve = analytics.CalculateLedgerEquityCurve(src.Ledger)
+	} else {
+		curve = analytics.CalculateEquityCurve(r.Trades(src.Trades))
+	}
+	for _, point := range curve {
+		if r.Contains(point.Date) {
+			table.Rows = append(table.Rows, []string{timestamp(point.Date), amount(point.Equity)})
+		}
+	}
+	return table
+}
+
+func amount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func optionalAmount(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return amount(*v)
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(timestampLayout)
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return timestamp(*t)
+}
@@ -0,0 +1,67 @@
+// Package filelock provides advisory locks on files that work across
+// processes. Locks belong to the open file, so two locks on the same path
+// conflict even within one process.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked is returned by TryLock when another holder has the lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// Lock is a held lock. Unlock releases it.
+type Lock struct {
+	f *os.File
+}
+
+// Exclusive blocks until it holds an exclusive lock on path, creating the
+// file if needed
+func Exclusive(path string) (*Lock, error) {
+	return acquire(path, true, true)
+}
+
+// Shared blocks until it holds a shared lock on path. Shared locks coexist
+// with each other but not with an exclusive lock.
+func Shared(path string) (*Lock, error) {
+	return acquire(path, false, true)
+}
+
+// TryExclusive takes an exclusive lock without waiting. Returns ErrLocked if
+// it is held elsewhere.
+func TryExclusive(path string) (*Lock, error) {
+	return acquire(path, true, false)
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
+
+func acquire(path string, exclusive, wait bool) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("lock error: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("lock error: %w", err)
+	}
+
+	if err := lock(f, exclusive, wait); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{f: f}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package filelock
+
+import "os"
+
+// Platforms without file locking (e.g. js/wasm) run a single process
+
+func lock(f *os.File, exclusive, wait bool) error { return nil }
+
+func unlock(f *os.File) error { return nil }
@@ -0,0 +1,60 @@
+package filelock
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryExclusive_ConflictsUntilUnlocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", ".lock")
+
+	first, err := TryExclusive(path)
+	if err != nil {
+		t.Fatalf("TryExclusive failed: %v", err)
+	}
+
+	if _, err := TryExclusive(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked while held, got %v", err)
+	}
+
+	first.Unlock()
+	second, err := TryExclusive(path)
+	if err != nil {
+		t.Fatalf("Lock should be free after Unlock: %v", err)
+	}
+	second.Unlock()
+}
+
+func TestExclusive_WaitsForSharedHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".lock")
+
+	r1, _ := Shared(path)
+	r2, err := Shared(path)
+	if err != nil {
+		t.Fatalf("Shared locks should coexist: %v", err)
+	}
+
+	acquired := make(chan *Lock)
+	go func() {
+		l, _ := Exclusive(path)
+		acquired <- l
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Exclusive lock acquired while shared locks are held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	r1.Unlock()
+	r2.Unlock()
+
+	select {
+	case l := <-acquired:
+		l.Unlock()
+	case <-time.After(2 * time.Second):
+		t.Fatal("Exclusive lock not acquired after shared locks were released")
+	}
+}
@@ -0,0 +1,163 @@
+// Package instance makes sure only one running copy of the app owns a data
+// directory. A second copy can ask the first one to come to the front.
+package instance
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tf-engine/internal/filelock"
+)
+
+// ErrAlreadyRunning is returned by Acquire when another instance owns the
+// data directory
+var ErrAlreadyRunning = errors.New("another instance is already running")
+
+// Names of the files kept in the data directory
+const (
+	lockName = ".instance.lock"
+	infoName = ".instance"
+)
+
+// focusRequest is the only message instances send each other
+const focusRequest = "focus"
+
+// dialTimeout bounds how long RequestFocus waits for the running instance
+var dialTimeout = 2 * time.Second
+
+// Guard is held by the instance that owns the data directory
+type Guard struct {
+	lock     *filelock.Lock
+	listener net.Listener
+	infoFile string
+
+	mu      sync.Mutex
+	onFocus func()
+}
+
+// info tells a second instance how to reach the first
+type info struct {
+	PID  int    `json:"pid"`
+	Addr string `json:"addr"`
+}
+
+// Acquire claims the data directory for this process. Returns
+// ErrAlreadyRunning if another instance holds it. The lock is released when
+// the process exits, even if it crashes.
+func Acquire(dir string) (*Guard, error) {
+	lock, err := filelock.TryExclusive(filepath.Join(dir, lockName))
+	if errors.Is(err, filelock.ErrLocked) {
+		return nil, ErrAlreadyRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		lock.Unlock()
+		return nil, fmt.Errorf("instance listener: %w", err)
+	}
+
+	g := &Guard{lock: lock, listener: listener, infoFile: filepath.Join(dir, infoName)}
+
+	data, err := json.Marshal(info{PID: os.Getpid(), Addr: listener.Addr().String()})
+	if err == nil {
+		err = os.WriteFile(g.infoFile, data, 0644)
+	}
+	if err != nil {
+		g.Release()
+		return nil, fmt.Errorf("instance info: %w", err)
+	}
+
+	go g.serve()
+	return g, nil
+}
+
+// OnFocus sets what happens when another instance asks this one to come to
+// the front
+func (g *Guard) OnFocus(fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onFocus = fn
+}
+
+// Release gives up the data directory
+func (g *Guard) Release() error {
+	if g == nil {
+		return nil
+	}
+	g.listener.Close()
+	os.Remove(g.infoFile)
+	return g.lock.Unlock()
+}
+
+// serve answers focus requests until the listener is closed
+func (g *Guard) serve() {
+	for {
+		conn, err := g.listener.Accept()
+		if err != nil {
+			return
+		}
+		go g.handle(conn)
+	}
+}
+
+func (g *Guard) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != focusRequest {
+		return
+	}
+
+	g.mu.Lock()
+	fn := g.onFocus
+	g.mu.Unlock()
+	if fn != nil {
+		fn()
+	}
+	fmt.Fprintln(conn, "ok")
+}
+
+// RequestFocus asks the instance that owns dir to bring its window to the
+// front
+func RequestFocus(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, infoName))
+	if err != nil {
+		return fmt.Errorf("running instance not found: %w", err)
+	}
+
+	var inf info
+	if err := json.Unmarshal(data, &inf); err != nil || inf.Addr == "" {
+		return fmt.Errorf("running instance not found: bad %s", infoName)
+	}
+
+	conn, err := net.DialTimeout("tcp", inf.Addr, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("running instance (pid %d) not reachable: %w", inf.PID, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	if _, err := fmt.Fprintln(conn, focusRequest); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("running instance (pid %d) did not answer: %w", inf.PID, err)
+	}
+	if strings.TrimSpace(reply) != "ok" {
+		return fmt.Errorf("running instance (pid %d) refused: %q", inf.PID, reply)
+	}
+	return nil
+}
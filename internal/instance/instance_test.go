@@ -0,0 +1,56 @@
+package instance
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquire_SecondInstanceIsRefused(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	if _, err := Acquire(dir); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("Expected ErrAlreadyRunning, got %v", err)
+	}
+
+	first.Release()
+	second, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire after Release failed: %v", err)
+	}
+	second.Release()
+}
+
+func TestRequestFocus_ReachesRunningInstance(t *testing.T) {
+	dir := t.TempDir()
+
+	g, err := Acquire(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Release()
+
+	focused := make(chan struct{}, 1)
+	g.OnFocus(func() { focused <- struct{}{} })
+
+	if err := RequestFocus(dir); err != nil {
+		t.Fatalf("RequestFocus failed: %v", err)
+	}
+
+	select {
+	case <-focused:
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnFocus was not called")
+	}
+}
+
+func TestRequestFocus_NoRunningInstance(t *testing.T) {
+	if err := RequestFocus(t.TempDir()); err == nil {
+		t.Error("Expected an error with no running instance")
+	}
+}
@@ -0,0 +1,165 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Levels is the minimum level logged, overall and per subsystem
+type Levels struct {
+	Default    slog.Level
+	Subsystems map[string]slog.Level
+}
+
+// ParseLevels parses a level spec such as "info" or "warn,storage=debug".
+// An empty spec means info.
+func ParseLevels(spec string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		subsystem, name, scoped := strings.Cut(part, "=")
+		if !scoped {
+			name = subsystem
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return levels, fmt.Errorf("log level %q: %w", part, err)
+		}
+		if !scoped {
+			levels.Default = level
+			continue
+		}
+		if levels.Subsystems == nil {
+			levels.Subsystems = map[string]slog.Level{}
+		}
+		levels.Subsystems[strings.TrimSpace(subsystem)] = level
+	}
+	return levels, nil
+}
+
+// For returns the level for a subsystem
+func (l Levels) For(subsystem string) slog.Level {
+	if level, ok := l.Subsystems[subsystem]; ok {
+		return level
+	}
+	return l.Default
+}
+
+// String formats the levels the way ParseLevels reads them
+func (l Levels) String() string {
+	parts := []string{strings.ToLower(l.Default.String())}
+	for subsystem, level := range l.Subsystems {
+		parts = append(parts, subsystem+"="+strings.ToLower(level.String()))
+	}
+	sort.Strings(parts[1:])
+	return strings.Join(parts, ",")
+}
+
+// redacting hides account equity and P&L values while the privacy setting
+// is on
+var redacting atomic.Bool
+
+// Redacted replaces sensitive values
+const Redacted = "[redacted]"
+
+// SetRedact turns redaction of equity and P&L attributes on or off
+func SetRedact(on bool) {
+	redacting.Store(on)
+}
+
+// Redacting reports whether equity and P&L are being redacted
+func Redacting() bool {
+	return redacting.Load()
+}
+
+// sensitive reports whether an attribute holds equity or P&L: "equity",
+// "pnl" and keys ending in "_equity" or "_pnl"
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	return key == "equity" || key == "pnl" ||
+		strings.HasSuffix(key, "_equity") || strings.HasSuffix(key, "_pnl")
+}
+
+func redact(a slog.Attr) slog.Attr {
+	if !redacting.Load() {
+		return a
+	}
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = redact(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	}
+	if sensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// handler tags records with their subsystem, applies the subsystem's level
+// and redaction, and passes them to the output configured by
+// InitializeLogging. Attributes and groups are replayed onto the output for
+// each record, so loggers made before InitializeLogging still reach it.
+type handler struct {
+	subsystem string
+	ops       []func(slog.Handler) slog.Handler
+}
+
+func (h *handler) output() (slog.Handler, slog.Level) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if base == nil {
+		return slog.Default().Handler(), levels.For(h.subsystem)
+	}
+	return base, levels.For(h.subsystem)
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	out, min := h.output()
+	return level >= min && out.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	out, _ := h.output()
+	out = out.WithAttrs([]slog.Attr{slog.String("subsystem", h.subsystem)})
+	for _, op := range h.ops {
+		out = op(out)
+	}
+
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(redact(a))
+		return true
+	})
+	return out.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(out slog.Handler) slog.Handler {
+		redacted := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			redacted[i] = redact(a)
+		}
+		return out.WithAttrs(redacted)
+	})
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return h.with(func(out slog.Handler) slog.Handler { return out.WithGroup(name) })
+}
+
+func (h *handler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := append(append([]func(slog.Handler) slog.Handler{}, h.ops...), op)
+	return &handler{subsystem: h.subsystem, ops: ops}
+}
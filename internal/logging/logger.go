@@ -3,68 +3,130 @@ package logging
 import (
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
 	"time"
+
+	"tf-engine/internal/paths"
+)
+
+// Subsystems with their own logger and level
+const (
+	App     = "app"
+	Storage = "storage"
+	Policy  = "policy"
+	UI      = "ui"
+	Vimium  = "vimium"
 )
 
+// Environment variables consulted by main for the logging options
+const (
+	EnvLevel  = "TF_ENGINE_LOG_LEVEL"
+	EnvFormat = "TF_ENGINE_LOG_FORMAT"
+)
+
+// LogFileName is the current log file; rotated files are numbered
+// (tf-engine.1.log is the newest)
+const LogFileName = "tf-engine.log"
+
+// Options configure InitializeLogging
+type Options struct {
+	Levels   Levels
+	Format   string    // "text" (default) or "json"
+	Dir      string    // Defaults to paths.Log()
+	MaxSize  int64     // Bytes before the log file rotates; defaults to 5 MB
+	MaxFiles int       // Rotated files kept; defaults to 5
+	Console  io.Writer // Also written to; defaults to stdout
+}
+
 var (
-	// InfoLogger logs general information
-	InfoLogger *log.Logger
-	// ErrorLogger logs errors
-	ErrorLogger *log.Logger
-	// DebugLogger logs debug information
-	DebugLogger *log.Logger
-	// logFile holds the log file handle
-	logFile *os.File
+	mu      sync.RWMutex
+	base    slog.Handler // nil until InitializeLogging
+	levels  = Levels{Default: slog.LevelInfo}
+	logFile *RotatingFile
 )
 
-// InitializeLogging sets up logging to both file and console
-func InitializeLogging() error {
-	// Create logs directory if it doesn't exist
-	logsDir := "logs"
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create logs directory: %w", err)
-	}
+// For returns the logger for a subsystem. It can be created before
+// InitializeLogging; until then records go to slog's default handler.
+func For(subsystem string) *slog.Logger {
+	return slog.New(&handler{subsystem: subsystem})
+}
 
-	// Create log file with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	logPath := filepath.Join(logsDir, fmt.Sprintf("tf-engine_%s.log", timestamp))
+// InitializeLogging sets up logging to the rotating log file and the console
+func InitializeLogging(opts Options) error {
+	if opts.Dir == "" {
+		opts.Dir = paths.Log()
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 5 << 20
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = 5
+	}
+	if opts.Console == nil {
+		opts.Console = os.Stdout
+	}
 
-	var err error
-	logFile, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	logPath := filepath.Join(opts.Dir, LogFileName)
+	file, err := OpenRotatingFile(logPath, opts.MaxSize, opts.MaxFiles)
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
 
-	// Create multi-writer for both file and stdout
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	// Levels are applied per subsystem by handler, so the output accepts all
+	out := io.MultiWriter(opts.Console, file)
+	handlerOpts := &slog.HandlerOptions{Level: slog.Level(-1 << 10)}
+	var h slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		h = slog.NewTextHandler(out, handlerOpts)
+	case "json":
+		h = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		file.Close()
+		return fmt.Errorf("unknown log format %q (want text or json)", opts.Format)
+	}
 
-	// Initialize loggers
-	InfoLogger = log.New(multiWriter, "INFO:  ", log.Ldate|log.Ltime|log.Lshortfile)
-	ErrorLogger = log.New(multiWriter, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
-	DebugLogger = log.New(multiWriter, "DEBUG: ", log.Ldate|log.Ltime|log.Lshortfile)
+	mu.Lock()
+	if logFile != nil {
+		logFile.Close()
+	}
+	base, levels, logFile = h, opts.Levels, file
+	mu.Unlock()
 
-	InfoLogger.Printf("Logging initialized: %s", logPath)
+	For(App).Info("Logging initialized", "path", logPath, "level", opts.Levels.String())
 	return nil
 }
 
 // CloseLogging closes the log file
 func CloseLogging() {
+	mu.Lock()
+	defer mu.Unlock()
 	if logFile != nil {
-		InfoLogger.Println("Closing log file")
 		logFile.Close()
+		logFile = nil
+		base = nil
 	}
 }
 
 // LogStartup logs application startup information
 func LogStartup() {
-	InfoLogger.Println("=== TF-Engine 2.0 Starting ===")
-	InfoLogger.Printf("Working directory: %s", mustGetWd())
-	InfoLogger.Printf("Executable path: %s", mustGetExecutable())
-	InfoLogger.Printf("Go version: %s", mustGetGoVersion())
-	InfoLogger.Printf("OS: %s", mustGetOS())
+	logger := For(App)
+	logger.Info("=== TF-Engine 2.0 Starting ===")
+	logger.Info("Environment",
+		"wd", mustGetWd(),
+		"executable", mustGetExecutable(),
+		"go", runtime.Version(),
+		"os", runtime.GOOS+"/"+runtime.GOARCH,
+		"revision", vcsRevision())
 }
 
 // Helper functions to gather system info
@@ -84,23 +146,28 @@ func mustGetExecutable() string {
 	return exe
 }
 
-func mustGetGoVersion() string {
-	// This will be compiled in
-	return "go1.21+"
-}
-
-func mustGetOS() string {
-	return fmt.Sprintf("%s/%s", os.Getenv("GOOS"), os.Getenv("GOARCH"))
+// vcsRevision returns the commit the binary was built from, if stamped
+func vcsRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				return s.Value
+			}
+		}
+	}
+	return "unknown"
 }
 
 // LogPanic logs a recovered panic
 func LogPanic(r interface{}) {
-	ErrorLogger.Printf("PANIC: %v", r)
+	For(App).Error("PANIC", "panic", fmt.Sprint(r))
 }
 
-// CleanupOldLogs removes log files older than 30 days
+// CleanupOldLogs removes log files older than 30 days, such as the per-launch
+// files written before logs rotated by size
 func CleanupOldLogs() error {
-	logsDir := "logs"
+	logger := For(App)
+	logsDir := paths.Log()
 	entries, err := os.ReadDir(logsDir)
 	if err != nil {
 		return err
@@ -108,7 +175,7 @@ func CleanupOldLogs() error {
 
 	cutoff := time.Now().AddDate(0, 0, -30)
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || entry.Name() == LogFileName {
 			continue
 		}
 
@@ -120,9 +187,9 @@ func CleanupOldLogs() error {
 		if info.ModTime().Before(cutoff) {
 			path := filepath.Join(logsDir, entry.Name())
 			if err := os.Remove(path); err != nil {
-				DebugLogger.Printf("Failed to remove old log: %s", path)
+				logger.Debug("Failed to remove old log", "path", path, "err", err)
 			} else {
-				DebugLogger.Printf("Removed old log: %s", path)
+				logger.Debug("Removed old log", "path", path)
 			}
 		}
 	}
@@ -0,0 +1,152 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupTestLogging(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+	console := &bytes.Buffer{}
+	opts.Dir = t.TempDir()
+	opts.Console = console
+	if err := InitializeLogging(opts); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		CloseLogging()
+		SetRedact(false)
+	})
+	console.Reset()
+	return console
+}
+
+func jsonLines(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Not JSON: %q", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn, storage=debug,vimium=error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levels.For(UI) != slog.LevelWarn || levels.For(Storage) != slog.LevelDebug || levels.For(Vimium) != slog.LevelError {
+		t.Errorf("Unexpected levels %s", levels)
+	}
+	if got := levels.String(); got != "warn,storage=debug,vimium=error" {
+		t.Errorf("Unexpected string %q", got)
+	}
+
+	if levels, _ := ParseLevels(""); levels.For(App) != slog.LevelInfo {
+		t.Error("An empty spec should mean info")
+	}
+	if _, err := ParseLevels("storage=loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestSubsystemLevelsAndJSON(t *testing.T) {
+	levels, _ := ParseLevels("info,storage=debug")
+	out := setupTestLogging(t, Options{Levels: levels, Format: "json"})
+
+	For(Storage).Debug("lock taken", "path", "trades.json")
+	For(UI).Debug("hidden")
+	For(UI).Info("screen shown", "screen", "dashboard")
+
+	records := jsonLines(t, out)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d:\n%s", len(records), out)
+	}
+	if records[0]["subsystem"] != Storage || records[0]["msg"] != "lock taken" || records[0]["path"] != "trades.json" {
+		t.Errorf("Unexpected storage record %v", records[0])
+	}
+	if records[1]["subsystem"] != UI || records[1]["screen"] != "dashboard" {
+		t.Errorf("Unexpected ui record %v", records[1])
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	out := setupTestLogging(t, Options{Format: "json"})
+	logger := For(App).With("account_equity", 25000.0)
+
+	SetRedact(true)
+	logger.Info("settings", "realized_pnl", 120.5, slog.Group("ledger", "equity", 25120.5, "entries", 3), "risk", 0.028)
+	SetRedact(false)
+	logger.Info("settings", "pnl", 120.5)
+
+	records := jsonLines(t, out)
+	redacted, plain := records[0], records[1]
+	if redacted["account_equity"] != Redacted || redacted["realized_pnl"] != Redacted {
+		t.Errorf("Equity and P&L should be redacted: %v", redacted)
+	}
+	ledger := redacted["ledger"].(map[string]any)
+	if ledger["equity"] != Redacted || ledger["entries"] != 3.0 {
+		t.Errorf("Grouped equity should be redacted: %v", ledger)
+	}
+	if redacted["risk"] != 0.028 {
+		t.Errorf("Other values should be kept: %v", redacted)
+	}
+	if plain["pnl"] != 120.5 || plain["account_equity"] != 25000.0 {
+		t.Errorf("Nothing should be redacted with privacy off: %v", plain)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFileName)
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+	if got := read(path); got != "four\nfive\n" {
+		t.Errorf("Current file: %q", got)
+	}
+	if got := read(RotatedName(path, 1)); got != "three\n" {
+		t.Errorf("Newest rotated file: %q", got)
+	}
+	if got := read(RotatedName(path, 2)); got != "one\ntwo\n" {
+		t.Errorf("Oldest rotated file: %q", got)
+	}
+	if got := read(RotatedName(path, 3)); got != "<missing>" {
+		t.Errorf("Only 2 rotated files should be kept, found %q", got)
+	}
+}
+
+func TestInitializeLogging_UnknownFormat(t *testing.T) {
+	err := InitializeLogging(Options{Dir: t.TempDir(), Format: "xml"})
+	if err == nil {
+		CloseLogging()
+		t.Fatal("Expected an error for an unknown format")
+	}
+}
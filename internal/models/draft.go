@@ -0,0 +1,28 @@
+package models
+
+// Draft is a trade still going through the entry workflow. Each draft keeps
+// its own cooldown start and checklist state (in Trade) and the navigator
+// position it was left on, so several trades can be in progress at once and
+// each resumes exactly where it stopped.
+type Draft struct {
+	Trade   Trade    `json:"trade"`
+	Step    string   `json:"step,omitempty"`    // Screen name, e.g. "checklist"
+	History []string `json:"history,omitempty"` // Screens Back returns through, oldest first
+}
+
+// ID returns the draft's trade ID
+func (d *Draft) ID() string {
+	return d.Trade.ID
+}
+
+// Title returns a short label for draft lists
+func (d *Draft) Title() string {
+	switch {
+	case d.Trade.Ticker != "":
+		return d.Trade.Ticker
+	case d.Trade.Sector != "":
+		return d.Trade.Sector + " (no ticker yet)"
+	default:
+		return "New trade"
+	}
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Ledger entry types
+const (
+	LedgerStartingBalance = "starting_balance"
+	LedgerDeposit         = "deposit"
+	LedgerWithdrawal      = "withdrawal"
+	LedgerRealizedPnL     = "realized_pnl"
+)
+
+// LedgerEntry represents a single posting to the account ledger.
+// Amount is signed: withdrawals and losses are negative.
+type LedgerEntry struct {
+	ID       string    `json:"id"`
+	Date     time.Time `json:"date"`
+	Type     string    `json:"type"`
+	Amount   float64   `json:"amount"`
+	TradeID  string    `json:"trade_id,omitempty"` // Set for realized P&L postings
+	Note     string    `json:"note,omitempty"`
+	PostedAt time.Time `json:"posted_at"`
+}
+
+// Ledger holds the account's cash history. Current equity is always derived
+// from the entries, never stored.
+type Ledger struct {
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// LedgerBalance pairs a ledger entry with the running balance after it
+type LedgerBalance struct {
+	Entry   LedgerEntry
+	Balance float64
+}
+
+// NewLedger creates a ledger opened with a starting balance
+func NewLedger(startingBalance float64, date time.Time) *Ledger {
+	ledger := &Ledger{Entries: []LedgerEntry{}}
+	ledger.post(LedgerStartingBalance, startingBalance, date, "", "Starting balance")
+	return ledger
+}
+
+// Deposit records a deposit into the account
+func (l *Ledger) Deposit(amount float64, date time.Time, note string) error {
+	if amount <= 0 {
+		return fmt.Errorf("deposit amount must be positive: %.2f", amount)
+	}
+	l.post(LedgerDeposit, amount, date, "", note)
+	return nil
+}
+
+// Withdraw records a withdrawal from the account
+func (l *Ledger) Withdraw(amount float64, date time.Time, note string) error {
+	if amount <= 0 {
+		return fmt.Errorf("withdrawal amount must be positive: %.2f", amount)
+	}
+	if amount > l.Equity() {
+		return fmt.Errorf("withdrawal of $%.2f exceeds equity of $%.2f", amount, l.Equity())
+	}
+	l.post(LedgerWithdrawal, -amount, date, "", note)
+	return nil
+}
+
+// SyncRealizedPnL posts realized P&L for closed trades so the ledger matches
+// the trade history. Postings are never rewritten: an edited P&L posts the
+// difference, and a deleted or reopened trade posts a reversal.
+// Returns the number of entries posted.
+func (l *Ledger) SyncRealizedPnL(trades []Trade, now time.Time) int {
+	posted := make(map[string]float64)
+	for _, entry := range l.Entries {
+		if entry.Type == LedgerRealizedPnL && entry.TradeID != "" {
+			posted[entry.TradeID] += entry.Amount
+		}
+	}
+
+	count := 0
+	seen := make(map[string]bool)
+	for _, trade := range trades {
+		if trade.ID == "" {
+			continue
+		}
+		seen[trade.ID] = true
+
+		target := 0.0
+		if trade.GetStatus() != "active" && trade.ProfitLoss != nil {
+			target = *trade.ProfitLoss
+		}
+
+		diff := target - posted[trade.ID]
+		if math.Abs(diff) < 0.005 {
+			continue
+		}
+
+		_, hasPostings := posted[trade.ID]
+		date, note := now, fmt.Sprintf("%s P&L adjustment", trade.Ticker)
+		if !hasPostings {
+			date, note = tradeCloseDate(trade), fmt.Sprintf("%s closed", trade.Ticker)
+		}
+		l.post(LedgerRealizedPnL, diff, date, trade.ID, note)
+		count++
+	}
+
+	// Reverse postings for trades that no longer exist
+	for tradeID, amount := range posted {
+		if seen[tradeID] || math.Abs(amount) < 0.005 {
+			continue
+		}
+		l.post(LedgerRealizedPnL, -amount, now, tradeID, "Trade deleted - reversal")
+		count++
+	}
+
+	return count
+}
+
+// Equity returns the current account equity
+func (l *Ledger) Equity() float64 {
+	var equity float64
+	for _, entry := range l.Entries {
+		equity += entry.Amount
+	}
+	return equity
+}
+
+// EquityAt reconstructs the account equity at the end of the given date (in
+// the date's location), whatever its time of day
+func (l *Ledger) EquityAt(date time.Time) float64 {
+	y, m, d := date.Date()
+	end := time.Date(y, m, d+1, 0, 0, 0, 0, date.Location())
+
+	var equity float64
+	for _, entry := range l.Entries {
+		if entry.Date.Before(end) {
+			equity += entry.Amount
+		}
+	}
+	return equity
+}
+
+// Total returns the sum of all entries of the given type
+func (l *Ledger) Total(entryType string) float64 {
+	var total float64
+	for _, entry := range l.Entries {
+		if entry.Type == entryType {
+			total += entry.Amount
+		}
+	}
+	return total
+}
+
+// History returns entries in date order with the running balance after each
+func (l *Ledger) History() []LedgerBalance {
+	entries := make([]LedgerEntry, len(l.Entries))
+	copy(entries, l.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	history := make([]LedgerBalance, 0, len(entries))
+	balance := 0.0
+	for _, entry := range entries {
+		balance += entry.Amount
+		history = append(history, LedgerBalance{Entry: entry, Balance: balance})
+	}
+	return history
+}
+
+// post appends an entry to the ledger
+func (l *Ledger) post(entryType string, amount float64, date time.Time, tradeID, note string) {
+	now := time.Now()
+	l.Entries = append(l.Entries, LedgerEntry{
+		ID:       fmt.Sprintf("L%d-%d", now.UnixNano(), len(l.Entries)),
+		Date:     date,
+		Type:     entryType,
+		Amount:   amount,
+		TradeID:  tradeID,
+		Note:     note,
+		PostedAt: now,
+	})
+}
+
+// tradeCloseDate returns the date a trade's P&L was realized
+func tradeCloseDate(trade Trade) time.Time {
+	if trade.ExitDate != nil {
+		return *trade.ExitDate
+	}
+	if !trade.UpdatedAt.IsZero() {
+		return trade.UpdatedAt
+	}
+	return time.Now()
+}
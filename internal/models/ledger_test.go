@@ -0,0 +1,128 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLedger_DepositsAndWithdrawals(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ledger := NewLedger(25000, start)
+
+	if err := ledger.Deposit(5000, start.AddDate(0, 1, 0), "Bonus"); err != nil {
+		t.Fatalf("Deposit failed: %v", err)
+	}
+	if err := ledger.Withdraw(2000, start.AddDate(0, 2, 0), "Taxes"); err != nil {
+		t.Fatalf("Withdraw failed: %v", err)
+	}
+
+	if ledger.Equity() != 28000 {
+		t.Errorf("Expected equity $28000, got $%.2f", ledger.Equity())
+	}
+	if ledger.Total(LedgerWithdrawal) != -2000 {
+		t.Errorf("Expected withdrawals -$2000, got $%.2f", ledger.Total(LedgerWithdrawal))
+	}
+}
+
+func TestLedger_RejectsInvalidCashFlows(t *testing.T) {
+	ledger := NewLedger(1000, time.Now())
+
+	if err := ledger.Deposit(-50, time.Now(), ""); err == nil {
+		t.Error("Negative deposit should be rejected")
+	}
+	if err := ledger.Withdraw(0, time.Now(), ""); err == nil {
+		t.Error("Zero withdrawal should be rejected")
+	}
+	if err := ledger.Withdraw(1500, time.Now(), ""); err == nil {
+		t.Error("Withdrawal larger than equity should be rejected")
+	}
+	if len(ledger.Entries) != 1 {
+		t.Errorf("Rejected cash flows should not post entries, got %d entries", len(ledger.Entries))
+	}
+}
+
+func TestLedger_SyncRealizedPnL_Idempotent(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ledger := NewLedger(25000, start)
+
+	win, loss := 800.0, -300.0
+	exit := start.AddDate(0, 0, 10)
+	trades := []Trade{
+		{ID: "t1", Ticker: "UNH", ProfitLoss: &win, ExitDate: &exit, Status: "closed"},
+		{ID: "t2", Ticker: "MSFT", ProfitLoss: &loss, ExitDate: &exit, Status: "closed"},
+		{ID: "t3", Ticker: "AAPL", Status: "active"},
+	}
+
+	if posted := ledger.SyncRealizedPnL(trades, start.AddDate(0, 0, 20)); posted != 2 {
+		t.Errorf("Expected 2 postings, got %d", posted)
+	}
+	if posted := ledger.SyncRealizedPnL(trades, start.AddDate(0, 0, 21)); posted != 0 {
+		t.Errorf("Second sync should post nothing, got %d", posted)
+	}
+	if ledger.Equity() != 25500 {
+		t.Errorf("Expected equity $25500, got $%.2f", ledger.Equity())
+	}
+
+	// Postings are dated on the trade's exit date
+	for _, entry := range ledger.Entries {
+		if entry.Type == LedgerRealizedPnL && !entry.Date.Equal(exit) {
+			t.Errorf("Expected posting dated %v, got %v", exit, entry.Date)
+		}
+	}
+}
+
+func TestLedger_SyncRealizedPnL_EditsAndDeletesPostDifferences(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ledger := NewLedger(25000, start)
+
+	pnl := 800.0
+	exit := start.AddDate(0, 0, 10)
+	trades := []Trade{{ID: "t1", Ticker: "UNH", ProfitLoss: &pnl, ExitDate: &exit, Status: "closed"}}
+	ledger.SyncRealizedPnL(trades, start.AddDate(0, 0, 11))
+
+	// Edit the P&L: an adjustment is posted, the original is kept
+	edited := 650.0
+	trades[0].ProfitLoss = &edited
+	ledger.SyncRealizedPnL(trades, start.AddDate(0, 0, 12))
+
+	if ledger.Equity() != 25650 {
+		t.Errorf("Expected equity $25650 after edit, got $%.2f", ledger.Equity())
+	}
+	if len(ledger.Entries) != 3 {
+		t.Errorf("Expected 3 entries (start, posting, adjustment), got %d", len(ledger.Entries))
+	}
+
+	// Delete the trade: the postings are reversed
+	ledger.SyncRealizedPnL([]Trade{}, start.AddDate(0, 0, 13))
+	if ledger.Equity() != 25000 {
+		t.Errorf("Expected equity $25000 after delete, got $%.2f", ledger.Equity())
+	}
+}
+
+func TestLedger_EquityAtReconstructsHistory(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ledger := NewLedger(25000, start)
+	ledger.Deposit(5000, start.AddDate(0, 1, 0), "")
+
+	pnl := -1000.0
+	exit := start.AddDate(0, 2, 0).Add(15*time.Hour + 30*time.Minute)
+	ledger.SyncRealizedPnL([]Trade{{ID: "t1", ProfitLoss: &pnl, ExitDate: &exit, Status: "closed"}}, exit)
+
+	tests := []struct {
+		date     time.Time
+		expected float64
+	}{
+		{start.AddDate(0, 0, -1), 0},
+		{start.AddDate(0, 0, 15), 25000},
+		{start.AddDate(0, 1, 15), 30000},
+		{start.AddDate(0, 3, 0), 29000},
+		// The loss posted at 15:30 counts for its whole date, midnight included
+		{time.Date(2025, 3, 1, 23, 0, 0, 0, time.UTC), 30000},
+		{time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), 29000},
+	}
+	for _, tt := range tests {
+		if got := ledger.EquityAt(tt.date); got != tt.expected {
+			t.Errorf("EquityAt(%s): expected $%.2f, got $%.2f", tt.date.Format("2006-01-02"), tt.expected, got)
+		}
+	}
+}
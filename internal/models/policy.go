@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,6 +17,7 @@ type Policy struct {
 	Strategies      map[string]Strategy        `json:"strategies"`
 	Checklist       Checklist                  `json:"checklist"`
 	Defaults        PolicyDefaults             `json:"defaults"`
+	RiskSchedule    *RiskSchedule              `json:"risk_schedule,omitempty"`
 	Calendar        CalendarConfig             `json:"calendar"`
 	FinvizHelpers   map[string]string          `json:"finviz_helpers"`
 	ScreenerSorting map[string]ScreenerSorting `json:"screener_sorting"`
@@ -29,6 +32,7 @@ type Sector struct {
 	HeatCapPercent      float64                        `json:"heat_cap_percent"`
 	Notes               string                         `json:"notes"`
 	AllowedStrategies   []string                       `json:"allowed_strategies"`
+	Tickers             []string                       `json:"tickers,omitempty"` // Symbols that belong to the sector (used to route alerts)
 	ScreenerURLs        map[string]string              `json:"screener_urls"`
 	StrategySuitability map[string]StrategySuitability `json:"strategy_suitability,omitempty"`
 	UtilitiesWarning    *UtilitiesWarning              `json:"utilities_warning,omitempty"`
@@ -75,6 +79,16 @@ type PolicyDefaults struct {
 	ChartViewParam   string  `json:"chart_view_param"`
 }
 
+// RiskSchedule reduces risk per trade in steps as the account draws down
+// from its equity high (e.g. cut 25% of risk for every 5% of drawdown)
+type RiskSchedule struct {
+	Enabled          bool    `json:"enabled"`
+	DrawdownStep     float64 `json:"drawdown_step"`       // Drawdown per step (0.05 = 5%)
+	RiskCutPerStep   float64 `json:"risk_cut_per_step"`   // Fraction of base risk cut per step (0.25 = 25%)
+	MinRiskFraction  float64 `json:"min_risk_fraction"`   // Floor as a fraction of base risk
+	RestoreAtNewHigh bool    `json:"restore_at_new_high"` // Hold the deepest step until equity makes a new high
+}
+
 // CalendarConfig defines calendar view settings
 type CalendarConfig struct {
 	PastDays   int    `json:"past_days"`
@@ -105,6 +119,52 @@ func LoadPolicy(path string) (*Policy, error) {
 	return &policy, nil
 }
 
+// FindSector returns the sector named name (case-insensitive), or nil
+func (p *Policy) FindSector(name string) *Sector {
+	for i := range p.Sectors {
+		if strings.EqualFold(p.Sectors[i].Name, name) {
+			return &p.Sectors[i]
+		}
+	}
+	return nil
+}
+
+// SectorForTicker returns the sector whose tickers include ticker, or nil
+func (p *Policy) SectorForTicker(ticker string) *Sector {
+	for i := range p.Sectors {
+		for _, t := range p.Sectors[i].Tickers {
+			if strings.EqualFold(t, ticker) {
+				return &p.Sectors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// MaxHoldWeeks returns the upper end of HoldWeeks ("3-12" gives 12, "8"
+// gives 8). It reports false for "n/a", "variable" and other free text.
+func (s Strategy) MaxHoldWeeks() (int, bool) {
+	text := strings.TrimSpace(s.HoldWeeks)
+	if i := strings.LastIndex(text, "-"); i >= 0 {
+		text = strings.TrimSpace(text[i+1:])
+	}
+	weeks, err := strconv.Atoi(text)
+	if err != nil || weeks <= 0 {
+		return 0, false
+	}
+	return weeks, true
+}
+
+// FindStrategy returns the policy's ID for a strategy ID given in any case
+func (p *Policy) FindStrategy(id string) (string, bool) {
+	for key := range p.Strategies {
+		if strings.EqualFold(key, id) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
 // SafeModePolicy returns a minimal safe-mode policy
 func SafeModePolicy() *Policy {
 	return &Policy{
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// Policy verification results
+const (
+	PolicyVerified = "verified" // Signature matches the hash
+	PolicyUnsigned = "unsigned" // Signature is empty or the placeholder
+	PolicyMismatch = "mismatch" // Signature doesn't match; the file was edited
+)
+
+// PolicySignaturePlaceholder is the signature of a policy that was never signed
+const PolicySignaturePlaceholder = "REPLACE_WITH_SHA256_OR_SIGNATURE"
+
+// PolicyVerification is the result of checking a policy's signature
+type PolicyVerification struct {
+	Hash        string `json:"hash"`      // SHA-256 of the canonical JSON without the signature
+	Signature   string `json:"signature"` // Signature recorded in the file
+	Status      string `json:"status"`
+	EnforceHash bool   `json:"enforce_hash"`
+}
+
+// VerifyPolicy hashes a policy the way scripts/verify_policy_hash.go does:
+// SHA-256 of the JSON with security.signature removed and keys sorted
+func VerifyPolicy(data []byte) (PolicyVerification, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return PolicyVerification{}, err
+	}
+	security, ok := raw["security"].(map[string]interface{})
+	if !ok {
+		return PolicyVerification{}, errors.New("policy has no security section")
+	}
+
+	v := PolicyVerification{}
+	v.Signature, _ = security["signature"].(string)
+	v.EnforceHash, _ = security["enforce_hash"].(bool)
+	delete(security, "signature")
+
+	canonical, err := json.Marshal(raw)
+	if err != nil {
+		return PolicyVerification{}, err
+	}
+	sum := sha256.Sum256(canonical)
+	v.Hash = hex.EncodeToString(sum[:])
+
+	switch v.Signature {
+	case "", PolicySignaturePlaceholder:
+		v.Status = PolicyUnsigned
+	case v.Hash:
+		v.Status = PolicyVerified
+	default:
+		v.Status = PolicyMismatch
+	}
+	return v, nil
+}
+
+// VerifyPolicyFile verifies the policy at path
+func VerifyPolicyFile(path string) (PolicyVerification, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PolicyVerification{}, err
+	}
+	return VerifyPolicy(data)
+}
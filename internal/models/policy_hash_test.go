@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyPolicy(t *testing.T) {
+	unsigned := `{"version": "1", "security": {"enforce_hash": true, "signature": "REPLACE_WITH_SHA256_OR_SIGNATURE"}, "defaults": {"risk_per_trade": 0.01}}`
+
+	v, err := VerifyPolicy([]byte(unsigned))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Status != PolicyUnsigned || len(v.Hash) != 64 || !v.EnforceHash {
+		t.Fatalf("Unexpected result for an unsigned policy: %+v", v)
+	}
+
+	// Signing doesn't change the hash, so the signature verifies
+	signed := strings.Replace(unsigned, PolicySignaturePlaceholder, v.Hash, 1)
+	if got, _ := VerifyPolicy([]byte(signed)); got.Status != PolicyVerified || got.Hash != v.Hash {
+		t.Errorf("Expected the signed policy to verify, got %+v", got)
+	}
+
+	// Key order and whitespace don't matter
+	reordered := `{"defaults":{"risk_per_trade":0.01},"security":{"signature":"` + v.Hash + `","enforce_hash":true},"version":"1"}`
+	if got, _ := VerifyPolicy([]byte(reordered)); got.Status != PolicyVerified {
+		t.Errorf("Expected the reordered policy to verify, got %+v", got)
+	}
+
+	// Any edit breaks it
+	tampered := strings.Replace(signed, "0.01", "0.05", 1)
+	if got, _ := VerifyPolicy([]byte(tampered)); got.Status != PolicyMismatch {
+		t.Errorf("Expected a mismatch for an edited policy, got %+v", got)
+	}
+
+	if _, err := VerifyPolicy([]byte(`{"version": "1"}`)); err == nil {
+		t.Error("Expected an error for a policy without a security section")
+	}
+}
@@ -35,3 +35,52 @@ func TestLoadPolicyStrategyCount(t *testing.T) {
 		}
 	}
 }
+
+func TestPolicyLookups(t *testing.T) {
+	policy := SafeModePolicy()
+	policy.FindSector("technology").Tickers = []string{"XLK", "NVDA"}
+
+	if sector := policy.SectorForTicker("nvda"); sector == nil || sector.Name != "Technology" {
+		t.Errorf("SectorForTicker(nvda) = %+v", sector)
+	}
+	if sector := policy.SectorForTicker("XOM"); sector != nil {
+		t.Errorf("SectorForTicker(XOM) = %s, want none", sector.Name)
+	}
+	if id, ok := policy.FindStrategy("alt10"); !ok || id != "Alt10" {
+		t.Errorf("FindStrategy(alt10) = %q, %v", id, ok)
+	}
+	if _, ok := policy.FindStrategy("Alt99"); ok {
+		t.Error("FindStrategy(Alt99) should fail")
+	}
+
+	for text, want := range map[string]int{"3-12": 12, "8": 8, " 2 - 6 ": 6, "n/a": 0, "variable": 0, "": 0} {
+		if weeks, ok := (Strategy{HoldWeeks: text}).MaxHoldWeeks(); weeks != want || ok != (want > 0) {
+			t.Errorf("MaxHoldWeeks(%q) = %d, %v; want %d", text, weeks, ok, want)
+		}
+	}
+}
+
+// TestShippedPolicyTickers checks that every sector ETF in the shipped policy
+// maps to its sector, so webhook alerts route without an explicit sector
+func TestShippedPolicyTickers(t *testing.T) {
+	policyPath := filepath.Join("..", "..", "data", "policy.v1.json")
+	policy, err := LoadPolicy(policyPath)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+
+	for ticker, want := range map[string]string{
+		"XLV": "Healthcare", "XLK": "Technology", "XLY": "Consumer Discretionary", "XLI": "Industrials",
+		"XLC": "Communication Services", "XLP": "Consumer Defensive", "XLF": "Financials", "XLRE": "Real Estate",
+		"XLE": "Energy", "XLU": "Utilities",
+	} {
+		if sector := policy.SectorForTicker(ticker); sector == nil || sector.Name != want {
+			t.Errorf("SectorForTicker(%s) = %+v, want %s", ticker, sector, want)
+		}
+	}
+
+	v, err := VerifyPolicyFile(policyPath)
+	if err != nil || v.Status != PolicyVerified {
+		t.Errorf("Shipped policy signature: %s (%v)", v.Status, err)
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultProfileID identifies the profile that uses the original data/ layout
+const DefaultProfileID = "default"
+
+// Profile is a trading account with its own settings, trades and ledger
+type Profile struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	PolicyFile string    `json:"policy_file,omitempty"` // Optional policy override
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ProfileList holds all account profiles and which one is active
+type ProfileList struct {
+	Active   string    `json:"active"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// DefaultProfileList returns a list containing only the default profile
+func DefaultProfileList() *ProfileList {
+	return &ProfileList{
+		Active: DefaultProfileID,
+		Profiles: []Profile{
+			{ID: DefaultProfileID, Name: "Default"},
+		},
+	}
+}
+
+// Find returns the profile with the given ID
+func (l *ProfileList) Find(id string) (Profile, bool) {
+	for _, p := range l.Profiles {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// ActiveProfile returns the active profile, falling back to the first one
+func (l *ProfileList) ActiveProfile() Profile {
+	if p, ok := l.Find(l.Active); ok {
+		return p
+	}
+	if len(l.Profiles) > 0 {
+		return l.Profiles[0]
+	}
+	return Profile{ID: DefaultProfileID, Name: "Default"}
+}
+
+// Add creates a profile with a unique ID derived from its name
+func (l *ProfileList) Add(name, policyFile string, now time.Time) (Profile, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Profile{}, errors.New("profile name is required")
+	}
+
+	base := profileSlug(name)
+	if base == "" {
+		return Profile{}, fmt.Errorf("profile name %q must contain letters or digits", name)
+	}
+
+	id := base
+	for i := 2; ; i++ {
+		if _, exists := l.Find(id); !exists {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	profile := Profile{
+		ID:         id,
+		Name:       name,
+		PolicyFile: strings.TrimSpace(policyFile),
+		CreatedAt:  now,
+	}
+	l.Profiles = append(l.Profiles, profile)
+	return profile, nil
+}
+
+// profileSlug turns a display name into a filesystem-safe ID
+func profileSlug(name string) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+			lastDash = false
+		case !lastDash:
+			b.WriteRune('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfileList_AddGeneratesUniqueIDs(t *testing.T) {
+	list := DefaultProfileList()
+	now := time.Now()
+
+	p1, err := list.Add("Prop Account #1", "", now)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if p1.ID != "prop-account-1" {
+		t.Errorf("Expected ID prop-account-1, got %s", p1.ID)
+	}
+
+	p2, err := list.Add("prop account 1", "data/policy.prop.json", now)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if p2.ID != "prop-account-1-2" {
+		t.Errorf("Expected ID prop-account-1-2, got %s", p2.ID)
+	}
+	if p2.PolicyFile != "data/policy.prop.json" {
+		t.Errorf("Expected policy override to be kept, got %q", p2.PolicyFile)
+	}
+
+	// "Default" collides with the built-in profile
+	p3, _ := list.Add("Default", "", now)
+	if p3.ID != "default-2" {
+		t.Errorf("Expected ID default-2, got %s", p3.ID)
+	}
+}
+
+func TestProfileList_AddRejectsEmptyNames(t *testing.T) {
+	list := DefaultProfileList()
+
+	if _, err := list.Add("   ", "", time.Now()); err == nil {
+		t.Error("Expected error for blank name")
+	}
+	if _, err := list.Add("!!!", "", time.Now()); err == nil {
+		t.Error("Expected error for name without letters or digits")
+	}
+	if len(list.Profiles) != 1 {
+		t.Errorf("Rejected profiles should not be added, got %d", len(list.Profiles))
+	}
+}
+
+func TestProfileList_ActiveProfileFallback(t *testing.T) {
+	list := DefaultProfileList()
+	list.Active = "missing"
+
+	if got := list.ActiveProfile(); got.ID != DefaultProfileID {
+		t.Errorf("Expected fallback to default profile, got %s", got.ID)
+	}
+}
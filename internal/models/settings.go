@@ -2,13 +2,65 @@ package models
 
 // Settings represents user preferences
 type Settings struct {
-	ThemeMode        string  `json:"theme_mode"`
-	AccountEquity    float64 `json:"account_equity"`
-	RiskPerTrade     float64 `json:"risk_per_trade"`
-	PortfolioHeatCap float64 `json:"portfolio_heat_cap"`
-	BucketHeatCap    float64 `json:"bucket_heat_cap"`
-	VimiumEnabled    bool    `json:"vimium_enabled"`
-	SampleDataMode   bool    `json:"sample_data_mode"`
+	ThemeMode        string          `json:"theme_mode"`
+	AccountEquity    float64         `json:"account_equity"`
+	RiskPerTrade     float64         `json:"risk_per_trade"`
+	PortfolioHeatCap float64         `json:"portfolio_heat_cap"`
+	BucketHeatCap    float64         `json:"bucket_heat_cap"`
+	VimiumEnabled    bool            `json:"vimium_enabled"`
+	SampleDataMode   bool            `json:"sample_data_mode"`
+	PrivateLogs      bool            `json:"private_logs"` // Redact equity and P&L from logs
+	PropFirm         PropFirmRules   `json:"prop_firm"`
+	API              APISettings     `json:"api"`
+	Webhook          WebhookSettings `json:"webhook"`
+}
+
+// DefaultAPIPort is the localhost port of the local API
+const DefaultAPIPort = 8787
+
+// DefaultWebhookPort is the localhost port of the alert webhook
+const DefaultWebhookPort = 8788
+
+// APISettings control the local HTTP API. It only listens on localhost and
+// is off until the user turns it on.
+type APISettings struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"` // 0 means DefaultAPIPort
+}
+
+// ListenPort returns the configured port or the default
+func (a APISettings) ListenPort() int {
+	if a.Port == 0 {
+		return DefaultAPIPort
+	}
+	return a.Port
+}
+
+// WebhookSettings control the localhost listener for strategy alerts
+// (e.g. TradingView). It is off until the user turns it on.
+type WebhookSettings struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"` // 0 means DefaultWebhookPort
+}
+
+// ListenPort returns the configured port or the default
+func (w WebhookSettings) ListenPort() int {
+	if w.Port == 0 {
+		return DefaultWebhookPort
+	}
+	return w.Port
+}
+
+// PropFirmRules represents the evaluation rules of a prop firm account.
+// Dollar limits are absolute amounts; a zero limit disables that rule.
+type PropFirmRules struct {
+	Enabled             bool    `json:"enabled"`
+	StartingBalance     float64 `json:"starting_balance"`
+	DailyLossLimit      float64 `json:"daily_loss_limit"`      // Max loss in one trading day ($)
+	TrailingMaxDrawdown float64 `json:"trailing_max_drawdown"` // Max drawdown from the high-water mark ($)
+	MaxOpenContracts    int     `json:"max_open_contracts"`
+	MinTradingDays      int     `json:"min_trading_days"`
+	ProfitTarget        float64 `json:"profit_target"` // Profit required to pass the evaluation ($)
 }
 
 // DefaultSettings returns default user settings
@@ -21,5 +73,22 @@ func DefaultSettings() *Settings {
 		BucketHeatCap:    0.03,     // 3.0% max per sector (allows 1 full + 1 half position)
 		VimiumEnabled:    false,
 		SampleDataMode:   false,
+		PropFirm:         DefaultPropFirmRules(),
+		API:              APISettings{Port: DefaultAPIPort},
+		Webhook:          WebhookSettings{Port: DefaultWebhookPort},
+	}
+}
+
+// DefaultPropFirmRules returns a typical rule set for a $25K prop firm account.
+// Rules are disabled until the user turns them on in Settings.
+func DefaultPropFirmRules() PropFirmRules {
+	return PropFirmRules{
+		Enabled:             false,
+		StartingBalance:     25000.00,
+		DailyLossLimit:      1000.00, // 4% of starting balance
+		TrailingMaxDrawdown: 1500.00, // 6% of starting balance
+		MaxOpenContracts:    10,
+		MinTradingDays:      5,
+		ProfitTarget:        1500.00, // 6% of starting balance
 	}
 }
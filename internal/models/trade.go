@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -35,6 +36,8 @@ type Trade struct {
 	AccountEquity    float64 `json:"account_equity"`
 	RiskPerTrade     float64 `json:"risk_per_trade"`
 	SizingMultiplier float64 `json:"sizing_multiplier"` // From poker sizing
+	RiskScheduleStep int     `json:"risk_schedule_step,omitempty"`
+	RiskScheduleMult float64 `json:"risk_schedule_multiplier,omitempty"` // From drawdown risk schedule
 	PositionSize     int     `json:"position_size"`
 	MaxLoss          float64 `json:"max_loss"`
 
@@ -55,11 +58,33 @@ type Trade struct {
 	Premium         float64   `json:"premium"`
 	Risk            float64   `json:"risk,omitempty"` // Alias for MaxLoss
 
+	// Alert that pre-filled the trade, if any
+	Signal *Signal `json:"signal,omitempty"`
+
 	// Exit Information (filled later)
 	ExitDate   *time.Time `json:"exit_date,omitempty"`
 	ExitPrice  *float64   `json:"exit_price,omitempty"`
 	ProfitLoss *float64   `json:"profit_loss,omitempty"`
-	Status     string     `json:"status"` // "active", "closed", "expired"
+	OpenPnL    *float64   `json:"open_pnl,omitempty"` // Mark-to-market P&L while the trade is active
+	Status     string     `json:"status"`             // "active", "closed", "expired"
+
+	// When OpenPnL was last marked, and the mark carried into that day
+	OpenPnLMarkedAt *time.Time `json:"open_pnl_marked_at,omitempty"`
+	OpenPnLDayStart *float64   `json:"open_pnl_day_start,omitempty"`
+}
+
+// Signal is a strategy alert (e.g. from TradingView) that started a trade
+type Signal struct {
+	Source     string    `json:"source"` // e.g. "tradingview"
+	Price      float64   `json:"price,omitempty"`
+	N          float64   `json:"n,omitempty"` // Turtle N (20-day ATR)
+	ATR        float64   `json:"atr,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// NewTradeID returns a unique trade identifier
+func NewTradeID() string {
+	return fmt.Sprintf("T%d", time.Now().UnixNano())
 }
 
 // GetStatus returns the current status of the trade
@@ -88,3 +113,53 @@ func (t *Trade) GetPnL() float64 {
 	}
 	return 0.0
 }
+
+// GetOpenPnL returns the unrealized profit/loss of an active trade
+func (t *Trade) GetOpenPnL() float64 {
+	if t.OpenPnL != nil {
+		return *t.OpenPnL
+	}
+	return 0.0
+}
+
+// MarkOpenPnL records the trade's open P&L as of now. The first mark of a
+// day keeps the previous one as the day's starting open P&L.
+func (t *Trade) MarkOpenPnL(pnl float64, now time.Time) {
+	if t.OpenPnLMarkedAt == nil || !SameDay(now, *t.OpenPnLMarkedAt) {
+		start := t.GetOpenPnL()
+		t.OpenPnLDayStart = &start
+	}
+	t.OpenPnL = &pnl
+	t.OpenPnLMarkedAt = &now
+}
+
+// OpenPnLChangeOn returns how much the open P&L moved on day's date. Marks
+// from earlier days don't count. A trade marked before mark times were kept
+// is only known to have moved that day if it was entered then.
+func (t *Trade) OpenPnLChangeOn(day time.Time) float64 {
+	if t.OpenPnLMarkedAt == nil {
+		entered := t.EntryDate
+		if entered.IsZero() {
+			entered = t.CreatedAt
+		}
+		if SameDay(day, entered) {
+			return t.GetOpenPnL()
+		}
+		return 0
+	}
+	if !SameDay(day, *t.OpenPnLMarkedAt) {
+		return 0
+	}
+	start := 0.0
+	if t.OpenPnLDayStart != nil {
+		start = *t.OpenPnLDayStart
+	}
+	return t.GetOpenPnL() - start
+}
+
+// SameDay reports whether a and b fall on the same date in a's location
+func SameDay(a, b time.Time) bool {
+	y1, m1, d1 := a.Date()
+	y2, m2, d2 := b.In(a.Location()).Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
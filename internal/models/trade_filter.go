@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TradeFilter selects trades. Empty fields match everything; text fields
+// are case-insensitive.
+type TradeFilter struct {
+	Status   string
+	Sector   string
+	Strategy string
+	Ticker   string
+	Since    time.Time // Created at or after
+	Until    time.Time // Created before
+}
+
+// Match reports whether t passes every filter that is set
+func (f TradeFilter) Match(t *Trade) bool {
+	switch {
+	case f.Status != "" && !strings.EqualFold(t.GetStatus(), f.Status),
+		f.Sector != "" && !strings.EqualFold(t.Sector, f.Sector),
+		f.Strategy != "" && !strings.EqualFold(t.Strategy, f.Strategy),
+		f.Ticker != "" && !strings.EqualFold(t.Ticker, f.Ticker),
+		!f.Since.IsZero() && t.CreatedAt.Before(f.Since),
+		!f.Until.IsZero() && !t.CreatedAt.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+// Apply returns the matching trades in their original order
+func (f TradeFilter) Apply(trades []Trade) []Trade {
+	result := []Trade{}
+	for i := range trades {
+		if f.Match(&trades[i]) {
+			result = append(result, trades[i])
+		}
+	}
+	return result
+}
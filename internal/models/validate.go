@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is a value that can't be saved
+type ValidationError string
+
+func (e ValidationError) Error() string { return string(e) }
+
+func invalidf(format string, args ...interface{}) error {
+	return ValidationError(fmt.Sprintf(format, args...))
+}
+
+// ValidStatus reports whether a trade can be set to status
+func ValidStatus(status string) bool {
+	switch status {
+	case "active", "closed", "expired":
+		return true
+	}
+	return false
+}
+
+// Validate checks the fields that can be edited after the trade is saved
+func (t *Trade) Validate() error {
+	if strings.TrimSpace(t.Ticker) == "" {
+		return invalidf("ticker is required")
+	}
+	if t.Status != "" && !ValidStatus(t.Status) {
+		return invalidf("invalid status %q (want active, closed or expired)", t.Status)
+	}
+	if t.ExitPrice != nil && *t.ExitPrice < 0 {
+		return invalidf("invalid exit price: %.2f", *t.ExitPrice)
+	}
+	if t.ExitDate != nil && t.ExitDate.Before(t.CreatedAt) {
+		return invalidf("exit date %s is before the trade was opened", t.ExitDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// Validate checks settings before they are saved
+func (s *Settings) Validate() error {
+	if s.AccountEquity <= 0 {
+		return invalidf("invalid account equity: %.2f", s.AccountEquity)
+	}
+	if s.RiskPerTrade <= 0 {
+		return invalidf("invalid risk per trade: %v", s.RiskPerTrade)
+	}
+	if s.ThemeMode != "day" && s.ThemeMode != "night" {
+		return invalidf("invalid theme mode %q (want day or night)", s.ThemeMode)
+	}
+
+	rules := s.PropFirm
+	for name, value := range map[string]float64{
+		"starting balance":      rules.StartingBalance,
+		"daily loss limit":      rules.DailyLossLimit,
+		"trailing max drawdown": rules.TrailingMaxDrawdown,
+		"profit target":         rules.ProfitTarget,
+		"max open contracts":    float64(rules.MaxOpenContracts),
+		"minimum trading days":  float64(rules.MinTradingDays),
+	} {
+		if value < 0 {
+			return invalidf("invalid %s: %v", name, value)
+		}
+	}
+
+	if s.API.Port != 0 && (s.API.Port < 1024 || s.API.Port > 65535) {
+		return invalidf("invalid API port %d (want 1024-65535)", s.API.Port)
+	}
+	if s.Webhook.Port != 0 && (s.Webhook.Port < 1024 || s.Webhook.Port > 65535) {
+		return invalidf("invalid webhook port %d (want 1024-65535)", s.Webhook.Port)
+	}
+	if s.API.Enabled && s.Webhook.Enabled && s.API.ListenPort() == s.Webhook.ListenPort() {
+		return invalidf("the API and the webhook can't share port %d", s.API.ListenPort())
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTradeValidate(t *testing.T) {
+	opened := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	before := opened.AddDate(0, 0, -1)
+	negative := -1.0
+
+	valid := Trade{Ticker: "NVDA", Status: "closed", CreatedAt: opened}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Valid trade rejected: %v", err)
+	}
+
+	for name, trade := range map[string]Trade{
+		"no ticker":        {Ticker: " ", Status: "active"},
+		"bad status":       {Ticker: "NVDA", Status: "pending"},
+		"negative exit":    {Ticker: "NVDA", ExitPrice: &negative},
+		"exit before open": {Ticker: "NVDA", CreatedAt: opened, ExitDate: &before},
+	} {
+		var invalid ValidationError
+		if err := trade.Validate(); !errors.As(err, &invalid) {
+			t.Errorf("%s: expected a ValidationError, got %v", name, err)
+		}
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	if err := DefaultSettings().Validate(); err != nil {
+		t.Errorf("Default settings rejected: %v", err)
+	}
+
+	for name, change := range map[string]func(*Settings){
+		"zero equity":     func(s *Settings) { s.AccountEquity = 0 },
+		"zero risk":       func(s *Settings) { s.RiskPerTrade = 0 },
+		"unknown theme":   func(s *Settings) { s.ThemeMode = "dusk" },
+		"negative limit":  func(s *Settings) { s.PropFirm.DailyLossLimit = -5 },
+		"privileged port": func(s *Settings) { s.API.Port = 80 },
+		"shared port": func(s *Settings) {
+			s.API = APISettings{Enabled: true, Port: 9000}
+			s.Webhook = WebhookSettings{Enabled: true, Port: 9000}
+		},
+	} {
+		s := DefaultSettings()
+		change(s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
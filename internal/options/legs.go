@@ -0,0 +1,135 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+// ErrUnsupported is returned for strategies whose contracts a trade doesn't
+// fully describe, such as calendars with their second expiry
+var ErrUnsupported = errors.New("contracts not derivable")
+
+// Leg is one contract of a trade. Ratio is how many are bought (positive)
+// or sold (negative) per unit of the strategy.
+type Leg struct {
+	Contract Contract `json:"contract"`
+	Ratio    int      `json:"ratio"`
+}
+
+// Name returns the leg as "+1 UNH   250117C00500000"
+func (l Leg) Name() string {
+	return fmt.Sprintf("%+d %s", l.Ratio, l.Contract.Symbol())
+}
+
+// legTemplate places one leg: its right, ratio, and which of the trade's
+// distinct strikes (lowest first) it uses
+type legTemplate struct {
+	right  string
+	ratio  int
+	strike int
+}
+
+// templates maps strategy names (lower case) to their legs
+var templates = map[string][]legTemplate{
+	"long call":               {{Call, 1, 0}},
+	"long put":                {{Put, 1, 0}},
+	"covered call":            {{Call, -1, 0}},
+	"cash-secured put":        {{Put, -1, 0}},
+	"bull call spread":        {{Call, 1, 0}, {Call, -1, 1}},
+	"bear call credit spread": {{Call, -1, 0}, {Call, 1, 1}},
+	"bear put spread":         {{Put, -1, 0}, {Put, 1, 1}},
+	"bull put credit spread":  {{Put, 1, 0}, {Put, -1, 1}},
+	"straddle":                {{Put, 1, 0}, {Call, 1, 0}},
+	"strangle":                {{Put, 1, 0}, {Call, 1, 1}},
+	"call ratio backspread":   {{Call, -1, 0}, {Call, 2, 1}},
+	"put ratio backspread":    {{Put, 2, 0}, {Put, -1, 1}},
+	"long call butterfly":     {{Call, 1, 0}, {Call, -2, 1}, {Call, 1, 2}},
+	"long put butterfly":      {{Put, 1, 0}, {Put, -2, 1}, {Put, 1, 2}},
+	"short call butterfly":    {{Call, -1, 0}, {Call, 2, 1}, {Call, -1, 2}},
+	"short put butterfly":     {{Put, -1, 0}, {Put, 2, 1}, {Put, -1, 2}},
+	"call broken wing":        {{Call, 1, 0}, {Call, -2, 1}, {Call, 1, 2}},
+	"put broken wing":         {{Put, 1, 0}, {Put, -2, 1}, {Put, 1, 2}},
+	"iron condor":             {{Put, 1, 0}, {Put, -1, 1}, {Call, -1, 2}, {Call, 1, 3}},
+	"inverse iron condor":     {{Put, -1, 0}, {Put, 1, 1}, {Call, 1, 2}, {Call, -1, 3}},
+	"iron butterfly":          {{Put, 1, 0}, {Put, -1, 1}, {Call, -1, 1}, {Call, 1, 2}},
+	"inverse iron butterfly":  {{Put, -1, 0}, {Put, 1, 1}, {Call, 1, 1}, {Call, -1, 2}},
+}
+
+// Legs returns the contracts a trade's options strategy, strikes and
+// expiration stand for. Strikes may be entered in any order; a strike shared
+// by two legs (a straddle, an iron butterfly's body) may be entered once or
+// twice.
+func Legs(t models.Trade) ([]Leg, error) {
+	strategy := t.OptionsStrategy
+	if strategy == "" {
+		strategy = t.OptionsType
+	}
+	template, ok := templates[strings.ToLower(strings.TrimSpace(strategy))]
+	if !ok {
+		return nil, fmt.Errorf("%w for strategy %q", ErrUnsupported, strategy)
+	}
+	if t.ExpirationDate.IsZero() {
+		return nil, fmt.Errorf("%w: no expiration date", ErrUnsupported)
+	}
+
+	strikes := distinctStrikes(t)
+	want := 0
+	for _, leg := range template {
+		want = max(want, leg.strike+1)
+	}
+	if len(strikes) != want {
+		return nil, fmt.Errorf("%s needs %d distinct strikes, has %d", strategy, want, len(strikes))
+	}
+
+	y, m, d := t.ExpirationDate.Date()
+	expiry := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	legs := make([]Leg, 0, len(template))
+	for _, lt := range template {
+		c := Contract{Root: strings.ToUpper(strings.TrimSpace(t.Ticker)), Expiry: expiry, Right: lt.right, Strike: strikes[lt.strike]}
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Ticker, err)
+		}
+		legs = append(legs, Leg{Contract: c, Ratio: lt.ratio})
+	}
+	return legs, nil
+}
+
+// Names returns the legs as "+1 UNH   250117C00500000, -1 ..."
+func Names(legs []Leg) string {
+	names := make([]string, len(legs))
+	for i, leg := range legs {
+		names[i] = leg.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Describe returns a trade's contract names, or "" when they can't be derived
+func Describe(t models.Trade) string {
+	legs, err := Legs(t)
+	if err != nil {
+		return ""
+	}
+	return Names(legs)
+}
+
+func distinctStrikes(t models.Trade) []float64 {
+	strikes := []float64{}
+	for _, s := range []float64{t.Strike1, t.Strike2, t.Strike3, t.Strike4} {
+		if s > 0 {
+			strikes = append(strikes, s)
+		}
+	}
+	sort.Float64s(strikes)
+	distinct := []float64{}
+	for _, s := range strikes {
+		if len(distinct) == 0 || s != distinct[len(distinct)-1] {
+			distinct = append(distinct, s)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,54 @@
+package options
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func TestLegs(t *testing.T) {
+	expiry := time.Date(2025, 2, 21, 16, 0, 0, 0, time.Local)
+	trade := func(strategy string, strikes ...float64) models.Trade {
+		t := models.Trade{Ticker: "nvda", OptionsStrategy: strategy, ExpirationDate: expiry}
+		for i, s := range strikes {
+			*[]*float64{&t.Strike1, &t.Strike2, &t.Strike3, &t.Strike4}[i] = s
+		}
+		return t
+	}
+
+	for _, tc := range []struct {
+		trade models.Trade
+		want  string
+	}{
+		{trade("Long call", 900), "+1 NVDA  250221C00900000"},
+		{trade("Bull put credit spread", 880, 860), "+1 NVDA  250221P00860000, -1 NVDA  250221P00880000"},
+		{trade("Straddle", 900, 900), "+1 NVDA  250221P00900000, +1 NVDA  250221C00900000"},
+		{trade("Iron condor", 950, 850, 870, 930), "+1 NVDA  250221P00850000, -1 NVDA  250221P00870000, -1 NVDA  250221C00930000, +1 NVDA  250221C00950000"},
+		{trade("Iron butterfly", 850, 900, 950), "+1 NVDA  250221P00850000, -1 NVDA  250221P00900000, -1 NVDA  250221C00900000, +1 NVDA  250221C00950000"},
+		{trade("Long call butterfly", 880, 900, 920), "+1 NVDA  250221C00880000, -2 NVDA  250221C00900000, +1 NVDA  250221C00920000"},
+	} {
+		legs, err := Legs(tc.trade)
+		if err != nil {
+			t.Errorf("%s: %v", tc.trade.OptionsStrategy, err)
+			continue
+		}
+		if got := Names(legs); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.trade.OptionsStrategy, got, tc.want)
+		}
+	}
+
+	if _, err := Legs(trade("Calendar call spread", 900)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Calendars need a second expiry, got %v", err)
+	}
+	if _, err := Legs(trade("Bull call spread", 900)); err == nil || !strings.Contains(err.Error(), "2 distinct strikes") {
+		t.Errorf("Expected a missing strike to fail, got %v", err)
+	}
+	noExpiry := trade("Long put", 800)
+	noExpiry.ExpirationDate = time.Time{}
+	if Describe(noExpiry) != "" {
+		t.Error("Describe should be empty without an expiration")
+	}
+}
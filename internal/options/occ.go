@@ -0,0 +1,107 @@
+// Package options names option contracts the way brokers and clearing do:
+// OCC symbols such as "UNH   250117C00500000" (root padded to six
+// characters, expiry YYMMDD, C or P, strike times 1000 in eight digits),
+// and the contracts a journal trade's strategy and strikes stand for.
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Rights
+const (
+	Call = "C"
+	Put  = "P"
+)
+
+// ErrSymbol is returned for text that is not an OCC symbol
+var ErrSymbol = errors.New("invalid OCC symbol")
+
+// expiryLayout is the OCC expiry format
+const expiryLayout = "060102"
+
+// maxStrike is the largest strike eight digits of thousandths can hold
+const maxStrike = 99999.999
+
+// Contract is one option series
+type Contract struct {
+	Root   string    `json:"root"`   // Underlying symbol, e.g. "UNH" or "BRKB"
+	Expiry time.Time `json:"expiry"` // Date only
+	Right  string    `json:"right"`  // Call or Put
+	Strike float64   `json:"strike"`
+}
+
+// Parse reads an OCC symbol, padded ("UNH   250117C00500000") or compact
+// ("UNH250117C00500000"). The expiry is midnight local time.
+func Parse(symbol string) (Contract, error) {
+	compact := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, symbol)
+	if len(compact) < 16 || len(compact) > 21 {
+		return Contract{}, fmt.Errorf("%w %q: want root, YYMMDD, C or P and an 8-digit strike", ErrSymbol, symbol)
+	}
+
+	split := len(compact) - 15
+	root, expiry, right, strike := compact[:split], compact[split:split+6], compact[split+6:split+7], compact[split+7:]
+	c := Contract{Root: strings.ToUpper(root), Right: strings.ToUpper(right)}
+	var err error
+	if c.Expiry, err = time.ParseInLocation(expiryLayout, expiry, time.Local); err != nil {
+		return Contract{}, fmt.Errorf("%w %q: expiry %q is not YYMMDD", ErrSymbol, symbol, expiry)
+	}
+	thousandths, err := strconv.ParseUint(strike, 10, 32)
+	if err != nil || len(strike) != 8 {
+		return Contract{}, fmt.Errorf("%w %q: strike %q is not 8 digits", ErrSymbol, symbol, strike)
+	}
+	c.Strike = float64(thousandths) / 1000
+	if err := c.Validate(); err != nil {
+		return Contract{}, fmt.Errorf("%w %q: %v", ErrSymbol, symbol, err)
+	}
+	return c, nil
+}
+
+// Validate checks that the contract can be written as an OCC symbol
+func (c Contract) Validate() error {
+	if c.Root == "" || len(c.Root) > 6 {
+		return fmt.Errorf("root %q must be 1 to 6 characters", c.Root)
+	}
+	for _, r := range c.Root {
+		if !unicode.IsUpper(r) && !unicode.IsDigit(r) {
+			return fmt.Errorf("root %q must be upper-case letters and digits", c.Root)
+		}
+	}
+	if c.Expiry.IsZero() {
+		return fmt.Errorf("no expiry")
+	}
+	if c.Right != Call && c.Right != Put {
+		return fmt.Errorf("right %q must be C or P", c.Right)
+	}
+	if c.Strike <= 0 || c.Strike > maxStrike {
+		return fmt.Errorf("strike %v out of range", c.Strike)
+	}
+	return nil
+}
+
+// Symbol returns the padded 21-character OCC symbol
+func (c Contract) Symbol() string {
+	return fmt.Sprintf("%-6s%s", c.Root, c.Compact()[len(c.Root):])
+}
+
+// Compact returns the OCC symbol without the root's padding
+func (c Contract) Compact() string {
+	return fmt.Sprintf("%s%s%s%08d", c.Root, c.Expiry.Format(expiryLayout), c.Right,
+		int64(math.Round(c.Strike*1000)))
+}
+
+// String returns the OCC symbol
+func (c Contract) String() string {
+	return c.Symbol()
+}
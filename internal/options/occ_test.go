@@ -0,0 +1,55 @@
+package options
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for symbol, want := range map[string]Contract{
+		"UNH   250117C00500000":  {Root: "UNH", Expiry: time.Date(2025, 1, 17, 0, 0, 0, 0, time.Local), Right: Call, Strike: 500},
+		"SPY250321P00572500":     {Root: "SPY", Expiry: time.Date(2025, 3, 21, 0, 0, 0, 0, time.Local), Right: Put, Strike: 572.5},
+		"brkb  260116c00000500":  {Root: "BRKB", Expiry: time.Date(2026, 1, 16, 0, 0, 0, 0, time.Local), Right: Call, Strike: 0.5},
+		"GOOGL1 271217P01234567": {Root: "GOOGL1", Expiry: time.Date(2027, 12, 17, 0, 0, 0, 0, time.Local), Right: Put, Strike: 1234.567},
+	} {
+		got, err := Parse(symbol)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", symbol, err)
+			continue
+		}
+		if got.Root != want.Root || !got.Expiry.Equal(want.Expiry) || got.Right != want.Right || got.Strike != want.Strike {
+			t.Errorf("Parse(%q) = %+v, want %+v", symbol, got, want)
+		}
+	}
+
+	for _, bad := range []string{"", "UNH", "UNH   251317C00500000", "UNH   250117X00500000", "UNH   250117C0050000A", "TOOLONG250117C00500000", "250117C00500000"} {
+		if _, err := Parse(bad); !errors.Is(err, ErrSymbol) {
+			t.Errorf("Parse(%q) should fail with ErrSymbol, got %v", bad, err)
+		}
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	c := Contract{Root: "UNH", Expiry: time.Date(2025, 1, 17, 15, 30, 0, 0, time.Local), Right: Call, Strike: 500}
+	if got := c.Symbol(); got != "UNH   250117C00500000" || len(got) != 21 {
+		t.Errorf("Symbol() = %q", got)
+	}
+	if got := c.Compact(); got != "UNH250117C00500000" {
+		t.Errorf("Compact() = %q", got)
+	}
+
+	// Strikes round to thousandths and the symbol parses back
+	c = Contract{Root: "SPY", Expiry: time.Date(2025, 3, 21, 0, 0, 0, 0, time.Local), Right: Put, Strike: 572.4999999}
+	back, err := Parse(c.Symbol())
+	if c.Symbol() != "SPY   250321P00572500" || err != nil || back.Strike != 572.5 {
+		t.Errorf("Round trip: %q -> %+v (%v)", c.Symbol(), back, err)
+	}
+
+	if err := (Contract{Root: "SPY", Right: Call, Strike: 500}).Validate(); err == nil {
+		t.Error("Expected a contract without expiry to be invalid")
+	}
+	if err := (Contract{Root: "SPY", Expiry: c.Expiry, Right: Call, Strike: 100000}).Validate(); err == nil {
+		t.Error("Expected a strike over 99999.999 to be invalid")
+	}
+}
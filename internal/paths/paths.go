@@ -0,0 +1,255 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// AppDirName is the directory name used under XDG and OS config locations
+const AppDirName = "tf-engine"
+
+// Environment variables consulted by Resolve
+const (
+	EnvDataDir       = "TF_ENGINE_DATA_DIR"
+	EnvPortable      = "TF_ENGINE_PORTABLE"
+	PortableMarker   = "portable.txt" // Next to the executable, enables portable mode
+	legacyPolicyFile = "policy.v1.json"
+)
+
+// Mode describes how the directories were chosen
+type Mode string
+
+// Resolution modes, in order of precedence
+const (
+	ModeFlag     Mode = "flag"
+	ModeEnv      Mode = "env"
+	ModePortable Mode = "portable"
+	ModeLegacy   Mode = "working-dir"
+	ModeXDG      Mode = "xdg"
+	ModeUser     Mode = "user-config"
+)
+
+// Dirs holds the resolved data, config and log directories
+type Dirs struct {
+	Data   string // Trades, settings, ledger, backups, profiles
+	Config string // feature.flags.json, policy.v1.json
+	Log    string
+	Mode   Mode
+}
+
+// Options are the inputs to Resolve. Zero-value functions use the OS.
+type Options struct {
+	DataDir  string // --data-dir flag
+	Portable bool   // --portable flag
+
+	Getenv     func(string) string
+	Executable func() (string, error)
+	WorkingDir func() (string, error)
+	HomeDir    func() (string, error)
+	GOOS       string
+}
+
+var (
+	mu      sync.RWMutex
+	current = Dirs{Data: "data", Config: ".", Log: "logs", Mode: ModeLegacy}
+)
+
+// Under lays out all directories beneath one base directory
+func Under(base string, mode Mode) Dirs {
+	return Dirs{
+		Data:   base,
+		Config: base,
+		Log:    filepath.Join(base, "logs"),
+		Mode:   mode,
+	}
+}
+
+// besideBase uses the original layout: base/data, base/logs and config in base
+func besideBase(base string, mode Mode) Dirs {
+	return Dirs{
+		Data:   filepath.Join(base, "data"),
+		Config: base,
+		Log:    filepath.Join(base, "logs"),
+		Mode:   mode,
+	}
+}
+
+// Resolve picks the directories from, in order: the --data-dir flag, the
+// TF_ENGINE_DATA_DIR variable, portable mode (flag, TF_ENGINE_PORTABLE or a
+// portable.txt next to the executable), an existing data/ directory in the
+// working directory, then XDG base directories on Linux or the OS user
+// config directory elsewhere.
+func Resolve(opts Options) (Dirs, error) {
+	opts = opts.withDefaults()
+
+	if opts.DataDir != "" {
+		return absDirs(Under(opts.DataDir, ModeFlag))
+	}
+
+	if dir := opts.Getenv(EnvDataDir); dir != "" {
+		return absDirs(Under(dir, ModeEnv))
+	}
+
+	if opts.Portable || opts.Getenv(EnvPortable) != "" || portableMarkerExists(opts) {
+		exe, err := opts.Executable()
+		if err != nil {
+			return Dirs{}, fmt.Errorf("portable mode: cannot locate executable: %w", err)
+		}
+		return absDirs(besideBase(filepath.Dir(exe), ModePortable))
+	}
+
+	// Existing installs and development checkouts keep ./data
+	if wd, err := opts.WorkingDir(); err == nil && isLegacyLayout(wd) {
+		return absDirs(besideBase(wd, ModeLegacy))
+	}
+
+	if opts.GOOS == "linux" {
+		return xdgDirs(opts)
+	}
+
+	return userDirs(opts)
+}
+
+// xdgDirs follows the XDG base directory specification
+func xdgDirs(opts Options) (Dirs, error) {
+	home, homeErr := opts.HomeDir()
+
+	base := func(env, fallback string) (string, error) {
+		if dir := opts.Getenv(env); filepath.IsAbs(dir) {
+			return dir, nil
+		}
+		if homeErr != nil {
+			return "", fmt.Errorf("%s not set and home directory unknown: %w", env, homeErr)
+		}
+		return filepath.Join(home, fallback), nil
+	}
+
+	data, err := base("XDG_DATA_HOME", filepath.Join(".local", "share"))
+	if err != nil {
+		return Dirs{}, err
+	}
+	config, err := base("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return Dirs{}, err
+	}
+	state, err := base("XDG_STATE_HOME", filepath.Join(".local", "state"))
+	if err != nil {
+		return Dirs{}, err
+	}
+
+	return Dirs{
+		Data:   filepath.Join(data, AppDirName),
+		Config: filepath.Join(config, AppDirName),
+		Log:    filepath.Join(state, AppDirName, "logs"),
+		Mode:   ModeXDG,
+	}, nil
+}
+
+// userDirs uses the OS user config directory (AppData, Library/Application Support)
+func userDirs(opts Options) (Dirs, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, homeErr := opts.HomeDir()
+		if homeErr != nil {
+			return Dirs{}, errors.Join(err, homeErr)
+		}
+		dir = home
+	}
+	return Under(filepath.Join(dir, AppDirName), ModeUser), nil
+}
+
+// isLegacyLayout reports whether dir holds a pre-paths data/ directory
+func isLegacyLayout(dir string) bool {
+	for _, name := range []string{"trades.json", legacyPolicyFile} {
+		if _, err := os.Stat(filepath.Join(dir, "data", name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func portableMarkerExists(opts Options) bool {
+	exe, err := opts.Executable()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(filepath.Dir(exe), PortableMarker))
+	return err == nil
+}
+
+func absDirs(d Dirs) (Dirs, error) {
+	var err error
+	if d.Data, err = filepath.Abs(d.Data); err != nil {
+		return Dirs{}, err
+	}
+	if d.Config, err = filepath.Abs(d.Config); err != nil {
+		return Dirs{}, err
+	}
+	if d.Log, err = filepath.Abs(d.Log); err != nil {
+		return Dirs{}, err
+	}
+	return d, nil
+}
+
+func (o Options) withDefaults() Options {
+	if o.Getenv == nil {
+		o.Getenv = os.Getenv
+	}
+	if o.Executable == nil {
+		o.Executable = os.Executable
+	}
+	if o.WorkingDir == nil {
+		o.WorkingDir = os.Getwd
+	}
+	if o.HomeDir == nil {
+		o.HomeDir = os.UserHomeDir
+	}
+	if o.GOOS == "" {
+		o.GOOS = runtime.GOOS
+	}
+	return o
+}
+
+// Set makes d the directories used by Data, Config and Log
+func Set(d Dirs) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = d
+}
+
+// Current returns the directories in use
+func Current() Dirs {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Data joins elem onto the data directory
+func Data(elem ...string) string {
+	return filepath.Join(append([]string{Current().Data}, elem...)...)
+}
+
+// Config joins elem onto the config directory
+func Config(elem ...string) string {
+	return filepath.Join(append([]string{Current().Config}, elem...)...)
+}
+
+// Log joins elem onto the log directory
+func Log(elem ...string) string {
+	return filepath.Join(append([]string{Current().Log}, elem...)...)
+}
+
+// EnsureDirs creates the data, config and log directories
+func EnsureDirs() error {
+	d := Current()
+	for _, dir := range []string{d.Data, d.Config, d.Log} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testOptions returns options isolated from the real environment
+func testOptions(t *testing.T, env map[string]string) (Options, string) {
+	root := t.TempDir()
+	exeDir := filepath.Join(root, "bin")
+	workDir := filepath.Join(root, "work")
+	home := filepath.Join(root, "home")
+	for _, dir := range []string{exeDir, workDir, home} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return Options{
+		Getenv:     func(key string) string { return env[key] },
+		Executable: func() (string, error) { return filepath.Join(exeDir, "tf-engine"), nil },
+		WorkingDir: func() (string, error) { return workDir, nil },
+		HomeDir:    func() (string, error) { return home, nil },
+		GOOS:       "linux",
+	}, root
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	opts, root := testOptions(t, map[string]string{EnvDataDir: "/env/data"})
+	opts.DataDir = filepath.Join(root, "flag")
+
+	dirs, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if dirs.Mode != ModeFlag || dirs.Data != filepath.Join(root, "flag") {
+		t.Errorf("Flag should win, got %+v", dirs)
+	}
+
+	opts.DataDir = ""
+	dirs, _ = Resolve(opts)
+	if dirs.Mode != ModeEnv || dirs.Data != "/env/data" {
+		t.Errorf("Env var should win over XDG, got %+v", dirs)
+	}
+	if dirs.Log != "/env/data/logs" {
+		t.Errorf("Expected logs under the data dir, got %s", dirs.Log)
+	}
+}
+
+func TestResolve_Portable(t *testing.T) {
+	opts, root := testOptions(t, nil)
+
+	// Marker file next to the executable enables portable mode
+	if err := os.WriteFile(filepath.Join(root, "bin", PortableMarker), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if dirs.Mode != ModePortable {
+		t.Fatalf("Expected portable mode, got %s", dirs.Mode)
+	}
+	if dirs.Data != filepath.Join(root, "bin", "data") || dirs.Config != filepath.Join(root, "bin") {
+		t.Errorf("Portable dirs should sit next to the executable, got %+v", dirs)
+	}
+}
+
+func TestResolve_PortableWithoutExecutable(t *testing.T) {
+	opts, _ := testOptions(t, nil)
+	opts.Portable = true
+	opts.Executable = func() (string, error) { return "", errors.New("unknown") }
+
+	if _, err := Resolve(opts); err == nil {
+		t.Error("Expected error when the executable cannot be located")
+	}
+}
+
+func TestResolve_LegacyWorkingDir(t *testing.T) {
+	opts, root := testOptions(t, nil)
+	legacyData := filepath.Join(root, "work", "data")
+	os.MkdirAll(legacyData, 0755)
+	os.WriteFile(filepath.Join(legacyData, "trades.json"), []byte("[]"), 0644)
+
+	dirs, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if dirs.Mode != ModeLegacy || dirs.Data != legacyData {
+		t.Errorf("Existing ./data should be kept, got %+v", dirs)
+	}
+}
+
+func TestResolve_XDG(t *testing.T) {
+	opts, root := testOptions(t, map[string]string{
+		"XDG_CONFIG_HOME": "/xdg/config",
+		"XDG_DATA_HOME":   "relative/ignored", // Spec: relative paths are invalid
+	})
+
+	dirs, err := Resolve(opts)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if dirs.Mode != ModeXDG {
+		t.Fatalf("Expected XDG mode, got %s", dirs.Mode)
+	}
+	if dirs.Config != filepath.Join("/xdg/config", AppDirName) {
+		t.Errorf("Unexpected config dir %s", dirs.Config)
+	}
+	if dirs.Data != filepath.Join(root, "home", ".local", "share", AppDirName) {
+		t.Errorf("Unexpected data dir %s", dirs.Data)
+	}
+	if dirs.Log != filepath.Join(root, "home", ".local", "state", AppDirName, "logs") {
+		t.Errorf("Unexpected log dir %s", dirs.Log)
+	}
+}
+
+func TestSetAndJoin(t *testing.T) {
+	old := Current()
+	t.Cleanup(func() { Set(old) })
+
+	base := t.TempDir()
+	Set(Under(base, ModeFlag))
+
+	if got := Data("ui", "settings.json"); got != filepath.Join(base, "ui", "settings.json") {
+		t.Errorf("Unexpected data path %s", got)
+	}
+	if err := EnsureDirs(); err != nil {
+		t.Fatalf("EnsureDirs failed: %v", err)
+	}
+	if _, err := os.Stat(Log()); err != nil {
+		t.Errorf("Log dir should exist: %v", err)
+	}
+}
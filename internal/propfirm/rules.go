@@ -0,0 +1,262 @@
+package propfirm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+// Rule identifiers
+const (
+	RuleDailyLoss        = "daily_loss_limit"
+	RuleTrailingDrawdown = "trailing_max_drawdown"
+	RuleMaxContracts     = "max_open_contracts"
+	RuleMinTradingDays   = "min_trading_days"
+	RuleProfitTarget     = "profit_target"
+)
+
+// ErrLimitBreached is returned when a prop firm limit is breached or would be
+// breached by a new trade
+var ErrLimitBreached = errors.New("prop firm limit breached")
+
+// RuleStatus holds the evaluation result of a single rule
+type RuleStatus struct {
+	Rule     string
+	Label    string
+	Current  float64
+	Limit    float64
+	Breached bool // Hard limit violated (loss limits, contracts)
+	Met      bool // Goal reached (trading days, profit target)
+	Detail   string
+}
+
+// Status holds the evaluation of all prop firm rules against the trade history
+type Status struct {
+	Enabled       bool
+	Rules         models.PropFirmRules
+	RealizedPnL   float64
+	OpenPnL       float64
+	DailyPnL      float64 // Realized P&L closed today plus today's change in open P&L
+	Equity        float64 // Starting balance + realized + open P&L
+	HighWaterMark float64
+	DrawdownFloor float64 // Equity level that breaches the trailing drawdown
+	OpenContracts int
+	TradingDays   int
+	RuleStatuses  []RuleStatus
+}
+
+// Evaluate evaluates the prop firm rules against realized and open P&L
+func Evaluate(rules models.PropFirmRules, trades []models.Trade, now time.Time) Status {
+	status := Status{
+		Enabled: rules.Enabled,
+		Rules:   rules,
+	}
+
+	// Sort closed trades by close date to build the balance curve
+	closed := []models.Trade{}
+	tradingDays := make(map[string]bool)
+	for _, trade := range trades {
+		tradingDays[entryDate(trade).Format("2006-01-02")] = true
+
+		if trade.GetStatus() == "active" {
+			status.OpenPnL += trade.GetOpenPnL()
+			status.DailyPnL += trade.OpenPnLChangeOn(now)
+			status.OpenContracts += trade.PositionSize
+			continue
+		}
+		if trade.ProfitLoss != nil {
+			closed = append(closed, trade)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool {
+		return closeDate(closed[i]).Before(closeDate(closed[j]))
+	})
+
+	balance := rules.StartingBalance
+	status.HighWaterMark = balance
+	year, month, day := now.Date()
+	for _, trade := range closed {
+		pnl := trade.GetPnL()
+		balance += pnl
+		status.RealizedPnL += pnl
+		if balance > status.HighWaterMark {
+			status.HighWaterMark = balance
+		}
+
+		y, m, d := closeDate(trade).Date()
+		if y == year && m == month && d == day {
+			status.DailyPnL += pnl
+		}
+	}
+
+	status.Equity = balance + status.OpenPnL
+	if status.Equity > status.HighWaterMark {
+		status.HighWaterMark = status.Equity
+	}
+	status.DrawdownFloor = status.HighWaterMark - rules.TrailingMaxDrawdown
+	status.TradingDays = len(tradingDays)
+
+	status.RuleStatuses = status.evaluateRules()
+	return status
+}
+
+// evaluateRules builds the per-rule status list
+func (s Status) evaluateRules() []RuleStatus {
+	rules := []RuleStatus{}
+
+	if s.Rules.DailyLossLimit > 0 {
+		loss := -s.DailyPnL
+		rules = append(rules, RuleStatus{
+			Rule:     RuleDailyLoss,
+			Label:    "Daily Loss Limit",
+			Current:  loss,
+			Limit:    s.Rules.DailyLossLimit,
+			Breached: loss >= s.Rules.DailyLossLimit,
+			Detail:   fmt.Sprintf("Today's P&L $%.2f / -$%.2f limit", s.DailyPnL, s.Rules.DailyLossLimit),
+		})
+	}
+
+	if s.Rules.TrailingMaxDrawdown > 0 {
+		drawdown := s.HighWaterMark - s.Equity
+		rules = append(rules, RuleStatus{
+			Rule:     RuleTrailingDrawdown,
+			Label:    "Trailing Max Drawdown",
+			Current:  drawdown,
+			Limit:    s.Rules.TrailingMaxDrawdown,
+			Breached: drawdown >= s.Rules.TrailingMaxDrawdown,
+			Detail: fmt.Sprintf("Equity $%.2f, high-water $%.2f, floor $%.2f",
+				s.Equity, s.HighWaterMark, s.DrawdownFloor),
+		})
+	}
+
+	if s.Rules.MaxOpenContracts > 0 {
+		rules = append(rules, RuleStatus{
+			Rule:     RuleMaxContracts,
+			Label:    "Max Open Contracts",
+			Current:  float64(s.OpenContracts),
+			Limit:    float64(s.Rules.MaxOpenContracts),
+			Breached: s.OpenContracts > s.Rules.MaxOpenContracts,
+			Detail:   fmt.Sprintf("%d / %d contracts open", s.OpenContracts, s.Rules.MaxOpenContracts),
+		})
+	}
+
+	if s.Rules.MinTradingDays > 0 {
+		rules = append(rules, RuleStatus{
+			Rule:    RuleMinTradingDays,
+			Label:   "Minimum Trading Days",
+			Current: float64(s.TradingDays),
+			Limit:   float64(s.Rules.MinTradingDays),
+			Met:     s.TradingDays >= s.Rules.MinTradingDays,
+			Detail:  fmt.Sprintf("%d / %d days traded", s.TradingDays, s.Rules.MinTradingDays),
+		})
+	}
+
+	if s.Rules.ProfitTarget > 0 {
+		profit := s.Equity - s.Rules.StartingBalance
+		rules = append(rules, RuleStatus{
+			Rule:    RuleProfitTarget,
+			Label:   "Profit Target",
+			Current: profit,
+			Limit:   s.Rules.ProfitTarget,
+			Met:     profit >= s.Rules.ProfitTarget,
+			Detail:  fmt.Sprintf("$%.2f / $%.2f target", profit, s.Rules.ProfitTarget),
+		})
+	}
+
+	return rules
+}
+
+// Breached returns true if any hard limit is currently breached
+func (s Status) Breached() bool {
+	if !s.Enabled {
+		return false
+	}
+	for _, rule := range s.RuleStatuses {
+		if rule.Breached {
+			return true
+		}
+	}
+	return false
+}
+
+// Passed returns true if the profit target and minimum trading days are met
+// without any limit breached
+func (s Status) Passed() bool {
+	if !s.Enabled || s.Breached() {
+		return false
+	}
+	for _, rule := range s.RuleStatuses {
+		if (rule.Rule == RuleProfitTarget || rule.Rule == RuleMinTradingDays) && !rule.Met {
+			return false
+		}
+	}
+	return true
+}
+
+// DailyLossRoom returns how much more can be lost today before the limit
+func (s Status) DailyLossRoom() float64 {
+	return s.Rules.DailyLossLimit + s.DailyPnL
+}
+
+// DrawdownRoom returns how much more can be lost before the trailing floor
+func (s Status) DrawdownRoom() float64 {
+	return s.Equity - s.DrawdownFloor
+}
+
+// CheckNewTrade returns an error wrapping ErrLimitBreached if a limit is
+// already breached, or would be if the new trade lost its full max loss
+func (s Status) CheckNewTrade(maxLoss float64, contracts int) error {
+	if !s.Enabled {
+		return nil
+	}
+
+	problems := []string{}
+	for _, rule := range s.RuleStatuses {
+		if rule.Breached {
+			problems = append(problems, fmt.Sprintf("%s breached (%s)", rule.Label, rule.Detail))
+		}
+	}
+
+	if len(problems) == 0 {
+		if s.Rules.DailyLossLimit > 0 && maxLoss > s.DailyLossRoom() {
+			problems = append(problems, fmt.Sprintf(
+				"Max loss $%.2f exceeds remaining daily loss room $%.2f",
+				maxLoss, s.DailyLossRoom()))
+		}
+		if s.Rules.TrailingMaxDrawdown > 0 && maxLoss > s.DrawdownRoom() {
+			problems = append(problems, fmt.Sprintf(
+				"Max loss $%.2f exceeds remaining drawdown room $%.2f",
+				maxLoss, s.DrawdownRoom()))
+		}
+		if s.Rules.MaxOpenContracts > 0 && s.OpenContracts+contracts > s.Rules.MaxOpenContracts {
+			problems = append(problems, fmt.Sprintf(
+				"%d new contracts would exceed max open contracts (%d open, %d max)",
+				contracts, s.OpenContracts, s.Rules.MaxOpenContracts))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w:\n%s", ErrLimitBreached, strings.Join(problems, "\n"))
+}
+
+// entryDate returns the date a trade was opened
+func entryDate(trade models.Trade) time.Time {
+	if !trade.EntryDate.IsZero() {
+		return trade.EntryDate
+	}
+	return trade.CreatedAt
+}
+
+// closeDate returns the date a trade's P&L was realized
+func closeDate(trade models.Trade) time.Time {
+	if trade.ExitDate != nil {
+		return *trade.ExitDate
+	}
+	return trade.UpdatedAt
+}
@@ -0,0 +1,234 @@
+package propfirm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func testRules() models.PropFirmRules {
+	return models.PropFirmRules{
+		Enabled:             true,
+		StartingBalance:     25000,
+		DailyLossLimit:      1000,
+		TrailingMaxDrawdown: 1500,
+		MaxOpenContracts:    10,
+		MinTradingDays:      3,
+		ProfitTarget:        1500,
+	}
+}
+
+func closedTrade(pnl float64, closed time.Time) models.Trade {
+	return models.Trade{
+		CreatedAt:  closed.AddDate(0, 0, -7),
+		UpdatedAt:  closed,
+		ExitDate:   &closed,
+		ProfitLoss: &pnl,
+		Status:     "closed",
+	}
+}
+
+func activeTrade(openPnL float64, contracts int, created time.Time) models.Trade {
+	return models.Trade{
+		CreatedAt:    created,
+		OpenPnL:      &openPnL,
+		PositionSize: contracts,
+		Status:       "active",
+	}
+}
+
+func findRule(t *testing.T, status Status, rule string) RuleStatus {
+	t.Helper()
+	for _, r := range status.RuleStatuses {
+		if r.Rule == rule {
+			return r
+		}
+	}
+	t.Fatalf("rule %s not evaluated", rule)
+	return RuleStatus{}
+}
+
+func TestEvaluate_NoTrades(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	status := Evaluate(testRules(), []models.Trade{}, now)
+
+	if status.Equity != 25000 {
+		t.Errorf("Expected equity $25000, got $%.2f", status.Equity)
+	}
+	if status.DrawdownFloor != 23500 {
+		t.Errorf("Expected floor $23500, got $%.2f", status.DrawdownFloor)
+	}
+	if status.Breached() {
+		t.Error("Empty history should not breach any limit")
+	}
+	if status.Passed() {
+		t.Error("Empty history should not pass the evaluation")
+	}
+}
+
+func TestEvaluate_DailyLossCountsTodayAndOpenPnL(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		closedTrade(-2000, now.AddDate(0, 0, -3)), // Previous day - not in daily P&L
+		closedTrade(600, now.AddDate(0, 0, -1)),
+		closedTrade(-700, now.Add(-2*time.Hour)),
+		activeTrade(-350, 2, now.Add(-3*time.Hour)), // Opened today
+	}
+
+	status := Evaluate(testRules(), trades, now)
+
+	if status.DailyPnL != -1050 {
+		t.Errorf("Expected daily P&L -$1050, got $%.2f", status.DailyPnL)
+	}
+	daily := findRule(t, status, RuleDailyLoss)
+	if !daily.Breached {
+		t.Error("Daily loss limit should be breached at -$1050")
+	}
+	if !status.Breached() {
+		t.Error("Status should report breach")
+	}
+}
+
+func TestEvaluate_DailyLossIgnoresOpenPnLFromEarlierDays(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	lastWeek := now.AddDate(0, 0, -7)
+
+	// Fell $900 last week and flat today
+	carried := activeTrade(0, 1, lastWeek)
+	carried.MarkOpenPnL(-900, lastWeek.Add(time.Hour))
+	carried.MarkOpenPnL(-900, now.Add(-time.Hour))
+	// Marked before mark times were kept
+	legacy := activeTrade(-400, 1, lastWeek)
+
+	status := Evaluate(testRules(), []models.Trade{carried, legacy}, now)
+	if status.DailyPnL != 0 || findRule(t, status, RuleDailyLoss).Breached {
+		t.Errorf("Expected no daily loss from earlier days, got $%.2f", status.DailyPnL)
+	}
+	if status.Equity != 23700 {
+		t.Errorf("Expected equity to include all open P&L, got $%.2f", status.Equity)
+	}
+	if err := status.CheckNewTrade(500, 1); err == nil || strings.Contains(err.Error(), "daily") {
+		t.Errorf("Expected only the drawdown room to block the trade, got %v", err)
+	}
+
+	// Today's further $150 drop counts
+	carried.MarkOpenPnL(-1050, now)
+	status = Evaluate(testRules(), []models.Trade{carried}, now)
+	if status.DailyPnL != -150 {
+		t.Errorf("Expected daily P&L -$150, got $%.2f", status.DailyPnL)
+	}
+}
+
+func TestEvaluate_TrailingDrawdownFromHighWaterMark(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		closedTrade(1200, now.AddDate(0, 0, -5)), // HWM 26200
+		closedTrade(-800, now.AddDate(0, 0, -4)), // 25400
+		closedTrade(-500, now.AddDate(0, 0, -2)), // 24900
+	}
+
+	status := Evaluate(testRules(), trades, now)
+
+	if status.HighWaterMark != 26200 {
+		t.Errorf("Expected high-water mark $26200, got $%.2f", status.HighWaterMark)
+	}
+	if status.DrawdownFloor != 24700 {
+		t.Errorf("Expected floor $24700, got $%.2f", status.DrawdownFloor)
+	}
+	if findRule(t, status, RuleTrailingDrawdown).Breached {
+		t.Error("Drawdown of $1300 should not breach $1500 limit")
+	}
+
+	// Open loss pushes equity through the floor
+	trades = append(trades, activeTrade(-250, 1, now))
+	status = Evaluate(testRules(), trades, now)
+	if !findRule(t, status, RuleTrailingDrawdown).Breached {
+		t.Errorf("Drawdown of $%.2f should breach $1500 limit", status.HighWaterMark-status.Equity)
+	}
+}
+
+func TestEvaluate_MaxOpenContracts(t *testing.T) {
+	now := time.Now()
+	trades := []models.Trade{
+		activeTrade(0, 6, now),
+		activeTrade(0, 5, now),
+	}
+
+	status := Evaluate(testRules(), trades, now)
+
+	if status.OpenContracts != 11 {
+		t.Errorf("Expected 11 open contracts, got %d", status.OpenContracts)
+	}
+	if !findRule(t, status, RuleMaxContracts).Breached {
+		t.Error("11 contracts should breach max of 10")
+	}
+}
+
+func TestEvaluate_PassedWhenTargetAndDaysMet(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		closedTrade(800, now.AddDate(0, 0, -3)),
+		closedTrade(500, now.AddDate(0, 0, -2)),
+		closedTrade(300, now.AddDate(0, 0, -1)),
+	}
+	// Spread entries over three separate days
+	trades[1].CreatedAt = trades[0].CreatedAt.AddDate(0, 0, 1)
+	trades[2].CreatedAt = trades[0].CreatedAt.AddDate(0, 0, 2)
+
+	status := Evaluate(testRules(), trades, now)
+
+	if status.TradingDays != 3 {
+		t.Errorf("Expected 3 trading days, got %d", status.TradingDays)
+	}
+	if !findRule(t, status, RuleProfitTarget).Met {
+		t.Error("Profit target of $1500 should be met with $1600 profit")
+	}
+	if !status.Passed() {
+		t.Error("Evaluation should be passed")
+	}
+}
+
+func TestCheckNewTrade_WouldBreach(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		closedTrade(-600, now.Add(-time.Hour)),
+	}
+	status := Evaluate(testRules(), trades, now)
+
+	if err := status.CheckNewTrade(300, 1); err != nil {
+		t.Errorf("$300 max loss fits in $400 daily room, got error: %v", err)
+	}
+
+	err := status.CheckNewTrade(500, 1)
+	if err == nil {
+		t.Fatal("$500 max loss should exceed $400 daily room")
+	}
+	if !errors.Is(err, ErrLimitBreached) {
+		t.Errorf("Expected ErrLimitBreached, got %v", err)
+	}
+
+	if err := status.CheckNewTrade(100, 11); err == nil {
+		t.Error("11 contracts should exceed max open contracts")
+	}
+}
+
+func TestCheckNewTrade_DisabledRulesNeverBlock(t *testing.T) {
+	rules := testRules()
+	rules.Enabled = false
+	now := time.Now()
+	trades := []models.Trade{
+		closedTrade(-5000, now),
+	}
+
+	status := Evaluate(rules, trades, now)
+
+	if status.Breached() {
+		t.Error("Disabled rules should never report a breach")
+	}
+	if err := status.CheckNewTrade(10000, 50); err != nil {
+		t.Errorf("Disabled rules should never block, got: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package sizing
+
+import (
+	"fmt"
+	"math"
+
+	"tf-engine/internal/models"
+)
+
+// RiskAdjustment describes the drawdown schedule step in force and why
+type RiskAdjustment struct {
+	Active     bool    // True when a schedule is enabled
+	Step       int     // 0 = full risk
+	Multiplier float64 // Applied to base risk per trade
+	Equity     float64 // Current equity
+	Peak       float64 // Equity high-water mark
+	Drawdown   float64 // Current drawdown from peak (0.07 = 7%)
+	Reason     string
+}
+
+// ApplyRiskSchedule evaluates the drawdown risk schedule against an equity
+// history (oldest first, last value = current equity). A nil or disabled
+// schedule always returns full risk.
+func ApplyRiskSchedule(schedule *models.RiskSchedule, equityHistory []float64) RiskAdjustment {
+	adj := RiskAdjustment{Multiplier: 1.0}
+	if len(equityHistory) > 0 {
+		adj.Equity = equityHistory[len(equityHistory)-1]
+	}
+
+	if schedule == nil || !schedule.Enabled || schedule.DrawdownStep <= 0 {
+		adj.Reason = "Fixed risk per trade (no drawdown schedule)"
+		return adj
+	}
+	adj.Active = true
+
+	if len(equityHistory) == 0 {
+		adj.Reason = "Full risk: no equity history yet"
+		return adj
+	}
+
+	// Walk the equity history tracking the peak and the deepest step reached
+	// since that peak
+	deepestStep := 0
+	currentStep := 0
+	for _, equity := range equityHistory {
+		if equity >= adj.Peak {
+			adj.Peak = equity
+			deepestStep = 0
+		}
+
+		currentStep = stepFor(drawdown(adj.Peak, equity), schedule.DrawdownStep)
+		if currentStep > deepestStep {
+			deepestStep = currentStep
+		}
+	}
+
+	adj.Drawdown = drawdown(adj.Peak, adj.Equity)
+	adj.Step = currentStep
+	if schedule.RestoreAtNewHigh {
+		adj.Step = deepestStep
+	}
+
+	adj.Multiplier = 1.0 - float64(adj.Step)*schedule.RiskCutPerStep
+	if adj.Multiplier < schedule.MinRiskFraction {
+		adj.Multiplier = schedule.MinRiskFraction
+	}
+	if adj.Multiplier < 0 {
+		adj.Multiplier = 0
+	}
+
+	adj.Reason = adj.describe(schedule)
+	return adj
+}
+
+// describe explains the step in force
+func (a RiskAdjustment) describe(schedule *models.RiskSchedule) string {
+	if a.Step == 0 {
+		return fmt.Sprintf("Full risk: equity $%.0f is %.1f%% below its $%.0f high (first cut at %.0f%%)",
+			a.Equity, a.Drawdown*100, a.Peak, schedule.DrawdownStep*100)
+	}
+
+	reason := fmt.Sprintf("Step %d: equity $%.0f is %.1f%% below its $%.0f high → risk ×%.2f (%.0f%% cut per %.0f%% drawdown)",
+		a.Step, a.Equity, a.Drawdown*100, a.Peak, a.Multiplier,
+		schedule.RiskCutPerStep*100, schedule.DrawdownStep*100)
+
+	currentStep := stepFor(a.Drawdown, schedule.DrawdownStep)
+	if schedule.RestoreAtNewHigh && currentStep < a.Step {
+		reason += fmt.Sprintf(". Held at step %d until a new equity high above $%.0f", a.Step, a.Peak)
+	}
+	return reason
+}
+
+// EquityHistory returns the account's trading equity, oldest first: one
+// point when the ledger opens and one after each realized P&L posting.
+// Deposits and withdrawals are taken out - each posting moves the series by
+// its return on the balance it was made with, and the series is scaled to
+// end at current equity - so cash flows neither cut risk nor set a new high.
+func EquityHistory(ledger *models.Ledger) []float64 {
+	if ledger == nil {
+		return []float64{}
+	}
+
+	growth := []float64{}
+	balances := []float64{}
+	index := 1.0
+	for _, point := range ledger.History() {
+		switch {
+		case point.Entry.Type == models.LedgerRealizedPnL:
+			if before := point.Balance - point.Entry.Amount; before > 0 {
+				index *= point.Balance / before
+			}
+		case len(growth) > 0:
+			continue // Cash flows don't move trading equity
+		}
+		growth = append(growth, index)
+		balances = append(balances, point.Balance)
+	}
+
+	// A loss of the whole balance has no return to scale from
+	if index <= 0 {
+		return balances
+	}
+	equity := ledger.Equity()
+	history := make([]float64, len(growth))
+	for i, g := range growth {
+		history[i] = g / index * equity
+	}
+	return history
+}
+
+// drawdown returns the fractional drawdown of equity from peak
+func drawdown(peak, equity float64) float64 {
+	if peak <= 0 || equity >= peak {
+		return 0
+	}
+	return (peak - equity) / peak
+}
+
+// stepFor returns the number of whole drawdown steps reached
+func stepFor(dd, step float64) int {
+	// Small epsilon so exactly 5.00% counts as a full step
+	return int(math.Floor(dd/step + 1e-9))
+}
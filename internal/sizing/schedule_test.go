@@ -0,0 +1,141 @@
+package sizing
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func testSchedule() *models.RiskSchedule {
+	return &models.RiskSchedule{
+		Enabled:          true,
+		DrawdownStep:     0.05,
+		RiskCutPerStep:   0.25,
+		MinRiskFraction:  0.25,
+		RestoreAtNewHigh: true,
+	}
+}
+
+func TestApplyRiskSchedule_Steps(t *testing.T) {
+	tests := []struct {
+		name       string
+		history    []float64
+		wantStep   int
+		wantMult   float64
+		wantActive bool
+	}{
+		{"at high", []float64{25000, 26000}, 0, 1.0, true},
+		{"shallow drawdown", []float64{25000, 24000}, 0, 1.0, true},
+		{"exactly one step", []float64{20000, 19000}, 1, 0.75, true},
+		{"two steps", []float64{25000, 22400}, 2, 0.5, true},
+		{"floor reached", []float64{25000, 21000}, 3, 0.25, true},
+		{"deep drawdown clamps at floor", []float64{25000, 10000}, 12, 0.25, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adj := ApplyRiskSchedule(testSchedule(), tt.history)
+			if adj.Step != tt.wantStep {
+				t.Errorf("Expected step %d, got %d", tt.wantStep, adj.Step)
+			}
+			if math.Abs(adj.Multiplier-tt.wantMult) > 1e-9 {
+				t.Errorf("Expected multiplier %.2f, got %.2f", tt.wantMult, adj.Multiplier)
+			}
+			if adj.Active != tt.wantActive {
+				t.Errorf("Expected active=%v, got %v", tt.wantActive, adj.Active)
+			}
+		})
+	}
+}
+
+func TestApplyRiskSchedule_HoldsStepUntilNewHigh(t *testing.T) {
+	// Drop 12% (step 2), recover to 3% below the high
+	history := []float64{25000, 22000, 24250}
+
+	adj := ApplyRiskSchedule(testSchedule(), history)
+	if adj.Step != 2 {
+		t.Errorf("Expected step 2 held until new high, got %d", adj.Step)
+	}
+	if !strings.Contains(adj.Reason, "until a new equity high") {
+		t.Errorf("Reason should explain the hold, got: %s", adj.Reason)
+	}
+
+	// New high restores full risk
+	adj = ApplyRiskSchedule(testSchedule(), append(history, 25100))
+	if adj.Step != 0 || adj.Multiplier != 1.0 {
+		t.Errorf("Expected full risk at new high, got step %d ×%.2f", adj.Step, adj.Multiplier)
+	}
+
+	// Without hysteresis the step follows the current drawdown
+	schedule := testSchedule()
+	schedule.RestoreAtNewHigh = false
+	adj = ApplyRiskSchedule(schedule, history)
+	if adj.Step != 0 {
+		t.Errorf("Expected step 0 without hold, got %d", adj.Step)
+	}
+}
+
+func TestApplyRiskSchedule_DisabledOrMissing(t *testing.T) {
+	history := []float64{25000, 15000}
+
+	adj := ApplyRiskSchedule(nil, history)
+	if adj.Active || adj.Multiplier != 1.0 {
+		t.Errorf("Nil schedule should give full risk, got active=%v ×%.2f", adj.Active, adj.Multiplier)
+	}
+
+	schedule := testSchedule()
+	schedule.Enabled = false
+	adj = ApplyRiskSchedule(schedule, history)
+	if adj.Active || adj.Multiplier != 1.0 {
+		t.Errorf("Disabled schedule should give full risk, got active=%v ×%.2f", adj.Active, adj.Multiplier)
+	}
+}
+
+func TestEquityHistory_FromLedger(t *testing.T) {
+	start := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	ledger := models.NewLedger(25000, start)
+	ledger.Deposit(1000, start.AddDate(0, 0, 1), "")
+
+	// A deposit is not a gain: the series is flat at current equity
+	history := EquityHistory(ledger)
+	if len(history) != 1 || history[0] != 26000 {
+		t.Errorf("Expected [26000], got %v", history)
+	}
+
+	if len(EquityHistory(nil)) != 0 {
+		t.Error("Nil ledger should give empty history")
+	}
+}
+
+func TestEquityHistory_IgnoresCashFlows(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	post := func(l *models.Ledger, amount float64, d int) {
+		l.Entries = append(l.Entries, models.LedgerEntry{Type: models.LedgerRealizedPnL, Amount: amount, Date: day(d)})
+	}
+	schedule := &models.RiskSchedule{Enabled: true, DrawdownStep: 0.05, RiskCutPerStep: 0.25, MinRiskFraction: 0.25, RestoreAtNewHigh: true}
+
+	// Withdrawing 10% is not a drawdown
+	ledger := models.NewLedger(100000, day(1))
+	post(ledger, 2000, 2)
+	ledger.Withdraw(10200, day(3), "")
+	if adj := ApplyRiskSchedule(schedule, EquityHistory(ledger)); adj.Step != 0 || adj.Drawdown != 0 || adj.Equity != 91800 {
+		t.Errorf("After a withdrawal: step %d, drawdown %.3f, equity %.0f", adj.Step, adj.Drawdown, adj.Equity)
+	}
+
+	// A 6% trading loss cuts one step, and a deposit doesn't restore it
+	post(ledger, -5508, 4)
+	ledger.Deposit(20000, day(5), "")
+	adj := ApplyRiskSchedule(schedule, EquityHistory(ledger))
+	if adj.Step != 1 || math.Abs(adj.Drawdown-0.06) > 1e-9 {
+		t.Errorf("After a loss and a deposit: step %d, drawdown %.4f", adj.Step, adj.Drawdown)
+	}
+
+	// Trading back above the high restores full risk
+	post(ledger, 7500, 6)
+	if adj := ApplyRiskSchedule(schedule, EquityHistory(ledger)); adj.Step != 0 {
+		t.Errorf("After recovering the loss: step %d, drawdown %.4f", adj.Step, adj.Drawdown)
+	}
+}
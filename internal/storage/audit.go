@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/models"
+)
+
+// AuditFile holds the active profile's append-only audit journal
+var AuditFile = "data/audit.jsonl"
+
+// RecordTradeChange journals the difference between two versions of a trade.
+// A nil before records a new trade; a nil after records a deletion.
+func RecordTradeChange(eventType audit.EventType, before, after *models.Trade) error {
+	if ReadOnly() {
+		return ErrReadOnly
+	}
+
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	id := ""
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+
+	_, err = audit.Append(AuditFile, audit.Event{Type: eventType, TradeID: id, Changes: changes})
+	return err
+}
+
+// RecordTradeFields journals the current values of selected trade fields
+// (checklist and sizing state of a trade still in progress). Trades started
+// before IDs were assigned are given one.
+func RecordTradeFields(eventType audit.EventType, trade *models.Trade, fields ...string) error {
+	if ReadOnly() {
+		return ErrReadOnly
+	}
+	if trade.ID == "" {
+		trade.ID = models.NewTradeID()
+	}
+
+	changes, err := audit.Fields(trade, fields...)
+	if err != nil {
+		return err
+	}
+
+	_, err = audit.Append(AuditFile, audit.Event{Type: eventType, TradeID: trade.ID, Changes: changes})
+	return err
+}
+
+// LoadAuditJournal loads the active profile's audit journal
+func LoadAuditJournal() ([]audit.Event, error) {
+	return audit.Load(AuditFile)
+}
+
+// JournalTradeChanges records every trade created, edited, closed or deleted
+// between two versions of the trade history
+func JournalTradeChanges(before, after []models.Trade) error {
+	old := map[string]*models.Trade{}
+	for i := range before {
+		old[before[i].ID] = &before[i]
+	}
+
+	seen := map[string]bool{}
+	for i := range after {
+		trade := &after[i]
+		seen[trade.ID] = true
+
+		prev, existed := old[trade.ID]
+		switch {
+		case !existed:
+			if err := RecordTradeChange(audit.EventCreated, nil, trade); err != nil {
+				return err
+			}
+		case prev.GetStatus() != "closed" && trade.GetStatus() == "closed":
+			if err := RecordTradeChange(audit.EventClosed, prev, trade); err != nil {
+				return err
+			}
+		default:
+			changes, err := audit.Diff(prev, trade)
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			if _, err := audit.Append(AuditFile, audit.Event{Type: audit.EventEdited, TradeID: trade.ID, Changes: changes}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range before {
+		if !seen[before[i].ID] {
+			if err := RecordTradeChange(audit.EventDeleted, &before[i], nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RevertTradeChanges records that the changes journaled from onDisk to
+// journaled never reached the trade file, so the journal again describes the
+// history on disk
+func RevertTradeChanges(onDisk, journaled []models.Trade) error {
+	saved := map[string]*models.Trade{}
+	for i := range onDisk {
+		saved[onDisk[i].ID] = &onDisk[i]
+	}
+
+	revert := func(id string, from, to *models.Trade) error {
+		changes, err := audit.Diff(from, to)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+		_, err = audit.Append(AuditFile, audit.Event{Type: audit.EventReverted, TradeID: id, Changes: changes})
+		return err
+	}
+
+	seen := map[string]bool{}
+	for i := range journaled {
+		trade := &journaled[i]
+		seen[trade.ID] = true
+		if err := revert(trade.ID, trade, saved[trade.ID]); err != nil {
+			return err
+		}
+	}
+	for i := range onDisk {
+		if !seen[onDisk[i].ID] {
+			if err := revert(onDisk[i].ID, nil, &onDisk[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TradeStore updates the active profile's trade history, journaling every
+// change (used by the undo/redo command stack)
+type TradeStore struct{}
+
+// Update transforms the trade history under the storage lock, journals the
+// changes and writes the result
+func (TradeStore) Update(transform func([]models.Trade) ([]models.Trade, error)) ([]models.Trade, error) {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	before, err := loadAllTradesUnsafe()
+	if err != nil {
+		return nil, err
+	}
+	if err := assignTradeIDsUnsafe(before); err != nil {
+		return nil, err
+	}
+
+	after, err := transform(before)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := JournalTradeChanges(before, after); err != nil {
+		return nil, fmt.Errorf("audit journal error: %w", err)
+	}
+	if err := saveAllTradesUnsafe(after); err != nil {
+		return nil, errors.Join(err, RevertTradeChanges(before, after))
+	}
+	return after, nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/backup"
+	"tf-engine/internal/models"
+)
+
+// TradeDiff compares a backup with the current trade history
+type TradeDiff struct {
+	Added   []models.Trade // In the backup only (restoring brings them back)
+	Removed []models.Trade // In the current file only (restoring drops them)
+	Changed []models.Trade // In both with different values (backup version)
+}
+
+// Empty reports whether the backup matches the current history
+func (d TradeDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Backups returns the backup manager for the active profile
+func Backups() *backup.Manager {
+	return backupsIn(BackupDir)
+}
+
+// stepBackupWrite follows archiving the trade file (fault injection in tests)
+const stepBackupWrite = "backup-write"
+
+// backupTradesFile archives the trade file before it is overwritten, then
+// prunes old backups (caller holds the storage lock). Only a failure to
+// archive stops the write; a backup that can't be pruned is just logged.
+func backupTradesFile() error {
+	if !fileExists(TradesFile) {
+		return nil
+	}
+	m := Backups()
+	if _, err := m.Create(TradesFile); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	fault(stepBackupWrite)
+
+	if _, err := m.Prune(); err != nil {
+		logger.Warn("Failed to prune old backups", "dir", m.Dir, "err", err)
+	}
+	return nil
+}
+
+// ListBackups returns the active profile's backups, newest first
+func ListBackups() ([]backup.Info, error) {
+	return Backups().List()
+}
+
+// VerifyBackups checks every backup and reports corrupt or unparseable files
+func VerifyBackups() ([]backup.Result, error) {
+	return Backups().VerifyAll()
+}
+
+// LoadBackupTrades reads and verifies the trades stored in a backup
+func LoadBackupTrades(info backup.Info) ([]models.Trade, error) {
+	data, err := Backups().Read(info)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []models.Trade
+	if err := json.Unmarshal(data, &trades); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return trades, nil
+}
+
+// DiffTrades compares backup trades against the current history by ID
+func DiffTrades(current, backupTrades []models.Trade) (TradeDiff, error) {
+	var diff TradeDiff
+
+	byID := map[string]*models.Trade{}
+	for i := range current {
+		byID[current[i].ID] = &current[i]
+	}
+
+	inBackup := map[string]bool{}
+	for i := range backupTrades {
+		b := &backupTrades[i]
+		inBackup[b.ID] = true
+
+		c, ok := byID[b.ID]
+		if !ok {
+			diff.Added = append(diff.Added, *b)
+			continue
+		}
+		changes, err := audit.Diff(c, b)
+		if err != nil {
+			return diff, err
+		}
+		if len(changes) > 0 {
+			diff.Changed = append(diff.Changed, *b)
+		}
+	}
+
+	for _, c := range current {
+		if !inBackup[c.ID] {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+// DraftsDir holds the active profile's in-progress trades, one file per draft
+var DraftsDir = "data/drafts/"
+
+// SaveDraft saves a draft under its trade ID, assigning one if needed
+func SaveDraft(draft *models.Draft) error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if draft.Trade.ID == "" {
+		draft.Trade.ID = models.NewTradeID()
+	}
+	draft.Trade.UpdatedAt = time.Now()
+
+	return saveDraftUnsafe(draft)
+}
+
+// saveDraftUnsafe writes a draft without locking (internal use)
+func saveDraftUnsafe(draft *models.Draft) error {
+	data, err := json.MarshalIndent(draft, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return writeDataFile(draftPath(draft.Trade.ID), data)
+}
+
+// LoadDraft loads one draft. Returns nil if there is no such draft.
+func LoadDraft(id string) (*models.Draft, error) {
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	path := draftPath(id)
+	if !fileExists(path) {
+		return nil, nil
+	}
+	return loadDraftFile(path)
+}
+
+// LoadDrafts loads every draft, most recently updated first. A trade left in
+// the old single in-progress file becomes a draft the first time this runs.
+func LoadDrafts() ([]models.Draft, error) {
+	if fileExists(InProgressFile) && !ReadOnly() {
+		if err := migrateInProgressTrade(); err != nil {
+			return nil, err
+		}
+	}
+
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	files, err := filepath.Glob(filepath.Join(DraftsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	drafts := []models.Draft{}
+	for _, path := range files {
+		draft, err := loadDraftFile(path)
+		if err != nil {
+			return drafts, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		drafts = append(drafts, *draft)
+	}
+
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].Trade.UpdatedAt.After(drafts[j].Trade.UpdatedAt)
+	})
+	return drafts, nil
+}
+
+// DeleteDraft removes a draft. Deleting a missing draft is not an error.
+func DeleteDraft(id string) error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return deleteDraftUnsafe(id)
+}
+
+// deleteDraftUnsafe removes a draft without locking (internal use)
+func deleteDraftUnsafe(id string) error {
+	if id == "" {
+		return nil
+	}
+	if err := os.Remove(draftPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// migrateInProgressTrade moves the old single in-progress trade into the
+// drafts directory
+func migrateInProgressTrade() error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !fileExists(InProgressFile) {
+		return nil // Migrated by another process meanwhile
+	}
+
+	data, err := readDataFile(InProgressFile)
+	if err != nil {
+		return err
+	}
+
+	var trade models.Trade
+	if err := json.Unmarshal(data, &trade); err != nil {
+		return fmt.Errorf("unmarshal error: %w", err)
+	}
+	if trade.ID == "" {
+		trade.ID = models.NewTradeID()
+	}
+
+	if err := saveDraftUnsafe(&models.Draft{Trade: trade}); err != nil {
+		return err
+	}
+	return os.Remove(InProgressFile)
+}
+
+func loadDraftFile(path string) (*models.Draft, error) {
+	data, err := readDataFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var draft models.Draft
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return &draft, nil
+}
+
+// draftFiles lists the draft files in dir (used when re-encrypting)
+func draftFiles(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return files
+}
+
+// draftPath returns the file for a draft. IDs are generated by the app, but
+// strip path separators anyway so an ID can't point outside the directory.
+func draftPath(id string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, id)
+	return filepath.Join(DraftsDir, safe+".json")
+}
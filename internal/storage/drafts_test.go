@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func TestDrafts_SaveLoadAndDelete(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	unh := &models.Draft{Trade: models.Trade{ID: "unh", Ticker: "UNH"}, Step: "checklist"}
+	if err := SaveDraft(unh); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	msft := &models.Draft{Trade: models.Trade{Ticker: "MSFT"}, Step: "ticker_entry"}
+	if err := SaveDraft(msft); err != nil {
+		t.Fatal(err)
+	}
+	if msft.ID() == "" {
+		t.Fatal("SaveDraft should assign an ID")
+	}
+
+	drafts, err := LoadDrafts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 2 || drafts[0].Trade.Ticker != "MSFT" || drafts[1].Step != "checklist" {
+		t.Fatalf("Expected MSFT then UNH with their steps, got %+v", drafts)
+	}
+
+	if err := DeleteDraft("unh"); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := LoadDraft("unh"); d != nil {
+		t.Error("Deleted draft should be gone")
+	}
+	if err := DeleteDraft("unh"); err != nil {
+		t.Errorf("Deleting a missing draft should not error: %v", err)
+	}
+}
+
+func TestLoadDrafts_MigratesInProgressFile(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	legacy, _ := json.Marshal(models.Trade{Ticker: "UNH", Sector: "Healthcare"})
+	os.MkdirAll(filepath.Dir(InProgressFile), 0755)
+	os.WriteFile(InProgressFile, legacy, 0644)
+
+	drafts, err := LoadDrafts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 1 || drafts[0].Trade.Ticker != "UNH" || drafts[0].ID() == "" {
+		t.Fatalf("Expected the old in-progress trade as a draft with an ID, got %+v", drafts)
+	}
+	if fileExists(InProgressFile) {
+		t.Error("Old in-progress file should be removed after migration")
+	}
+}
+
+func TestSaveCompletedTrade_RemovesDraft(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	draft := &models.Draft{Trade: models.Trade{ID: "unh", Ticker: "UNH"}, Step: "trade_entry"}
+	other := &models.Draft{Trade: models.Trade{ID: "msft", Ticker: "MSFT"}}
+	SaveDraft(draft)
+	SaveDraft(other)
+
+	if err := SaveCompletedTrade(&draft.Trade); err != nil {
+		t.Fatal(err)
+	}
+
+	drafts, _ := LoadDrafts()
+	if len(drafts) != 1 || drafts[0].ID() != "msft" {
+		t.Errorf("Only the completed draft should be removed, got %+v", drafts)
+	}
+}
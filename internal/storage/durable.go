@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"tf-engine/internal/paths"
+)
+
+// WALDir holds write-ahead records for writes in progress (set by InitProfiles)
+var WALDir = "data/wal/"
+
+// Steps of a durable write, in order. Tests inject crashes after each one.
+const (
+	stepWALWrite  = "wal-write"
+	stepWALSync   = "wal-sync"
+	stepTmpWrite  = "tmp-write"
+	stepTmpSync   = "tmp-sync"
+	stepRename    = "rename"
+	stepDirSync   = "dir-sync"
+	stepWALRemove = "wal-remove"
+)
+
+// faultHook is called after each write step (fault injection in tests)
+var faultHook func(step string)
+
+// RecoveredWrites lists the files InitProfiles completed from the WAL
+var RecoveredWrites []string
+
+// walSeq orders records written within the same nanosecond
+var walSeq atomic.Int64
+
+// walRecord is a write that was about to happen. Records left behind by a
+// crash are replayed on startup.
+type walRecord struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Data   []byte `json:"data"`
+}
+
+// writeFileDurable replaces path with data so that after a crash at any point
+// the file holds either the old or the new contents, never a mix. The write
+// is recorded in the WAL first so a crash after that point still completes
+// on the next start.
+func writeFileDurable(path string, data []byte) error {
+	walPath, err := walBegin(path, data)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceFile(path, data); err != nil {
+		// The caller sees the error, so don't complete the write on restart
+		os.Remove(walPath)
+		return err
+	}
+
+	if err := os.Remove(walPath); err != nil {
+		return fmt.Errorf("wal error: %w", err)
+	}
+	fault(stepWALRemove)
+	return nil
+}
+
+// replaceFile writes a temp file, fsyncs it, renames it over path and fsyncs
+// the directory so the rename itself is durable
+func replaceFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	tmpFile := path + ".tmp"
+	if err := writeAndSync(tmpFile, data, stepTmpWrite, stepTmpSync); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("rename error: %w", err)
+	}
+	fault(stepRename)
+
+	if err := syncDir(dir); err != nil {
+		return fmt.Errorf("sync error: %w", err)
+	}
+	fault(stepDirSync)
+	return nil
+}
+
+// walBegin durably records an intended write and returns the record's path
+func walBegin(path string, data []byte) (string, error) {
+	if err := os.MkdirAll(WALDir, 0755); err != nil {
+		return "", fmt.Errorf("wal error: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	record, err := json.Marshal(walRecord{Path: path, SHA256: hex.EncodeToString(sum[:]), Data: data})
+	if err != nil {
+		return "", fmt.Errorf("wal error: %w", err)
+	}
+
+	name := fmt.Sprintf("%019d-%06d.wal", time.Now().UnixNano(), walSeq.Add(1)%1000000)
+	walPath := filepath.Join(WALDir, name)
+	if err := writeAndSync(walPath, record, stepWALWrite, stepWALSync); err != nil {
+		os.Remove(walPath)
+		return "", fmt.Errorf("wal error: %w", err)
+	}
+	if err := syncDir(WALDir); err != nil {
+		os.Remove(walPath)
+		return "", fmt.Errorf("wal error: %w", err)
+	}
+	return walPath, nil
+}
+
+// ReplayWAL completes writes interrupted by a crash, oldest first. Torn
+// records (the crash hit while writing the record) are discarded; the target
+// file was not touched yet. Returns the files that were rewritten.
+func ReplayWAL() ([]string, error) {
+	entries, err := os.ReadDir(WALDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("wal error: %w", err)
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".wal") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	replayed := []string{}
+	for _, name := range names {
+		walPath := filepath.Join(WALDir, name)
+
+		if rec, ok := readWALRecord(walPath); ok {
+			if err := replaceFile(rec.Path, rec.Data); err != nil {
+				return replayed, fmt.Errorf("wal replay of %s: %w", rec.Path, err)
+			}
+			replayed = append(replayed, rec.Path)
+		}
+
+		if err := os.Remove(walPath); err != nil {
+			return replayed, fmt.Errorf("wal error: %w", err)
+		}
+	}
+
+	return replayed, nil
+}
+
+// readWALRecord parses a record and checks its checksum
+func readWALRecord(path string) (walRecord, bool) {
+	var rec walRecord
+
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &rec) != nil || rec.Path == "" {
+		return rec, false
+	}
+
+	sum := sha256.Sum256(rec.Data)
+	return rec, hex.EncodeToString(sum[:]) == rec.SHA256
+}
+
+// writeAndSync writes a new file and fsyncs it before closing
+func writeAndSync(path string, data []byte, writeStep, syncStep string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	fault(writeStep)
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync error: %w", err)
+	}
+	fault(syncStep)
+
+	return f.Close()
+}
+
+// syncDir fsyncs a directory so renames and removals in it are durable.
+// Windows can't open directories for syncing; NTFS journals renames itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func fault(step string) {
+	if faultHook != nil {
+		faultHook(step)
+	}
+}
+
+// initWAL points at the data directory's WAL and replays it
+func initWAL() error {
+	WALDir = paths.Data("wal") + string(filepath.Separator)
+
+	var err error
+	RecoveredWrites, err = ReplayWAL()
+	for _, path := range RecoveredWrites {
+		logger.Info("Recovered interrupted write", "path", path)
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/models"
+)
+
+// simulatedCrash is panicked by the fault hook to stop a write mid-way, the
+// way a killed process or power loss would
+type simulatedCrash struct{ step string }
+
+// crashAt runs fn, crashing it after the given write step. Reports whether
+// the crash happened.
+func crashAt(t *testing.T, step string, fn func() error) (crashed bool) {
+	t.Helper()
+
+	faultHook = func(s string) {
+		if s == step {
+			panic(simulatedCrash{step})
+		}
+	}
+	defer func() {
+		faultHook = nil
+		if r := recover(); r != nil {
+			if _, ok := r.(simulatedCrash); !ok {
+				panic(r)
+			}
+			crashed = true
+		}
+	}()
+
+	if err := fn(); err != nil {
+		t.Fatalf("write failed before reaching %s: %v", step, err)
+	}
+	return false
+}
+
+func TestDurableWrite_CrashAtEachStep(t *testing.T) {
+	steps := []string{
+		stepWALWrite, stepWALSync, stepTmpWrite, stepTmpSync,
+		stepRename, stepDirSync, stepWALRemove,
+	}
+
+	for _, step := range steps {
+		t.Run(step, func(t *testing.T) {
+			cleanup := setupTestDataDir(t)
+			defer cleanup()
+
+			old := []models.Trade{{ID: "1", Ticker: "AAPL", Status: "active"}}
+			if err := SaveAllTrades(old); err != nil {
+				t.Fatal(err)
+			}
+
+			updated := []models.Trade{
+				{ID: "1", Ticker: "AAPL", Status: "closed"},
+				{ID: "2", Ticker: "MSFT", Status: "active"},
+			}
+			if !crashAt(t, step, func() error { return SaveAllTrades(updated) }) {
+				t.Fatalf("expected a crash at %s", step)
+			}
+
+			// Restart: the WAL completes the interrupted write
+			if _, err := InitProfiles(); err != nil {
+				t.Fatalf("InitProfiles after crash: %v", err)
+			}
+
+			trades, err := LoadAllTrades()
+			if err != nil {
+				t.Fatalf("trades unreadable after crash at %s: %v", step, err)
+			}
+			if len(trades) != 2 || trades[0].Status != "closed" {
+				t.Errorf("crash at %s: expected the new history, got %+v", step, trades)
+			}
+
+			// Nothing is left to replay
+			if left, _ := filepath.Glob(filepath.Join(WALDir, "*.wal")); len(left) != 0 {
+				t.Errorf("WAL records left after replay: %v", left)
+			}
+		})
+	}
+}
+
+func TestReplayWAL_DiscardsTornRecord(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	old := []models.Trade{{ID: "1", Ticker: "AAPL"}}
+	if err := SaveAllTrades(old); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash while writing the record leaves a truncated file
+	os.MkdirAll(WALDir, 0755)
+	torn := `{"path":"` + TradesFile + `","sha256":"abc","data":"W3si`
+	os.WriteFile(filepath.Join(WALDir, "0000000000000000001-000001.wal"), []byte(torn), 0644)
+
+	// A record whose data doesn't match its checksum is also discarded
+	bad := `{"path":"` + TradesFile + `","sha256":"0000","data":"W10="}`
+	os.WriteFile(filepath.Join(WALDir, "0000000000000000002-000001.wal"), []byte(bad), 0644)
+
+	replayed, err := ReplayWAL()
+	if err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	if len(replayed) != 0 {
+		t.Errorf("Torn records should not be replayed, got %v", replayed)
+	}
+
+	trades, err := LoadAllTrades()
+	if err != nil || len(trades) != 1 {
+		t.Errorf("Original history should be untouched: %+v, %v", trades, err)
+	}
+}
+
+func TestDurableWrite_FailedWriteIsNotReplayed(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	// The target's directory is a file, so the write fails after the WAL record
+	blocker := filepath.Join(filepath.Dir(TradesFile), "blocked")
+	os.WriteFile(blocker, []byte("x"), 0644)
+	if err := writeFileDurable(filepath.Join(blocker, "trades.json"), []byte("[]")); err == nil {
+		t.Fatal("Expected write error")
+	}
+
+	if left, _ := filepath.Glob(filepath.Join(WALDir, "*.wal")); len(left) != 0 {
+		t.Errorf("A failed write the caller saw should not be replayed: %v", left)
+	}
+}
+
+func TestSaveCompletedTrade_RefusesToOverwriteUnreadableHistory(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	corrupt := `[{"id":"1","ticker":"AAPL"`
+	os.MkdirAll(filepath.Dir(TradesFile), 0755)
+	os.WriteFile(TradesFile, []byte(corrupt), 0644)
+
+	err := SaveCompletedTrade(&models.Trade{ID: "2", Ticker: "MSFT"})
+	if err == nil || !strings.Contains(err.Error(), "refusing to overwrite") {
+		t.Fatalf("Expected refusal, got %v", err)
+	}
+	if err := SaveAllTrades([]models.Trade{}); err == nil {
+		t.Error("SaveAllTrades should also refuse")
+	}
+
+	data, _ := os.ReadFile(TradesFile)
+	if string(data) != corrupt {
+		t.Errorf("Unreadable history must be left as it was, got %q", data)
+	}
+
+	// Nothing was journaled for the refused save
+	events, _ := LoadAuditJournal()
+	if len(events) != 0 {
+		t.Errorf("Refused save should not be journaled, got %d events", len(events))
+	}
+}
+
+func TestSaveCompletedTrade_FailedWriteRevertsJournal(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	if err := SaveCompletedTrade(&models.Trade{ID: "1", Ticker: "AAPL", Status: "active"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory where the temp file goes makes the write fail
+	os.MkdirAll(TradesFile+".tmp", 0755)
+	if err := SaveCompletedTrade(&models.Trade{ID: "2", Ticker: "MSFT", Status: "active"}); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+
+	events, err := LoadAuditJournal()
+	if err != nil || audit.Verify(events) != nil {
+		t.Fatalf("Journal unreadable: %v", err)
+	}
+	if len(events) != 3 || events[1].TradeID != "2" || events[2].Type != audit.EventReverted || events[2].TradeID != "2" {
+		t.Fatalf("Expected the failed creation to be reverted, got %+v", events)
+	}
+	for _, c := range events[2].Changes {
+		if len(c.After) != 0 {
+			t.Errorf("The revert should remove every field, got %s = %s", c.Field, c.After)
+		}
+	}
+	if trades, _ := LoadAllTrades(); len(trades) != 1 {
+		t.Errorf("Expected only the first trade on disk, got %+v", trades)
+	}
+	if rebuilt, err := audit.Rebuild(events); err != nil || len(rebuilt) != 1 || rebuilt[0].ID != "1" {
+		t.Errorf("Expected the journal to rebuild only the first trade, got %+v (%v)", rebuilt, err)
+	}
+}
@@ -0,0 +1,421 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/backup"
+	"tf-engine/internal/crypt"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+)
+
+// EncryptionFile holds the key derivation parameters when encryption is on
+// (set by InitProfiles). Encryption covers every profile in the data directory.
+var EncryptionFile = "data/encryption.json"
+
+// RecodeFile records a committed change of key until every file is rewritten
+// (set by InitProfiles)
+var RecodeFile = "data/recode.json"
+
+// kdfParams are the scrypt costs for new keys (lowered in tests)
+var kdfParams = crypt.DefaultParams
+
+var (
+	keyMu     sync.RWMutex
+	activeKey *crypt.Key
+)
+
+// EncryptionEnabled reports whether the data directory is encrypted
+func EncryptionEnabled() bool {
+	return fileExists(EncryptionFile)
+}
+
+// Locked reports whether the data is encrypted and not yet unlocked
+func Locked() bool {
+	return EncryptionEnabled() && currentKey() == nil
+}
+
+// Unlock derives the key from the passphrase so encrypted files can be read
+func Unlock(passphrase string) error {
+	kf, err := crypt.LoadKeyFile(EncryptionFile)
+	if err != nil {
+		return err
+	}
+	if kf == nil {
+		return errors.New("encryption is not enabled")
+	}
+
+	key, err := kf.Unlock(passphrase)
+	if err != nil {
+		return err
+	}
+	setKey(key)
+	return nil
+}
+
+// EnableEncryption encrypts every profile's trades, settings, ledger, audit
+// journal and backups with a key derived from passphrase
+func EnableEncryption(passphrase string) error {
+	if EncryptionEnabled() {
+		return errors.New("encryption is already enabled")
+	}
+
+	kf, key, err := crypt.NewKey(passphrase, kdfParams)
+	if err != nil {
+		return err
+	}
+	return recodeAll(kf, key)
+}
+
+// Rekey re-encrypts all data with a key derived from a new passphrase
+func Rekey(current, next string) error {
+	if err := checkPassphrase(current); err != nil {
+		return err
+	}
+
+	kf, key, err := crypt.NewKey(next, kdfParams)
+	if err != nil {
+		return err
+	}
+	return recodeAll(kf, key)
+}
+
+// DisableEncryption decrypts all data back to plaintext JSON
+func DisableEncryption(current string) error {
+	if err := checkPassphrase(current); err != nil {
+		return err
+	}
+	return recodeAll(nil, nil)
+}
+
+// checkPassphrase unlocks with the passphrase, failing if encryption is off
+func checkPassphrase(passphrase string) error {
+	if !EncryptionEnabled() {
+		return errors.New("encryption is not enabled")
+	}
+	return Unlock(passphrase)
+}
+
+// Steps of a recode, in order. Tests inject crashes after each one.
+const (
+	stepRecodeStage  = "recode-stage"
+	stepRecodeCommit = "recode-commit"
+	stepRecodeKey    = "recode-key"
+	stepRecodeMove   = "recode-move"
+)
+
+// recodeRecord is a recode whose files are all staged: the key file to
+// install (none when disabling encryption) and the staged files to move over
+// the originals. Once it is on disk the recode is committed; a crash before
+// it finishes is completed on the next start.
+type recodeRecord struct {
+	KeyFile json.RawMessage `json:"key_file,omitempty"`
+	Files   []recodeMove    `json:"files"`
+	Remove  []string        `json:"remove,omitempty"` // Legacy backups replaced by archives
+}
+
+// recodeMove moves a staged file over the file it replaces
+type recodeMove struct {
+	Staged string `json:"staged"`
+	Path   string `json:"path"`
+}
+
+// stagedSuffix names a data file's re-encoded copy; backups are staged in
+// a subdirectory of the backups directory
+const (
+	stagedSuffix    = ".recode"
+	stagedBackupDir = "recode"
+)
+
+// recodeAll reads every data file with the current key and rewrites it with
+// the new one (nil writes plaintext). Everything is read, then written to
+// staged copies, before the key file or any data file changes, so a wrong
+// key, a write error or a crash leaves the data readable with one key or
+// the other.
+func recodeAll(kf *crypt.KeyFile, key *crypt.Key) (err error) {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	list, err := LoadProfiles()
+	if err != nil {
+		return err
+	}
+
+	rec := recodeRecord{Files: []recodeMove{}}
+	if kf != nil {
+		if rec.KeyFile, err = kf.Marshal(); err != nil {
+			return err
+		}
+	}
+	// A failure before the commit discards the staged copies; a crash
+	// leaves them for the next start to remove
+	committed := false
+	defer func() {
+		if err != nil && !committed {
+			removeStaged(list)
+		}
+	}()
+	stage := func(path string, data []byte) error {
+		staged := path + stagedSuffix
+		rec.Files = append(rec.Files, recodeMove{Staged: staged, Path: path})
+		if err := writeAndSync(staged, data, "", ""); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fault(stepRecodeStage)
+		return nil
+	}
+
+	var journalCodec audit.Codec
+	if key != nil {
+		journalCodec = fixedCodec{key}
+	}
+	for _, profile := range list.Profiles {
+		p := PathsForProfile(profile.ID)
+
+		// Read with the current key, write with the new one
+		files := append([]string{p.Trades, p.InProgress, p.Ledger, p.Settings}, draftFiles(p.Drafts)...)
+		for _, path := range files {
+			if !fileExists(path) {
+				continue
+			}
+			data, err := readDataFile(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if data, err = crypt.Encode(key, data); err != nil {
+				return fmt.Errorf("encrypt error: %w", err)
+			}
+			if err := stage(path, data); err != nil {
+				return err
+			}
+		}
+
+		events, err := audit.Load(p.Audit)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Audit, err)
+		}
+		if len(events) > 0 {
+			data, err := audit.Encode(events, journalCodec)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.Audit, err)
+			}
+			if err := stage(p.Audit, data); err != nil {
+				return err
+			}
+		}
+
+		backups := backupsIn(p.Backups)
+		infos, err := backups.List()
+		if err != nil {
+			return err
+		}
+		staging := backup.New(filepath.Join(p.Backups, stagedBackupDir))
+		staging.Codec = fixedCodec{key}
+		for _, info := range infos {
+			// Corrupt backups are left as they are; verify reports them
+			data, err := backups.Read(info)
+			if err != nil {
+				continue
+			}
+			legacy := info.Legacy
+			info.Legacy = false // Removed only once the recode is committed
+			staged, err := staging.Rewrite(info, data)
+			if err != nil {
+				return err
+			}
+			if err := syncFile(staged.Path); err != nil {
+				return err
+			}
+			rec.Files = append(rec.Files, recodeMove{Staged: staged.Path, Path: filepath.Join(p.Backups, staged.Name)})
+			if legacy {
+				rec.Remove = append(rec.Remove, info.Path)
+			}
+			fault(stepRecodeStage)
+		}
+	}
+
+	// Commit: from here on the recode completes, now or on the next start
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	if err := writeFileDurable(RecodeFile, data); err != nil {
+		return err
+	}
+	committed = true
+	fault(stepRecodeCommit)
+
+	err = applyRecode(rec)
+	setKey(key)
+	return err
+}
+
+// applyRecode installs a committed recode's key file, then moves its staged
+// files into place. Moves already done before a crash are skipped.
+func applyRecode(rec recodeRecord) error {
+	if rec.KeyFile != nil {
+		if err := writeFileDurable(EncryptionFile, rec.KeyFile); err != nil {
+			return err
+		}
+	} else if err := os.Remove(EncryptionFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	fault(stepRecodeKey)
+
+	dirs := map[string]bool{filepath.Dir(EncryptionFile): true}
+	for _, m := range rec.Files {
+		if err := os.Rename(m.Staged, m.Path); err != nil {
+			if errors.Is(err, os.ErrNotExist) && fileExists(m.Path) {
+				continue
+			}
+			return fmt.Errorf("recode of %s: %w", m.Path, err)
+		}
+		dirs[filepath.Dir(m.Path)] = true
+		dirs[filepath.Dir(m.Staged)] = true
+		fault(stepRecodeMove)
+	}
+	for _, path := range rec.Remove {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	for dir := range dirs {
+		if err := syncDir(dir); err != nil {
+			return fmt.Errorf("sync error: %w", err)
+		}
+	}
+	for _, m := range rec.Files {
+		if dir := filepath.Dir(m.Staged); filepath.Base(dir) == stagedBackupDir {
+			os.Remove(dir) // Fails harmlessly until the last backup is moved
+		}
+	}
+
+	if err := os.Remove(RecodeFile); err != nil {
+		return fmt.Errorf("recode error: %w", err)
+	}
+	return syncDir(filepath.Dir(RecodeFile))
+}
+
+// resumeRecode completes a recode a crash interrupted after it was
+// committed. Returns whether there was one.
+func resumeRecode() (bool, error) {
+	data, err := os.ReadFile(RecodeFile)
+	if errors.Is(err, os.ErrNotExist) {
+		list, err := LoadProfiles()
+		if err != nil {
+			return false, err
+		}
+		removeStaged(list)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("recode error: %w", err)
+	}
+
+	var rec recodeRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return false, fmt.Errorf("recode error: %w", err)
+	}
+	return true, applyRecode(rec)
+}
+
+// removeStaged deletes the staged copies of a recode that wasn't committed
+func removeStaged(list *models.ProfileList) {
+	for _, profile := range list.Profiles {
+		p := PathsForProfile(profile.ID)
+		staged, _ := filepath.Glob(filepath.Join(p.Drafts, "*"+stagedSuffix))
+		for _, path := range []string{p.Trades, p.InProgress, p.Ledger, p.Settings, p.Audit} {
+			staged = append(staged, path+stagedSuffix)
+		}
+		for _, path := range staged {
+			os.Remove(path)
+		}
+		os.RemoveAll(filepath.Join(p.Backups, stagedBackupDir))
+	}
+}
+
+// syncFile fsyncs a file written without it
+func syncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("sync error: %w", err)
+	}
+	defer f.Close()
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync error: %w", err)
+	}
+	return f.Close()
+}
+
+// readDataFile reads a data file, decrypting it if needed
+func readDataFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+	return crypt.Decode(currentKey(), data)
+}
+
+// writeDataFile writes a data file durably, encrypting it when a key is set
+func writeDataFile(path string, data []byte) error {
+	data, err := crypt.Encode(currentKey(), data)
+	if err != nil {
+		return fmt.Errorf("encrypt error: %w", err)
+	}
+	return writeFileDurable(path, data)
+}
+
+func currentKey() *crypt.Key {
+	keyMu.RLock()
+	defer keyMu.RUnlock()
+	return activeKey
+}
+
+// setKey sets the key for data files, backups and the audit journal
+func setKey(key *crypt.Key) {
+	keyMu.Lock()
+	activeKey = key
+	keyMu.Unlock()
+
+	if key == nil {
+		audit.SetCodec(nil)
+	} else {
+		audit.SetCodec(keyCodec{})
+	}
+}
+
+// keyCodec encrypts with whatever key is active when it's used
+type keyCodec struct{}
+
+func (keyCodec) Encode(data []byte) ([]byte, error) { return crypt.Encode(currentKey(), data) }
+func (keyCodec) Decode(data []byte) ([]byte, error) { return crypt.Decode(currentKey(), data) }
+
+// fixedCodec encrypts with one key (nil for plaintext) whatever is active
+type fixedCodec struct{ key *crypt.Key }
+
+func (c fixedCodec) Encode(data []byte) ([]byte, error) { return crypt.Encode(c.key, data) }
+func (c fixedCodec) Decode(data []byte) ([]byte, error) { return crypt.Decode(c.key, data) }
+
+// backupsIn returns a backup manager for dir using the active key
+func backupsIn(dir string) *backup.Manager {
+	m := backup.New(dir)
+	m.Codec = keyCodec{}
+	return m
+}
+
+// initEncryption points at the data directory's key file and forgets any
+// key from a previous data directory
+func initEncryption() {
+	EncryptionFile = paths.Data("encryption.json")
+	RecodeFile = paths.Data("recode.json")
+	setKey(nil)
+}
@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/crypt"
+	"tf-engine/internal/models"
+)
+
+func setupEncryptionTest(t *testing.T) func() {
+	cleanup := setupTestDataDir(t)
+	old := kdfParams
+	kdfParams = crypt.Params{N: 1 << 10, R: 8, P: 1}
+	return func() {
+		kdfParams = old
+		cleanup()
+	}
+}
+
+// assertNoPlaintext fails if any file under dir contains needle
+func assertNoPlaintext(t *testing.T, dir, needle string) {
+	t.Helper()
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Base(path) == "encryption.json" {
+			return err
+		}
+		data, _ := os.ReadFile(path)
+		if bytes.Contains(data, []byte(needle)) {
+			t.Errorf("%s contains plaintext %q", path, needle)
+		}
+		return nil
+	})
+}
+
+func TestEncryption_EnableLockUnlockRekeyDisable(t *testing.T) {
+	cleanup := setupEncryptionTest(t)
+	defer cleanup()
+
+	// Plaintext data written before encryption is turned on
+	pnl := 340.0
+	SaveCompletedTrade(&models.Trade{ID: "E1", Ticker: "AAPL", Status: "closed", ProfitLoss: &pnl})
+	SaveCompletedTrade(&models.Trade{ID: "E2", Ticker: "MSFT", Status: "active"}) // Creates a backup
+	SaveSettings(&models.Settings{AccountEquity: 98765})
+	ledger := models.NewLedger(98765, time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local))
+	SaveLedger(ledger)
+
+	if EncryptionEnabled() {
+		t.Fatal("Encryption should be off by default")
+	}
+	if err := EnableEncryption("correct horse"); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+
+	dataDir := filepath.Dir(TradesFile)
+	assertNoPlaintext(t, dataDir, "AAPL")
+	assertNoPlaintext(t, dataDir, "98765")
+
+	// Data still loads while unlocked
+	trades, err := LoadAllTrades()
+	if err != nil || len(trades) != 2 {
+		t.Fatalf("LoadAllTrades while unlocked: %d trades, %v", len(trades), err)
+	}
+	results, _ := VerifyBackups()
+	if len(results) == 0 || results[0].Err != nil {
+		t.Errorf("Backups should verify while unlocked: %+v", results)
+	}
+
+	// A fresh start is locked until the passphrase is given
+	InitProfiles()
+	if !Locked() {
+		t.Fatal("Expected data to be locked after restart")
+	}
+	if _, err := LoadAllTrades(); !errors.Is(err, crypt.ErrLocked) {
+		t.Errorf("Expected ErrLocked, got %v", err)
+	}
+	if err := Unlock("wrong"); !errors.Is(err, crypt.ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if err := Unlock("correct horse"); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	settings, err := LoadSettings()
+	if err != nil || settings.AccountEquity != 98765 {
+		t.Errorf("LoadSettings after unlock: %+v, %v", settings, err)
+	}
+	events, err := LoadAuditJournal()
+	if err != nil || audit.Verify(events) != nil || len(events) != 2 {
+		t.Errorf("Audit journal after unlock: %d events, %v", len(events), err)
+	}
+
+	// Re-key: the old passphrase stops working
+	if err := Rekey("wrong", "battery staple"); err == nil {
+		t.Error("Rekey with the wrong passphrase should fail")
+	}
+	if err := Rekey("correct horse", "battery staple"); err != nil {
+		t.Fatalf("Rekey failed: %v", err)
+	}
+	InitProfiles()
+	if err := Unlock("correct horse"); !errors.Is(err, crypt.ErrWrongPassphrase) {
+		t.Errorf("Old passphrase should fail after re-key, got %v", err)
+	}
+	if err := Unlock("battery staple"); err != nil {
+		t.Fatalf("Unlock with new passphrase failed: %v", err)
+	}
+	if trades, err := LoadAllTrades(); err != nil || len(trades) != 2 {
+		t.Errorf("Trades after re-key: %d, %v", len(trades), err)
+	}
+
+	// Disable: back to plaintext that loads without a passphrase
+	if err := DisableEncryption("battery staple"); err != nil {
+		t.Fatalf("DisableEncryption failed: %v", err)
+	}
+	InitProfiles()
+	if Locked() || EncryptionEnabled() {
+		t.Error("Encryption should be off")
+	}
+	if trades, err := LoadAllTrades(); err != nil || len(trades) != 2 {
+		t.Errorf("Plaintext trades after disable: %d, %v", len(trades), err)
+	}
+	if results, _ := VerifyBackups(); len(results) == 0 || results[0].Err != nil {
+		t.Errorf("Backups should verify after disable: %+v", results)
+	}
+}
+
+// seedEncrypted writes trades (with a backup), settings and journal entries
+// and encrypts them with passphrase
+func seedEncrypted(t *testing.T, passphrase string) {
+	t.Helper()
+	SaveCompletedTrade(&models.Trade{ID: "E1", Ticker: "AAPL", Status: "active"})
+	SaveCompletedTrade(&models.Trade{ID: "E2", Ticker: "MSFT", Status: "active"})
+	SaveSettings(&models.Settings{AccountEquity: 98765})
+	if err := EnableEncryption(passphrase); err != nil {
+		t.Fatalf("EnableEncryption failed: %v", err)
+	}
+}
+
+// assertReadableWith restarts, unlocks with passphrase and checks every kind
+// of data file reads back
+func assertReadableWith(t *testing.T, passphrase string) {
+	t.Helper()
+	if _, err := InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles: %v", err)
+	}
+	if err := Unlock(passphrase); err != nil {
+		t.Fatalf("Unlock(%q): %v", passphrase, err)
+	}
+	if trades, err := LoadAllTrades(); err != nil || len(trades) != 2 {
+		t.Errorf("Trades: %d, %v", len(trades), err)
+	}
+	if settings, err := LoadSettings(); err != nil || settings.AccountEquity != 98765 {
+		t.Errorf("Settings: %+v, %v", settings, err)
+	}
+	if events, err := LoadAuditJournal(); err != nil || audit.Verify(events) != nil || len(events) != 2 {
+		t.Errorf("Audit journal: %d events, %v", len(events), err)
+	}
+	if results, _ := VerifyBackups(); len(results) == 0 || results[0].Err != nil {
+		t.Errorf("Backups: %+v", results)
+	}
+
+	// Nothing staged is left behind
+	dataDir := filepath.Dir(RecodeFile)
+	filepath.Walk(dataDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && (strings.HasSuffix(path, stagedSuffix) || path == RecodeFile) {
+			t.Errorf("Left behind: %s", path)
+		}
+		return nil
+	})
+}
+
+func TestRekey_CrashAtEachStep(t *testing.T) {
+	for step, want := range map[string]string{
+		stepRecodeStage:  "correct horse", // Not committed: the old key stays
+		stepRecodeCommit: "battery staple",
+		stepRecodeKey:    "battery staple",
+		stepRecodeMove:   "battery staple",
+	} {
+		t.Run(step, func(t *testing.T) {
+			cleanup := setupEncryptionTest(t)
+			defer cleanup()
+			seedEncrypted(t, "correct horse")
+
+			if !crashAt(t, step, func() error { return Rekey("correct horse", "battery staple") }) {
+				t.Fatalf("expected a crash at %s", step)
+			}
+			assertReadableWith(t, want)
+		})
+	}
+}
+
+func TestRekey_WriteErrorKeepsOldKey(t *testing.T) {
+	cleanup := setupEncryptionTest(t)
+	defer cleanup()
+	seedEncrypted(t, "correct horse")
+
+	// A directory where the staged settings go makes staging fail part way
+	if err := os.MkdirAll(settingsFile+stagedSuffix, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Rekey("correct horse", "battery staple"); err == nil {
+		t.Fatal("Expected Rekey to fail")
+	}
+	os.Remove(settingsFile + stagedSuffix)
+	assertReadableWith(t, "correct horse")
+}
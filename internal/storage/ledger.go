@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+// LedgerFile holds the account ledger of the active profile
+var LedgerFile = "data/ledger.json"
+
+// SaveLedger saves the account ledger atomically
+func SaveLedger(ledger *models.Ledger) error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	return writeDataFile(LedgerFile, data)
+}
+
+// LoadLedger loads the account ledger. Returns nil if no ledger exists yet.
+func LoadLedger() (*models.Ledger, error) {
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return loadLedgerFile(LedgerFile)
+}
+
+// loadLedgerFile reads a ledger file. Returns nil if the file does not exist.
+func loadLedgerFile(path string) (*models.Ledger, error) {
+	if !fileExists(path) {
+		return nil, nil
+	}
+
+	data, err := readDataFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ledger models.Ledger
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+
+	return &ledger, nil
+}
+
+// LoadOrCreateLedger loads the ledger, creating one opened with the given
+// starting balance (dated before the first trade) if none exists yet
+func LoadOrCreateLedger(startingBalance float64, trades []models.Trade) (*models.Ledger, error) {
+	ledger, err := LoadLedger()
+	if err != nil {
+		return nil, err
+	}
+	if ledger != nil {
+		return ledger, nil
+	}
+
+	openDate := time.Now()
+	for _, trade := range trades {
+		if !trade.CreatedAt.IsZero() && trade.CreatedAt.Before(openDate) {
+			openDate = trade.CreatedAt
+		}
+	}
+
+	ledger = models.NewLedger(startingBalance, openDate.Add(-time.Second))
+	ledger.SyncRealizedPnL(trades, time.Now())
+	if err := SaveLedger(ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// PostRealizedPnL posts realized P&L for closed trades and saves the ledger
+// if anything changed
+func PostRealizedPnL(ledger *models.Ledger, trades []models.Trade) error {
+	if ledger == nil {
+		return nil
+	}
+	if ledger.SyncRealizedPnL(trades, time.Now()) == 0 {
+		return nil
+	}
+	return SaveLedger(ledger)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+func TestLoadLedger_NoFileReturnsNil(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+	os.Remove(LedgerFile)
+
+	ledger, err := LoadLedger()
+	if err != nil {
+		t.Fatalf("LoadLedger failed: %v", err)
+	}
+	if ledger != nil {
+		t.Error("Expected nil ledger when no file exists")
+	}
+}
+
+func TestLoadOrCreateLedger_PostsExistingTrades(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+	defer os.Remove(LedgerFile)
+	os.Remove(LedgerFile)
+
+	pnl := 1250.0
+	created := time.Now().AddDate(0, -1, 0)
+	exit := time.Now().AddDate(0, 0, -3)
+	trades := []models.Trade{
+		{ID: "t1", Ticker: "UNH", CreatedAt: created, ProfitLoss: &pnl, ExitDate: &exit, Status: "closed"},
+	}
+
+	ledger, err := LoadOrCreateLedger(25000, trades)
+	if err != nil {
+		t.Fatalf("LoadOrCreateLedger failed: %v", err)
+	}
+	if ledger.Equity() != 26250 {
+		t.Errorf("Expected equity $26250, got $%.2f", ledger.Equity())
+	}
+	if !ledger.Entries[0].Date.Before(created) {
+		t.Error("Starting balance should be dated before the first trade")
+	}
+
+	// Reloading returns the saved ledger rather than creating a new one
+	reloaded, err := LoadOrCreateLedger(99999, trades)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reloaded.Equity() != 26250 {
+		t.Errorf("Expected reloaded equity $26250, got $%.2f", reloaded.Equity())
+	}
+}
+
+func TestPostRealizedPnL_SavesChanges(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+	defer os.Remove(LedgerFile)
+
+	ledger := models.NewLedger(25000, time.Now().AddDate(0, -1, 0))
+	pnl := -400.0
+	trades := []models.Trade{{ID: "t1", ProfitLoss: &pnl, Status: "closed", UpdatedAt: time.Now()}}
+
+	if err := PostRealizedPnL(ledger, trades); err != nil {
+		t.Fatalf("PostRealizedPnL failed: %v", err)
+	}
+
+	saved, err := LoadLedger()
+	if err != nil || saved == nil {
+		t.Fatalf("Failed to load saved ledger: %v", err)
+	}
+	if saved.Equity() != 24600 {
+		t.Errorf("Expected saved equity $24600, got $%.2f", saved.Equity())
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"tf-engine/internal/filelock"
+	"tf-engine/internal/logging"
+	"tf-engine/internal/paths"
+)
+
+var logger = logging.For(logging.Storage)
+
+// LockFile is locked around every read and write of the data directory so
+// other processes (a second instance, the CLI) can't interleave with us
+var LockFile = "data/.lock"
+
+// ErrReadOnly is returned by writes when another instance owns the data
+var ErrReadOnly = errors.New("data is open read-only because another instance is running")
+
+var readOnly atomic.Bool
+
+// SetReadOnly makes every write fail with ErrReadOnly (used when another
+// instance of the app already owns the data directory)
+func SetReadOnly(ro bool) {
+	if ro && !readOnly.Load() {
+		logger.Warn("Storage is read-only")
+	}
+	readOnly.Store(ro)
+}
+
+// ReadOnly reports whether writes are disabled
+func ReadOnly() bool {
+	return readOnly.Load()
+}
+
+// lock takes the in-process write lock and an exclusive lock on the data
+// directory. Call the returned function to release both.
+func (s *TradeStorage) lock() (func(), error) {
+	if ReadOnly() {
+		return nil, ErrReadOnly
+	}
+
+	s.mu.Lock()
+	fl, err := filelock.Exclusive(LockFile)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		fl.Unlock()
+		s.mu.Unlock()
+	}, nil
+}
+
+// rlock takes the in-process read lock and a shared lock on the data
+// directory, so reads never see another process's half-done update
+func (s *TradeStorage) rlock() (func(), error) {
+	s.mu.RLock()
+	fl, err := filelock.Shared(LockFile)
+	if err != nil {
+		s.mu.RUnlock()
+		return nil, err
+	}
+
+	return func() {
+		fl.Unlock()
+		s.mu.RUnlock()
+	}, nil
+}
+
+// initLock points at the data directory's lock file
+func initLock() {
+	LockFile = paths.Data(".lock")
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/filelock"
+	"tf-engine/internal/models"
+)
+
+func TestReadOnly_RefusesWrites(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	if err := SaveAllTrades([]models.Trade{{ID: "1", Ticker: "AAPL"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+
+	if err := SaveAllTrades(nil); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveAllTrades: expected ErrReadOnly, got %v", err)
+	}
+	if err := SaveInProgressTrade(&models.Trade{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("SaveInProgressTrade: expected ErrReadOnly, got %v", err)
+	}
+	if err := RecordTradeChange(audit.EventCreated, nil, &models.Trade{ID: "2"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("RecordTradeChange: expected ErrReadOnly, got %v", err)
+	}
+	if _, err := CreateProfile("Paper", ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateProfile: expected ErrReadOnly, got %v", err)
+	}
+
+	// Reads and switching profiles still work
+	trades, err := LoadAllTrades()
+	if err != nil || len(trades) != 1 {
+		t.Errorf("Reads should work read-only: %+v, %v", trades, err)
+	}
+	if _, err := SetActiveProfile(models.DefaultProfileID); err != nil {
+		t.Errorf("SetActiveProfile should work read-only: %v", err)
+	}
+}
+
+func TestTradeStore_UpdateWaitsForOtherProcess(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	// Another process holding the data lock (a separate open file conflicts
+	// the same way)
+	other, err := filelock.Exclusive(LockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := TradeStore{}.Update(func(trades []models.Trade) ([]models.Trade, error) {
+			return append(trades, models.Trade{ID: "1", Ticker: "AAPL"}), nil
+		})
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Update ran while another process held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	other.Unlock()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Update did not run after the lock was released")
+	}
+
+	trades, _ := LoadAllTrades()
+	if len(trades) != 1 {
+		t.Errorf("Expected the update to be saved, got %+v", trades)
+	}
+}
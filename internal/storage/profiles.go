@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tf-engine/internal/crypt"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+)
+
+// Profile storage locations in the data directory (set by InitProfiles)
+var (
+	ProfilesFile = "data/profiles.json"
+	ProfilesDir  = "data/profiles/"
+)
+
+// ProfilePaths holds the storage locations for one account profile
+type ProfilePaths struct {
+	Trades     string
+	InProgress string
+	Drafts     string
+	Backups    string
+	Ledger     string
+	Settings   string
+	Audit      string
+}
+
+// PathsForProfile returns the storage locations for a profile. The default
+// profile keeps the original data directory layout so existing installs need
+// no migration.
+func PathsForProfile(id string) ProfilePaths {
+	if id == "" || id == models.DefaultProfileID {
+		return ProfilePaths{
+			Trades:     paths.Data("trades.json"),
+			InProgress: paths.Data("trades_in_progress.json"),
+			Drafts:     paths.Data("drafts") + string(filepath.Separator),
+			Backups:    paths.Data("backups") + string(filepath.Separator),
+			Ledger:     paths.Data("ledger.json"),
+			Settings:   paths.Data("ui", "settings.json"),
+			Audit:      paths.Data("audit.jsonl"),
+		}
+	}
+
+	dir := paths.Data("profiles", id)
+	return ProfilePaths{
+		Trades:     filepath.Join(dir, "trades.json"),
+		InProgress: filepath.Join(dir, "trades_in_progress.json"),
+		Drafts:     filepath.Join(dir, "drafts") + string(filepath.Separator),
+		Backups:    filepath.Join(dir, "backups") + string(filepath.Separator),
+		Ledger:     filepath.Join(dir, "ledger.json"),
+		Settings:   filepath.Join(dir, "settings.json"),
+		Audit:      filepath.Join(dir, "audit.jsonl"),
+	}
+}
+
+// LoadProfiles loads the profile list. Returns the default profile alone if
+// no profiles have been created yet.
+func LoadProfiles() (*models.ProfileList, error) {
+	if !fileExists(ProfilesFile) {
+		return models.DefaultProfileList(), nil
+	}
+
+	data, err := os.ReadFile(ProfilesFile)
+	if err != nil {
+		return models.DefaultProfileList(), fmt.Errorf("read error: %w", err)
+	}
+
+	var list models.ProfileList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return models.DefaultProfileList(), fmt.Errorf("unmarshal error: %w", err)
+	}
+
+	if _, ok := list.Find(models.DefaultProfileID); !ok {
+		list.Profiles = append([]models.Profile{{ID: models.DefaultProfileID, Name: "Default"}}, list.Profiles...)
+	}
+
+	return &list, nil
+}
+
+// SaveProfiles saves the profile list atomically
+func SaveProfiles(list *models.ProfileList) error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return saveProfilesUnsafe(list)
+}
+
+// saveProfilesUnsafe saves the profile list without locking (internal use)
+func saveProfilesUnsafe(list *models.ProfileList) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	return writeFileDurable(ProfilesFile, data)
+}
+
+// InitProfiles points storage at the current data directory and the active
+// profile recorded there. Call it again after paths.Set.
+func InitProfiles() (models.Profile, error) {
+	ProfilesFile = paths.Data("profiles.json")
+	ProfilesDir = paths.Data("profiles") + string(filepath.Separator)
+	initEncryption()
+	initLock()
+
+	// Finish writes interrupted by a crash before reading anything. A
+	// read-only instance leaves them to the instance that owns the data.
+	var walErr error
+	if !ReadOnly() {
+		walErr = replayUnderLock()
+	}
+
+	list, err := LoadProfiles()
+	profile := list.ActiveProfile()
+	usePaths(PathsForProfile(profile.ID))
+	return profile, errors.Join(walErr, err)
+}
+
+// Snapshot is where storage points: the data directory's files, the active
+// profile's files and the key in use
+type Snapshot struct {
+	profilesFile, profilesDir  string
+	encryptionFile, recodeFile string
+	lockFile, walDir           string
+	profile                    ProfilePaths
+	key                        *crypt.Key
+}
+
+// TakeSnapshot records where storage points now, so a caller that switches
+// data directories (tests) can switch back without touching the disk
+func TakeSnapshot() Snapshot {
+	globalStorage.mu.RLock()
+	defer globalStorage.mu.RUnlock()
+
+	return Snapshot{
+		profilesFile:   ProfilesFile,
+		profilesDir:    ProfilesDir,
+		encryptionFile: EncryptionFile,
+		recodeFile:     RecodeFile,
+		lockFile:       LockFile,
+		walDir:         WALDir,
+		profile: ProfilePaths{
+			Trades:     TradesFile,
+			InProgress: InProgressFile,
+			Drafts:     DraftsDir,
+			Backups:    BackupDir,
+			Ledger:     LedgerFile,
+			Settings:   settingsFile,
+			Audit:      AuditFile,
+		},
+		key: currentKey(),
+	}
+}
+
+// Restore points storage back where it was when s was taken
+func (s Snapshot) Restore() {
+	ProfilesFile, ProfilesDir = s.profilesFile, s.profilesDir
+	EncryptionFile, RecodeFile = s.encryptionFile, s.recodeFile
+	LockFile, WALDir = s.lockFile, s.walDir
+	setKey(s.key)
+	usePaths(s.profile)
+}
+
+// replayUnderLock replays the WAL and finishes an interrupted recode while
+// holding the data directory lock
+func replayUnderLock() error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := initWAL(); err != nil {
+		return err
+	}
+
+	// A committed change of key is finished before anything is decrypted
+	resumed, err := resumeRecode()
+	if resumed {
+		logger.Info("Completed interrupted encryption change", "err", err)
+	}
+	return err
+}
+
+// SetActiveProfile points storage at the given profile and records it as the
+// active one for the next launch (unless storage is read-only)
+func SetActiveProfile(id string) (models.Profile, error) {
+	list, err := LoadProfiles()
+	if err != nil {
+		return models.Profile{}, err
+	}
+
+	profile, ok := list.Find(id)
+	if !ok {
+		return models.Profile{}, fmt.Errorf("profile %q not found", id)
+	}
+
+	if !ReadOnly() {
+		err := updateProfiles(func(list *models.ProfileList) error {
+			list.Active = profile.ID
+			return nil
+		})
+		if err != nil {
+			return models.Profile{}, err
+		}
+	}
+
+	usePaths(PathsForProfile(profile.ID))
+	return profile, nil
+}
+
+// CreateProfile adds a new profile. It does not switch to it.
+func CreateProfile(name, policyFile string) (models.Profile, error) {
+	var profile models.Profile
+	err := updateProfiles(func(list *models.ProfileList) error {
+		var err error
+		profile, err = list.Add(name, policyFile, time.Now())
+		return err
+	})
+	return profile, err
+}
+
+// updateProfiles changes the profile list under the storage lock so another
+// process can't save in between the load and the save
+func updateProfiles(change func(*models.ProfileList) error) error {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	list, err := LoadProfiles()
+	if err != nil {
+		return err
+	}
+	if err := change(list); err != nil {
+		return err
+	}
+	return saveProfilesUnsafe(list)
+}
+
+// LoadProfileTrades loads a profile's trade history without switching to it
+func LoadProfileTrades(id string) ([]models.Trade, error) {
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return loadTradesFile(PathsForProfile(id).Trades)
+}
+
+// LoadProfileLedger loads a profile's ledger without switching to it.
+// Returns nil if the profile has no ledger yet.
+func LoadProfileLedger(id string) (*models.Ledger, error) {
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return loadLedgerFile(PathsForProfile(id).Ledger)
+}
+
+// usePaths switches the package storage paths
+func usePaths(p ProfilePaths) {
+	globalStorage.mu.Lock()
+	defer globalStorage.mu.Unlock()
+
+	TradesFile = p.Trades
+	InProgressFile = p.InProgress
+	DraftsDir = p.Drafts
+	BackupDir = p.Backups
+	LedgerFile = p.Ledger
+	settingsFile = p.Settings
+	AuditFile = p.Audit
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"testing"
+
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+)
+
+func setupProfiles(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	t.Cleanup(cleanup)
+}
+
+func TestInitProfiles_DefaultsToLegacyPaths(t *testing.T) {
+	setupProfiles(t)
+
+	profile, err := InitProfiles()
+	if err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
+	if profile.ID != models.DefaultProfileID {
+		t.Errorf("Expected default profile, got %s", profile.ID)
+	}
+	if TradesFile != paths.Data("trades.json") {
+		t.Errorf("Default profile should use the top-level trades.json, got %s", TradesFile)
+	}
+}
+
+func TestSetActiveProfile_IsolatesTrades(t *testing.T) {
+	setupProfiles(t)
+
+	// Save a trade to the default profile
+	if err := SaveAllTrades([]models.Trade{{ID: "personal-1", Ticker: "AAPL"}}); err != nil {
+		t.Fatalf("SaveAllTrades failed: %v", err)
+	}
+
+	prop, err := CreateProfile("Prop Account", "")
+	if err != nil {
+		t.Fatalf("CreateProfile failed: %v", err)
+	}
+	if _, err := SetActiveProfile(prop.ID); err != nil {
+		t.Fatalf("SetActiveProfile failed: %v", err)
+	}
+
+	trades, err := LoadAllTrades()
+	if err != nil {
+		t.Fatalf("LoadAllTrades failed: %v", err)
+	}
+	if len(trades) != 0 {
+		t.Errorf("New profile should start with no trades, got %d", len(trades))
+	}
+
+	if err := SaveAllTrades([]models.Trade{{ID: "prop-1", Ticker: "ES"}}); err != nil {
+		t.Fatalf("SaveAllTrades failed: %v", err)
+	}
+
+	// The other profile is readable without switching
+	personal, err := LoadProfileTrades(models.DefaultProfileID)
+	if err != nil {
+		t.Fatalf("LoadProfileTrades failed: %v", err)
+	}
+	if len(personal) != 1 || personal[0].ID != "personal-1" {
+		t.Errorf("Default profile trades changed: %+v", personal)
+	}
+
+	// The active profile is remembered for the next launch
+	profile, _ := InitProfiles()
+	if profile.ID != prop.ID {
+		t.Errorf("Expected active profile %s after restart, got %s", prop.ID, profile.ID)
+	}
+}
+
+func TestSetActiveProfile_UnknownProfile(t *testing.T) {
+	setupProfiles(t)
+
+	if _, err := SetActiveProfile("missing"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if TradesFile != paths.Data("trades.json") {
+		t.Errorf("Paths should not change on failure, got %s", TradesFile)
+	}
+}
@@ -3,20 +3,20 @@ package storage
 import (
 	"encoding/json"
 	"os"
-	"path/filepath"
 
 	"tf-engine/internal/models"
 )
 
-const settingsFile = "data/ui/settings.json"
+// settingsFile holds the settings of the active profile
+var settingsFile = "data/ui/settings.json"
 
 // SaveSettings saves user settings to disk
 func SaveSettings(settings *models.Settings) error {
-	// Ensure directory exists
-	dir := filepath.Dir(settingsFile)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	unlock, err := globalStorage.lock()
+	if err != nil {
 		return err
 	}
+	defer unlock()
 
 	// Marshal settings to JSON
 	data, err := json.MarshalIndent(settings, "", "  ")
@@ -24,12 +24,18 @@ func SaveSettings(settings *models.Settings) error {
 		return err
 	}
 
-	// Write to file
-	return os.WriteFile(settingsFile, data, 0644)
+	// Write to file (encrypted when encryption is on)
+	return writeDataFile(settingsFile, data)
 }
 
 // LoadSettings loads user settings from disk
 func LoadSettings() (*models.Settings, error) {
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return models.DefaultSettings(), err
+	}
+	defer unlock()
+
 	// Check if file exists
 	if _, err := os.Stat(settingsFile); os.IsNotExist(err) {
 		// Return default settings if file doesn't exist
@@ -37,7 +43,7 @@ func LoadSettings() (*models.Settings, error) {
 	}
 
 	// Read file
-	data, err := os.ReadFile(settingsFile)
+	data, err := readDataFile(settingsFile)
 	if err != nil {
 		return models.DefaultSettings(), err
 	}
@@ -2,18 +2,18 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
+	"tf-engine/internal/audit"
 	"tf-engine/internal/models"
 )
 
-// Storage paths
-const (
+// Storage paths for the active profile (see SetActiveProfile)
+var (
 	TradesFile     = "data/trades.json"
 	InProgressFile = "data/trades_in_progress.json"
 	BackupDir      = "data/backups/"
@@ -28,8 +28,11 @@ var globalStorage = &TradeStorage{}
 
 // SaveInProgressTrade saves current trade state atomically
 func SaveInProgressTrade(trade *models.Trade) error {
-	globalStorage.mu.Lock()
-	defer globalStorage.mu.Unlock()
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	trade.UpdatedAt = time.Now()
 
@@ -38,36 +41,25 @@ func SaveInProgressTrade(trade *models.Trade) error {
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
-	// Ensure data directory exists
-	if err := os.MkdirAll("data", 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
-	}
-
 	// Write atomically (write to temp, then rename)
-	tmpFile := InProgressFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
-		return fmt.Errorf("write error: %w", err)
-	}
-
-	if err := os.Rename(tmpFile, InProgressFile); err != nil {
-		return fmt.Errorf("rename error: %w", err)
-	}
-
-	return nil
+	return writeDataFile(InProgressFile, data)
 }
 
 // LoadInProgressTrade loads incomplete trade
 func LoadInProgressTrade() (*models.Trade, error) {
-	globalStorage.mu.RLock()
-	defer globalStorage.mu.RUnlock()
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	if !fileExists(InProgressFile) {
 		return nil, nil // No in-progress trade
 	}
 
-	data, err := os.ReadFile(InProgressFile)
+	data, err := readDataFile(InProgressFile)
 	if err != nil {
-		return nil, fmt.Errorf("read error: %w", err)
+		return nil, err
 	}
 
 	var trade models.Trade
@@ -80,38 +72,53 @@ func LoadInProgressTrade() (*models.Trade, error) {
 
 // SaveCompletedTrade saves trade to history and creates backup
 func SaveCompletedTrade(trade *models.Trade) error {
-	globalStorage.mu.Lock()
-	defer globalStorage.mu.Unlock()
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	trade.UpdatedAt = time.Now()
+	if trade.ID == "" {
+		trade.ID = models.NewTradeID()
+	}
+	if trade.CreatedAt.IsZero() {
+		trade.CreatedAt = trade.UpdatedAt
+	}
 
-	// Load existing trades
+	// Load existing trades. Never replace a history that can't be read: a
+	// transient read error or a corrupt file would otherwise wipe it.
 	trades, err := loadAllTradesUnsafe()
 	if err != nil {
-		trades = []models.Trade{}
+		return fmt.Errorf("refusing to overwrite %s: %w", TradesFile, err)
+	}
+	if err := assignTradeIDsUnsafe(trades); err != nil {
+		return err
 	}
 
-	// Append new trade
-	trades = append(trades, *trade)
-
-	// Backup existing file before overwriting
-	if fileExists(TradesFile) {
-		if err := os.MkdirAll(BackupDir, 0755); err != nil {
-			return fmt.Errorf("failed to create backup directory: %w", err)
-		}
+	// Journal before writing so no trade is saved without an audit record,
+	// and revert the entry if the write fails
+	if err := RecordTradeChange(audit.EventCreated, nil, trade); err != nil {
+		return fmt.Errorf("audit journal error: %w", err)
+	}
+	if err := saveNewTradeUnsafe(append(trades, *trade)); err != nil {
+		return errors.Join(err, RevertTradeChanges(trades, append(trades, *trade)))
+	}
 
-		backupPath := filepath.Join(BackupDir,
-			fmt.Sprintf("trades_%s.json", time.Now().Format("20060102_150405")))
+	// The trade is no longer a draft (the trade is saved, so a leftover
+	// draft file is harmless)
+	os.Remove(InProgressFile)
+	deleteDraftUnsafe(trade.ID)
 
-		if err := copyFile(TradesFile, backupPath); err != nil {
-			// Log warning but don't fail
-			fmt.Printf("Warning: Failed to create backup: %v\n", err)
-		}
-	}
+	return nil
+}
 
-	// Ensure data directory exists
-	if err := os.MkdirAll("data", 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
+// saveNewTradeUnsafe backs up the trade file and writes the history with the
+// new trade (caller holds the lock)
+func saveNewTradeUnsafe(trades []models.Trade) error {
+	// Backup existing file before overwriting
+	if err := backupTradesFile(); err != nil {
+		return err
 	}
 
 	// Save to file atomically
@@ -119,38 +126,101 @@ func SaveCompletedTrade(trade *models.Trade) error {
 	if err != nil {
 		return fmt.Errorf("marshal error: %w", err)
 	}
+	return writeDataFile(TradesFile, data)
+}
 
-	tmpFile := TradesFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
-		return fmt.Errorf("write error: %w", err)
+// LoadAllTrades loads complete trade history. Trades saved before IDs were
+// assigned are given one the first time this runs.
+func LoadAllTrades() ([]models.Trade, error) {
+	unlock, err := globalStorage.rlock()
+	if err != nil {
+		return nil, err
+	}
+	trades, err := loadAllTradesUnsafe()
+	unlock()
+	if err != nil || ReadOnly() || !missingTradeIDs(trades) {
+		return trades, err
 	}
+	return migrateTradeIDs()
+}
 
-	if err := os.Rename(tmpFile, TradesFile); err != nil {
-		return fmt.Errorf("rename error: %w", err)
+// migrateTradeIDs assigns IDs to the trades saved without one
+func migrateTradeIDs() ([]models.Trade, error) {
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	// Clear in-progress file
-	os.Remove(InProgressFile)
+	trades, err := loadAllTradesUnsafe()
+	if err != nil {
+		return nil, err
+	}
+	if err := assignTradeIDsUnsafe(trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
 
+// assignTradeIDsUnsafe gives each trade without an ID a new one, journals it
+// as created and saves the history (caller holds the lock). The journal and
+// every lookup by ID rely on IDs being unique.
+func assignTradeIDsUnsafe(trades []models.Trade) error {
+	if !missingTradeIDs(trades) {
+		return nil
+	}
+	taken := map[string]bool{}
+	kept := []models.Trade{}
+	for _, t := range trades {
+		taken[t.ID] = true
+		if t.ID != "" {
+			kept = append(kept, t)
+		}
+	}
+	for i := range trades {
+		if trades[i].ID != "" {
+			continue
+		}
+		for trades[i].ID == "" || taken[trades[i].ID] {
+			trades[i].ID = models.NewTradeID()
+		}
+		taken[trades[i].ID] = true
+		if err := RecordTradeChange(audit.EventCreated, nil, &trades[i]); err != nil {
+			return fmt.Errorf("audit journal error: %w", err)
+		}
+	}
+
+	if err := saveAllTradesUnsafe(trades); err != nil {
+		return errors.Join(err, RevertTradeChanges(kept, trades))
+	}
+	logger.Info("Assigned IDs to trades saved without one", "trades", TradesFile)
 	return nil
 }
 
-// LoadAllTrades loads complete trade history
-func LoadAllTrades() ([]models.Trade, error) {
-	globalStorage.mu.RLock()
-	defer globalStorage.mu.RUnlock()
-	return loadAllTradesUnsafe()
+// missingTradeIDs reports whether any trade was saved without an ID
+func missingTradeIDs(trades []models.Trade) bool {
+	for _, t := range trades {
+		if t.ID == "" {
+			return true
+		}
+	}
+	return false
 }
 
 // loadAllTradesUnsafe loads trades without locking (internal use)
 func loadAllTradesUnsafe() ([]models.Trade, error) {
-	if !fileExists(TradesFile) {
+	return loadTradesFile(TradesFile)
+}
+
+// loadTradesFile reads a trade history file. A missing file is an empty history.
+func loadTradesFile(path string) ([]models.Trade, error) {
+	if !fileExists(path) {
 		return []models.Trade{}, nil
 	}
 
-	data, err := os.ReadFile(TradesFile)
+	data, err := readDataFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("read error: %w", err)
+		return nil, err
 	}
 
 	var trades []models.Trade
@@ -163,27 +233,27 @@ func loadAllTradesUnsafe() ([]models.Trade, error) {
 
 // SaveAllTrades saves the entire trade history (used for edit/delete operations)
 func SaveAllTrades(trades []models.Trade) error {
-	globalStorage.mu.Lock()
-	defer globalStorage.mu.Unlock()
-
-	// Backup existing file before overwriting
-	if fileExists(TradesFile) {
-		if err := os.MkdirAll(BackupDir, 0755); err != nil {
-			return fmt.Errorf("failed to create backup directory: %w", err)
-		}
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-		backupPath := filepath.Join(BackupDir,
-			fmt.Sprintf("trades_%s.json", time.Now().Format("20060102_150405")))
+	return saveAllTradesUnsafe(trades)
+}
 
-		if err := copyFile(TradesFile, backupPath); err != nil {
-			// Log warning but don't fail
-			fmt.Printf("Warning: Failed to create backup: %v\n", err)
-		}
+// saveAllTradesUnsafe backs up and replaces the trade history without
+// locking (internal use)
+func saveAllTradesUnsafe(trades []models.Trade) error {
+	// The caller loaded the history it is replacing; refuse if that file
+	// has since become unreadable
+	if _, err := loadAllTradesUnsafe(); err != nil {
+		return fmt.Errorf("refusing to overwrite %s: %w", TradesFile, err)
 	}
 
-	// Ensure data directory exists
-	if err := os.MkdirAll("data", 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
+	// Backup existing file before overwriting
+	if err := backupTradesFile(); err != nil {
+		return err
 	}
 
 	// Save to file atomically
@@ -192,22 +262,16 @@ func SaveAllTrades(trades []models.Trade) error {
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
-	tmpFile := TradesFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
-		return fmt.Errorf("write error: %w", err)
-	}
-
-	if err := os.Rename(tmpFile, TradesFile); err != nil {
-		return fmt.Errorf("rename error: %w", err)
-	}
-
-	return nil
+	return writeDataFile(TradesFile, data)
 }
 
 // DeleteInProgressTrade removes the in-progress trade file
 func DeleteInProgressTrade() error {
-	globalStorage.mu.Lock()
-	defer globalStorage.mu.Unlock()
+	unlock, err := globalStorage.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	if fileExists(InProgressFile) {
 		return os.Remove(InProgressFile)
@@ -221,20 +285,3 @@ func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
-
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, sourceFile)
-	return err
-}
@@ -7,37 +7,25 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
-	"time"
 
+	"tf-engine/internal/audit"
 	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
 )
 
 func setupTestDataDir(t *testing.T) func() {
-	// Create temp test directory
-	testDir := filepath.Join("testdata", fmt.Sprintf("test_%d", time.Now().UnixNano()))
-	os.MkdirAll(testDir, 0755)
-
-	// Override constants for testing
-	oldTradesFile := TradesFile
-	oldInProgressFile := InProgressFile
-	oldBackupDir := BackupDir
-
-	// Update to use test directory (via reflection or exported setters)
-	// For now, we'll use a simpler approach with relative paths
-	os.MkdirAll("data", 0755)
-	os.MkdirAll("data/backups", 0755)
+	// Point storage at a fresh temporary data directory
+	old := paths.Current()
+	saved := TakeSnapshot()
+	paths.Set(paths.Under(t.TempDir(), paths.ModeFlag))
+	if _, err := InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
 
 	// Return cleanup function
 	return func() {
-		os.RemoveAll(InProgressFile)
-		os.RemoveAll(TradesFile)
-		os.RemoveAll(BackupDir)
-		os.RemoveAll(testDir)
-
-		// Restore original values
-		_ = oldTradesFile
-		_ = oldInProgressFile
-		_ = oldBackupDir
+		paths.Set(old)
+		saved.Restore()
 	}
 }
 
@@ -186,7 +174,7 @@ func TestSaveCompletedTrade_CreatesBackup(t *testing.T) {
 		t.Error("Backup directory should exist")
 	}
 
-	backups, err := filepath.Glob(filepath.Join(BackupDir, "trades_*.json"))
+	backups, err := filepath.Glob(filepath.Join(BackupDir, "trades_*.zip"))
 	if err != nil {
 		t.Fatalf("Failed to glob backups: %v", err)
 	}
@@ -194,6 +182,74 @@ func TestSaveCompletedTrade_CreatesBackup(t *testing.T) {
 	if len(backups) == 0 {
 		t.Error("Expected at least one backup file")
 	}
+
+	// The backup holds the first trade and verifies
+	results, err := VerifyBackups()
+	if err != nil || len(results) == 0 {
+		t.Fatalf("VerifyBackups failed: %v", err)
+	}
+	if results[0].Err != nil || results[0].Trades != 1 {
+		t.Errorf("Expected a valid backup with 1 trade, got %+v", results[0])
+	}
+}
+
+func TestSaveCompletedTrade_SavesWhenPruneFails(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	if err := SaveCompletedTrade(&models.Trade{ID: "trade-1", Ticker: "UNH"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the archive is written, a file in place of the backup directory
+	// makes listing old backups fail
+	dir := filepath.Clean(BackupDir)
+	faultHook = func(step string) {
+		if step == stepBackupWrite {
+			os.RemoveAll(dir)
+			os.WriteFile(dir, []byte("not a directory"), 0644)
+		}
+	}
+	defer func() { faultHook = nil }()
+
+	if err := SaveCompletedTrade(&models.Trade{ID: "trade-2", Ticker: "MSFT"}); err != nil {
+		t.Fatalf("A prune failure should not stop the save: %v", err)
+	}
+	if trades, _ := LoadAllTrades(); len(trades) != 2 {
+		t.Errorf("Expected both trades saved, got %d", len(trades))
+	}
+}
+
+func TestDiffTrades(t *testing.T) {
+	pnl := 50.0
+	current := []models.Trade{
+		{ID: "1", Ticker: "AAPL", Status: "active"},
+		{ID: "2", Ticker: "MSFT", Status: "closed", ProfitLoss: &pnl},
+		{ID: "3", Ticker: "NEW", Status: "active"},
+	}
+	backup := []models.Trade{
+		{ID: "1", Ticker: "AAPL", Status: "active"},
+		{ID: "2", Ticker: "MSFT", Status: "active"},
+		{ID: "0", Ticker: "OLD", Status: "closed"},
+	}
+
+	diff, err := DiffTrades(current, backup)
+	if err != nil {
+		t.Fatalf("DiffTrades failed: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Ticker != "OLD" {
+		t.Errorf("Expected OLD to be added back, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Ticker != "NEW" {
+		t.Errorf("Expected NEW to be removed, got %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Status != "active" {
+		t.Errorf("Expected MSFT to revert to active, got %+v", diff.Changed)
+	}
+
+	if diff, _ := DiffTrades(current, current); !diff.Empty() {
+		t.Errorf("Identical histories should have an empty diff, got %+v", diff)
+	}
 }
 
 func TestSaveCompletedTrade_ClearsInProgress(t *testing.T) {
@@ -277,6 +333,48 @@ func TestLoadAllTrades_EmptyFile_ReturnsEmptySlice(t *testing.T) {
 	}
 }
 
+func TestLoadAllTrades_AssignsMissingIDs(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	// Trades saved before IDs were assigned
+	legacy := `[{"id":"","ticker":"AAPL"},{"ticker":"MSFT"},{"id":"T1","ticker":"XOM"}]`
+	os.MkdirAll(filepath.Dir(TradesFile), 0755)
+	os.WriteFile(TradesFile, []byte(legacy), 0644)
+
+	trades, err := LoadAllTrades()
+	if err != nil {
+		t.Fatalf("LoadAllTrades failed: %v", err)
+	}
+	if len(trades) != 3 || trades[0].ID == "" || trades[1].ID == "" || trades[0].ID == trades[1].ID || trades[2].ID != "T1" {
+		t.Fatalf("Expected unique IDs, got %+v", trades)
+	}
+
+	// The IDs are saved and journaled once
+	again, _ := LoadAllTrades()
+	if again[0].ID != trades[0].ID || again[1].ID != trades[1].ID {
+		t.Errorf("Expected the assigned IDs to be saved, got %+v", again)
+	}
+	events, _ := LoadAuditJournal()
+	if len(events) != 2 || events[0].Type != audit.EventCreated || events[0].TradeID != trades[0].ID || events[1].TradeID != trades[1].ID {
+		t.Errorf("Expected a creation for each migrated trade, got %+v", events)
+	}
+
+	// Edits now journal against the right trade
+	_, err = TradeStore{}.Update(func(trades []models.Trade) ([]models.Trade, error) {
+		edited := append([]models.Trade{}, trades...)
+		edited[1].Ticker = "NVDA"
+		return edited, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, _ = LoadAuditJournal()
+	if rebuilt, _ := audit.Rebuild(events); len(rebuilt) != 2 || rebuilt[0].Ticker != "AAPL" || rebuilt[1].Ticker != "NVDA" {
+		t.Errorf("Expected the journal to match the migrated trades, got %+v", rebuilt)
+	}
+}
+
 func TestDeleteInProgressTrade_RemovesFile(t *testing.T) {
 	cleanup := setupTestDataDir(t)
 	defer cleanup()
@@ -0,0 +1,71 @@
+package components
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/config"
+)
+
+// featureFlagRows describes each flag's effective value and the layer that
+// set it, one row per flag in name order
+func featureFlagRows(flags *config.FeatureFlags) [][]string {
+	rows := [][]string{}
+	if flags == nil {
+		return rows
+	}
+	for _, name := range flags.Names() {
+		flag := flags.Flags[name]
+		source := flags.Source(name)
+
+		value := "OFF"
+		if flag.Enabled {
+			value = "ON"
+		}
+		layer := source.Layer
+		if source.Detail != "" {
+			layer = fmt.Sprintf("%s (%s)", source.Layer, source.Detail)
+		}
+		rows = append(rows, []string{name, value, layer, source.Blocked, flag.Description})
+	}
+	return rows
+}
+
+// ShowFeatureFlagsDialog shows every flag's effective value, the layer that
+// set it and why it was forced off, if it was
+func ShowFeatureFlagsDialog(flags *config.FeatureFlags, window fyne.Window) {
+	header := []string{"Flag", "Value", "Set by", "Blocked", "Description"}
+	rows := featureFlagRows(flags)
+
+	table := widget.NewTable(
+		func() (int, int) { return len(rows) + 1, len(header) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			label := cell.(*widget.Label)
+			if id.Row == 0 {
+				label.SetText(header[id.Col])
+				label.TextStyle = fyne.TextStyle{Bold: true}
+			} else {
+				label.SetText(rows[id.Row-1][id.Col])
+				label.TextStyle = fyne.TextStyle{}
+			}
+			label.Refresh()
+		},
+	)
+	for col, width := range []float32{170, 60, 260, 220, 260} {
+		table.SetColumnWidth(col, width)
+	}
+
+	note := widget.NewLabel(fmt.Sprintf("Layers, lowest first: defaults, feature.flags.json, %s, %s* environment variables, --flag arguments.",
+		config.UserFlagsFile, config.EnvFlagPrefix))
+	note.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(note, nil, nil, nil, table)
+	d := dialog.NewCustom("Feature Flags", "Close", content, window)
+	d.Resize(fyne.NewSize(1000, 400))
+	d.Show()
+}
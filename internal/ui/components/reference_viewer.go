@@ -14,6 +14,8 @@ import (
 	"tf-engine/internal/logging"
 )
 
+var logger = logging.For(logging.UI)
+
 // ReferenceViewer displays pine script strategies and screener guides
 type ReferenceViewer struct {
 	window fyne.Window
@@ -92,7 +94,7 @@ func (rv *ReferenceViewer) ShowReference(refType string) {
 	// Read the file
 	content, err := rv.readFile(filePath)
 	if err != nil {
-		logging.ErrorLogger.Printf("Failed to read reference file %s: %v", filePath, err)
+		logger.Error("Failed to read reference file", "path", filePath, "err", err)
 		dialog.ShowError(fmt.Errorf("failed to load reference: %v", err), rv.window)
 		return
 	}
@@ -120,7 +122,7 @@ func (rv *ReferenceViewer) readFile(filePath string) (string, error) {
 	for _, loc := range locations {
 		content, err := os.ReadFile(loc)
 		if err == nil {
-			logging.DebugLogger.Printf("Found reference file at: %s", loc)
+			logger.Debug("Found reference file", "path", loc)
 			return string(content), nil
 		}
 		lastErr = err
@@ -0,0 +1,45 @@
+package components
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ToastDuration is how long a toast stays on screen
+const ToastDuration = 6 * time.Second
+
+// ShowToast shows a short message at the bottom of the window with an
+// optional action button (e.g. "Undo"). It hides itself after ToastDuration.
+func ShowToast(window fyne.Window, message, actionLabel string, action func()) {
+	if window == nil || window.Canvas() == nil {
+		return
+	}
+	c := window.Canvas()
+
+	var popup *widget.PopUp
+	content := container.NewHBox(widget.NewLabel(message))
+	if action != nil {
+		btn := widget.NewButton(actionLabel, func() {
+			popup.Hide()
+			action()
+		})
+		btn.Importance = widget.HighImportance
+		content.Add(btn)
+	}
+
+	popup = widget.NewPopUp(content, c)
+	size := popup.MinSize()
+	canvasSize := c.Size()
+	popup.ShowAtPosition(fyne.NewPos(
+		(canvasSize.Width-size.Width)/2,
+		canvasSize.Height-size.Height-theme.Padding()*4,
+	))
+
+	time.AfterFunc(ToastDuration, func() {
+		fyne.Do(popup.Hide)
+	})
+}
@@ -1,33 +1,43 @@
 package components
 
 import (
+	"fmt"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/storage"
 )
 
+// NavButton is a top bar button that opens a screen
+type NavButton struct {
+	Label string
+	OnTap func()
+}
+
 // TopBar provides quick navigation to key screens and reference materials
 type TopBar struct {
 	state         *appcore.AppState
 	window        fyne.Window
-	onSettings    func()
-	onCalendar    func()
-	onHome        func()
+	nav           []NavButton
 	onReferences  func(refType string)
 	onThemeToggle func()
 	themeButton   *widget.Button // Store reference to update text
+
+	onSwitchProfile func(id string)
+	onConsolidated  func()
 }
 
-// NewTopBar creates a new top bar navigation component
-func NewTopBar(state *appcore.AppState, window fyne.Window, onSettings, onCalendar, onHome func(), onReferences func(refType string), onThemeToggle func()) *TopBar {
+// NewTopBar creates a new top bar navigation component. nav lists the
+// screen buttons shown first, in order.
+func NewTopBar(state *appcore.AppState, window fyne.Window, nav []NavButton, onReferences func(refType string), onThemeToggle func()) *TopBar {
 	return &TopBar{
 		state:         state,
 		window:        window,
-		onSettings:    onSettings,
-		onCalendar:    onCalendar,
-		onHome:        onHome,
+		nav:           nav,
 		onReferences:  onReferences,
 		onThemeToggle: onThemeToggle,
 	}
@@ -35,26 +45,11 @@ func NewTopBar(state *appcore.AppState, window fyne.Window, onSettings, onCalend
 
 // Render creates the top bar UI
 func (t *TopBar) Render() fyne.CanvasObject {
-	// Home/Dashboard button
-	homeBtn := widget.NewButton("🏠 Home", func() {
-		if t.onHome != nil {
-			t.onHome()
-		}
-	})
-
-	// Settings button
-	settingsBtn := widget.NewButton("⚙️ Settings", func() {
-		if t.onSettings != nil {
-			t.onSettings()
-		}
-	})
-
-	// Calendar button
-	calendarBtn := widget.NewButton("📅 Calendar", func() {
-		if t.onCalendar != nil {
-			t.onCalendar()
-		}
-	})
+	// Screen buttons (Home, Settings, Calendar...)
+	items := []fyne.CanvasObject{}
+	for _, b := range t.nav {
+		items = append(items, widget.NewButton(b.Label, b.OnTap))
+	}
 
 	// Day/Night mode toggle
 	themeBtn := widget.NewButton("🌙 Night Mode", func() {
@@ -153,30 +148,112 @@ func (t *TopBar) Render() fyne.CanvasObject {
 	screenersPopup := widget.NewPopUpMenu(screenersMenu, t.window.Canvas())
 
 	// Reference buttons that show popup menus
-	strategiesBtn := widget.NewButton("Pine Scripts ▾", func() {
-		strategiesPopup.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(settingsBtn))
+	var strategiesBtn, screenersBtn *widget.Button
+	strategiesBtn = widget.NewButton("Pine Scripts ▾", func() {
+		strategiesPopup.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(strategiesBtn))
 	})
 
-	screenersBtn := widget.NewButton("Screeners ▾", func() {
-		screenersPopup.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(settingsBtn))
+	screenersBtn = widget.NewButton("Screeners ▾", func() {
+		screenersPopup.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(screenersBtn))
+	})
+
+	// Account profile switcher
+	var profileBtn *widget.Button
+	profileBtn = widget.NewButton(t.profileButtonText(), func() {
+		popup := widget.NewPopUpMenu(t.profileMenu(), t.window.Canvas())
+		popup.ShowAtPosition(fyne.CurrentApp().Driver().AbsolutePositionForObject(profileBtn))
 	})
 
 	// Spacer to push everything to the left
 	spacer := widget.NewLabel("")
 
 	// Create horizontal container with buttons
-	topBar := container.NewHBox(
-		homeBtn,
-		settingsBtn,
-		calendarBtn,
+	items = append(items,
 		widget.NewSeparator(),
 		strategiesBtn,
 		screenersBtn,
 		spacer,
+		profileBtn,
 		themeBtn,
 	)
 
-	return topBar
+	return container.NewHBox(items...)
+}
+
+// SetProfileCallbacks sets the profile switch and consolidated analytics
+// callbacks. A nil onConsolidated hides the All Profiles menu item.
+func (t *TopBar) SetProfileCallbacks(onSwitch func(id string), onConsolidated func()) {
+	t.onSwitchProfile = onSwitch
+	t.onConsolidated = onConsolidated
+}
+
+// profileButtonText shows the active profile name
+func (t *TopBar) profileButtonText() string {
+	name := "Default"
+	if t.state != nil && t.state.Profile.Name != "" {
+		name = t.state.Profile.Name
+	}
+	return fmt.Sprintf("👤 %s ▾", name)
+}
+
+// profileMenu lists the profiles with the active one checked
+func (t *TopBar) profileMenu() *fyne.Menu {
+	items := []*fyne.MenuItem{}
+
+	list, err := storage.LoadProfiles()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load profiles: %w", err), t.window)
+	}
+	for _, profile := range list.Profiles {
+		id := profile.ID
+		item := fyne.NewMenuItem(profile.Name, func() {
+			if t.onSwitchProfile != nil {
+				t.onSwitchProfile(id)
+			}
+		})
+		item.Checked = t.state != nil && id == t.state.Profile.ID
+		items = append(items, item)
+	}
+
+	items = append(items,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("➕ New Profile...", t.showNewProfileDialog),
+	)
+	if t.onConsolidated != nil {
+		items = append(items, fyne.NewMenuItem("📊 All Profiles (read-only)", t.onConsolidated))
+	}
+
+	return fyne.NewMenu("Profiles", items...)
+}
+
+// showNewProfileDialog creates a profile and switches to it
+func (t *TopBar) showNewProfileDialog() {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g., Prop Account")
+
+	policyEntry := widget.NewEntry()
+	policyEntry.SetPlaceHolder("Optional, e.g., data/policy.prop.json")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Policy Override", policyEntry),
+	}
+
+	dialog.ShowForm("New Profile", "Create", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		profile, err := storage.CreateProfile(nameEntry.Text, policyEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, t.window)
+			return
+		}
+
+		if t.onSwitchProfile != nil {
+			t.onSwitchProfile(profile.ID)
+		}
+	}, t.window)
 }
 
 // SetThemeToggleCallback sets the theme toggle callback (called from main after creation)
@@ -2,17 +2,22 @@ package ui
 
 import (
 	"fmt"
+	"image/color"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
+	"tf-engine/internal/propfirm"
 	"tf-engine/internal/storage"
 	"tf-engine/internal/testing/generators"
 	"tf-engine/internal/ui/help"
-	"tf-engine/internal/ui/screens"
+	"tf-engine/internal/workflow"
 )
 
 // Dashboard represents the main dashboard screen
@@ -31,6 +36,16 @@ func NewDashboard(state *appcore.AppState, window fyne.Window, navigator *Naviga
 	}
 }
 
+// Validate always passes: the dashboard has no input
+func (d *Dashboard) Validate() bool {
+	return true
+}
+
+// GetName returns the screen name
+func (d *Dashboard) GetName() string {
+	return "dashboard"
+}
+
 // Render renders the dashboard UI
 func (d *Dashboard) Render() fyne.CanvasObject {
 	title := widget.NewLabel("TF-Engine 2.0 - Dashboard")
@@ -38,31 +53,26 @@ func (d *Dashboard) Render() fyne.CanvasObject {
 
 	startButton := widget.NewButton("Start New Trade", func() {
 		if d.navigator != nil {
-			d.navigator.NavigateToScreen(0) // Navigate to Sector Selection
+			d.navigator.StartNewTrade() // Other drafts are kept
 		}
 	})
 
-	resumeButton := widget.NewButton("Resume Session", func() {
-		// TODO: Resume from saved progress
-	})
-
-	calendarButton := widget.NewButton("View Calendar", func() {
-		if d.navigator != nil {
-			d.navigator.JumpToCalendar()
-		}
-	})
-
-	// Trade Management button (Phase 2 feature)
-	manageTradesButton := widget.NewButton("Manage Trades", func() {
-		if d.navigator != nil {
-			d.navigator.JumpToTradeManagement()
+	// Screen buttons from the registry: core screens first, Phase 2 screens
+	// below. Screens whose feature flag is off are left out.
+	coreButtons := container.NewVBox()
+	phase2Buttons := container.NewVBox()
+	for _, e := range DefaultRegistry().Dashboard(d.state.FeatureFlags) {
+		id := e.ID
+		button := widget.NewButton(e.Dashboard.Label, func() {
+			if d.navigator != nil {
+				d.navigator.Open(id)
+			}
+		})
+		if e.Flag == "" {
+			coreButtons.Add(button)
+		} else {
+			phase2Buttons.Add(button)
 		}
-	})
-
-	// Check if Trade Management feature is enabled
-	manageTradesEnabled := d.state.FeatureFlags != nil && d.state.FeatureFlags.IsEnabled("trade_management")
-	if !manageTradesEnabled {
-		manageTradesButton.Disable()
 	}
 
 	// Sample Data Generator button (Phase 2 feature)
@@ -76,19 +86,6 @@ func (d *Dashboard) Render() fyne.CanvasObject {
 		sampleDataButton.Disable()
 	}
 
-	// Advanced Analytics button (Phase 2 feature)
-	analyticsButton := widget.NewButton("📊 View Analytics", func() {
-		if d.navigator != nil {
-			d.navigator.JumpToAnalytics()
-		}
-	})
-
-	// Check if Advanced Analytics feature is enabled
-	analyticsEnabled := d.state.FeatureFlags != nil && d.state.FeatureFlags.IsEnabled("advanced_analytics")
-	if !analyticsEnabled {
-		analyticsButton.Disable()
-	}
-
 	// Phase 2 features label
 	phase2Label := widget.NewLabel("Phase 2 Features:")
 	phase2Label.TextStyle = fyne.TextStyle{Italic: true}
@@ -103,18 +100,14 @@ func (d *Dashboard) Render() fyne.CanvasObject {
 	}
 
 	helpButton := widget.NewButton("Help", func() {
-		help.ShowHelpDialog("welcome", d.window)
-	})
-
-	settingsButton := widget.NewButton("⚙️ Settings", func() {
-		d.showSettings()
+		help.ShowContent(DefaultRegistry().Help(workflow.Dashboard, d.state.FeatureFlags), d.window)
 	})
 
 	// Account info (from Settings)
 	accountEquity := 25000.0
 	riskPercent := 2.8
 	if d.state.Settings != nil {
-		accountEquity = d.state.Settings.AccountEquity
+		accountEquity = d.state.CurrentEquity()
 		riskPercent = d.state.Settings.RiskPerTrade * 100
 	}
 	accountInfo := widget.NewLabel(fmt.Sprintf("Account Equity: $%.0f\nRisk per Trade: %.2f%%",
@@ -127,15 +120,13 @@ func (d *Dashboard) Render() fyne.CanvasObject {
 		title,
 		widget.NewSeparator(),
 		startButton,
-		resumeButton,
-		calendarButton,
-		settingsButton,
+		d.createDraftsCard(),
+		coreButtons,
 		helpButton,
 		widget.NewSeparator(),
 		phase2Label,
-		manageTradesButton,
+		phase2Buttons,
 		sampleDataButton,
-		analyticsButton,
 		vimModeButton,
 		widget.NewSeparator(),
 		widget.NewLabel("Account Settings"),
@@ -145,9 +136,132 @@ func (d *Dashboard) Render() fyne.CanvasObject {
 		heatInfo,
 	)
 
+	// Prop firm rules status (only when rules are enabled in Settings)
+	if d.state.Settings != nil && d.state.Settings.PropFirm.Enabled {
+		content.Add(widget.NewSeparator())
+		content.Add(d.createPropFirmCard())
+	}
+
 	return container.NewPadded(content)
 }
 
+// createDraftsCard lists the trades in progress, each resumable at its own
+// workflow step
+func (d *Dashboard) createDraftsCard() fyne.CanvasObject {
+	drafts, err := storage.LoadDrafts()
+	if err != nil {
+		return widget.NewLabel("⚠️ Failed to load drafts: " + err.Error())
+	}
+	if len(drafts) == 0 {
+		return container.NewVBox()
+	}
+
+	title := widget.NewLabel(fmt.Sprintf("Trades in Progress (%d)", len(drafts)))
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	rows := container.NewVBox(title)
+
+	for i := range drafts {
+		draft := drafts[i]
+		rows.Add(d.createDraftRow(&draft))
+	}
+	return rows
+}
+
+// createDraftRow shows one draft with resume and discard buttons
+func (d *Dashboard) createDraftRow(draft *models.Draft) fyne.CanvasObject {
+	detail := fmt.Sprintf("%s • %s • updated %s",
+		draft.Title(), StepTitle(draft.Step), draft.Trade.UpdatedAt.Format("Jan 2 15:04"))
+	if cooldown := draftCooldown(d.state, &draft.Trade); cooldown != "" {
+		detail += " • " + cooldown
+	}
+	if d.state.CurrentTrade != nil && d.state.CurrentTrade.ID == draft.ID() {
+		detail = "▶ " + detail
+	}
+
+	id := draft.ID()
+	resumeBtn := widget.NewButton("Resume", func() {
+		if d.navigator == nil {
+			return
+		}
+		if err := d.navigator.ResumeDraft(id); err != nil {
+			dialog.ShowError(err, d.window)
+		}
+	})
+	resumeBtn.Importance = widget.HighImportance
+
+	discardBtn := widget.NewButton("Discard", func() {
+		dialog.ShowConfirm("Discard Draft?",
+			fmt.Sprintf("Discard the %s draft? Its checklist and sizing progress will be lost.", draft.Title()),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := d.state.DiscardDraft(id); err != nil {
+					dialog.ShowError(err, d.window)
+					return
+				}
+				if d.navigator != nil {
+					d.navigator.NavigateToDashboard()
+				}
+			}, d.window)
+	})
+
+	return container.NewBorder(nil, nil, nil,
+		container.NewHBox(discardBtn, resumeBtn),
+		widget.NewLabel(detail))
+}
+
+// draftCooldown describes a draft's own cooldown ("" if not started)
+func draftCooldown(state *appcore.AppState, trade *models.Trade) string {
+	if trade.CooldownStartTime.IsZero() {
+		return ""
+	}
+
+	remaining := state.CooldownPeriod() - time.Since(trade.CooldownStartTime)
+	if remaining <= 0 {
+		return "cooldown done"
+	}
+	return fmt.Sprintf("cooldown %d:%02d left", int(remaining.Minutes()), int(remaining.Seconds())%60)
+}
+
+// createPropFirmCard creates the prop firm rules status card
+func (d *Dashboard) createPropFirmCard() fyne.CanvasObject {
+	status := propfirm.Evaluate(d.state.Settings.PropFirm, d.state.AllTrades, time.Now())
+
+	title := widget.NewLabel("Prop Firm Rules")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+
+	var headline string
+	var bgColor color.Color
+	switch {
+	case status.Breached():
+		headline = "❌ Limit breached - new trades are blocked"
+		bgColor = color.RGBA{R: 255, G: 200, B: 200, A: 255}
+	case status.Passed():
+		headline = "✓ Evaluation targets met"
+		bgColor = color.RGBA{R: 200, G: 240, B: 200, A: 255}
+	default:
+		headline = "✓ Within all limits"
+		bgColor = color.RGBA{R: 240, G: 248, B: 255, A: 255}
+	}
+	headlineLabel := widget.NewLabel(headline)
+	headlineLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	rows := container.NewVBox(title, headlineLabel)
+	for _, rule := range status.RuleStatuses {
+		icon := "•"
+		if rule.Breached {
+			icon = "❌"
+		} else if rule.Met {
+			icon = "✓"
+		}
+		rows.Add(widget.NewLabel(fmt.Sprintf("%s %s: %s", icon, rule.Label, rule.Detail)))
+	}
+
+	bg := canvas.NewRectangle(bgColor)
+	return container.NewStack(bg, container.NewPadded(rows))
+}
+
 // generateSampleData generates sample trades and saves them
 func (d *Dashboard) generateSampleData() {
 	// Confirm with user
@@ -194,16 +308,3 @@ func (d *Dashboard) generateSampleData() {
 		d.window,
 	)
 }
-
-// showSettings displays the settings screen
-func (d *Dashboard) showSettings() {
-	settingsScreen := screens.NewSettings(d.state, d.window)
-
-	// Set back callback to return to dashboard
-	settingsScreen.SetBackCallback(func() {
-		// Refresh dashboard to show updated settings
-		d.window.SetContent(d.Render())
-	})
-
-	d.window.SetContent(settingsScreen.Render())
-}
@@ -202,7 +202,11 @@ func GetHelpForScreen(screenName string) HelpContent {
 
 // ShowHelpDialog displays a help dialog for the current screen
 func ShowHelpDialog(screenName string, window fyne.Window) {
-	help := GetHelpForScreen(screenName)
+	ShowContent(GetHelpForScreen(screenName), window)
+}
+
+// ShowContent displays help content in a dialog
+func ShowContent(help HelpContent, window fyne.Window) {
 
 	// Title
 	title := widget.NewLabel(help.Title)
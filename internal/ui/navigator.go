@@ -3,20 +3,26 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
 
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/logging"
+	"tf-engine/internal/propfirm"
 	"tf-engine/internal/storage"
 	"tf-engine/internal/ui/components"
 	"tf-engine/internal/ui/help"
-	"tf-engine/internal/ui/screens"
 	"tf-engine/internal/ui/vimium"
+	"tf-engine/internal/workflow"
 )
 
+var logger = logging.For(logging.UI)
+
 // Screen represents a single screen in the workflow
 type Screen interface {
 	Render() fyne.CanvasObject
@@ -24,11 +30,25 @@ type Screen interface {
 	GetName() string
 }
 
-// Navigator manages screen transitions and workflow state
+// StepTitle returns the display name of a workflow step for draft lists
+func StepTitle(step string) string {
+	registry := DefaultRegistry()
+	if e, ok := registry.Lookup(workflow.State(step)); ok && e.Position > 0 {
+		return e.Title
+	}
+	e, _ := registry.Lookup(workflow.SectorSelection)
+	return e.Title
+}
+
+// Navigator manages screen transitions and workflow state. Which moves are
+// legal, and what must be true to make them, is decided by the workflow
+// state machine; the navigator renders screens and keeps the Back history.
 type Navigator struct {
-	screens         []Screen
-	currentIndex    int
-	history         []int
+	registry        Registry
+	screens         map[workflow.State]Screen
+	machine         *workflow.Machine
+	current         workflow.State
+	history         []workflow.State
 	state           *appcore.AppState
 	window          fyne.Window
 	topBar          *components.TopBar
@@ -36,14 +56,17 @@ type Navigator struct {
 	vimiumManager   *vimium.VimiumManager
 }
 
-// NewNavigator creates a new navigator with all 9 screens (8 workflow + 1 management)
+// NewNavigator creates a new navigator with the screens in the registry
+// whose feature flags are on
 func NewNavigator(state *appcore.AppState, window fyne.Window) *Navigator {
 	nav := &Navigator{
-		currentIndex: -1, // -1 = dashboard
-		history:      []int{},
-		state:        state,
-		window:       window,
+		registry: DefaultRegistry(),
+		current:  workflow.Dashboard,
+		history:  []workflow.State{},
+		state:    state,
+		window:   window,
 	}
+	nav.machine = nav.newMachine()
 
 	// Initialize reference viewer
 	nav.referenceViewer = components.NewReferenceViewer(window)
@@ -57,37 +80,117 @@ func NewNavigator(state *appcore.AppState, window fyne.Window) *Navigator {
 		func() { nav.ShowHelp() },            // Help
 	)
 
-	// Initialize top bar with navigation callbacks
+	// Initialize top bar with the registry's entries
 	nav.topBar = components.NewTopBar(
 		state,
 		window,
-		nav.NavigateToSettings,
-		nav.JumpToCalendar,
-		nav.NavigateToDashboard,
+		nav.topBarButtons(),
 		nav.ShowReference,
 		nil, // Theme toggle will be set by main
 	)
+	var onConsolidated func()
+	if nav.IsAvailable(workflow.ConsolidatedAnalytics) {
+		onConsolidated = nav.ShowConsolidatedAnalytics
+	}
+	nav.topBar.SetProfileCallbacks(nav.SwitchProfile, onConsolidated)
 
-	// Initialize all screens (8 workflow screens + 2 Phase 2 screens)
-	nav.screens = []Screen{
-		screens.NewSectorSelection(state, window),
-		screens.NewScreenerLaunch(state, window),
-		screens.NewTickerEntry(state, window),
-		screens.NewChecklist(state, window),
-		screens.NewPositionSizing(state, window),
-		screens.NewHeatCheck(state, window),
-		screens.NewTradeEntry(state, window),
-		screens.NewCalendarWithFlags(state, window, state.FeatureFlags, nav), // Pass feature flags and navigator
-		screens.NewTradeManagement(state, window, state.FeatureFlags),        // Screen 9: Phase 2 feature
-		screens.NewAnalytics(state, window, state.FeatureFlags),              // Screen 10: Phase 2 feature
+	// Screens rebuilt on every visit are created when rendered
+	nav.screens = map[workflow.State]Screen{}
+	for _, e := range nav.registry.Enabled(state.FeatureFlags) {
+		if !e.Rebuild {
+			nav.screens[e.ID] = e.New(nav)
+		}
 	}
 
 	// Set navigation callbacks on screens that support them
 	nav.initializeCallbacks()
 
+	// Ctrl+Z / Ctrl+Y undo and redo trade changes
+	nav.registerUndoShortcuts()
+
 	return nav
 }
 
+// newMachine builds the workflow with auto-save as its side effect.
+// Screens whose feature flag is off are removed from it.
+func (n *Navigator) newMachine() *workflow.Machine {
+	m := workflow.Default()
+	for _, e := range n.registry {
+		if !e.Enabled(n.state.FeatureFlags) {
+			m.Remove(e.ID)
+		}
+	}
+	m.OnMove(func(move workflow.Move) error {
+		if err := n.autoSaveAt(move.To, move.History); err != nil {
+			return fmt.Errorf("auto-save failed: %w", err)
+		}
+		return nil
+	})
+	return m
+}
+
+// topBarButtons builds the top bar's navigation buttons from the registry
+func (n *Navigator) topBarButtons() []components.NavButton {
+	buttons := []components.NavButton{}
+	for _, e := range n.registry.TopBar(n.state.FeatureFlags) {
+		id := e.ID
+		buttons = append(buttons, components.NavButton{Label: e.TopBar.Label, OnTap: func() { n.Open(id) }})
+	}
+	return buttons
+}
+
+// IsAvailable reports whether a screen is registered and its feature flag is on
+func (n *Navigator) IsAvailable(id workflow.State) bool {
+	e, ok := n.registry.Lookup(id)
+	return ok && e.Enabled(n.state.FeatureFlags)
+}
+
+// facts gathers what the workflow guards check
+func (n *Navigator) facts() workflow.Facts {
+	return workflow.Facts{
+		Trade:          n.state.CurrentTrade,
+		CooldownPeriod: n.state.CooldownPeriod(),
+		Now:            time.Now(),
+		TradingAllowed: n.checkPropFirmRules(),
+	}
+}
+
+// registerUndoShortcuts binds the undo/redo keyboard shortcuts
+func (n *Navigator) registerUndoShortcuts() {
+	if n.window == nil || n.window.Canvas() == nil {
+		return
+	}
+	c := n.window.Canvas()
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault}, func(fyne.Shortcut) {
+		n.Undo()
+	})
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyY, Modifier: fyne.KeyModifierShortcutDefault}, func(fyne.Shortcut) {
+		n.Redo()
+	})
+}
+
+// Undo reverts the last trade change and refreshes the current screen
+func (n *Navigator) Undo() {
+	cmd, err := n.state.UndoTradeCommand()
+	if err != nil {
+		components.ShowToast(n.window, err.Error(), "", nil)
+		return
+	}
+	n.RefreshCurrentScreen()
+	components.ShowToast(n.window, "↶ Undid "+cmd.Name(), "Redo", n.Redo)
+}
+
+// Redo re-applies the last undone trade change and refreshes the current screen
+func (n *Navigator) Redo() {
+	cmd, err := n.state.RedoTradeCommand()
+	if err != nil {
+		components.ShowToast(n.window, err.Error(), "", nil)
+		return
+	}
+	n.RefreshCurrentScreen()
+	components.ShowToast(n.window, "↷ Redid "+cmd.Name(), "Undo", n.Undo)
+}
+
 // initializeCallbacks sets navigation callbacks on all screens
 func (n *Navigator) initializeCallbacks() {
 	for _, screen := range n.screens {
@@ -100,36 +203,35 @@ func (n *Navigator) initializeCallbacks() {
 	}
 }
 
-// Next navigates to the next screen in the workflow
+// Next navigates to the next step in the workflow. From the dashboard it
+// starts at sector selection; nothing follows trade entry.
 func (n *Navigator) Next() error {
 	// Validate current screen before proceeding
-	if n.currentIndex >= 0 && n.currentIndex < len(n.screens) {
-		if !n.screens[n.currentIndex].Validate() {
-			return errors.New("current screen validation failed")
-		}
+	if !n.ValidateCurrentScreen() {
+		return errors.New("current screen validation failed")
 	}
 
-	// Auto-save before navigation
-	if err := n.AutoSave(); err != nil {
-		return fmt.Errorf("auto-save failed: %w", err)
+	next := workflow.Steps[0]
+	if n.current != workflow.Dashboard {
+		var ok bool
+		if next, ok = workflow.Next(n.current); !ok {
+			return fmt.Errorf("no step after %s", n.current)
+		}
 	}
 
-	// Record history for back button
-	n.history = append(n.history, n.currentIndex)
+	return n.GoTo(next)
+}
 
-	// Move to next screen
-	n.currentIndex++
-	if n.currentIndex >= len(n.screens) {
-		return errors.New("no more screens")
+// GoTo moves to state if the workflow allows it, auto-saving on the way.
+// A blocked move is shown to the user and returned.
+func (n *Navigator) GoTo(to workflow.State) error {
+	history := append(append([]workflow.State{}, n.history...), n.current)
+	if err := n.fire(to, history); err != nil {
+		return err
 	}
 
-	// Update state
-	n.state.CurrentScreen = n.GetCurrentScreenName()
-
-	// Render new screen with top bar
-	content := n.screens[n.currentIndex].Render()
-	n.setContent(n.wrapWithTopBar(content))
-
+	n.history = history
+	n.enter(to)
 	return nil
 }
 
@@ -139,27 +241,57 @@ func (n *Navigator) Back() error {
 		return errors.New("no previous screen")
 	}
 
-	// Auto-save before navigation
-	if err := n.AutoSave(); err != nil {
-		return fmt.Errorf("auto-save failed: %w", err)
+	to := n.history[len(n.history)-1]
+	history := n.history[:len(n.history)-1]
+	if err := n.fire(to, history); err != nil {
+		return err
+	}
+
+	if to == workflow.Dashboard {
+		n.NavigateToDashboard()
+		return nil
 	}
+	n.history = history
+	n.enter(to)
+	return nil
+}
 
-	// Pop from history
-	n.currentIndex = n.history[len(n.history)-1]
-	n.history = n.history[:len(n.history)-1]
+// fire runs the move from the current state through the workflow machine
+func (n *Navigator) fire(to workflow.State, history []workflow.State) error {
+	err := n.machine.Fire(workflow.Move{From: n.current, To: to, History: history}, n.facts())
+	var guardErr *workflow.GuardError
+	if errors.As(err, &guardErr) {
+		n.showError(guardErr.Err)
+	}
+	return err
+}
 
-	// Update state
-	n.state.CurrentScreen = n.GetCurrentScreenName()
+// enter makes state current and renders it
+func (n *Navigator) enter(to workflow.State) {
+	n.current = to
+	n.state.CurrentScreen = string(to)
+	n.render()
+}
 
-	// Render previous screen
-	if n.currentIndex == -1 {
-		n.NavigateToDashboard()
-	} else {
-		content := n.screens[n.currentIndex].Render()
-		n.setContent(n.wrapWithTopBar(content))
+// render shows the current screen with the top bar
+func (n *Navigator) render() {
+	screen, ok := n.screens[n.current]
+	if !ok {
+		e, registered := n.registry.Lookup(n.current)
+		if !registered || !e.Rebuild || !e.Enabled(n.state.FeatureFlags) {
+			return
+		}
+		screen = e.New(n)
 	}
+	n.setContent(n.wrapWithTopBar(screen.Render()))
+}
 
-	return nil
+// showError reports a blocked move, when there is a window to show it in
+func (n *Navigator) showError(err error) {
+	if n.window == nil || n.window.Canvas() == nil {
+		return
+	}
+	dialog.ShowError(err, n.window)
 }
 
 // Cancel prompts for confirmation and returns to dashboard
@@ -177,123 +309,199 @@ func (n *Navigator) Cancel() {
 	)
 }
 
-// JumpToCalendar navigates directly to calendar view (read-only mode)
-func (n *Navigator) JumpToCalendar() {
-	// Auto-save current progress
-	n.AutoSave()
-
-	// Remember where we came from
-	n.history = append(n.history, n.currentIndex)
-
-	// Jump to calendar (screen index 7)
-	n.currentIndex = 7
-	n.state.CurrentScreen = "calendar"
+// Open shows a screen from a menu: the dashboard, or a modal screen
+func (n *Navigator) Open(id workflow.State) {
+	if id == workflow.Dashboard {
+		n.NavigateToDashboard()
+		return
+	}
+	n.GoTo(id)
+}
 
-	// Render calendar with top bar
-	content := n.screens[7].Render()
-	n.setContent(n.wrapWithTopBar(content))
+// ShowConsolidatedAnalytics shows read-only analytics across all profiles
+func (n *Navigator) ShowConsolidatedAnalytics() {
+	n.Open(workflow.ConsolidatedAnalytics)
 }
 
-// JumpToTradeManagement navigates directly to trade management screen
-func (n *Navigator) JumpToTradeManagement() {
-	// Auto-save current progress
-	n.AutoSave()
+// SwitchProfile saves the in-progress trade to the current profile, loads
+// the selected profile and returns to the dashboard
+func (n *Navigator) SwitchProfile(id string) {
+	if id == n.state.Profile.ID {
+		return
+	}
 
-	// Remember where we came from
-	n.history = append(n.history, n.currentIndex)
+	if err := n.AutoSave(); err != nil {
+		dialog.ShowError(fmt.Errorf("failed to save in-progress trade, profile not switched: %w", err), n.window)
+		return
+	}
 
-	// Jump to trade management (screen index 8)
-	n.currentIndex = 8
-	n.state.CurrentScreen = "trade_management"
+	if err := n.state.SwitchProfile(id); err != nil {
+		dialog.ShowError(fmt.Errorf("profile %s loaded with errors: %w", id, err), n.window)
+	}
 
-	// Render trade management with top bar
-	content := n.screens[8].Render()
-	n.setContent(n.wrapWithTopBar(content))
+	n.NavigateToDashboard()
 }
 
-// JumpToAnalytics navigates directly to analytics screen
-func (n *Navigator) JumpToAnalytics() {
-	// Auto-save current progress
-	n.AutoSave()
+// NavigateToDashboard returns to the main dashboard and clears the Back
+// history. The dashboard is reachable from every state, so this doesn't go
+// through the machine or auto-save (callers save first when they need to).
+func (n *Navigator) NavigateToDashboard() {
+	n.history = []workflow.State{}
+	n.enter(workflow.Dashboard)
+}
 
-	// Remember where we came from
-	n.history = append(n.history, n.currentIndex)
+// AutoSave saves current trade progress as a draft
+func (n *Navigator) AutoSave() error {
+	return n.autoSaveAt(n.current, n.history)
+}
 
-	// Jump to analytics (screen index 9)
-	n.currentIndex = 9
-	n.state.CurrentScreen = "analytics"
+// autoSaveAt saves the current trade as a draft positioned on the workflow
+// step being navigated to, with the given Back history. Leaving the
+// workflow for a modal or the dashboard keeps the position the user was
+// at. A read-only window can't save, but can still be navigated.
+func (n *Navigator) autoSaveAt(to workflow.State, history []workflow.State) error {
+	if n.state.CurrentTrade == nil || storage.ReadOnly() {
+		return nil // Nothing to save
+	}
 
-	// Render analytics with top bar
-	content := n.screens[9].Render()
-	n.setContent(n.wrapWithTopBar(content))
+	switch {
+	case workflow.IsStep(to):
+		return n.state.SaveDraft(string(to), stepNames(history))
+	case workflow.IsStep(n.current):
+		return n.state.SaveDraft(string(n.current), stepNames(n.history))
+	default:
+		return n.state.SaveDraft("", nil)
+	}
 }
 
-// NavigateToDashboard returns to the main dashboard
-func (n *Navigator) NavigateToDashboard() {
-	n.currentIndex = -1
-	n.history = []int{}
-	n.state.CurrentScreen = "dashboard"
-
-	// Render dashboard (pass navigator so dashboard can navigate to screens)
-	dashboard := NewDashboard(n.state, n.window, n)
-	content := dashboard.Render()
-	n.setContent(n.wrapWithTopBar(content))
+// stepNames converts a Back history to the names saved with a draft. Only
+// the dashboard and workflow steps are kept.
+func stepNames(history []workflow.State) []string {
+	names := []string{}
+	for _, s := range history {
+		if s == workflow.Dashboard || workflow.IsStep(s) {
+			names = append(names, string(s))
+		}
+	}
+	return names
 }
 
-// NavigateToScreen jumps directly to a specific screen by index
-func (n *Navigator) NavigateToScreen(index int) error {
-	if index < 0 || index >= len(n.screens) {
-		return fmt.Errorf("invalid screen index: %d", index)
+// stepStates converts a saved draft history back to states. Reports false
+// if a step no longer exists.
+func stepStates(names []string) ([]workflow.State, bool) {
+	history := []workflow.State{}
+	for _, name := range names {
+		s := workflow.State(name)
+		if s != workflow.Dashboard && !workflow.IsStep(s) {
+			return nil, false
+		}
+		history = append(history, s)
 	}
+	return history, len(history) > 0
+}
 
-	// Auto-save before navigation
+// StartNewTrade parks the current trade as a draft and starts a new one at
+// sector selection
+func (n *Navigator) StartNewTrade() error {
 	if err := n.AutoSave(); err != nil {
 		return fmt.Errorf("auto-save failed: %w", err)
 	}
+	n.state.StartNewDraft()
+	n.history = []workflow.State{}
+	n.current = workflow.Dashboard
+	return n.GoTo(workflow.SectorSelection)
+}
+
+// ResumeDraft parks the current trade and continues a draft on the step it
+// was left on, with its own Back history, cooldown and checklist state. If
+// the draft no longer passes the guards for that step (e.g. its checklist
+// was reset), it resumes on the furthest step it can reach.
+func (n *Navigator) ResumeDraft(id string) error {
+	if n.state.CurrentTrade == nil || n.state.CurrentTrade.ID != id {
+		if err := n.AutoSave(); err != nil {
+			return fmt.Errorf("auto-save failed: %w", err)
+		}
+	}
 
-	// Record history
-	n.history = append(n.history, n.currentIndex)
+	draft, err := n.state.ResumeDraft(id)
+	if err != nil {
+		return err
+	}
 
-	// Navigate to screen
-	n.currentIndex = index
-	n.state.CurrentScreen = n.GetCurrentScreenName()
-	content := n.screens[n.currentIndex].Render()
-	n.setContent(n.wrapWithTopBar(content))
+	// Block new trades when a prop firm limit is breached
+	facts := n.facts()
+	if err := n.machine.Check(workflow.Dashboard, workflow.Steps[0], facts); err != nil {
+		var guardErr *workflow.GuardError
+		if errors.As(err, &guardErr) {
+			n.showError(guardErr.Err)
+		}
+		return err
+	}
 
+	index := workflow.StepIndex(workflow.State(draft.Step))
+	if index < 0 {
+		index = 0
+	}
+	for index > 0 && n.machine.Check(workflow.Dashboard, workflow.Steps[index], facts) != nil {
+		index--
+	}
+	step := workflow.Steps[index]
+
+	// Back returns the way the draft came, or else through the earlier
+	// workflow steps to the dashboard
+	history, ok := stepStates(draft.History)
+	if !ok || step != workflow.State(draft.Step) {
+		history = append([]workflow.State{workflow.Dashboard}, workflow.Steps[:index]...)
+	}
+
+	// The draft is already saved at this position
+	n.history = history
+	n.enter(step)
 	return nil
 }
 
-// AutoSave saves current trade progress
-func (n *Navigator) AutoSave() error {
-	if n.state.CurrentTrade == nil {
-		return nil // Nothing to save
+// checkPropFirmRules returns an error if a prop firm limit is breached, or
+// would be breached if the current trade lost its full MaxLoss
+func (n *Navigator) checkPropFirmRules() error {
+	if n.state.Settings == nil || !n.state.Settings.PropFirm.Enabled {
+		return nil
 	}
-	return storage.SaveInProgressTrade(n.state.CurrentTrade)
+
+	status := propfirm.Evaluate(n.state.Settings.PropFirm, n.state.AllTrades, time.Now())
+
+	var maxLoss float64
+	var contracts int
+	if n.state.CurrentTrade != nil {
+		maxLoss = n.state.CurrentTrade.MaxLoss
+		contracts = n.state.CurrentTrade.PositionSize
+	}
+
+	return status.CheckNewTrade(maxLoss, contracts)
 }
 
 // ValidateCurrentScreen validates the current screen's data
 func (n *Navigator) ValidateCurrentScreen() bool {
-	if n.currentIndex < 0 || n.currentIndex >= len(n.screens) {
-		return true // Dashboard or out of bounds
+	screen, ok := n.screens[n.current]
+	if !ok {
+		return true // Dashboard and screens without validation
 	}
-	return n.screens[n.currentIndex].Validate()
+	return screen.Validate()
 }
 
-// GetCurrentScreenName returns human-readable screen name
-func (n *Navigator) GetCurrentScreenName() string {
-	if n.currentIndex < 0 {
-		return "dashboard"
-	}
+// Current returns the workflow state the navigator is on
+func (n *Navigator) Current() workflow.State {
+	return n.current
+}
 
-	if n.currentIndex < len(n.screens) {
-		return n.screens[n.currentIndex].GetName()
-	}
-	return "unknown"
+// GetCurrentScreenName returns the current screen's name
+func (n *Navigator) GetCurrentScreenName() string {
+	return string(n.current)
 }
 
-// GetCurrentIndex returns the current screen index
+// GetCurrentIndex returns the current position in the workflow steps, or
+// -1 off the workflow (dashboard and modal screens)
 func (n *Navigator) GetCurrentIndex() int {
-	return n.currentIndex
+	return workflow.StepIndex(n.current)
 }
 
 // CanGoBack returns true if there's navigation history
@@ -308,26 +516,22 @@ func (n *Navigator) GetHistoryDepth() int {
 
 // ClearHistory resets the navigation history
 func (n *Navigator) ClearHistory() {
-	n.history = []int{}
+	n.history = []workflow.State{}
 }
 
 // ShowHelp displays context-sensitive help for the current screen
 func (n *Navigator) ShowHelp() {
-	screenName := n.GetCurrentScreenName()
-	help.ShowHelpDialog(screenName, n.window)
+	help.ShowContent(n.registry.Help(n.current, n.state.FeatureFlags), n.window)
 }
 
 // wrapWithTopBar wraps screen content with the top navigation bar and Vimium overlay
 func (n *Navigator) wrapWithTopBar(content fyne.CanvasObject) fyne.CanvasObject {
 	// Extract scroll container from content if it exists
-	println("DEBUG: wrapWithTopBar called, searching for scroll container...")
 	scrollContainer := n.findScrollContainer(content)
 	if scrollContainer != nil && n.vimiumManager != nil {
-		println("DEBUG: Found scroll container, setting it in Vim manager")
 		n.vimiumManager.SetScrollContainer(scrollContainer)
-	} else {
-		println("DEBUG: No scroll container found in content")
 	}
+	logger.Debug("Wrapped screen with top bar", "screen", n.current, "scrollable", scrollContainer != nil)
 
 	// Wrap content with Vimium overlay if enabled
 	var wrappedContent fyne.CanvasObject = content
@@ -342,6 +546,9 @@ func (n *Navigator) wrapWithTopBar(content fyne.CanvasObject) fyne.CanvasObject
 	} else {
 		topBarContent = widget.NewLabel("Top Bar") // Placeholder for tests
 	}
+	if storage.ReadOnly() {
+		topBarContent = container.NewVBox(topBarContent, readOnlyBanner())
+	}
 
 	fullContainer := container.NewBorder(
 		topBarContent,  // Top
@@ -359,6 +566,15 @@ func (n *Navigator) wrapWithTopBar(content fyne.CanvasObject) fyne.CanvasObject
 	return fullContainer
 }
 
+// readOnlyBanner explains why nothing can be saved in a second instance
+func readOnlyBanner() fyne.CanvasObject {
+	banner := widget.NewLabelWithStyle(
+		"🔒 Read-only: another TF-Engine window is using this data. Changes can't be saved here.",
+		fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	banner.Importance = widget.WarningImportance
+	return banner
+}
+
 // findScrollContainer recursively searches for a scroll container in the content tree
 func (n *Navigator) findScrollContainer(obj fyne.CanvasObject) *container.Scroll {
 	if obj == nil {
@@ -387,24 +603,6 @@ func (n *Navigator) GetVimiumManager() *vimium.VimiumManager {
 	return n.vimiumManager
 }
 
-// NavigateToSettings navigates to the settings screen
-func (n *Navigator) NavigateToSettings() {
-	// Auto-save current progress
-	n.AutoSave()
-
-	// Remember where we came from
-	n.history = append(n.history, n.currentIndex)
-
-	// Create and show settings screen
-	settingsScreen := screens.NewSettings(n.state, n.window)
-	n.currentIndex = -2 // Special index for settings (not in main workflow)
-	n.state.CurrentScreen = "settings"
-
-	// Render settings with top bar
-	content := settingsScreen.Render()
-	n.setContent(n.wrapWithTopBar(content))
-}
-
 // ShowReference displays the requested reference material
 func (n *Navigator) ShowReference(refType string) {
 	if n.referenceViewer != nil {
@@ -437,17 +635,5 @@ func (n *Navigator) setContent(content fyne.CanvasObject) {
 
 // RefreshCurrentScreen re-renders the current screen (useful for vim overlay toggle)
 func (n *Navigator) RefreshCurrentScreen() {
-	if n.currentIndex == -1 {
-		// Re-render dashboard
-		n.NavigateToDashboard()
-	} else if n.currentIndex == -2 {
-		// Re-render settings
-		settingsScreen := screens.NewSettings(n.state, n.window)
-		content := settingsScreen.Render()
-		n.setContent(n.wrapWithTopBar(content))
-	} else if n.currentIndex >= 0 && n.currentIndex < len(n.screens) {
-		// Re-render current screen
-		content := n.screens[n.currentIndex].Render()
-		n.setContent(n.wrapWithTopBar(content))
-	}
+	n.render()
 }
@@ -1,14 +1,19 @@
 package ui
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
 
 	"tf-engine/internal/appcore"
 	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/propfirm"
 	"tf-engine/internal/storage"
+	"tf-engine/internal/workflow"
 )
 
 // MockScreen implements the Screen interface for testing
@@ -73,7 +78,40 @@ func (m *MockWindow) FullScreen() bool                             { return fals
 func (m *MockWindow) FixedSize() bool                              { return false }
 func (m *MockWindow) Padded() bool                                 { return false }
 
+// mockWorkflow returns valid mock screens for every workflow step
+func mockWorkflow() map[workflow.State]Screen {
+	screens := map[workflow.State]Screen{}
+	for _, step := range workflow.Steps {
+		screens[step] = &MockScreen{name: string(step), isValid: true}
+	}
+	return screens
+}
+
+// newTestNavigator builds a navigator over mock workflow screens
+func newTestNavigator(state *appcore.AppState, window fyne.Window) *Navigator {
+	nav := &Navigator{
+		registry: DefaultRegistry(),
+		screens:  mockWorkflow(),
+		current:  workflow.Dashboard,
+		history:  []workflow.State{},
+		state:    state,
+		window:   window,
+	}
+	nav.machine = nav.newMachine()
+	return nav
+}
+
 func setupTestNavigator(t *testing.T) (*Navigator, *appcore.AppState, *MockWindow) {
+	// Keep auto-saves in a temporary data directory
+	oldDirs := paths.Current()
+	saved := storage.TakeSnapshot()
+	paths.Set(paths.Under(t.TempDir(), paths.ModeFlag))
+	storage.InitProfiles()
+	t.Cleanup(func() {
+		paths.Set(oldDirs)
+		saved.Restore()
+	})
+
 	// Setup test environment
 	state := appcore.NewAppState()
 	state.Policy = &models.Policy{
@@ -83,30 +121,15 @@ func setupTestNavigator(t *testing.T) (*Navigator, *appcore.AppState, *MockWindo
 	}
 	state.CurrentTrade = &models.Trade{
 		ID:        "test-123",
+		Sector:    "Healthcare",
+		Ticker:    "UNH",
+		Strategy:  "Alt10",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	mockWindow := &MockWindow{}
-
-	nav := &Navigator{
-		screens:      []Screen{},
-		currentIndex: -1,
-		history:      []int{},
-		state:        state,
-		window:       mockWindow,
-	}
-
-	// Add mock screens
-	for i := 0; i < 3; i++ {
-		screen := &MockScreen{
-			name:    []string{"screen1", "screen2", "screen3"}[i],
-			isValid: true,
-		}
-		nav.screens = append(nav.screens, screen)
-	}
-
-	return nav, state, mockWindow
+	return newTestNavigator(state, mockWindow), state, mockWindow
 }
 
 func TestNavigator_Next_ValidData(t *testing.T) {
@@ -118,12 +141,12 @@ func TestNavigator_Next_ValidData(t *testing.T) {
 		t.Errorf("Next() failed: %v", err)
 	}
 
-	if nav.currentIndex != 0 {
-		t.Errorf("Expected currentIndex 0, got %d", nav.currentIndex)
+	if nav.current != workflow.SectorSelection {
+		t.Errorf("Expected sector selection, got %s", nav.current)
 	}
 
-	if state.CurrentScreen != "screen1" {
-		t.Errorf("Expected current screen 'screen1', got '%s'", state.CurrentScreen)
+	if state.CurrentScreen != "sector_selection" {
+		t.Errorf("Expected current screen 'sector_selection', got '%s'", state.CurrentScreen)
 	}
 
 	if mockWindow.setContentCalled != 1 {
@@ -138,7 +161,7 @@ func TestNavigator_Next_InvalidData_Fails(t *testing.T) {
 	nav.Next()
 
 	// Make current screen invalid
-	nav.screens[0].(*MockScreen).isValid = false
+	nav.screens[workflow.SectorSelection].(*MockScreen).isValid = false
 
 	// Try to move to next screen
 	err := nav.Next()
@@ -146,8 +169,103 @@ func TestNavigator_Next_InvalidData_Fails(t *testing.T) {
 		t.Error("Next() should fail with invalid data")
 	}
 
-	if nav.currentIndex != 0 {
-		t.Errorf("CurrentIndex should remain 0, got %d", nav.currentIndex)
+	if nav.current != workflow.SectorSelection {
+		t.Errorf("Should stay on sector selection, got %s", nav.current)
+	}
+}
+
+func TestNavigator_Next_BlockedByGuard(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
+
+	nav.Next()
+	state.CurrentTrade.Sector = ""
+
+	err := nav.Next()
+	var guardErr *workflow.GuardError
+	if !errors.As(err, &guardErr) || guardErr.Guard != workflow.SectorChosen.Name {
+		t.Fatalf("Expected the sector guard to block, got %v", err)
+	}
+	if nav.current != workflow.SectorSelection || nav.GetHistoryDepth() != 1 {
+		t.Errorf("A blocked move must not change state, got %s with history %v", nav.current, nav.history)
+	}
+	if d, _ := storage.LoadDraft("test-123"); d == nil || d.Step != "sector_selection" {
+		t.Errorf("A blocked move must not auto-save a new position, got %+v", d)
+	}
+}
+
+func TestNavigator_Next_NothingAfterTradeEntry(t *testing.T) {
+	nav, _, _ := setupTestNavigator(t)
+	nav.current = workflow.TradeEntry
+
+	if err := nav.Next(); err == nil {
+		t.Error("Next() after trade entry should fail")
+	}
+	if nav.current != workflow.TradeEntry {
+		t.Errorf("Expected to stay on trade entry, got %s", nav.current)
+	}
+}
+
+func TestNavigator_GoTo(t *testing.T) {
+	nav, state, mockWindow := setupTestNavigator(t)
+
+	// Jump straight to the checklist: sector and ticker are chosen
+	if err := nav.GoTo(workflow.Checklist); err != nil {
+		t.Fatalf("GoTo(checklist) failed: %v", err)
+	}
+	if nav.current != workflow.Checklist || mockWindow.setContentCalled != 1 {
+		t.Errorf("Expected checklist rendered once, got %s (%d renders)", nav.current, mockWindow.setContentCalled)
+	}
+	if len(nav.history) != 1 || nav.history[0] != workflow.Dashboard {
+		t.Errorf("Expected history [dashboard], got %v", nav.history)
+	}
+
+	// Skipping steps is illegal
+	if err := nav.GoTo(workflow.TradeEntry); !errors.Is(err, workflow.ErrIllegal) {
+		t.Errorf("Expected ErrIllegal skipping to trade entry, got %v", err)
+	}
+
+	// The cooldown hasn't run yet
+	state.CurrentTrade.ChecklistPassed = true
+	state.CurrentTrade.CooldownStartTime = time.Now()
+	var guardErr *workflow.GuardError
+	if err := nav.GoTo(workflow.PositionSizing); !errors.As(err, &guardErr) || guardErr.Guard != workflow.CooldownComplete.Name {
+		t.Errorf("Expected the cooldown guard to block, got %v", err)
+	}
+
+	state.CurrentTrade.CooldownStartTime = time.Now().Add(-time.Hour)
+	if err := nav.GoTo(workflow.PositionSizing); err != nil {
+		t.Errorf("Expected position sizing once the cooldown is over: %v", err)
+	}
+}
+
+func TestNavigator_Modals_ReturnWithBack(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
+	nav.screens[workflow.Calendar] = &MockScreen{name: "calendar", isValid: true}
+
+	nav.GoTo(workflow.Checklist)
+	nav.Open(workflow.Calendar)
+	if nav.current != workflow.Calendar || state.CurrentScreen != "calendar" {
+		t.Fatalf("Expected calendar, got %s", nav.current)
+	}
+	if nav.GetCurrentIndex() != -1 {
+		t.Errorf("Modals are outside the workflow, got index %d", nav.GetCurrentIndex())
+	}
+
+	// Opening a modal keeps the draft on the step it was on
+	if d, _ := storage.LoadDraft("test-123"); d == nil || d.Step != "checklist" {
+		t.Errorf("Expected the draft parked at checklist, got %+v", d)
+	}
+
+	// Modals never advance the workflow
+	if err := nav.Next(); err == nil {
+		t.Error("Next() from the calendar should fail")
+	}
+
+	if err := nav.Back(); err != nil {
+		t.Fatalf("Back() failed: %v", err)
+	}
+	if nav.current != workflow.Checklist {
+		t.Errorf("Back should return to checklist, got %s", nav.current)
 	}
 }
 
@@ -156,9 +274,9 @@ func TestNavigator_Back_PreservesData(t *testing.T) {
 
 	// Navigate forward twice
 	nav.Next()
-	state.CurrentTrade.Sector = "Healthcare"
+	state.CurrentTrade.Sector = "Technology"
 	nav.Next()
-	state.CurrentTrade.Ticker = "UNH"
+	state.CurrentTrade.Ticker = "MSFT"
 
 	// Navigate back
 	err := nav.Back()
@@ -166,15 +284,15 @@ func TestNavigator_Back_PreservesData(t *testing.T) {
 		t.Errorf("Back() failed: %v", err)
 	}
 
-	if nav.currentIndex != 0 {
-		t.Errorf("Expected currentIndex 0 after Back(), got %d", nav.currentIndex)
+	if nav.current != workflow.SectorSelection {
+		t.Errorf("Expected sector selection after Back(), got %s", nav.current)
 	}
 
 	// Verify data preserved
-	if state.CurrentTrade.Sector != "Healthcare" {
+	if state.CurrentTrade.Sector != "Technology" {
 		t.Error("Data should be preserved after Back()")
 	}
-	if state.CurrentTrade.Ticker != "UNH" {
+	if state.CurrentTrade.Ticker != "MSFT" {
 		t.Error("Data should be preserved after Back()")
 	}
 }
@@ -221,9 +339,6 @@ func TestNavigator_HistoryStack(t *testing.T) {
 func TestNavigator_AutoSave_CalledOnNavigation(t *testing.T) {
 	nav, state, _ := setupTestNavigator(t)
 
-	// Ensure clean state
-	storage.DeleteInProgressTrade()
-
 	// Set up a trade
 	state.CurrentTrade = &models.Trade{
 		ID:     "test-autosave",
@@ -237,83 +352,137 @@ func TestNavigator_AutoSave_CalledOnNavigation(t *testing.T) {
 		t.Fatalf("Next() failed: %v", err)
 	}
 
-	// Verify trade was saved
-	savedTrade, err := storage.LoadInProgressTrade()
+	// Verify trade was saved as a draft
+	draft, err := storage.LoadDraft("test-autosave")
 	if err != nil {
-		t.Fatalf("Failed to load saved trade: %v", err)
+		t.Fatalf("Failed to load saved draft: %v", err)
 	}
 
-	if savedTrade == nil {
+	if draft == nil {
 		t.Fatal("Trade should have been auto-saved")
 	}
 
-	if savedTrade.Sector != "Healthcare" {
-		t.Errorf("Expected saved sector 'Healthcare', got '%s'", savedTrade.Sector)
+	if draft.Trade.Sector != "Healthcare" {
+		t.Errorf("Expected saved sector 'Healthcare', got '%s'", draft.Trade.Sector)
 	}
-
-	// Cleanup
-	storage.DeleteInProgressTrade()
 }
 
-func TestNavigator_GetCurrentScreenName(t *testing.T) {
-	nav, _, _ := setupTestNavigator(t)
+func TestNavigator_ResumeDraft_RestoresStepAndCooldown(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
 
-	// Dashboard
-	name := nav.GetCurrentScreenName()
-	if name != "dashboard" {
-		t.Errorf("Expected 'dashboard', got '%s'", name)
+	// UNH is waiting out its cooldown on the checklist
+	cooldownStart := time.Now().Add(-30 * time.Second)
+	unh := &models.Draft{
+		Trade: models.Trade{ID: "unh", Sector: "Healthcare", Ticker: "UNH", Strategy: "Alt10", CooldownStartTime: cooldownStart,
+			ChecklistRequired: map[string]bool{"trend": true}},
+		Step: "checklist",
+	}
+	if err := storage.SaveDraft(unh); err != nil {
+		t.Fatal(err)
 	}
 
-	// First screen (mock screen returns "screen1")
-	nav.currentIndex = 0
-	name = nav.GetCurrentScreenName()
-	if name != "screen1" {
-		t.Errorf("Expected 'screen1', got '%s'", name)
+	// A breakout on MSFT: start a second trade without losing UNH
+	state.CurrentTrade = nil
+	if err := nav.StartNewTrade(); err != nil {
+		t.Fatalf("StartNewTrade failed: %v", err)
 	}
+	state.CurrentTrade = &models.Trade{ID: "msft", Sector: "Technology", Ticker: "MSFT"}
+	nav.Next()
+	nav.Next()
 
-	// Second screen (mock screen returns "screen2")
-	nav.currentIndex = 1
-	name = nav.GetCurrentScreenName()
-	if name != "screen2" {
-		t.Errorf("Expected 'screen2', got '%s'", name)
+	if err := nav.ResumeDraft("unh"); err != nil {
+		t.Fatalf("ResumeDraft failed: %v", err)
+	}
+
+	if nav.GetCurrentScreenName() != "checklist" {
+		t.Errorf("Expected to resume at checklist, got %s", nav.GetCurrentScreenName())
+	}
+	if state.CurrentTrade.Ticker != "UNH" || !state.CurrentTrade.ChecklistRequired["trend"] {
+		t.Errorf("Expected UNH with its checklist state, got %+v", state.CurrentTrade)
+	}
+	if state.CooldownStart == nil || !state.CooldownStart.Equal(cooldownStart) {
+		t.Errorf("Cooldown should continue from the draft's start, got %v", state.CooldownStart)
+	}
+	if nav.GetHistoryDepth() != 4 {
+		t.Errorf("Back should walk the earlier steps, history depth %d", nav.GetHistoryDepth())
+	}
+
+	// MSFT was parked on the step it reached
+	msft, err := storage.LoadDraft("msft")
+	if err != nil || msft == nil {
+		t.Fatalf("MSFT draft should be saved: %v", err)
+	}
+	if msft.Step != "ticker_entry" {
+		t.Errorf("Expected MSFT parked at ticker_entry, got %q", msft.Step)
 	}
 }
 
-func TestNavigator_NavigateToScreen(t *testing.T) {
-	nav, _, mockWindow := setupTestNavigator(t)
+func TestNavigator_ResumeDraft_AfterRestart(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
 
-	// Jump to screen 2
-	err := nav.NavigateToScreen(2)
-	if err != nil {
-		t.Errorf("NavigateToScreen(2) failed: %v", err)
+	state.CurrentTrade = &models.Trade{ID: "unh", Sector: "Healthcare", Ticker: "UNH", Strategy: "Alt10",
+		CooldownStartTime: time.Now().Add(-time.Hour)}
+	nav.GoTo(workflow.SectorSelection)
+	nav.Next()
+	nav.Next()
+	state.StartCooldown()
+	started := state.CurrentTrade.CooldownStartTime
+	nav.Next()
+	state.CurrentTrade.ChecklistPassed = true
+	if err := nav.Next(); err != nil { // position_sizing
+		t.Fatalf("Next() to position sizing failed: %v", err)
 	}
 
-	if nav.currentIndex != 2 {
-		t.Errorf("Expected currentIndex 2, got %d", nav.currentIndex)
+	// Restart: a fresh state and navigator
+	state2 := appcore.NewAppState()
+	state2.Policy = state.Policy
+	nav2 := newTestNavigator(state2, &MockWindow{})
+
+	if err := nav2.ResumeDraft("unh"); err != nil {
+		t.Fatalf("ResumeDraft failed: %v", err)
+	}
+	if nav2.GetCurrentScreenName() != "position_sizing" {
+		t.Errorf("Expected position_sizing, got %s", nav2.GetCurrentScreenName())
+	}
+	if nav2.GetHistoryDepth() != nav.GetHistoryDepth() {
+		t.Errorf("Expected history depth %d, got %d", nav.GetHistoryDepth(), nav2.GetHistoryDepth())
 	}
 
-	if mockWindow.setContentCalled != 1 {
-		t.Errorf("Expected SetContent called 1 time, got %d", mockWindow.setContentCalled)
+	// The cooldown continues from the saved start, even if ticker entry
+	// starts it again
+	state2.StartCooldown()
+	if state2.CooldownStart == nil || !state2.CooldownStart.Equal(started) {
+		t.Errorf("Cooldown should continue from %v, got %v", started, state2.CooldownStart)
+	}
+	if !state2.CurrentTrade.CooldownStartTime.Equal(started) {
+		t.Errorf("Restarting must not reset the cooldown, got %v", state2.CurrentTrade.CooldownStartTime)
 	}
 
-	// History should be updated
-	if len(nav.history) != 1 {
-		t.Errorf("Expected history length 1, got %d", len(nav.history))
+	nav2.Back()
+	if nav2.GetCurrentScreenName() != "checklist" {
+		t.Errorf("Back should return to checklist, got %s", nav2.GetCurrentScreenName())
 	}
 }
 
-func TestNavigator_NavigateToScreen_InvalidIndex(t *testing.T) {
+func TestNavigator_GetCurrentScreenName(t *testing.T) {
 	nav, _, _ := setupTestNavigator(t)
 
-	// Try invalid indices
-	err := nav.NavigateToScreen(-1)
-	if err == nil {
-		t.Error("NavigateToScreen(-1) should fail")
+	// Dashboard
+	name := nav.GetCurrentScreenName()
+	if name != "dashboard" {
+		t.Errorf("Expected 'dashboard', got '%s'", name)
 	}
 
-	err = nav.NavigateToScreen(100)
-	if err == nil {
-		t.Error("NavigateToScreen(100) should fail")
+	nav.current = workflow.SectorSelection
+	name = nav.GetCurrentScreenName()
+	if name != "sector_selection" {
+		t.Errorf("Expected 'sector_selection', got '%s'", name)
+	}
+
+	nav.current = workflow.Settings
+	name = nav.GetCurrentScreenName()
+	if name != "settings" {
+		t.Errorf("Expected 'settings', got '%s'", name)
 	}
 }
 
@@ -334,7 +503,7 @@ func TestNavigator_ValidateCurrentScreen(t *testing.T) {
 	}
 
 	// Make screen invalid
-	nav.screens[0].(*MockScreen).isValid = false
+	nav.screens[workflow.SectorSelection].(*MockScreen).isValid = false
 
 	if nav.ValidateCurrentScreen() {
 		t.Error("Screen should be invalid")
@@ -394,3 +563,78 @@ func TestNavigator_AutoSave_NilTrade_NoError(t *testing.T) {
 		t.Errorf("AutoSave() should not error with nil trade: %v", err)
 	}
 }
+
+func TestNavigator_Next_BlockedByPropFirmRules(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
+	nav.window = test.NewWindow(nil)
+
+	// Daily loss limit already breached by a trade closed today
+	pnl := -1200.0
+	now := time.Now()
+	state.AllTrades = []models.Trade{
+		{ID: "loss", ProfitLoss: &pnl, ExitDate: &now, Status: "closed"},
+	}
+	state.Settings.PropFirm = models.DefaultPropFirmRules()
+	state.Settings.PropFirm.Enabled = true
+
+	err := nav.Next()
+	if !errors.Is(err, propfirm.ErrLimitBreached) {
+		t.Fatalf("Expected ErrLimitBreached, got %v", err)
+	}
+	if nav.current != workflow.Dashboard {
+		t.Errorf("Navigator should stay on dashboard, got %s", nav.current)
+	}
+
+	// Disabling the rules lets the workflow continue
+	state.Settings.PropFirm.Enabled = false
+	if err := nav.Next(); err != nil {
+		t.Errorf("Next() should succeed with rules disabled: %v", err)
+	}
+}
+
+func TestNavigator_Next_BlockedWhenMaxLossWouldBreach(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
+	nav.window = test.NewWindow(nil)
+
+	state.Settings.PropFirm = models.DefaultPropFirmRules()
+	state.Settings.PropFirm.Enabled = true
+	state.CurrentTrade.MaxLoss = 400
+
+	if err := nav.Next(); err != nil {
+		t.Fatalf("$400 max loss should fit within limits: %v", err)
+	}
+
+	// Max loss larger than the daily loss limit
+	state.CurrentTrade.MaxLoss = 1100
+	if err := nav.Next(); !errors.Is(err, propfirm.ErrLimitBreached) {
+		t.Errorf("Expected ErrLimitBreached for $1100 max loss, got %v", err)
+	}
+}
+
+func TestNavigator_SwitchProfile_ReloadsState(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
+	nav.window = test.NewWindow(nil)
+
+	state.AllTrades = []models.Trade{{ID: "personal-1"}}
+	nav.current = workflow.ScreenerLaunch
+
+	profile, err := storage.CreateProfile("Nav Test Prop", "")
+	if err != nil {
+		t.Fatalf("CreateProfile failed: %v", err)
+	}
+
+	nav.SwitchProfile(profile.ID)
+
+	if state.Profile.ID != profile.ID {
+		t.Errorf("Expected active profile %s, got %s", profile.ID, state.Profile.ID)
+	}
+	if len(state.AllTrades) != 0 {
+		t.Errorf("New profile should have no trades, got %d", len(state.AllTrades))
+	}
+	if state.CurrentTrade != nil {
+		t.Error("In-progress trade should stay with the previous profile")
+	}
+	if nav.current != workflow.Dashboard {
+		t.Errorf("Expected dashboard after switch, got %s", nav.current)
+	}
+}
@@ -0,0 +1,272 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"tf-engine/internal/config"
+	"tf-engine/internal/ui/help"
+	"tf-engine/internal/ui/screens"
+	"tf-engine/internal/workflow"
+)
+
+// MenuEntry places a screen in the top bar or on the dashboard
+type MenuEntry struct {
+	Label string
+	Order int // Lower first
+}
+
+// ScreenEntry declares a screen: what it is, when it's available and where
+// it appears. The navigator, help, top bar and dashboard are built from
+// these, so a screen whose flag is off is unreachable from all of them.
+type ScreenEntry struct {
+	ID       workflow.State
+	Title    string
+	Help     help.HelpContent
+	Flag     string // Feature flag the screen needs; "" = always available
+	Position int    // 1-based workflow step, 0 for screens outside the workflow
+
+	TopBar    *MenuEntry // nil = not in the top bar
+	Dashboard *MenuEntry // nil = no dashboard button
+
+	New     func(n *Navigator) Screen
+	Rebuild bool // Build a new screen on every visit instead of reusing one
+}
+
+// Enabled reports whether the screen's feature flag is on. Flagged screens
+// are off when no flags are loaded.
+func (e ScreenEntry) Enabled(flags *config.FeatureFlags) bool {
+	return e.Flag == "" || (flags != nil && flags.IsEnabled(e.Flag))
+}
+
+// Registry lists every screen the app can show
+type Registry []ScreenEntry
+
+// DefaultRegistry declares the dashboard, the trade entry workflow and the
+// modal screens
+func DefaultRegistry() Registry {
+	step := func(id workflow.State, title string, position int, newScreen func(n *Navigator) Screen) ScreenEntry {
+		return ScreenEntry{
+			ID:       id,
+			Title:    title,
+			Help:     help.GetHelpForScreen(string(id)),
+			Position: position,
+			New:      newScreen,
+		}
+	}
+
+	return Registry{
+		{
+			ID:      workflow.Dashboard,
+			Title:   "Dashboard",
+			TopBar:  &MenuEntry{Label: "🏠 Home", Order: 0},
+			New:     func(n *Navigator) Screen { return NewDashboard(n.state, n.window, n) },
+			Rebuild: true,
+		},
+		step(workflow.SectorSelection, "Sector Selection", 1, func(n *Navigator) Screen {
+			return screens.NewSectorSelection(n.state, n.window)
+		}),
+		step(workflow.ScreenerLaunch, "Screener Launch", 2, func(n *Navigator) Screen {
+			return screens.NewScreenerLaunch(n.state, n.window)
+		}),
+		step(workflow.TickerEntry, "Ticker Entry", 3, func(n *Navigator) Screen {
+			return screens.NewTickerEntry(n.state, n.window)
+		}),
+		step(workflow.Checklist, "Checklist", 4, func(n *Navigator) Screen {
+			return screens.NewChecklist(n.state, n.window)
+		}),
+		step(workflow.PositionSizing, "Position Sizing", 5, func(n *Navigator) Screen {
+			return screens.NewPositionSizing(n.state, n.window)
+		}),
+		step(workflow.HeatCheck, "Heat Check", 6, func(n *Navigator) Screen {
+			return screens.NewHeatCheck(n.state, n.window)
+		}),
+		step(workflow.TradeEntry, "Trade Entry", 7, func(n *Navigator) Screen {
+			return screens.NewTradeEntry(n.state, n.window)
+		}),
+		{
+			ID:        workflow.Calendar,
+			Title:     "Calendar",
+			Help:      help.GetHelpForScreen("calendar"),
+			TopBar:    &MenuEntry{Label: "📅 Calendar", Order: 2},
+			Dashboard: &MenuEntry{Label: "View Calendar", Order: 0},
+			New: func(n *Navigator) Screen {
+				return screens.NewCalendarWithFlags(n.state, n.window, n.state.FeatureFlags, n)
+			},
+		},
+		{
+			ID:        workflow.TradeManagement,
+			Title:     "Trade Management",
+			Help:      help.GetHelpForScreen("trade_management"),
+			Flag:      "trade_management",
+			Dashboard: &MenuEntry{Label: "Manage Trades", Order: 10},
+			New: func(n *Navigator) Screen {
+				return screens.NewTradeManagement(n.state, n.window)
+			},
+		},
+		{
+			ID:    workflow.Analytics,
+			Title: "Analytics",
+			Help: help.HelpContent{
+				Title:       "Advanced Analytics (Phase 2 Feature)",
+				Description: "Win rate, profit factor and equity curve for the active profile, broken down by sector and strategy.",
+				Steps: []string{
+					"1. Review overall statistics at the top",
+					"2. Compare sectors and strategies in the tables",
+					"3. Follow the equity curve for drawdowns",
+				},
+				Tips: []string{
+					"✓ Statistics use closed trades only",
+					"✓ Use All Profiles in the profile menu to compare accounts",
+				},
+			},
+			Flag:      "advanced_analytics",
+			Dashboard: &MenuEntry{Label: "📊 View Analytics", Order: 12},
+			New: func(n *Navigator) Screen {
+				return screens.NewAnalytics(n.state, n.window)
+			},
+		},
+		{
+			ID:    workflow.ConsolidatedAnalytics,
+			Title: "All Profiles",
+			Help: help.HelpContent{
+				Title:       "All Profiles (read-only)",
+				Description: "Analytics across every account profile. Nothing here can be edited.",
+				Steps: []string{
+					"1. Compare each profile's statistics in the table",
+					"2. Switch profiles from the profile menu to work in one",
+				},
+				Tips: []string{
+					"✓ Profiles that fail to load are listed as warnings and left out",
+				},
+			},
+			Flag:    "advanced_analytics",
+			New:     func(n *Navigator) Screen { return screens.NewConsolidatedAnalytics(n.state, n.window) },
+			Rebuild: true,
+		},
+		{
+			ID:    workflow.Settings,
+			Title: "Settings",
+			Help: help.HelpContent{
+				Title:       "Account Settings",
+				Description: "Account size, risk per trade, prop firm rules, cash flows, backups and encryption.",
+				Steps: []string{
+					"1. Edit account equity and risk per trade",
+					"2. Review the position size preview",
+					"3. Click 'Save Settings'",
+				},
+				Tips: []string{
+					"✓ Deposits and withdrawals go in the ledger, not in account equity",
+					"⚠ Prop firm rules block new trades once a limit is breached",
+				},
+			},
+			TopBar:    &MenuEntry{Label: "⚙️ Settings", Order: 1},
+			Dashboard: &MenuEntry{Label: "⚙️ Settings", Order: 1},
+			New: func(n *Navigator) Screen {
+				s := screens.NewSettings(n.state, n.window)
+				s.SetBackCallback(func() { n.Back() })
+				return s
+			},
+			Rebuild: true,
+		},
+		{
+			ID:    workflow.Diagnostics,
+			Title: "Diagnostics",
+			Help: help.HelpContent{
+				Title:       "Diagnostics",
+				Description: "Build, system, policy and feature flag details for bug reports, with an anonymized trade summary.",
+				Steps: []string{
+					"1. Check the policy signature and feature flags",
+					"2. Click 'Export Diagnostics…' and choose where to save the zip",
+					"3. Attach the zip to the bug report",
+				},
+				Tips: []string{
+					"✓ The trade summary has counts only: no tickers, prices or account values",
+					"⚠ Recent logs are included; turn on log redaction in Settings to hide equity and P&L",
+				},
+			},
+			Dashboard: &MenuEntry{Label: "🩺 Diagnostics", Order: 20},
+			New: func(n *Navigator) Screen {
+				d := screens.NewDiagnostics(n.state, n.window)
+				d.SetBackCallback(func() { n.Back() })
+				return d
+			},
+			Rebuild: true,
+		},
+	}
+}
+
+// Lookup returns the entry for id
+func (r Registry) Lookup(id workflow.State) (ScreenEntry, bool) {
+	for _, e := range r {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return ScreenEntry{}, false
+}
+
+// Enabled returns the entries whose feature flags are on
+func (r Registry) Enabled(flags *config.FeatureFlags) Registry {
+	enabled := Registry{}
+	for _, e := range r {
+		if e.Enabled(flags) {
+			enabled = append(enabled, e)
+		}
+	}
+	return enabled
+}
+
+// Steps returns the enabled workflow steps in order
+func (r Registry) Steps(flags *config.FeatureFlags) Registry {
+	steps := Registry{}
+	for _, e := range r.Enabled(flags) {
+		if e.Position > 0 {
+			steps = append(steps, e)
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Position < steps[j].Position })
+	return steps
+}
+
+// TopBar returns the enabled top bar entries in order
+func (r Registry) TopBar(flags *config.FeatureFlags) Registry {
+	return r.menu(flags, func(e ScreenEntry) *MenuEntry { return e.TopBar })
+}
+
+// Dashboard returns the enabled dashboard entries in order
+func (r Registry) Dashboard(flags *config.FeatureFlags) Registry {
+	return r.menu(flags, func(e ScreenEntry) *MenuEntry { return e.Dashboard })
+}
+
+func (r Registry) menu(flags *config.FeatureFlags, entry func(ScreenEntry) *MenuEntry) Registry {
+	items := Registry{}
+	for _, e := range r.Enabled(flags) {
+		if entry(e) != nil {
+			items = append(items, e)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return entry(items[i]).Order < entry(items[j]).Order })
+	return items
+}
+
+// Help returns the help for id. The dashboard, unknown and disabled
+// screens get an overview of the enabled screens.
+func (r Registry) Help(id workflow.State, flags *config.FeatureFlags) help.HelpContent {
+	if e, ok := r.Lookup(id); ok && e.Enabled(flags) && e.Help.Title != "" {
+		return e.Help
+	}
+
+	overview := help.GetHelpForScreen("")
+	steps := r.Steps(flags)
+	overview.Steps = []string{fmt.Sprintf("This application guides you through a %d-step workflow:", len(steps))}
+	for _, e := range steps {
+		overview.Steps = append(overview.Steps, fmt.Sprintf("%d. %s", e.Position, e.Title))
+	}
+	for _, e := range r.Enabled(flags) {
+		if e.Position == 0 && e.ID != workflow.Dashboard {
+			overview.Steps = append(overview.Steps, "• "+e.Title)
+		}
+	}
+	return overview
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"tf-engine/internal/config"
+	"tf-engine/internal/workflow"
+)
+
+func phase2Flags(enabled bool) *config.FeatureFlags {
+	return &config.FeatureFlags{Flags: map[string]config.FeatureFlag{
+		"trade_management":   {Enabled: enabled},
+		"advanced_analytics": {Enabled: enabled},
+	}}
+}
+
+func TestDefaultRegistry_CoversEveryState(t *testing.T) {
+	registry := DefaultRegistry()
+
+	states := append(append([]workflow.State{workflow.Dashboard}, workflow.Steps...), workflow.Modals...)
+	for _, s := range states {
+		e, ok := registry.Lookup(s)
+		if !ok {
+			t.Errorf("%s is not registered", s)
+			continue
+		}
+		if e.Title == "" || e.New == nil {
+			t.Errorf("%s needs a title and a constructor", s)
+		}
+		if s != workflow.Dashboard && e.Help.Title == "" {
+			t.Errorf("%s has no help", s)
+		}
+	}
+	if len(registry) != len(states) {
+		t.Errorf("Expected %d entries, got %d", len(states), len(registry))
+	}
+}
+
+func TestDefaultRegistry_PositionsMatchWorkflow(t *testing.T) {
+	steps := DefaultRegistry().Steps(nil)
+	if len(steps) != len(workflow.Steps) {
+		t.Fatalf("Expected %d steps, got %d", len(workflow.Steps), len(steps))
+	}
+	for i, e := range steps {
+		if e.ID != workflow.Steps[i] || e.Position != i+1 {
+			t.Errorf("Step %d: got %s at position %d, want %s", i+1, e.ID, e.Position, workflow.Steps[i])
+		}
+	}
+}
+
+func TestDefaultRegistry_MenusFollowFlags(t *testing.T) {
+	registry := DefaultRegistry()
+
+	labels := func(entries Registry, menu func(ScreenEntry) *MenuEntry) string {
+		names := []string{}
+		for _, e := range entries {
+			names = append(names, menu(e).Label)
+		}
+		return strings.Join(names, ", ")
+	}
+	topBar := func(e ScreenEntry) *MenuEntry { return e.TopBar }
+	dashboard := func(e ScreenEntry) *MenuEntry { return e.Dashboard }
+
+	if got := labels(registry.TopBar(nil), topBar); got != "🏠 Home, ⚙️ Settings, 📅 Calendar" {
+		t.Errorf("Unexpected top bar: %s", got)
+	}
+	if got := labels(registry.Dashboard(phase2Flags(false)), dashboard); got != "View Calendar, ⚙️ Settings, 🩺 Diagnostics" {
+		t.Errorf("Disabled screens should have no dashboard buttons, got %s", got)
+	}
+	if got := labels(registry.Dashboard(phase2Flags(true)), dashboard); got != "View Calendar, ⚙️ Settings, Manage Trades, 📊 View Analytics, 🩺 Diagnostics" {
+		t.Errorf("Unexpected dashboard buttons: %s", got)
+	}
+}
+
+func TestDefaultRegistry_HelpOverviewListsEnabledScreens(t *testing.T) {
+	registry := DefaultRegistry()
+
+	off := strings.Join(registry.Help(workflow.Dashboard, phase2Flags(false)).Steps, "\n")
+	if !strings.Contains(off, "7-step workflow") || !strings.Contains(off, "4. Checklist") {
+		t.Errorf("Overview should list the workflow steps, got:\n%s", off)
+	}
+	if strings.Contains(off, "Trade Management") || strings.Contains(off, "Analytics") {
+		t.Errorf("Overview should leave out disabled screens, got:\n%s", off)
+	}
+
+	// A disabled screen's own help is not shown
+	if got := registry.Help(workflow.TradeManagement, phase2Flags(false)); strings.Contains(got.Title, "Trade Management") {
+		t.Errorf("Help for a disabled screen should fall back to the overview, got %q", got.Title)
+	}
+	if got := registry.Help(workflow.TradeManagement, phase2Flags(true)); !strings.Contains(got.Title, "Trade Management") {
+		t.Errorf("Expected trade management help, got %q", got.Title)
+	}
+
+	on := strings.Join(registry.Help(workflow.Dashboard, phase2Flags(true)).Steps, "\n")
+	if !strings.Contains(on, "Trade Management") {
+		t.Errorf("Overview should list enabled screens, got:\n%s", on)
+	}
+}
+
+func TestNavigator_DisabledScreenUnreachable(t *testing.T) {
+	nav, state, _ := setupTestNavigator(t)
+	state.FeatureFlags = phase2Flags(false)
+	nav.machine = nav.newMachine()
+
+	for _, id := range []workflow.State{workflow.TradeManagement, workflow.Analytics, workflow.ConsolidatedAnalytics} {
+		if nav.IsAvailable(id) {
+			t.Errorf("%s should be unavailable", id)
+		}
+		if err := nav.GoTo(id); !errors.Is(err, workflow.ErrIllegal) {
+			t.Errorf("Opening %s should be illegal, got %v", id, err)
+		}
+	}
+	if nav.current != workflow.Dashboard {
+		t.Errorf("Expected to stay on the dashboard, got %s", nav.current)
+	}
+
+	// Turning the flag on makes it reachable
+	state.FeatureFlags = phase2Flags(true)
+	nav.machine = nav.newMachine()
+	nav.screens[workflow.TradeManagement] = &MockScreen{name: "trade_management", isValid: true}
+	if err := nav.GoTo(workflow.TradeManagement); err != nil {
+		t.Errorf("Trade management should open with its flag on: %v", err)
+	}
+}
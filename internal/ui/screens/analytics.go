@@ -12,23 +12,22 @@ import (
 
 	"tf-engine/internal/analytics"
 	"tf-engine/internal/appcore"
-	"tf-engine/internal/config"
+	"tf-engine/internal/export"
+	"tf-engine/internal/models"
 	"tf-engine/internal/storage"
 )
 
 // Analytics represents the analytics dashboard (Phase 2 Feature)
 type Analytics struct {
-	state        *appcore.AppState
-	window       fyne.Window
-	featureFlags *config.FeatureFlags
+	state  *appcore.AppState
+	window fyne.Window
 }
 
 // NewAnalytics creates a new analytics screen
-func NewAnalytics(state *appcore.AppState, window fyne.Window, featureFlags *config.FeatureFlags) *Analytics {
+func NewAnalytics(state *appcore.AppState, window fyne.Window) *Analytics {
 	return &Analytics{
-		state:        state,
-		window:       window,
-		featureFlags: featureFlags,
+		state:  state,
+		window: window,
 	}
 }
 
@@ -37,11 +36,6 @@ func (a *Analytics) Render() fyne.CanvasObject {
 	title := widget.NewLabel("📊 Advanced Analytics")
 	title.TextStyle = fyne.TextStyle{Bold: true}
 
-	// Check if feature is enabled
-	if a.featureFlags != nil && !a.featureFlags.IsEnabled("advanced_analytics") {
-		return a.renderDisabledState()
-	}
-
 	// Load trades
 	trades, err := storage.LoadAllTrades()
 	if err != nil {
@@ -56,12 +50,18 @@ func (a *Analytics) Render() fyne.CanvasObject {
 	overallStats := analytics.CalculateTradeStats(trades)
 	sectorStats := analytics.CalculateSectorStats(trades)
 	strategyStats := analytics.CalculateStrategyStats(trades)
+	convictionStats := analytics.CalculateConvictionStats(trades)
 	equityCurve := analytics.CalculateEquityCurve(trades)
+	if a.state.Ledger != nil {
+		// Account equity (starting balance, cash flows and realized P&L)
+		equityCurve = analytics.CalculateLedgerEquityCurve(a.state.Ledger)
+	}
 
 	// Create UI sections
 	overallSection := a.renderOverallStats(overallStats)
 	sectorSection := a.renderSectorStats(sectorStats)
 	strategySection := a.renderStrategyStats(strategyStats)
+	convictionSection := a.renderConvictionStats(convictionStats)
 	equityCurveSection := a.renderEquityCurve(equityCurve)
 
 	// Back button
@@ -69,57 +69,31 @@ func (a *Analytics) Render() fyne.CanvasObject {
 		// Navigator will handle navigation
 	})
 
+	exportBtn := widget.NewButton("Export...", func() {
+		showExportDialog(a.window, a.state, []string{export.Sectors, export.Strategies, export.Convictions, export.Equity})
+	})
+
 	content := container.NewVBox(
 		title,
 		widget.NewSeparator(),
+		a.renderAccountSummary(),
+		widget.NewSeparator(),
 		overallSection,
 		widget.NewSeparator(),
 		sectorSection,
 		widget.NewSeparator(),
 		strategySection,
 		widget.NewSeparator(),
+		convictionSection,
+		widget.NewSeparator(),
 		equityCurveSection,
 		widget.NewSeparator(),
-		backBtn,
+		container.NewHBox(backBtn, exportBtn),
 	)
 
 	return container.NewScroll(content)
 }
 
-// renderDisabledState shows a message when feature flag is OFF
-func (a *Analytics) renderDisabledState() fyne.CanvasObject {
-	title := widget.NewLabel("📊 Advanced Analytics")
-	title.TextStyle = fyne.TextStyle{Bold: true}
-
-	message := widget.NewLabel("This feature is currently disabled.")
-	message.Wrapping = fyne.TextWrapWord
-
-	flag := a.featureFlags.GetFlag("advanced_analytics")
-	var details string
-	if flag != nil {
-		details = fmt.Sprintf("Feature: %s\nPhase: %d\nAvailable in version: %s",
-			flag.Description, flag.Phase, flag.SinceVersion)
-	}
-
-	detailsLabel := widget.NewLabel(details)
-	detailsLabel.Wrapping = fyne.TextWrapWord
-
-	backBtn := widget.NewButton("Back to Dashboard", func() {
-		// Navigator will handle navigation
-	})
-
-	content := container.NewVBox(
-		title,
-		widget.NewSeparator(),
-		message,
-		detailsLabel,
-		widget.NewSeparator(),
-		backBtn,
-	)
-
-	return container.NewCenter(content)
-}
-
 // renderError displays an error message
 func (a *Analytics) renderError(errorMsg string) fyne.CanvasObject {
 	title := widget.NewLabel("📊 Advanced Analytics")
@@ -195,6 +169,25 @@ func (a *Analytics) renderOverallStats(stats analytics.TradeStats) fyne.CanvasOb
 	return content
 }
 
+// renderAccountSummary displays account equity derived from the ledger
+func (a *Analytics) renderAccountSummary() fyne.CanvasObject {
+	header := widget.NewLabelWithStyle("Account", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	if a.state.Ledger == nil {
+		return container.NewVBox(header,
+			a.createStatRow("Account Equity", fmt.Sprintf("$%.2f", a.state.CurrentEquity())))
+	}
+
+	ledger := a.state.Ledger
+	deposits := ledger.Total(models.LedgerDeposit) + ledger.Total(models.LedgerStartingBalance)
+	return container.NewVBox(header,
+		a.createStatRow("Current Equity", fmt.Sprintf("$%.2f", ledger.Equity())),
+		a.createStatRow("Capital In", fmt.Sprintf("$%.2f", deposits)),
+		a.createStatRow("Withdrawals", fmt.Sprintf("$%.2f", -ledger.Total(models.LedgerWithdrawal))),
+		a.createStatRow("Realized P&L", a.formatPnL(ledger.Total(models.LedgerRealizedPnL))),
+	)
+}
+
 // renderSectorStats displays performance by sector
 func (a *Analytics) renderSectorStats(stats []analytics.SectorStats) fyne.CanvasObject {
 	header := widget.NewLabelWithStyle("Performance by Sector", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
@@ -269,6 +262,44 @@ func (a *Analytics) renderStrategyStats(stats []analytics.StrategyStats) fyne.Ca
 	return container.NewVBox(rows...)
 }
 
+// renderConvictionStats displays performance by conviction
+func (a *Analytics) renderConvictionStats(stats []analytics.ConvictionStats) fyne.CanvasObject {
+	header := widget.NewLabelWithStyle("Performance by Conviction", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	if len(stats) == 0 {
+		return container.NewVBox(header, widget.NewLabel("No conviction data available"))
+	}
+
+	rows := []fyne.CanvasObject{header}
+
+	headerRow := container.NewHBox(
+		a.createTableCell("Conviction", 120, true),
+		a.createTableCell("Trades", 60, true),
+		a.createTableCell("Win Rate", 80, true),
+		a.createTableCell("Total P&L", 100, true),
+		a.createTableCell("Avg P&L", 100, true),
+	)
+	rows = append(rows, headerRow)
+	rows = append(rows, widget.NewSeparator())
+
+	for _, stat := range stats {
+		conviction := fmt.Sprintf("%d", stat.Conviction)
+		if stat.Conviction == 0 {
+			conviction = "None"
+		}
+		row := container.NewHBox(
+			a.createTableCell(conviction, 120, false),
+			a.createTableCell(fmt.Sprintf("%d", stat.TotalTrades), 60, false),
+			a.createTableCell(fmt.Sprintf("%.1f%%", stat.WinRate), 80, false),
+			a.createTableCell(a.formatPnL(stat.TotalPnL), 100, false),
+			a.createTableCell(a.formatPnL(stat.AveragePnL), 100, false),
+		)
+		rows = append(rows, row)
+	}
+
+	return container.NewVBox(rows...)
+}
+
 // renderEquityCurve displays the equity curve chart
 func (a *Analytics) renderEquityCurve(curve []analytics.EquityCurvePoint) fyne.CanvasObject {
 	header := widget.NewLabelWithStyle("Equity Curve", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
@@ -0,0 +1,159 @@
+package screens
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/audit"
+	"tf-engine/internal/storage"
+)
+
+// showTradeHistory shows every journaled change to one trade
+func showTradeHistory(window fyne.Window, tradeID, title string) {
+	events, err := storage.LoadAuditJournal()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load audit journal: %w", err), window)
+		return
+	}
+
+	content := container.NewVBox(journalStatusLabel(events))
+
+	history := audit.History(events, tradeID)
+	if len(history) == 0 {
+		content.Add(widget.NewLabel("No journaled changes for this trade."))
+	}
+	for _, e := range history {
+		content.Add(widget.NewSeparator())
+		content.Add(renderAuditEvent(e))
+	}
+
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+
+	d := dialog.NewCustom("History: "+title, "Close", scroll, window)
+	d.Show()
+}
+
+// showAuditJournal lists every journaled trade, including deleted ones
+func showAuditJournal(window fyne.Window) {
+	events, err := storage.LoadAuditJournal()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load audit journal: %w", err), window)
+		return
+	}
+
+	type journaledTrade struct {
+		id, ticker string
+		events     int
+		deleted    bool
+	}
+	trades := map[string]*journaledTrade{}
+	for _, e := range events {
+		jt, ok := trades[e.TradeID]
+		if !ok {
+			jt = &journaledTrade{id: e.TradeID}
+			trades[e.TradeID] = jt
+		}
+		jt.events++
+		jt.deleted = e.Type == audit.EventDeleted
+		for _, c := range e.Changes {
+			var ticker string
+			if c.Field == "ticker" && json.Unmarshal(c.After, &ticker) == nil && ticker != "" {
+				jt.ticker = ticker
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(trades))
+	for id := range trades {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	content := container.NewVBox(journalStatusLabel(events))
+	if len(ids) == 0 {
+		content.Add(widget.NewLabel("The audit journal is empty."))
+	}
+	for _, id := range ids {
+		jt := trades[id]
+		label := fmt.Sprintf("%s  %s  (%d events)", jt.ticker, jt.id, jt.events)
+		if jt.deleted {
+			label += "  — deleted"
+		}
+		tradeID, title := jt.id, jt.ticker+" "+jt.id
+		content.Add(widget.NewButton(label, func() {
+			showTradeHistory(window, tradeID, title)
+		}))
+	}
+
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+
+	d := dialog.NewCustom("Audit Journal", "Close", scroll, window)
+	d.Show()
+}
+
+// journalStatusLabel reports whether the hash chain is intact
+func journalStatusLabel(events []audit.Event) fyne.CanvasObject {
+	text := fmt.Sprintf("✓ Journal verified: %d entries, hash chain intact", len(events))
+	if err := audit.Verify(events); err != nil {
+		text = "⚠️ " + err.Error()
+	}
+
+	label := widget.NewLabel(text)
+	label.TextStyle = fyne.TextStyle{Bold: true}
+	label.Wrapping = fyne.TextWrapWord
+	return label
+}
+
+// renderAuditEvent shows when, who, what and the field values that changed
+func renderAuditEvent(e audit.Event) fyne.CanvasObject {
+	header := widget.NewLabel(fmt.Sprintf("#%d  %s  %s  by %s",
+		e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), auditEventName(e.Type), e.Actor))
+	header.TextStyle = fyne.TextStyle{Bold: true}
+
+	lines := []string{}
+	for _, c := range e.Changes {
+		switch {
+		case len(c.Before) == 0:
+			lines = append(lines, fmt.Sprintf("%s: %s", c.Field, c.After))
+		case len(c.After) == 0:
+			lines = append(lines, fmt.Sprintf("%s: %s → (removed)", c.Field, c.Before))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", c.Field, c.Before, c.After))
+		}
+	}
+
+	details := widget.NewLabel(strings.Join(lines, "\n"))
+	details.Wrapping = fyne.TextWrapWord
+
+	return container.NewVBox(header, details)
+}
+
+// auditEventName returns a readable name for an event type
+func auditEventName(t audit.EventType) string {
+	switch t {
+	case audit.EventCreated:
+		return "Created"
+	case audit.EventChecklist:
+		return "Checklist"
+	case audit.EventSizing:
+		return "Sizing"
+	case audit.EventEdited:
+		return "Edited"
+	case audit.EventClosed:
+		return "Closed"
+	case audit.EventDeleted:
+		return "Deleted"
+	case audit.EventReverted:
+		return "Reverted (not saved)"
+	}
+	return string(t)
+}
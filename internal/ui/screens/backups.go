@@ -0,0 +1,195 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/backup"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+// createBackupSection creates the restore and verify buttons
+func (s *Settings) createBackupSection() fyne.CanvasObject {
+	sectionLabel := widget.NewLabel("Backups:")
+	sectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	help := widget.NewLabel(fmt.Sprintf(
+		"A zipped, checksummed backup is taken before every change to the trade history. "+
+			"Kept: newest per hour for %d hours, per day for %d days, per week for %d weeks.",
+		backup.DefaultRetention.Hourly, backup.DefaultRetention.Daily, backup.DefaultRetention.Weekly))
+	help.Wrapping = fyne.TextWrapWord
+
+	restoreBtn := widget.NewButton("Restore from Backup...", func() {
+		showRestoreBackupDialog(s.window, s.state, func() {
+			s.window.SetContent(s.Render())
+		})
+	})
+	verifyBtn := widget.NewButton("Verify All Backups", func() {
+		showVerifyBackups(s.window)
+	})
+
+	return container.NewVBox(sectionLabel, help, container.NewHBox(restoreBtn, verifyBtn))
+}
+
+// showRestoreBackupDialog lists backups with trade counts and shows a diff
+// against the current history before restoring
+func showRestoreBackupDialog(window fyne.Window, state *appcore.AppState, onRestored func()) {
+	results, err := storage.VerifyBackups()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to list backups: %w", err), window)
+		return
+	}
+	if len(results) == 0 {
+		dialog.ShowInformation("Restore from Backup", "No backups yet.", window)
+		return
+	}
+
+	current, err := storage.LoadAllTrades()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to load current trades: %w", err), window)
+		return
+	}
+
+	details := widget.NewLabel("Select a backup to compare it with the current trade history.")
+	details.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+	restoreBtn := widget.NewButton("Restore Selected", nil)
+	restoreBtn.Importance = widget.DangerImportance
+	restoreBtn.Disable()
+
+	list := widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(backupResultLine(results[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		r := results[id]
+		if r.Err != nil {
+			details.SetText(fmt.Sprintf("⚠️ %s cannot be restored: %v", r.Info.Name, r.Err))
+			restoreBtn.Disable()
+			return
+		}
+
+		trades, err := storage.LoadBackupTrades(r.Info)
+		if err == nil {
+			var diff storage.TradeDiff
+			diff, err = storage.DiffTrades(current, trades)
+			details.SetText(describeTradeDiff(r.Info, diff))
+		}
+		if err != nil {
+			details.SetText(fmt.Sprintf("⚠️ %s cannot be restored: %v", r.Info.Name, err))
+			restoreBtn.Disable()
+			return
+		}
+
+		info := r.Info
+		restoreBtn.OnTapped = func() {
+			dialog.ShowConfirm("Restore Backup",
+				fmt.Sprintf("Replace the current trade history with %s?\n\nYou can undo this with Ctrl+Z.", info.Name),
+				func(confirmed bool) {
+					if !confirmed {
+						return
+					}
+					if err := state.RestoreBackup(info); err != nil {
+						dialog.ShowError(err, window)
+						return
+					}
+					d.Hide()
+					if onRestored != nil {
+						onRestored()
+					}
+				}, window)
+		}
+		restoreBtn.Enable()
+	}
+
+	listScroll := container.NewVScroll(list)
+	listScroll.SetMinSize(fyne.NewSize(360, 360))
+	detailScroll := container.NewVScroll(details)
+	detailScroll.SetMinSize(fyne.NewSize(360, 360))
+
+	content := container.NewBorder(nil, restoreBtn, nil, nil,
+		container.NewHSplit(listScroll, detailScroll))
+
+	d = dialog.NewCustom("Restore from Backup", "Close", content, window)
+	d.Resize(fyne.NewSize(800, 480))
+	d.Show()
+}
+
+// showVerifyBackups checks every backup and reports the broken ones
+func showVerifyBackups(window fyne.Window) {
+	results, err := storage.VerifyBackups()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to list backups: %w", err), window)
+		return
+	}
+
+	bad := 0
+	lines := []string{}
+	for _, r := range results {
+		if r.Err != nil {
+			bad++
+		}
+		lines = append(lines, backupResultLine(r))
+	}
+
+	summary := fmt.Sprintf("✓ All %d backups verified", len(results))
+	if bad > 0 {
+		summary = fmt.Sprintf("⚠️ %d of %d backups are corrupt or unparseable", bad, len(results))
+	}
+
+	header := widget.NewLabel(summary)
+	header.TextStyle = fyne.TextStyle{Bold: true}
+	body := widget.NewLabel(strings.Join(lines, "\n"))
+
+	scroll := container.NewVScroll(container.NewVBox(header, widget.NewSeparator(), body))
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+
+	dialog.NewCustom("Verify Backups", "Close", scroll, window).Show()
+}
+
+// backupResultLine summarizes one backup for a list
+func backupResultLine(r backup.Result) string {
+	when := r.Info.Time.Format("2006-01-02 15:04:05")
+	if r.Err != nil {
+		return fmt.Sprintf("⚠️ %s  %s", when, r.Err)
+	}
+	line := fmt.Sprintf("✓ %s  %d trades", when, r.Trades)
+	if r.Info.Legacy {
+		line += "  (unchecksummed .json)"
+	}
+	return line
+}
+
+// describeTradeDiff explains what restoring a backup would change
+func describeTradeDiff(info backup.Info, diff storage.TradeDiff) string {
+	if diff.Empty() {
+		return info.Name + "\n\nIdentical to the current trade history."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Restoring %s would:\n", info.Name)
+	writeTrades := func(heading string, trades []models.Trade) {
+		if len(trades) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", heading, len(trades))
+		for _, t := range trades {
+			fmt.Fprintf(&b, "  %s  %s  %s  P&L $%.2f\n", t.CreatedAt.Format("2006-01-02"), t.Ticker, t.GetStatus(), t.GetPnL())
+		}
+	}
+	writeTrades("Bring back", diff.Added)
+	writeTrades("Remove", diff.Removed)
+	writeTrades("Revert to the backed-up version", diff.Changed)
+	return b.String()
+}
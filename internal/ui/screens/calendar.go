@@ -19,7 +19,7 @@ import (
 
 // Navigator interface for navigation
 type Navigator interface {
-	NavigateToScreen(index int) error
+	StartNewTrade() error
 	RefreshCurrentScreen()
 }
 
@@ -97,8 +97,8 @@ func (c *Calendar) Render() fyne.CanvasObject {
 	// Action buttons
 	newTradeBtn := widget.NewButton("+ New Trade", func() {
 		if c.navigator != nil {
-			// Navigate to Screen 0 (Sector Selection)
-			if err := c.navigator.NavigateToScreen(0); err != nil {
+			// Park the current trade and start a new one at sector selection
+			if err := c.navigator.StartNewTrade(); err != nil {
 				dialog.ShowError(err, c.window)
 			}
 		}
@@ -111,8 +111,13 @@ func (c *Calendar) Render() fyne.CanvasObject {
 		c.refreshCalendar()
 	})
 
+	// Planned dates as an .ics file for calendar apps
+	exportBtn := widget.NewButton("Export .ics...", func() {
+		saveExport(c.window, c.state, exportChoice{Kind: calendarKind})
+	})
+
 	// Sample data button (Phase 2 feature)
-	buttonsContainer := container.NewHBox(newTradeBtn, refreshBtn)
+	buttonsContainer := container.NewHBox(newTradeBtn, refreshBtn, exportBtn)
 
 	if c.featureFlags != nil && c.featureFlags.IsEnabled("sample_data_generator") {
 		sampleDataBtn := widget.NewButton("Generate Sample Data", func() {
@@ -461,8 +466,11 @@ func (c *Calendar) generateSampleData() {
 			// Generate sample trades
 			sampleTrades := generators.GenerateSampleTrades(10)
 
-			// Save to storage
-			if err := storage.SaveAllTrades(sampleTrades); err != nil {
+			// Save to storage, journaling the replaced history
+			_, err := storage.TradeStore{}.Update(func([]models.Trade) ([]models.Trade, error) {
+				return sampleTrades, nil
+			})
+			if err != nil {
 				dialog.ShowError(err, c.window)
 				return
 			}
@@ -7,10 +7,13 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"image/color"
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/audit"
+	"tf-engine/internal/storage"
 	"tf-engine/internal/widgets"
 )
 
@@ -133,8 +136,13 @@ func (s *Checklist) Validate() bool {
 		}
 	}
 
-	// Cooldown must be complete
-	if s.cooldownTimer != nil && !s.cooldownTimer.IsComplete() {
+	// Cooldown must have been started on ticker entry and be complete. The
+	// start time is saved with the trade, so restarting the app or switching
+	// drafts can't skip it.
+	if s.state.CurrentTrade == nil || s.state.CurrentTrade.CooldownStartTime.IsZero() {
+		return false
+	}
+	if time.Since(s.state.CurrentTrade.CooldownStartTime) < s.state.CooldownPeriod() {
 		return false
 	}
 
@@ -262,14 +270,17 @@ func (s *Checklist) createCooldownSection() fyne.CanvasObject {
 	sectionTitle := widget.NewLabel("⏱️ Cooldown Timer")
 	sectionTitle.TextStyle = fyne.TextStyle{Bold: true}
 
+	// Stop the timer of a previous render (or another draft)
+	if s.cooldownTimer != nil {
+		s.cooldownTimer.Stop()
+		s.cooldownTimer = nil
+	}
+
 	// Check if cooldown was started in previous screen
 	if s.state.CurrentTrade != nil && !s.state.CurrentTrade.CooldownStartTime.IsZero() {
-		// Calculate cooldown duration from policy
-		cooldownDuration := time.Duration(s.state.Policy.Defaults.CooldownSeconds) * time.Second
-
-		// Create timer from start time
+		// Rebuild the timer from the trade's saved start time
 		s.cooldownTimer = widgets.NewCooldownTimerFromTime(
-			cooldownDuration,
+			s.state.CooldownPeriod(),
 			s.state.CurrentTrade.CooldownStartTime,
 			func() {
 				// On complete, update validation state
@@ -434,6 +445,11 @@ func (s *Checklist) createNavigationButtons() fyne.CanvasObject {
 	s.continueBtn = widget.NewButton("Continue →", func() {
 		if s.Validate() {
 			s.saveChecklistState()
+			if err := storage.RecordTradeFields(audit.EventChecklist, s.state.CurrentTrade,
+				"checklist_passed", "checklist_required", "checklist_optional"); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to record checklist in audit journal: %w", err), s.window)
+				return
+			}
 			if s.onNext != nil {
 				s.onNext()
 			}
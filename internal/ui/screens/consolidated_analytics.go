@@ -0,0 +1,144 @@
+package screens
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/analytics"
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+// ConsolidatedAnalytics shows read-only performance across all account profiles
+type ConsolidatedAnalytics struct {
+	state  *appcore.AppState
+	window fyne.Window
+	view   *Analytics // Shared table and stat helpers
+}
+
+// NewConsolidatedAnalytics creates the cross-profile analytics screen
+func NewConsolidatedAnalytics(state *appcore.AppState, window fyne.Window) *ConsolidatedAnalytics {
+	return &ConsolidatedAnalytics{
+		state:  state,
+		window: window,
+		view:   NewAnalytics(state, window),
+	}
+}
+
+// Render renders the consolidated analytics UI
+func (c *ConsolidatedAnalytics) Render() fyne.CanvasObject {
+	title := widget.NewLabel("📊 All Profiles (read-only)")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+
+	list, err := storage.LoadProfiles()
+	if err != nil {
+		return c.view.renderError("Failed to load profiles: " + err.Error())
+	}
+
+	summaries := []analytics.ProfileSummary{}
+	tradeSets := [][]models.Trade{}
+	var warnings []string
+	for _, profile := range list.Profiles {
+		trades, err := storage.LoadProfileTrades(profile.ID)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: trades unavailable (%v)", profile.Name, err))
+			trades = []models.Trade{}
+		}
+		ledger, err := storage.LoadProfileLedger(profile.ID)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: ledger unavailable (%v)", profile.Name, err))
+		}
+
+		summaries = append(summaries, analytics.SummarizeProfile(profile, trades, ledger))
+		tradeSets = append(tradeSets, trades)
+	}
+
+	combined := analytics.ConsolidateTrades(tradeSets...)
+
+	content := container.NewVBox(
+		title,
+		widget.NewLabel("Combined view of every profile. Switch profiles from the top bar to make changes."),
+		widget.NewSeparator(),
+		c.renderProfileTable(summaries),
+		widget.NewSeparator(),
+		c.view.renderOverallStats(analytics.CalculateTradeStats(combined)),
+		widget.NewSeparator(),
+		c.view.renderSectorStats(analytics.CalculateSectorStats(combined)),
+		widget.NewSeparator(),
+		c.view.renderStrategyStats(analytics.CalculateStrategyStats(combined)),
+	)
+
+	for _, warning := range warnings {
+		label := widget.NewLabel("⚠️ " + warning)
+		label.Wrapping = fyne.TextWrapWord
+		content.Add(label)
+	}
+
+	return container.NewScroll(content)
+}
+
+// renderProfileTable shows one row per profile plus a combined total
+func (c *ConsolidatedAnalytics) renderProfileTable(summaries []analytics.ProfileSummary) fyne.CanvasObject {
+	header := widget.NewLabelWithStyle("By Profile", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	rows := []fyne.CanvasObject{header,
+		container.NewHBox(
+			c.view.createTableCell("Profile", 140, true),
+			c.view.createTableCell("Trades", 60, true),
+			c.view.createTableCell("Win Rate", 80, true),
+			c.view.createTableCell("Total P&L", 100, true),
+			c.view.createTableCell("Equity", 100, true),
+		),
+		widget.NewSeparator(),
+	}
+
+	var totalTrades int
+	var totalPnL, totalEquity float64
+	for _, s := range summaries {
+		name := s.Profile.Name
+		if s.Profile.ID == c.state.Profile.ID {
+			name += " (active)"
+		}
+
+		equity := "—"
+		if s.HasLedger {
+			equity = fmt.Sprintf("$%.2f", s.Equity)
+			totalEquity += s.Equity
+		}
+
+		rows = append(rows, container.NewHBox(
+			c.view.createTableCell(name, 140, false),
+			c.view.createTableCell(fmt.Sprintf("%d", s.Stats.TotalTrades), 60, false),
+			c.view.createTableCell(fmt.Sprintf("%.1f%%", s.Stats.WinRate), 80, false),
+			c.view.createTableCell(c.view.formatPnL(s.Stats.TotalPnL), 100, false),
+			c.view.createTableCell(equity, 100, false),
+		))
+
+		totalTrades += s.Stats.TotalTrades
+		totalPnL += s.Stats.TotalPnL
+	}
+
+	rows = append(rows, widget.NewSeparator(), container.NewHBox(
+		c.view.createTableCell("Total", 140, true),
+		c.view.createTableCell(fmt.Sprintf("%d", totalTrades), 60, true),
+		c.view.createTableCell("", 80, true),
+		c.view.createTableCell(c.view.formatPnL(totalPnL), 100, true),
+		c.view.createTableCell(fmt.Sprintf("$%.2f", totalEquity), 100, true),
+	))
+
+	return container.NewVBox(rows...)
+}
+
+// Validate validates the screen state (not used for read-only screen)
+func (c *ConsolidatedAnalytics) Validate() bool {
+	return true
+}
+
+// GetName returns the screen name
+func (c *ConsolidatedAnalytics) GetName() string {
+	return "consolidated_analytics"
+}
@@ -0,0 +1,220 @@
+package screens
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/diagnostics"
+	"tf-engine/internal/paths"
+)
+
+// Diagnostics shows what a bug report needs and exports it as a zip
+type Diagnostics struct {
+	state  *appcore.AppState
+	window fyne.Window
+	onBack func()
+}
+
+// NewDiagnostics creates the diagnostics screen
+func NewDiagnostics(state *appcore.AppState, window fyne.Window) *Diagnostics {
+	return &Diagnostics{
+		state:  state,
+		window: window,
+	}
+}
+
+// SetBackCallback sets the callback for the back button
+func (d *Diagnostics) SetBackCallback(onBack func()) {
+	d.onBack = onBack
+}
+
+// Validate validates the screen state (read-only screen)
+func (d *Diagnostics) Validate() bool {
+	return true
+}
+
+// GetName returns the screen name
+func (d *Diagnostics) GetName() string {
+	return "diagnostics"
+}
+
+// Render renders the diagnostics UI
+func (d *Diagnostics) Render() fyne.CanvasObject {
+	title := widget.NewLabel("🩺 Diagnostics")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+
+	report := d.collect()
+
+	exportBtn := widget.NewButton("Export Diagnostics…", func() {
+		d.export()
+	})
+	exportBtn.Importance = widget.HighImportance
+	backBtn := widget.NewButton("← Back", func() {
+		if d.onBack != nil {
+			d.onBack()
+		}
+	})
+
+	content := container.NewVBox(
+		title,
+		widget.NewLabel("Build, system, policy, feature flags and a trade summary without tickers, prices or account values."),
+		widget.NewSeparator(),
+	)
+	for _, section := range reportSections(report) {
+		content.Add(widget.NewLabelWithStyle(section.Title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		for _, line := range section.Lines {
+			label := widget.NewLabel(line)
+			label.Wrapping = fyne.TextWrapWord
+			content.Add(label)
+		}
+		content.Add(widget.NewSeparator())
+	}
+	content.Add(container.NewBorder(nil, nil, backBtn, exportBtn, nil))
+
+	return container.NewPadded(container.NewScroll(content))
+}
+
+func (d *Diagnostics) collect() diagnostics.Report {
+	return diagnostics.Collect(d.state, diagnostics.Options{
+		AppName:    d.state.AppName,
+		AppVersion: d.state.AppVersion,
+	})
+}
+
+// export asks where to save the bundle and writes it there
+func (d *Diagnostics) export() {
+	report := d.collect()
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		if w == nil {
+			return // Cancelled
+		}
+		defer w.Close()
+
+		logs, err := diagnostics.WriteZip(w, report, paths.Log())
+		if err != nil {
+			logger.Error("Diagnostics export failed", "err", err)
+			dialog.ShowError(fmt.Errorf("export diagnostics: %w", err), d.window)
+			return
+		}
+		logger.Info("Diagnostics exported", "path", w.URI().Path(), "logs", len(logs))
+		dialog.ShowInformation("Diagnostics Exported",
+			fmt.Sprintf("Saved %s with %d log file(s).\n\nReview it before sharing: logs may include tickers.", w.URI().Name(), len(logs)),
+			d.window)
+	}, d.window)
+	save.SetFileName(diagnostics.FileName(time.Now()))
+	save.Show()
+}
+
+// reportSection is a titled group of lines on the diagnostics screen
+type reportSection struct {
+	Title string
+	Lines []string
+}
+
+// reportSections formats a report for display
+func reportSections(r diagnostics.Report) []reportSection {
+	build := []string{
+		fmt.Sprintf("%s %s (Go %s)", r.App.Name, r.App.Version, r.Build.GoVersion),
+	}
+	if r.Build.Module != "" {
+		build = append(build, fmt.Sprintf("Module: %s %s", r.Build.Module, r.Build.Version))
+	}
+	if r.Build.VCSRevision != "" {
+		revision := r.Build.VCSRevision
+		if r.Build.VCSModified {
+			revision += " (modified)"
+		}
+		build = append(build, fmt.Sprintf("Revision: %s %s", revision, r.Build.VCSTime))
+	}
+
+	system := []string{
+		fmt.Sprintf("%s/%s, %d CPUs", r.System.OS, r.System.Arch, r.System.NumCPU),
+		fmt.Sprintf("Data directory: %s mode, read-only %t, encrypted %t", r.System.DataMode, r.System.ReadOnly, r.System.Encrypted),
+	}
+
+	policy := []string{fmt.Sprintf("%s version %s", orNone(r.Policy.File), orNone(r.Policy.Version))}
+	if r.Policy.SafeMode {
+		policy = append(policy, "⚠️ Safe mode: the policy failed to load")
+	}
+	if r.Policy.Error != "" {
+		policy = append(policy, "Verification failed: "+r.Policy.Error)
+	} else if r.Policy.Hash != "" {
+		policy = append(policy, fmt.Sprintf("Signature: %s (hash %s)", r.Policy.Status, r.Policy.Hash))
+	}
+
+	flags := []string{}
+	for _, f := range r.Flags {
+		line := fmt.Sprintf("%s: %s (%s)", f.Name, onOff(f.Enabled), f.Layer)
+		if f.Blocked != "" {
+			line += " — " + f.Blocked
+		}
+		flags = append(flags, line)
+	}
+
+	trades := []string{
+		fmt.Sprintf("%d trades, %d in progress, %d profile(s)", r.Trades.Total, r.Trades.Drafts, r.Trades.Profiles),
+		"By status: " + counts(r.Trades.ByStatus),
+		"By sector: " + counts(r.Trades.BySector),
+		"By strategy: " + counts(r.Trades.ByStrategy),
+	}
+	if r.Trades.FirstMonth != "" {
+		trades = append(trades, fmt.Sprintf("From %s to %s", r.Trades.FirstMonth, r.Trades.LastMonth))
+	}
+	if r.Trades.MissingIDs > 0 {
+		trades = append(trades, fmt.Sprintf("⚠️ %d trade(s) have no ID", r.Trades.MissingIDs))
+	}
+
+	sections := []reportSection{
+		{"Build", build},
+		{"System", system},
+		{"Policy", policy},
+		{"Feature Flags", flags},
+		{"Trades", trades},
+	}
+	if len(r.Errors) > 0 {
+		sections = append(sections, reportSection{"Problems", r.Errors})
+	}
+	return sections
+}
+
+func counts(m map[string]int) string {
+	if len(m) == 0 {
+		return "—"
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %d", k, m[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func onOff(on bool) string {
+	if on {
+		return "ON"
+	}
+	return "OFF"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package screens
+
+import (
+	"strings"
+	"testing"
+
+	"tf-engine/internal/diagnostics"
+	"tf-engine/internal/models"
+)
+
+func TestDiagnostics_ReportSections(t *testing.T) {
+	r := diagnostics.Report{
+		App:    diagnostics.AppInfo{Name: "TF-Engine", Version: "2.3.0"},
+		Build:  diagnostics.BuildInfo{GoVersion: "go1.25.3", VCSRevision: "abc123", VCSModified: true},
+		System: diagnostics.SystemInfo{OS: "linux", Arch: "amd64", NumCPU: 8},
+		Policy: diagnostics.PolicyInfo{File: "policy.v1.json", Version: "1.0",
+			PolicyVerification: models.PolicyVerification{Hash: "ff00", Status: models.PolicyMismatch}},
+		Flags: []diagnostics.FlagInfo{
+			{Name: "advanced_analytics", Layer: "cli", Blocked: "requires trade_management"},
+		},
+		Trades: diagnostics.SummarizeTrades([]models.Trade{{Sector: "Healthcare", Status: "closed"}}),
+		Errors: []string{"drafts: unreadable"},
+	}
+
+	text := map[string]string{}
+	for _, s := range reportSections(r) {
+		text[s.Title] = strings.Join(s.Lines, "\n")
+	}
+
+	expected := map[string][]string{
+		"Build":         {"TF-Engine 2.3.0 (Go go1.25.3)", "abc123 (modified)"},
+		"System":        {"linux/amd64, 8 CPUs"},
+		"Policy":        {"policy.v1.json version 1.0", "Signature: mismatch"},
+		"Feature Flags": {"advanced_analytics: OFF (cli) — requires trade_management"},
+		"Trades":        {"1 trades", "By sector: Healthcare 1", "By status: closed 1"},
+		"Problems":      {"drafts: unreadable"},
+	}
+	for title, wants := range expected {
+		for _, want := range wants {
+			if !strings.Contains(text[title], want) {
+				t.Errorf("%s section missing %q:\n%s", title, want, text[title])
+			}
+		}
+	}
+}
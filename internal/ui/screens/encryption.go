@@ -0,0 +1,127 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/storage"
+)
+
+// minPassphraseLen is the shortest passphrase accepted for encryption
+const minPassphraseLen = 8
+
+// createEncryptionSection creates the at-rest encryption controls
+func (s *Settings) createEncryptionSection() fyne.CanvasObject {
+	sectionLabel := widget.NewLabel("Encryption:")
+	sectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	if !storage.EncryptionEnabled() {
+		status := widget.NewLabel("Off — trades, settings and backups are stored as plaintext JSON.")
+		status.Wrapping = fyne.TextWrapWord
+
+		enableBtn := widget.NewButton("Enable Encryption...", s.showEnableEncryptionDialog)
+		return container.NewVBox(sectionLabel, status, enableBtn)
+	}
+
+	status := widget.NewLabel("🔒 On — trades, settings, ledger, audit journal and backups are encrypted (AES-GCM, scrypt). " +
+		"The passphrase is asked for on startup and cannot be recovered.")
+	status.Wrapping = fyne.TextWrapWord
+
+	rekeyBtn := widget.NewButton("Change Passphrase...", s.showRekeyDialog)
+	disableBtn := widget.NewButton("Disable Encryption...", s.showDisableEncryptionDialog)
+	disableBtn.Importance = widget.DangerImportance
+
+	return container.NewVBox(sectionLabel, status, container.NewHBox(rekeyBtn, disableBtn))
+}
+
+// showEnableEncryptionDialog asks for a new passphrase and encrypts all data
+func (s *Settings) showEnableEncryptionDialog() {
+	passEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{
+		{Text: "Passphrase", Widget: passEntry},
+		{Text: "Confirm", Widget: confirmEntry},
+	}
+
+	dialog.ShowForm("Enable Encryption", "Encrypt", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+		if err := checkNewPassphrase(passEntry.Text, confirmEntry.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if err := storage.EnableEncryption(passEntry.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.window.SetContent(s.Render())
+		dialog.ShowInformation("Encryption Enabled",
+			"Your data is now encrypted. Keep the passphrase safe — without it the data cannot be opened.", s.window)
+	}, s.window)
+}
+
+// showRekeyDialog re-encrypts all data with a new passphrase
+func (s *Settings) showRekeyDialog() {
+	currentEntry := widget.NewPasswordEntry()
+	passEntry := widget.NewPasswordEntry()
+	confirmEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{
+		{Text: "Current passphrase", Widget: currentEntry},
+		{Text: "New passphrase", Widget: passEntry},
+		{Text: "Confirm", Widget: confirmEntry},
+	}
+
+	dialog.ShowForm("Change Passphrase", "Re-key", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+		if err := checkNewPassphrase(passEntry.Text, confirmEntry.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if err := storage.Rekey(currentEntry.Text, passEntry.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		dialog.ShowInformation("Passphrase Changed", "All data was re-encrypted with the new passphrase.", s.window)
+	}, s.window)
+}
+
+// showDisableEncryptionDialog decrypts all data back to plaintext
+func (s *Settings) showDisableEncryptionDialog() {
+	currentEntry := widget.NewPasswordEntry()
+
+	items := []*widget.FormItem{
+		{Text: "Current passphrase", Widget: currentEntry},
+	}
+
+	dialog.ShowForm("Disable Encryption", "Decrypt", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+		if err := storage.DisableEncryption(currentEntry.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.window.SetContent(s.Render())
+	}, s.window)
+}
+
+// checkNewPassphrase validates a new passphrase and its confirmation
+func checkNewPassphrase(pass, confirm string) error {
+	if len(pass) < minPassphraseLen {
+		return fmt.Errorf("passphrase must be at least %d characters", minPassphraseLen)
+	}
+	if pass != confirm {
+		return errors.New("passphrases do not match")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package screens
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/export"
+)
+
+// archiveKind is the export menu entry for the JSON archive; calendarKind
+// is the calendar screen's .ics export
+const (
+	archiveKind  = "archive"
+	calendarKind = "calendar"
+)
+
+// exportLabels are the export menu entries
+var exportLabels = map[string]string{
+	export.Trades:      "Trades (CSV, one row per trade)",
+	export.Legs:        "Trades (CSV, one row per leg)",
+	export.Sectors:     "Sector statistics (CSV)",
+	export.Strategies:  "Strategy statistics (CSV)",
+	export.Convictions: "Conviction statistics (CSV)",
+	export.Equity:      "Equity curve (CSV)",
+	archiveKind:        "Full archive with policy and settings (JSON)",
+}
+
+// exportChoice is what the export dialog asks for
+type exportChoice struct {
+	Kind         string
+	Columns      []string // CSV only; empty means all
+	Since, Until string   // YYYY-MM-DD, inclusive; empty means open (calendar: since the screen's look-back)
+}
+
+// write writes the chosen export of src to w
+func (c exportChoice) write(w io.Writer, src export.Source, now time.Time) error {
+	r, err := export.ParseRange(c.Since, c.Until)
+	if err != nil {
+		return err
+	}
+	switch c.Kind {
+	case archiveKind:
+		return export.WriteArchive(w, export.NewArchive(src, r, now))
+	case calendarKind:
+		if c.Since == "" {
+			r.Since = export.DefaultCalendarRange(src.Policy, now).Since
+		}
+		return export.WriteCalendar(w, src, r, export.DefaultCalendarPlan(), now)
+	}
+	return export.WriteCSV(w, src, export.Options{Kind: c.Kind, Columns: c.Columns, Range: r})
+}
+
+func (c exportChoice) fileName(now time.Time) string {
+	switch c.Kind {
+	case archiveKind:
+		return export.FileName(c.Kind, "json", now)
+	case calendarKind:
+		return export.FileName(c.Kind, "ics", now)
+	}
+	return export.FileName(c.Kind, "csv", now)
+}
+
+func exportSource(state *appcore.AppState) export.Source {
+	return export.Source{
+		Trades:   state.AllTrades,
+		Ledger:   state.Ledger,
+		Policy:   state.Policy,
+		Settings: state.Settings,
+	}
+}
+
+// showExportDialog offers the given exports (and the archive) with column
+// and date range choices, then asks where to save the file
+func showExportDialog(window fyne.Window, state *appcore.AppState, kinds []string) {
+	kinds = append(kinds, archiveKind)
+	labels := make([]string, len(kinds))
+	kindFor := make(map[string]string, len(kinds))
+	for i, kind := range kinds {
+		labels[i] = exportLabels[kind]
+		kindFor[labels[i]] = kind
+	}
+
+	columns := widget.NewCheckGroup(nil, nil)
+	columnsBox := container.NewVBox(widget.NewLabel("Columns:"), columns)
+	kindSelect := widget.NewSelect(labels, func(label string) {
+		all, err := export.Columns(kindFor[label])
+		if err != nil {
+			columnsBox.Hide() // The archive has no columns
+			return
+		}
+		columns.Options = all
+		columns.SetSelected(all)
+		columnsBox.Show()
+	})
+
+	since := widget.NewEntry()
+	since.SetPlaceHolder("YYYY-MM-DD (optional)")
+	until := widget.NewEntry()
+	until.SetPlaceHolder("YYYY-MM-DD (optional)")
+
+	form := container.NewVBox(
+		kindSelect,
+		widget.NewForm(
+			widget.NewFormItem("Created from", since),
+			widget.NewFormItem("Created until", until),
+		),
+		container.NewVScroll(columnsBox),
+	)
+	kindSelect.SetSelected(labels[0])
+
+	confirm := dialog.NewCustomConfirm("Export", "Save...", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		choice := exportChoice{Kind: kindFor[kindSelect.Selected], Since: since.Text, Until: until.Text}
+		if choice.Kind != archiveKind && len(columns.Selected) < len(columns.Options) {
+			if len(columns.Selected) == 0 {
+				dialog.ShowError(fmt.Errorf("select at least one column"), window)
+				return
+			}
+			choice.Columns = orderedSelection(columns.Options, columns.Selected)
+		}
+		if _, err := export.ParseRange(choice.Since, choice.Until); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		saveExport(window, state, choice)
+	}, window)
+	confirm.Resize(fyne.NewSize(480, 560))
+	confirm.Show()
+}
+
+// orderedSelection returns the selected options in table order
+func orderedSelection(options, selected []string) []string {
+	picked := make(map[string]bool, len(selected))
+	for _, s := range selected {
+		picked[s] = true
+	}
+	result := []string{}
+	for _, o := range options {
+		if picked[o] {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// saveExport asks where to save the export and writes it there
+func saveExport(window fyne.Window, state *appcore.AppState, choice exportChoice) {
+	now := time.Now()
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if w == nil {
+			return // Cancelled
+		}
+		defer w.Close()
+
+		if err := choice.write(w, exportSource(state), now); err != nil {
+			logger.Error("Export failed", "kind", choice.Kind, "err", err)
+			dialog.ShowError(fmt.Errorf("export %s: %w", choice.Kind, err), window)
+			return
+		}
+		logger.Info("Exported", "kind", choice.Kind, "path", w.URI().Path())
+		dialog.ShowInformation("Exported", fmt.Sprintf("Saved %s.", w.URI().Name()), window)
+	}, window)
+	save.SetFileName(choice.fileName(now))
+	save.Show()
+}
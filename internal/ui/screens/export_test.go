@@ -0,0 +1,57 @@
+package screens
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/export"
+	"tf-engine/internal/models"
+)
+
+func TestExportChoice(t *testing.T) {
+	pnl := 120.0
+	src := export.Source{Trades: []models.Trade{
+		{ID: "T1", Ticker: "NVDA", CreatedAt: time.Date(2025, 5, 2, 9, 0, 0, 0, time.Local), ProfitLoss: &pnl},
+		{ID: "T2", Ticker: "UNH", CreatedAt: time.Date(2025, 6, 2, 9, 0, 0, 0, time.Local)},
+	}}
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)
+
+	buf := &bytes.Buffer{}
+	choice := exportChoice{Kind: export.Trades, Columns: []string{"id", "ticker"}, Until: "2025-05-31"}
+	if err := choice.write(buf, src, now); err != nil || buf.String() != "id,ticker\nT1,NVDA\n" {
+		t.Errorf("Got %q (%v)", buf.String(), err)
+	}
+	if name := choice.fileName(now); name != "tf-engine-trades-20250701.csv" {
+		t.Errorf("Unexpected file name %s", name)
+	}
+
+	buf.Reset()
+	archive := exportChoice{Kind: archiveKind}
+	if err := archive.write(buf, src, now); err != nil || !strings.Contains(buf.String(), export.ArchiveFormat) {
+		t.Errorf("Archive not written (%v)", err)
+	}
+	if !strings.HasSuffix(archive.fileName(now), ".json") {
+		t.Errorf("Archive file name %s", archive.fileName(now))
+	}
+
+	buf.Reset()
+	src.Trades[0].ExpirationDate = time.Date(2025, 7, 18, 0, 0, 0, 0, time.Local)
+	calendar := exportChoice{Kind: calendarKind}
+	if err := calendar.write(buf, src, now); err != nil || !strings.Contains(buf.String(), "UID:T1-expiration@tf-engine") {
+		t.Errorf("Calendar not written (%v): %s", err, buf.String())
+	}
+	if name := calendar.fileName(now); name != "tf-engine-calendar-20250701.ics" {
+		t.Errorf("Calendar file name %s", name)
+	}
+
+	if got := orderedSelection([]string{"id", "ticker", "status"}, []string{"status", "id"}); strings.Join(got, ",") != "id,status" {
+		t.Errorf("orderedSelection = %v", got)
+	}
+	for kind := range exportLabels {
+		if _, err := export.Columns(kind); err != nil && kind != archiveKind {
+			t.Errorf("Label for unknown export %s", kind)
+		}
+	}
+}
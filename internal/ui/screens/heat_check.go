@@ -344,7 +344,8 @@ func (s *HeatCheck) createNavigationButtons() fyne.CanvasObject {
 
 // calculateHeat calculates portfolio-wide and sector-specific heat
 func (s *HeatCheck) calculateHeat() (portfolioHeat, sectorHeat float64) {
-	if s.state.CurrentTrade == nil || s.state.CurrentTrade.AccountEquity == 0 {
+	accountSize := s.accountEquity()
+	if s.state.CurrentTrade == nil || accountSize == 0 {
 		return 0, 0
 	}
 
@@ -352,8 +353,6 @@ func (s *HeatCheck) calculateHeat() (portfolioHeat, sectorHeat float64) {
 	// For now, use empty slice (will be implemented in storage integration)
 	activeTrades := []models.Trade{}
 
-	accountSize := s.state.CurrentTrade.AccountEquity
-
 	// Calculate existing heat
 	for _, trade := range activeTrades {
 		tradeHeat := trade.MaxLoss / accountSize
@@ -374,14 +373,14 @@ func (s *HeatCheck) calculateHeat() (portfolioHeat, sectorHeat float64) {
 
 // calculateSectorHeat calculates heat for a specific sector
 func (s *HeatCheck) calculateSectorHeat(sectorName string, includeNewTrade bool) float64 {
-	if s.state.CurrentTrade == nil || s.state.CurrentTrade.AccountEquity == 0 {
+	accountSize := s.accountEquity()
+	if s.state.CurrentTrade == nil || accountSize == 0 {
 		return 0
 	}
 
 	// TODO: Load active trades from storage
 	activeTrades := []models.Trade{}
 
-	accountSize := s.state.CurrentTrade.AccountEquity
 	var heat float64
 
 	// Calculate existing heat
@@ -399,6 +398,18 @@ func (s *HeatCheck) calculateSectorHeat(sectorName string, includeNewTrade bool)
 	return heat
 }
 
+// accountEquity returns the equity heat is measured against: the ledger's
+// current equity, or the equity recorded on the trade when no ledger exists
+func (s *HeatCheck) accountEquity() float64 {
+	if s.state.Ledger != nil {
+		return s.state.Ledger.Equity()
+	}
+	if s.state.CurrentTrade != nil {
+		return s.state.CurrentTrade.AccountEquity
+	}
+	return 0
+}
+
 // getSectorCap returns the heat cap for a specific sector
 func (s *HeatCheck) getSectorCap(sectorName string) float64 {
 	// Find sector in policy
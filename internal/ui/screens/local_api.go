@@ -0,0 +1,121 @@
+package screens
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/api"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/webhook"
+)
+
+// createAPISection creates the local API controls. The server starts with
+// the app, so changes apply after a restart.
+func (s *Settings) createAPISection() fyne.CanvasObject {
+	sectionLabel := widget.NewLabel("Local API:")
+	sectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	apiSettings := models.APISettings{Port: models.DefaultAPIPort}
+	if s.state.Settings != nil {
+		apiSettings = s.state.Settings.API
+	}
+
+	s.apiEnabledCheck = widget.NewCheck("Serve trades, heat, stats, policy and settings as JSON on localhost", nil)
+	s.apiEnabledCheck.SetChecked(apiSettings.Enabled)
+	s.apiPortEntry = widget.NewEntry()
+	s.apiPortEntry.SetText(strconv.Itoa(apiSettings.ListenPort()))
+
+	help := widget.NewLabel(fmt.Sprintf("Listens on 127.0.0.1 only and needs the token below as a Bearer token. "+
+		"The endpoints are described at http://%s/api/v1/openapi.yaml. Changes apply after a restart.",
+		api.Addr(apiSettings.ListenPort())))
+	help.Wrapping = fyne.TextWrapWord
+
+	copyBtn := widget.NewButton("Copy Token", func() {
+		token, err := api.LoadOrCreateToken(paths.Config(api.TokenFile))
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		fyne.CurrentApp().Clipboard().SetContent(token)
+	})
+	regenerateBtn := widget.NewButton("Regenerate Token...", func() {
+		dialog.ShowConfirm("Regenerate Token",
+			"The new token replaces the current one when the app next starts. Scripts will need the new token. Continue?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if _, err := api.RegenerateToken(paths.Config(api.TokenFile)); err != nil {
+					dialog.ShowError(err, s.window)
+				}
+			}, s.window)
+	})
+
+	return container.NewVBox(
+		sectionLabel,
+		s.apiEnabledCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Port:"), nil, s.apiPortEntry),
+		help,
+		container.NewHBox(copyBtn, regenerateBtn),
+	)
+}
+
+// createWebhookSection creates the alert webhook controls. Like the API, the
+// listener starts with the app.
+func (s *Settings) createWebhookSection() fyne.CanvasObject {
+	sectionLabel := widget.NewLabel("Alert Webhook:")
+	sectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	hook := models.WebhookSettings{Port: models.DefaultWebhookPort}
+	if s.state.Settings != nil {
+		hook = s.state.Settings.Webhook
+	}
+
+	s.webhookEnabledCheck = widget.NewCheck("Create drafts from TradingView strategy alerts", nil)
+	s.webhookEnabledCheck.SetChecked(hook.Enabled)
+	s.webhookPortEntry = widget.NewEntry()
+	s.webhookPortEntry.SetText(strconv.Itoa(hook.ListenPort()))
+
+	help := widget.NewLabel(fmt.Sprintf("Alerts are POSTed to http://%s%s?token=<token> with a JSON message such as "+
+		`{"ticker": "{{ticker}}", "strategy": "Alt10", "direction": "{{strategy.order.action}}", "price": {{close}}}. `+
+		"The sector comes from the policy; unknown strategies and blocked sectors are rejected. "+
+		"Drafts still need the cooldown and checklist. Listens on 127.0.0.1 only, so TradingView needs a tunnel. "+
+		"Changes apply after a restart.",
+		api.Addr(hook.ListenPort()), webhook.Path))
+	help.Wrapping = fyne.TextWrapWord
+
+	copyBtn := widget.NewButton("Copy Token", func() {
+		token, err := api.LoadOrCreateToken(paths.Config(webhook.TokenFile))
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		fyne.CurrentApp().Clipboard().SetContent(token)
+	})
+	regenerateBtn := widget.NewButton("Regenerate Token...", func() {
+		dialog.ShowConfirm("Regenerate Token",
+			"The new token replaces the current one when the app next starts. Alerts will need the new token. Continue?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if _, err := api.RegenerateToken(paths.Config(webhook.TokenFile)); err != nil {
+					dialog.ShowError(err, s.window)
+				}
+			}, s.window)
+	})
+
+	return container.NewVBox(
+		sectionLabel,
+		s.webhookEnabledCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Port:"), nil, s.webhookPortEntry),
+		help,
+		container.NewHBox(copyBtn, regenerateBtn),
+	)
+}
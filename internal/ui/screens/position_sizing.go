@@ -7,10 +7,15 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"image/color"
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/audit"
+	"tf-engine/internal/models"
+	"tf-engine/internal/sizing"
+	"tf-engine/internal/storage"
 )
 
 // PositionSizing represents Screen 5: Position Size Calculator
@@ -30,6 +35,7 @@ type PositionSizing struct {
 	multiplierLabel  *widget.Label
 	calculatedRisk   *widget.Label
 	explanationLabel *widget.Label
+	scheduleLabel    *widget.Label
 	warningBanner    *fyne.Container
 	warningLabel     *widget.Label
 	continueBtn      *widget.Button
@@ -260,11 +266,11 @@ func (s *PositionSizing) createAccountSection() fyne.CanvasObject {
 	s.accountEntry = widget.NewEntry()
 	s.accountEntry.SetPlaceHolder("e.g., 25000")
 
-	// Restore previous value or use default from Settings
+	// Restore previous value or use current equity from the ledger
 	if s.state.CurrentTrade != nil && s.state.CurrentTrade.AccountEquity > 0 {
 		s.accountEntry.SetText(fmt.Sprintf("%.0f", s.state.CurrentTrade.AccountEquity))
-	} else if s.state.Settings != nil {
-		s.accountEntry.SetText(fmt.Sprintf("%.0f", s.state.Settings.AccountEquity))
+	} else if equity := s.state.CurrentEquity(); equity > 0 {
+		s.accountEntry.SetText(fmt.Sprintf("%.0f", equity))
 	} else {
 		s.accountEntry.SetText("25000") // Fallback default $25k
 	}
@@ -318,6 +324,11 @@ func (s *PositionSizing) createResultsSection() fyne.CanvasObject {
 	s.explanationLabel.TextStyle = fyne.TextStyle{Italic: true}
 	s.explanationLabel.Alignment = fyne.TextAlignCenter
 
+	s.scheduleLabel = widget.NewLabel("")
+	s.scheduleLabel.Wrapping = fyne.TextWrapWord
+	s.scheduleLabel.Alignment = fyne.TextAlignCenter
+	s.scheduleLabel.Hide()
+
 	// Initial calculation
 	s.updateCalculation()
 
@@ -327,6 +338,7 @@ func (s *PositionSizing) createResultsSection() fyne.CanvasObject {
 	resultCard := container.NewVBox(
 		s.calculatedRisk,
 		s.explanationLabel,
+		s.scheduleLabel,
 	)
 
 	// Card with colored background
@@ -361,6 +373,12 @@ func (s *PositionSizing) createNavigationButtons() fyne.CanvasObject {
 	s.continueBtn = widget.NewButton("Continue →", func() {
 		if s.Validate() {
 			s.savePositionSizing()
+			if err := storage.RecordTradeFields(audit.EventSizing, s.state.CurrentTrade,
+				"conviction", "account_equity", "risk_per_trade", "sizing_multiplier",
+				"risk_schedule_step", "risk_schedule_multiplier", "max_loss"); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to record sizing in audit journal: %w", err), s.window)
+				return
+			}
 			if s.onNext != nil {
 				s.onNext()
 			}
@@ -441,22 +459,61 @@ func (s *PositionSizing) updateCalculation() {
 	// Get multiplier
 	multiplier := s.getMultiplier(conviction)
 
+	// Apply the drawdown risk schedule (if the policy defines one)
+	adj := s.riskAdjustment()
+
 	// Calculate risk amount
 	baseRisk := account * (riskPercent / 100.0)
-	adjustedRisk := baseRisk * multiplier
+	adjustedRisk := baseRisk * adj.Multiplier * multiplier
 
 	// Update display
 	s.calculatedRisk.SetText(fmt.Sprintf("Risk Amount: $%.2f", adjustedRisk))
 
-	s.explanationLabel.SetText(fmt.Sprintf(
-		"Base risk: $%.2f (%.2f%% of $%.0f) × %.2f× conviction multiplier = $%.2f total risk",
-		baseRisk, riskPercent, account, multiplier, adjustedRisk,
-	))
+	if adj.Step > 0 {
+		s.explanationLabel.SetText(fmt.Sprintf(
+			"Base risk: $%.2f (%.2f%% of $%.0f) × %.2f× drawdown schedule × %.2f× conviction multiplier = $%.2f total risk",
+			baseRisk, riskPercent, account, adj.Multiplier, multiplier, adjustedRisk,
+		))
+	} else {
+		s.explanationLabel.SetText(fmt.Sprintf(
+			"Base risk: $%.2f (%.2f%% of $%.0f) × %.2f× conviction multiplier = $%.2f total risk",
+			baseRisk, riskPercent, account, multiplier, adjustedRisk,
+		))
+	}
+
+	if s.scheduleLabel != nil {
+		if adj.Active {
+			s.scheduleLabel.SetText("📉 Drawdown schedule - " + adj.Reason)
+			s.scheduleLabel.Show()
+		} else {
+			s.scheduleLabel.Hide()
+		}
+	}
 
 	// Check if position size exceeds sector heat cap
 	s.checkHeatLimits(account, adjustedRisk)
 }
 
+// riskAdjustment returns the drawdown risk schedule step currently in force
+func (s *PositionSizing) riskAdjustment() sizing.RiskAdjustment {
+	var schedule *models.RiskSchedule
+	if s.state.Policy != nil {
+		schedule = s.state.Policy.RiskSchedule
+	}
+	return sizing.ApplyRiskSchedule(schedule, sizing.EquityHistory(s.state.Ledger))
+}
+
+// applyMaxLoss records the schedule step and calculates the trade's max loss
+func (s *PositionSizing) applyMaxLoss() {
+	adj := s.riskAdjustment()
+	trade := s.state.CurrentTrade
+	trade.RiskScheduleStep = adj.Step
+	trade.RiskScheduleMult = adj.Multiplier
+
+	baseRisk := trade.AccountEquity * trade.RiskPerTrade
+	trade.MaxLoss = baseRisk * adj.Multiplier * trade.SizingMultiplier
+}
+
 // checkHeatLimits validates if the position size would exceed sector heat caps
 func (s *PositionSizing) checkHeatLimits(accountSize, riskAmount float64) {
 	if s.state.CurrentTrade == nil || accountSize <= 0 {
@@ -584,8 +641,7 @@ func (s *PositionSizing) saveInitialValues() {
 		s.state.CurrentTrade.AccountEquity > 0 &&
 		s.state.CurrentTrade.RiskPerTrade > 0 &&
 		s.state.CurrentTrade.SizingMultiplier > 0 {
-		baseRisk := s.state.CurrentTrade.AccountEquity * s.state.CurrentTrade.RiskPerTrade
-		s.state.CurrentTrade.MaxLoss = baseRisk * s.state.CurrentTrade.SizingMultiplier
+		s.applyMaxLoss()
 	}
 
 	// Update continue button state
@@ -619,7 +675,6 @@ func (s *PositionSizing) savePositionSizing() {
 		s.state.CurrentTrade.SizingMultiplier = s.getMultiplier(conviction)
 
 		// Calculate and save max loss
-		baseRisk := s.state.CurrentTrade.AccountEquity * s.state.CurrentTrade.RiskPerTrade
-		s.state.CurrentTrade.MaxLoss = baseRisk * s.state.CurrentTrade.SizingMultiplier
+		s.applyMaxLoss()
 	}
 }
@@ -0,0 +1,130 @@
+package screens
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/brokers"
+)
+
+// showStatementImport asks for a broker statement, reads it and shows the
+// reconciliation preview. onApplied runs after the changes are saved.
+func showStatementImport(window fyne.Window, state *appcore.AppState, onApplied func()) {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if r == nil {
+			return // Cancelled
+		}
+		defer r.Close()
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("read statement: %w", err), window)
+			return
+		}
+		plan, format, err := reconcileStatement(r.URI().Name(), data, state)
+		if err != nil {
+			logger.Error("Statement import failed", "file", r.URI().Name(), "err", err)
+			dialog.ShowError(err, window)
+			return
+		}
+		showReconcilePreview(window, state, plan, format, onApplied)
+	}, window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	open.Show()
+}
+
+// reconcileStatement parses a statement in whichever format it is and plans
+// its reconciliation with the journal. Generic CSV files must use the
+// default column names; other mappings need the trades reconcile command.
+func reconcileStatement(name string, data []byte, state *appcore.AppState) (brokers.Plan, string, error) {
+	parser, err := brokers.DetectParser(data, brokers.DefaultMapping())
+	if err != nil {
+		return brokers.Plan{}, "", fmt.Errorf("%s: %w", name, err)
+	}
+	fills, err := parser.Parse(data)
+	if err != nil {
+		return brokers.Plan{}, "", fmt.Errorf("%s: %w", name, err)
+	}
+	return brokers.Reconcile(name, fills, state.AllTrades, state.Policy, time.Now()), parser.Name(), nil
+}
+
+// selectedPlan keeps the unchanged items and the changes that are ticked
+func selectedPlan(plan brokers.Plan, ticked map[int]bool) brokers.Plan {
+	selected := brokers.Plan{Source: plan.Source, Items: []brokers.Item{}}
+	for i, item := range plan.Items {
+		if !item.Changes() || ticked[i] {
+			selected.Items = append(selected.Items, item)
+		}
+	}
+	return selected
+}
+
+// reconcileLabel describes an item, e.g. "close NVDA 2025-02-21: Closed ..."
+func reconcileLabel(item brokers.Item) string {
+	expiration := "stock"
+	if !item.Position.Expiration.IsZero() {
+		expiration = item.Position.Expiration.Format("2006-01-02")
+	}
+	label := fmt.Sprintf("%s %s %s", item.Action, item.Position.Underlying, expiration)
+	if item.After.OptionsStrategy != "" {
+		label += " (" + item.After.OptionsStrategy + ")"
+	}
+	return label + ": " + item.Note
+}
+
+// showReconcilePreview lists the plan with a tick per change; nothing is
+// written until Apply
+func showReconcilePreview(window fyne.Window, state *appcore.AppState, plan brokers.Plan, format string, onApplied func()) {
+	if len(plan.Items) == 0 {
+		dialog.ShowInformation("Import Statement", fmt.Sprintf("%s has no fills.", plan.Source), window)
+		return
+	}
+
+	ticked := map[int]bool{}
+	rows := container.NewVBox()
+	for i, item := range plan.Items {
+		if !item.Changes() {
+			rows.Add(widget.NewLabel("   " + reconcileLabel(item)))
+			continue
+		}
+		i := i
+		ticked[i] = true
+		check := widget.NewCheck(reconcileLabel(item), func(on bool) { ticked[i] = on })
+		check.SetChecked(true)
+		rows.Add(check)
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("%s statement: %s. Untick any change you don't want.", format, plan.Summary()))
+	summary.Wrapping = fyne.TextWrapWord
+	content := container.NewBorder(summary, nil, nil, nil, container.NewVScroll(rows))
+
+	confirm := dialog.NewCustomConfirm("Reconcile "+plan.Source, "Apply", "Cancel", content, func(apply bool) {
+		if !apply {
+			return
+		}
+		selected := selectedPlan(plan, ticked)
+		if selected.Changes() == 0 {
+			return
+		}
+		if err := state.ExecuteTradeCommand(selected.Command()); err != nil {
+			dialog.ShowError(fmt.Errorf("reconcile: %w", err), window)
+			return
+		}
+		logger.Info("Reconciled statement", "file", plan.Source, "format", format, "changes", selected.Changes())
+		onApplied()
+	}, window)
+	confirm.Resize(fyne.NewSize(720, 520))
+	confirm.Show()
+}
@@ -0,0 +1,39 @@
+package screens
+
+import (
+	"testing"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/brokers"
+)
+
+func TestReconcileStatement(t *testing.T) {
+	state := appcore.NewAppState()
+	state.UseSafeMode()
+
+	statement := "Date,Symbol,Side,Quantity,Price,Fees\n" +
+		"2025-02-10,XOM,buy,50,108.25,1\n" +
+		"2025-02-12,XOM,sell,50,110,1\n" +
+		"2025-02-14,KO,buy,10,62,0\n"
+	plan, format, err := reconcileStatement("fills.csv", []byte(statement), state)
+	if err != nil || format != "generic" {
+		t.Fatalf("reconcileStatement: %s, %v", format, err)
+	}
+	if len(plan.Items) != 2 || plan.Changes() != 2 {
+		t.Fatalf("Expected two new trades, got %s", plan.Summary())
+	}
+	if got := reconcileLabel(plan.Items[0]); got != "new XOM stock: Closed position not in the journal, P&L $85.50" {
+		t.Errorf("reconcileLabel = %q", got)
+	}
+
+	// Unticked changes are left out; unchanged items stay for the record
+	plan.Items = append(plan.Items, brokers.Item{Action: brokers.ActionUnchanged})
+	selected := selectedPlan(plan, map[int]bool{1: true})
+	if len(selected.Items) != 2 || selected.Items[0].Position.Underlying != "KO" || selected.Changes() != 1 {
+		t.Errorf("selectedPlan kept %+v", selected.Items)
+	}
+
+	if _, _, err := reconcileStatement("notes.csv", []byte("a,b\n1,2\n"), state); err == nil {
+		t.Error("Expected an unrecognized file to fail")
+	}
+}
@@ -459,7 +459,7 @@ func (s *ScreenerLaunch) createInfoBanner() fyne.CanvasObject {
 	return container.NewStack(bg, container.NewPadded(banner))
 }
 
-// showError displays an error message
+// showError logs an error message
 func (s *ScreenerLaunch) showError(message string) {
-	fmt.Printf("ERROR: %s\n", message)
+	logger.Error(message, "screen", s.GetName())
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image/color"
 	"sort"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -261,7 +262,7 @@ func (s *SectorSelection) selectSector(sector models.Sector) {
 
 	// Initialize a new trade with selected sector
 	if s.state.CurrentTrade == nil {
-		s.state.CurrentTrade = &models.Trade{}
+		s.state.CurrentTrade = &models.Trade{ID: models.NewTradeID(), CreatedAt: time.Now()}
 	}
 	s.state.CurrentTrade.Sector = sector.Name
 	s.selectedSector = sector.Name
@@ -333,7 +334,7 @@ func (s *SectorSelection) showUtilitiesModal(sector models.Sector) {
 	// Go Back button
 	goBackBtn := widget.NewButton("← Go Back", func() {
 		dialog.Hide()
-		fmt.Println("User declined Utilities sector (Go Back)")
+		logger.Info("User declined Utilities sector (Go Back)")
 	})
 
 	// Continue Anyway button (initially disabled)
@@ -341,14 +342,14 @@ func (s *SectorSelection) showUtilitiesModal(sector models.Sector) {
 		if ackCheckbox.Checked {
 			// Initialize trade with Utilities sector
 			if s.state.CurrentTrade == nil {
-				s.state.CurrentTrade = &models.Trade{}
+				s.state.CurrentTrade = &models.Trade{ID: models.NewTradeID(), CreatedAt: time.Now()}
 			}
 			s.state.CurrentTrade.Sector = sector.Name
 			s.state.CurrentTrade.UtilitiesWarningAcknowledged = true
 			s.selectedSector = sector.Name
 
 			// Log warning acknowledgement
-			fmt.Printf("⚠️  UTILITIES SECTOR WARNING ACKNOWLEDGED by user\n")
+			logger.Warn("Utilities sector warning acknowledged")
 
 			// Enable continue button
 			if s.continueBtn != nil {
@@ -395,6 +396,6 @@ func (s *SectorSelection) showError(message string) {
 
 		// Simple error display - in production, this would be a proper dialog
 		// For now, just log it since we don't want to disrupt the current screen
-		fmt.Printf("ERROR: %s\n", message)
+		logger.Error(message, "screen", s.GetName())
 	}
 }
@@ -3,6 +3,7 @@ package screens
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -12,7 +13,9 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"image/color"
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
 	"tf-engine/internal/storage"
+	"tf-engine/internal/ui/components"
 )
 
 // Settings represents the Settings/Configuration screen
@@ -24,9 +27,23 @@ type Settings struct {
 	onBack func()
 
 	// UI components
-	accountEntry     *widget.Entry
-	riskPercentEntry *widget.Entry
-	themeSelect      *widget.Select
+	accountEntry        *widget.Entry
+	riskPercentEntry    *widget.Entry
+	themeSelect         *widget.Select
+	privateLogsCheck    *widget.Check
+	apiEnabledCheck     *widget.Check
+	apiPortEntry        *widget.Entry
+	webhookEnabledCheck *widget.Check
+	webhookPortEntry    *widget.Entry
+
+	// Prop firm rule components
+	propFirmCheck         *widget.Check
+	propBalanceEntry      *widget.Entry
+	propDailyLossEntry    *widget.Entry
+	propDrawdownEntry     *widget.Entry
+	propContractsEntry    *widget.Entry
+	propTradingDaysEntry  *widget.Entry
+	propProfitTargetEntry *widget.Entry
 }
 
 // NewSettings creates a new settings screen
@@ -42,6 +59,16 @@ func (s *Settings) SetBackCallback(onBack func()) {
 	s.onBack = onBack
 }
 
+// Validate always passes: settings are checked when saved
+func (s *Settings) Validate() bool {
+	return true
+}
+
+// GetName returns the screen name
+func (s *Settings) GetName() string {
+	return "settings"
+}
+
 // Render renders the settings UI
 func (s *Settings) Render() fyne.CanvasObject {
 	// Header
@@ -71,11 +98,16 @@ func (s *Settings) Render() fyne.CanvasObject {
 		}
 	})
 
+	// Effective feature flags and where each came from
+	flagsBtn := widget.NewButton("🚩 Feature Flags", func() {
+		components.ShowFeatureFlagsDialog(s.state.FeatureFlags, s.window)
+	})
+
 	buttons := container.NewBorder(
 		nil, nil,
 		backBtn,
 		saveBtn,
-		nil,
+		container.NewCenter(flagsBtn),
 	)
 
 	content := container.NewVBox(
@@ -133,6 +165,13 @@ func (s *Settings) createSettingsForm() fyne.CanvasObject {
 	accountHelp := widget.NewLabel("Your total trading capital")
 	accountHelp.TextStyle = fyne.TextStyle{Italic: true}
 
+	// Equity is derived from the ledger once one exists
+	if s.state.Ledger != nil {
+		s.accountEntry.SetText(fmt.Sprintf("%.0f", s.state.Ledger.Equity()))
+		s.accountEntry.Disable()
+		accountHelp.SetText("Derived from the account ledger - record deposits and withdrawals below")
+	}
+
 	// Risk Per Trade
 	riskLabel := widget.NewLabel("Risk Per Trade (%):")
 	riskLabel.TextStyle = fyne.TextStyle{Bold: true}
@@ -171,6 +210,12 @@ func (s *Settings) createSettingsForm() fyne.CanvasObject {
 		s.themeSelect.Selected = "Day Mode"
 	}
 
+	// Privacy: keep equity and P&L out of the log files
+	s.privateLogsCheck = widget.NewCheck("Hide account equity and P&L in logs", nil)
+	if s.state.Settings != nil {
+		s.privateLogsCheck.SetChecked(s.state.Settings.PrivateLogs)
+	}
+
 	// Add change listeners for preview
 	s.accountEntry.OnChanged = func(value string) {
 		s.updatePreview(previewLabel)
@@ -195,11 +240,211 @@ func (s *Settings) createSettingsForm() fyne.CanvasObject {
 
 		themeLabel,
 		s.themeSelect,
+		s.privateLogsCheck,
+		widget.NewSeparator(),
+
+		s.createLedgerSection(),
+		widget.NewSeparator(),
+
+		s.createBackupSection(),
+		widget.NewSeparator(),
+
+		s.createEncryptionSection(),
+		widget.NewSeparator(),
+
+		s.createAPISection(),
+		widget.NewSeparator(),
+
+		s.createWebhookSection(),
+		widget.NewSeparator(),
+
+		s.createPropFirmForm(),
 	)
 
 	return form
 }
 
+// createLedgerSection creates the account ledger summary and cash flow buttons
+func (s *Settings) createLedgerSection() fyne.CanvasObject {
+	sectionLabel := widget.NewLabel("Account Ledger:")
+	sectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	if s.state.Ledger == nil {
+		return container.NewVBox(sectionLabel, widget.NewLabel("No ledger loaded"))
+	}
+
+	equityLabel := widget.NewLabel(fmt.Sprintf("Current Equity: $%.2f", s.state.Ledger.Equity()))
+	equityLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	depositBtn := widget.NewButton("Record Deposit", func() {
+		s.showCashFlowDialog(models.LedgerDeposit)
+	})
+	withdrawBtn := widget.NewButton("Record Withdrawal", func() {
+		s.showCashFlowDialog(models.LedgerWithdrawal)
+	})
+
+	// Most recent entries first
+	historyBox := container.NewVBox()
+	history := s.state.Ledger.History()
+	for i := len(history) - 1; i >= 0 && i >= len(history)-10; i-- {
+		entry := history[i].Entry
+		historyBox.Add(widget.NewLabel(fmt.Sprintf("%s  %-16s %+10.2f  → $%.2f  %s",
+			entry.Date.Format("2006-01-02"), entry.Type, entry.Amount, history[i].Balance, entry.Note)))
+	}
+
+	return container.NewVBox(
+		sectionLabel,
+		equityLabel,
+		container.NewHBox(depositBtn, withdrawBtn),
+		widget.NewLabel("Recent entries:"),
+		historyBox,
+	)
+}
+
+// showCashFlowDialog prompts for a deposit or withdrawal and posts it to the ledger
+func (s *Settings) showCashFlowDialog(entryType string) {
+	amountEntry := widget.NewEntry()
+	amountEntry.SetPlaceHolder("e.g., 1000")
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+
+	noteEntry := widget.NewEntry()
+
+	title := "Record Deposit"
+	if entryType == models.LedgerWithdrawal {
+		title = "Record Withdrawal"
+	}
+
+	items := []*widget.FormItem{
+		{Text: "Amount ($)", Widget: amountEntry},
+		{Text: "Date (YYYY-MM-DD)", Widget: dateEntry},
+		{Text: "Note", Widget: noteEntry},
+	}
+
+	dialog.ShowForm(title, "Save", "Cancel", items, func(submitted bool) {
+		if !submitted {
+			return
+		}
+
+		amount, err := strconv.ParseFloat(amountEntry.Text, 64)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Invalid amount: %s", amountEntry.Text), s.window)
+			return
+		}
+
+		date, err := time.ParseInLocation("2006-01-02", dateEntry.Text, time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Invalid date: %s", dateEntry.Text), s.window)
+			return
+		}
+
+		if entryType == models.LedgerWithdrawal {
+			err = s.state.Ledger.Withdraw(amount, date, noteEntry.Text)
+		} else {
+			err = s.state.Ledger.Deposit(amount, date, noteEntry.Text)
+		}
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+
+		if err := storage.SaveLedger(s.state.Ledger); err != nil {
+			dialog.ShowError(fmt.Errorf("Failed to save ledger: %v", err), s.window)
+			return
+		}
+
+		// Refresh to show the new equity
+		s.window.SetContent(s.Render())
+	}, s.window)
+}
+
+// createPropFirmForm creates the prop firm rules inputs
+func (s *Settings) createPropFirmForm() fyne.CanvasObject {
+	rules := models.DefaultPropFirmRules()
+	if s.state.Settings != nil {
+		rules = s.state.Settings.PropFirm
+	}
+
+	sectionLabel := widget.NewLabel("Prop Firm Rules:")
+	sectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	s.propFirmCheck = widget.NewCheck("Enforce prop firm rules (blocks new trades when a limit is breached)", nil)
+	s.propFirmCheck.SetChecked(rules.Enabled)
+
+	newEntry := func(value string) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(value)
+		return entry
+	}
+	s.propBalanceEntry = newEntry(fmt.Sprintf("%.0f", rules.StartingBalance))
+	s.propDailyLossEntry = newEntry(fmt.Sprintf("%.0f", rules.DailyLossLimit))
+	s.propDrawdownEntry = newEntry(fmt.Sprintf("%.0f", rules.TrailingMaxDrawdown))
+	s.propContractsEntry = newEntry(fmt.Sprintf("%d", rules.MaxOpenContracts))
+	s.propTradingDaysEntry = newEntry(fmt.Sprintf("%d", rules.MinTradingDays))
+	s.propProfitTargetEntry = newEntry(fmt.Sprintf("%.0f", rules.ProfitTarget))
+
+	grid := container.NewGridWithColumns(2,
+		widget.NewLabel("Starting Balance ($):"), s.propBalanceEntry,
+		widget.NewLabel("Daily Loss Limit ($):"), s.propDailyLossEntry,
+		widget.NewLabel("Trailing Max Drawdown ($):"), s.propDrawdownEntry,
+		widget.NewLabel("Max Open Contracts:"), s.propContractsEntry,
+		widget.NewLabel("Minimum Trading Days:"), s.propTradingDaysEntry,
+		widget.NewLabel("Profit Target ($):"), s.propProfitTargetEntry,
+	)
+
+	help := widget.NewLabel("Set a limit to 0 to disable that rule")
+	help.TextStyle = fyne.TextStyle{Italic: true}
+
+	return container.NewVBox(
+		sectionLabel,
+		s.propFirmCheck,
+		grid,
+		help,
+	)
+}
+
+// parsePropFirmRules parses the prop firm rule inputs
+func (s *Settings) parsePropFirmRules() (models.PropFirmRules, error) {
+	rules := models.PropFirmRules{Enabled: s.propFirmCheck.Checked}
+
+	dollarFields := []struct {
+		name  string
+		entry *widget.Entry
+		dest  *float64
+	}{
+		{"starting balance", s.propBalanceEntry, &rules.StartingBalance},
+		{"daily loss limit", s.propDailyLossEntry, &rules.DailyLossLimit},
+		{"trailing max drawdown", s.propDrawdownEntry, &rules.TrailingMaxDrawdown},
+		{"profit target", s.propProfitTargetEntry, &rules.ProfitTarget},
+	}
+	for _, field := range dollarFields {
+		value, err := strconv.ParseFloat(field.entry.Text, 64)
+		if err != nil || value < 0 {
+			return rules, fmt.Errorf("Invalid %s: %s", field.name, field.entry.Text)
+		}
+		*field.dest = value
+	}
+
+	countFields := []struct {
+		name  string
+		entry *widget.Entry
+		dest  *int
+	}{
+		{"max open contracts", s.propContractsEntry, &rules.MaxOpenContracts},
+		{"minimum trading days", s.propTradingDaysEntry, &rules.MinTradingDays},
+	}
+	for _, field := range countFields {
+		value, err := strconv.Atoi(field.entry.Text)
+		if err != nil || value < 0 {
+			return rules, fmt.Errorf("Invalid %s: %s", field.name, field.entry.Text)
+		}
+		*field.dest = value
+	}
+
+	return rules, nil
+}
+
 // createPreviewLabel creates the preview calculation label
 func (s *Settings) createPreviewLabel() *widget.Label {
 	previewLabel := widget.NewLabel("")
@@ -266,19 +511,43 @@ func (s *Settings) saveSettings() {
 		return
 	}
 
-	// Update settings
-	s.state.Settings.AccountEquity = account
-	s.state.Settings.RiskPerTrade = riskPercent / 100.0 // Store as decimal
+	// Parse and validate prop firm rules
+	propFirmRules, err := s.parsePropFirmRules()
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+
+	apiPort, err := strconv.Atoi(s.apiPortEntry.Text)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Invalid API port: %s", s.apiPortEntry.Text), s.window)
+		return
+	}
+	webhookPort, err := strconv.Atoi(s.webhookPortEntry.Text)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Invalid webhook port: %s", s.webhookPortEntry.Text), s.window)
+		return
+	}
+
+	// Update a copy so nothing changes unless it validates and saves
+	updated := *s.state.Settings
+	updated.PropFirm = propFirmRules
+	updated.AccountEquity = account
+	updated.RiskPerTrade = riskPercent / 100.0 // Store as decimal
+	updated.API = models.APISettings{Enabled: s.apiEnabledCheck.Checked, Port: apiPort}
+	updated.Webhook = models.WebhookSettings{Enabled: s.webhookEnabledCheck.Checked, Port: webhookPort}
 
 	// Update theme
 	if s.themeSelect.Selected == "Night Mode" {
-		s.state.Settings.ThemeMode = "night"
+		updated.ThemeMode = "night"
 	} else {
-		s.state.Settings.ThemeMode = "day"
+		updated.ThemeMode = "day"
 	}
 
-	// Save to disk
-	if err := storage.SaveSettings(s.state.Settings); err != nil {
+	updated.PrivateLogs = s.privateLogsCheck.Checked
+
+	// Validate and save to disk
+	if err := s.state.SaveSettings(&updated); err != nil {
 		dialog.ShowError(
 			fmt.Errorf("Failed to save settings: %v", err),
 			s.window,
@@ -12,9 +12,12 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"image/color"
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/logging"
 	"tf-engine/internal/models"
 )
 
+var logger = logging.For(logging.UI)
+
 // TickerEntry represents Screen 3: Ticker Entry & Strategy Selection
 type TickerEntry struct {
 	state  *appcore.AppState
@@ -87,6 +90,9 @@ func (s *TickerEntry) Render() fyne.CanvasObject {
 	// Navigation buttons
 	navButtons := s.createNavigationButtons()
 
+	// Show what a resumed draft or alert already filled in
+	s.restoreTrade()
+
 	// Layout
 	content := container.NewBorder(
 		container.NewVBox(header, infoBanner),
@@ -138,6 +144,10 @@ func (s *TickerEntry) createInfoBanner() fyne.CanvasObject {
 			"A 5-minute cooldown will start when you proceed.",
 			sectorName),
 	)
+	if trade := s.state.CurrentTrade; trade != nil && trade.Signal != nil {
+		text.SetText(text.Text + fmt.Sprintf(" Filled in from a %s %s alert at %.2f (received %s); check it before proceeding.",
+			trade.Direction, trade.Signal.Source, trade.Signal.Price, trade.Signal.ReceivedAt.Format("Jan 2 15:04")))
+	}
 	text.Wrapping = fyne.TextWrapWord
 
 	content := container.NewBorder(
@@ -235,17 +245,15 @@ func (s *TickerEntry) createStrategyDropdown() *widget.Select {
 // getAllStrategiesWithIndicators returns top strategies with color-coded suitability indicators
 func (s *TickerEntry) getAllStrategiesWithIndicators() []string {
 	if s.state.CurrentTrade == nil || s.state.CurrentTrade.Sector == "" {
-		fmt.Println("DEBUG: No current trade or sector, returning empty list")
+		logger.Debug("No current trade or sector, no strategies to list")
 		return []string{}
 	}
 
-	// DEBUG: Log policy strategy count
-	fmt.Printf("DEBUG: Total strategies in policy: %d\n", len(s.state.Policy.Strategies))
-	fmt.Printf("DEBUG: Current sector: %s\n", s.state.CurrentTrade.Sector)
+	logger.Debug("Listing strategies", "policy_strategies", len(s.state.Policy.Strategies), "sector", s.state.CurrentTrade.Sector)
 
 	sector := findSector(s.state.Policy, s.state.CurrentTrade.Sector)
 	if sector == nil {
-		fmt.Println("DEBUG: Sector not found in policy")
+		logger.Debug("Sector not found in policy", "sector", s.state.CurrentTrade.Sector)
 		return []string{}
 	}
 
@@ -253,18 +261,49 @@ func (s *TickerEntry) getAllStrategiesWithIndicators() []string {
 
 	var strategyLabels []string
 	for _, option := range options {
-		indicator := getColorIndicatorText(option.Suitability.Color)
-		label := fmt.Sprintf("%s %s - %s", indicator, option.ID, option.Strategy.Label)
-		strategyLabels = append(strategyLabels, label)
-		fmt.Printf("DEBUG: Added strategy: %s (color: %s, rating: %s)\n",
-			option.ID, option.Suitability.Color, option.Suitability.Rating)
+		strategyLabels = append(strategyLabels, strategyLabel(option))
+		logger.Debug("Added strategy", "strategy", option.ID, "color", option.Suitability.Color, "rating", option.Suitability.Rating)
 	}
 
-	fmt.Printf("DEBUG: Returning %d strategy labels\n", len(strategyLabels))
-
 	return strategyLabels
 }
 
+// strategyLabel formats a dropdown entry, e.g. "[GREEN] Alt10 - Profit Targets"
+func strategyLabel(option strategyOption) string {
+	indicator := getColorIndicatorText(option.Suitability.Color)
+	return fmt.Sprintf("%s %s - %s", indicator, option.ID, option.Strategy.Label)
+}
+
+// restoreTrade fills the form from the current trade, e.g. a draft created
+// from an alert. A strategy outside the top five is added to the dropdown.
+// Selecting it again resets the acknowledgement, so warnings still need one.
+func (s *TickerEntry) restoreTrade() {
+	trade := s.state.CurrentTrade
+	if trade == nil {
+		return
+	}
+	if trade.Ticker != "" {
+		s.tickerEntry.SetText(trade.Ticker)
+	}
+	if trade.Strategy == "" {
+		return
+	}
+
+	option, ok := buildStrategyOption(s.state.Policy, findSector(s.state.Policy, trade.Sector), trade.Strategy)
+	if !ok {
+		return
+	}
+	label := strategyLabel(option)
+	found := false
+	for _, existing := range s.strategySelect.Options {
+		found = found || existing == label
+	}
+	if !found {
+		s.strategySelect.Options = append(s.strategySelect.Options, label)
+	}
+	s.strategySelect.SetSelected(label)
+}
+
 // getSuitability returns the suitability rating for a strategy in a given sector
 func (s *TickerEntry) getSuitability(strategyID, sector string) models.StrategySuitability {
 	if s.state.Policy == nil {
@@ -324,8 +363,8 @@ func (s *TickerEntry) onStrategySelected(value string) {
 		s.continueBtn.Disable()
 
 		// Log warning display
-		fmt.Printf("⚠️  Strategy warning displayed: %s in %s (rating: %s)\n",
-			strategyID, s.state.CurrentTrade.Sector, suitability.Rating)
+		logger.Info("Strategy warning displayed",
+			"strategy", strategyID, "sector", s.state.CurrentTrade.Sector, "rating", suitability.Rating)
 	} else {
 		// Green strategy - no warning needed
 		s.hideWarningBanner()
@@ -473,10 +512,10 @@ func (s *TickerEntry) startCooldownAndProceed() {
 			s.state.CurrentTrade.StrategyWarningAcknowledged = true
 
 			// Log warning acknowledgement
-			fmt.Printf("⚠️  Strategy warning ACKNOWLEDGED: %s in %s (rating: %s)\n",
-				s.state.CurrentTrade.Strategy,
-				s.state.CurrentTrade.Sector,
-				suitability.Rating)
+			logger.Warn("Strategy warning acknowledged",
+				"strategy", s.state.CurrentTrade.Strategy,
+				"sector", s.state.CurrentTrade.Sector,
+				"rating", suitability.Rating)
 		}
 	}
 
@@ -484,9 +523,9 @@ func (s *TickerEntry) startCooldownAndProceed() {
 	s.state.StartCooldown()
 
 	// Log cooldown start
-	fmt.Printf("✓ Cooldown started: 5 minutes for %s (%s)\n",
-		s.state.CurrentTrade.Ticker,
-		s.state.CurrentTrade.Strategy,
+	logger.Info("Cooldown started",
+		"ticker", s.state.CurrentTrade.Ticker,
+		"strategy", s.state.CurrentTrade.Strategy,
 	)
 
 	// Proceed to next screen
@@ -545,7 +584,7 @@ func (s *TickerEntry) hideWarningBanner() {
 	s.warningBanner.Refresh()
 }
 
-// showError displays an error message (console for now)
+// showError logs an error message
 func (s *TickerEntry) showError(message string) {
-	fmt.Printf("❌ Error: %s\n", message)
+	logger.Error(message, "screen", s.GetName())
 }
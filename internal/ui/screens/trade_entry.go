@@ -10,6 +10,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
+	"tf-engine/internal/options"
 	"tf-engine/internal/storage"
 )
 
@@ -26,6 +28,7 @@ type TradeEntry struct {
 	strike4Entry   *widget.Entry
 	expirationDate *widget.Entry
 	premiumEntry   *widget.Entry
+	contractsLabel *widget.Label // OCC symbols of the entered legs
 	saveBtn        *widget.Button
 
 	// Dynamic containers for conditional fields
@@ -97,6 +100,13 @@ func (t *TradeEntry) initializeComponents() {
 	t.expirationDate = widget.NewEntry()
 	t.expirationDate.SetPlaceHolder("Days to expiration (e.g., 45)")
 
+	// Contract names follow the strikes and DTE as they're typed
+	t.contractsLabel = widget.NewLabel("")
+	t.contractsLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	for _, entry := range []*widget.Entry{t.strike1Entry, t.strike2Entry, t.strike3Entry, t.strike4Entry, t.expirationDate} {
+		entry.OnChanged = func(string) { t.updateContracts() }
+	}
+
 	// Premium
 	t.premiumEntry = widget.NewEntry()
 	t.premiumEntry.SetPlaceHolder("Total premium (e.g., 2.50)")
@@ -142,6 +152,43 @@ func (t *TradeEntry) onStrategySelected(strategy string) {
 	}
 
 	t.strikeContainer.Refresh()
+	t.updateContracts()
+}
+
+// updateContracts shows the contracts the entered legs stand for
+func (t *TradeEntry) updateContracts() {
+	t.contractsLabel.SetText(t.contractsPreview(time.Now()))
+}
+
+// contractsPreview names the OCC contracts of the selected strategy, shown
+// strikes and DTE, or returns "" until they describe complete legs
+func (t *TradeEntry) contractsPreview(now time.Time) string {
+	if t.state.CurrentTrade == nil || t.strategySelect.Selected == "" {
+		return ""
+	}
+	dte, err := strconv.Atoi(t.expirationDate.Text)
+	if err != nil {
+		return ""
+	}
+
+	trade := models.Trade{
+		Ticker:          t.state.CurrentTrade.Ticker,
+		OptionsStrategy: t.strategySelect.Selected,
+		ExpirationDate:  now.AddDate(0, 0, dte),
+	}
+	strikes := []*float64{&trade.Strike1, &trade.Strike2, &trade.Strike3, &trade.Strike4}
+	entries := []*widget.Entry{t.strike1Entry, t.strike2Entry, t.strike3Entry, t.strike4Entry}
+	for i := 0; i < t.getRequiredStrikes(trade.OptionsStrategy) && i < len(entries); i++ {
+		if v, err := strconv.ParseFloat(entries[i].Text, 64); err == nil {
+			*strikes[i] = v
+		}
+	}
+
+	legs, err := options.Legs(trade)
+	if err != nil {
+		return ""
+	}
+	return "Contracts: " + options.Names(legs)
 }
 
 // getRequiredStrikes returns the number of strikes needed for a strategy
@@ -249,6 +296,7 @@ func (t *TradeEntry) Render() fyne.CanvasObject {
 		widget.NewLabel("Expiration Date:"),
 		t.expirationDate,
 		widget.NewLabel("(Enter days to expiration, e.g., 45 for 45 DTE)"),
+		t.contractsLabel,
 		widget.NewSeparator(),
 
 		widget.NewLabel("Total Premium:"),
@@ -378,18 +426,20 @@ func (t *TradeEntry) saveTrade() {
 	t.state.AllTrades = append(t.state.AllTrades, *t.state.CurrentTrade)
 
 	// Show success message
-	dialog.ShowInformation(
-		"Trade Saved",
-		fmt.Sprintf("Trade saved successfully!\n\nTicker: %s\nOptions: %s\nExpiration: %s",
-			t.state.CurrentTrade.Ticker,
-			t.state.CurrentTrade.OptionsStrategy,
-			t.state.CurrentTrade.ExpirationDate.Format("2006-01-02"),
-		),
-		t.window,
+	message := fmt.Sprintf("Trade saved successfully!\n\nTicker: %s\nOptions: %s\nExpiration: %s",
+		t.state.CurrentTrade.Ticker,
+		t.state.CurrentTrade.OptionsStrategy,
+		t.state.CurrentTrade.ExpirationDate.Format("2006-01-02"),
 	)
+	if legs, err := options.Legs(*t.state.CurrentTrade); err == nil {
+		for _, leg := range legs {
+			message += "\n" + leg.Name()
+		}
+	}
+	dialog.ShowInformation("Trade Saved", message, t.window)
 
 	// Clear current trade (ready for next one)
-	t.state.CurrentTrade = nil
+	t.state.StartNewDraft()
 
 	// Navigation to calendar will be handled by navigator
 }
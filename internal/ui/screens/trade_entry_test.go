@@ -243,6 +243,33 @@ func TestTradeEntry_OnStrategySelected_TwoLeg(t *testing.T) {
 	}
 }
 
+// TestTradeEntry_ContractsPreview tests the OCC names shown while entering legs
+func TestTradeEntry_ContractsPreview(t *testing.T) {
+	state := appcore.NewAppState()
+	state.CurrentTrade = &models.Trade{Ticker: "UNH"}
+	window := test.NewWindow(nil)
+	defer window.Close()
+	screen := NewTradeEntry(state, window)
+	now := time.Date(2024, 12, 3, 10, 0, 0, 0, time.Local)
+
+	screen.strategySelect.SetSelected("Bull call spread")
+	screen.strike1Entry.SetText("500")
+	if got := screen.contractsPreview(now); got != "" {
+		t.Errorf("Incomplete legs should show nothing, got %q", got)
+	}
+
+	screen.strike2Entry.SetText("520")
+	screen.strike3Entry.SetText("540") // Hidden for a two-leg strategy
+	screen.expirationDate.SetText("45")
+	want := "Contracts: +1 UNH   250117C00500000, -1 UNH   250117C00520000"
+	if got := screen.contractsPreview(now); got != want {
+		t.Errorf("contractsPreview() = %q, want %q", got, want)
+	}
+	if screen.contractsLabel.Text == "" {
+		t.Error("Typing should update the contracts label")
+	}
+}
+
 // TestTradeEntry_Render tests screen rendering
 func TestTradeEntry_Render(t *testing.T) {
 	// Arrange
@@ -2,32 +2,38 @@ package screens
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"tf-engine/internal/appcore"
-	"tf-engine/internal/config"
+	"tf-engine/internal/commands"
+	"tf-engine/internal/export"
 	"tf-engine/internal/models"
+	"tf-engine/internal/options"
 	"tf-engine/internal/storage"
+	"tf-engine/internal/ui/components"
 )
 
 // TradeManagement represents Screen 9: Trade Management (Phase 2 Feature)
 type TradeManagement struct {
 	state        *appcore.AppState
 	window       fyne.Window
-	featureFlags *config.FeatureFlags
-	filterStatus string // "all", "active", "closed"
+	filterStatus string          // "all", "active", "closed"
+	selected     map[string]bool // Trade IDs ticked for bulk status changes
 }
 
 // NewTradeManagement creates a new trade management screen
-func NewTradeManagement(state *appcore.AppState, window fyne.Window, featureFlags *config.FeatureFlags) *TradeManagement {
+func NewTradeManagement(state *appcore.AppState, window fyne.Window) *TradeManagement {
 	return &TradeManagement{
 		state:        state,
 		window:       window,
-		featureFlags: featureFlags,
 		filterStatus: "all",
+		selected:     map[string]bool{},
 	}
 }
 
@@ -36,11 +42,6 @@ func (tm *TradeManagement) Render() fyne.CanvasObject {
 	title := widget.NewLabel("Screen 9: Trade Management")
 	title.TextStyle = fyne.TextStyle{Bold: true}
 
-	// Check if feature is enabled
-	if tm.featureFlags != nil && !tm.featureFlags.IsEnabled("trade_management") {
-		return tm.renderDisabledState()
-	}
-
 	// Filter dropdown
 	filterLabel := widget.NewLabel("Filter:")
 	filterSelect := widget.NewSelect([]string{"Show All", "Active Only", "Closed Only"}, func(value string) {
@@ -58,6 +59,19 @@ func (tm *TradeManagement) Render() fyne.CanvasObject {
 
 	filterBar := container.NewHBox(filterLabel, filterSelect)
 
+	// Bulk status change for ticked trades
+	bulkSelect := widget.NewSelect([]string{"active", "closed", "expired"}, func(status string) {
+		if err := tm.setSelectedStatus(status); err != nil {
+			dialog.ShowError(err, tm.window)
+			tm.window.SetContent(tm.Render())
+			return
+		}
+		tm.refreshAfterCommand()
+	})
+	bulkSelect.PlaceHolder = "Set status of selected..."
+	filterBar.Add(widget.NewSeparator())
+	filterBar.Add(bulkSelect)
+
 	// Load trades
 	trades := tm.getFilteredTrades()
 
@@ -69,7 +83,19 @@ func (tm *TradeManagement) Render() fyne.CanvasObject {
 		// Navigator will handle navigation back
 	})
 
-	buttons := container.NewHBox(backBtn)
+	journalBtn := widget.NewButton("📜 Audit Journal", func() {
+		showAuditJournal(tm.window)
+	})
+
+	exportBtn := widget.NewButton("Export...", func() {
+		showExportDialog(tm.window, tm.state, []string{export.Trades, export.Legs})
+	})
+
+	importBtn := widget.NewButton("Import Statement...", func() {
+		showStatementImport(tm.window, tm.state, tm.refreshAfterCommand)
+	})
+
+	buttons := container.NewHBox(backBtn, journalBtn, exportBtn, importBtn)
 
 	content := container.NewVBox(
 		title,
@@ -85,40 +111,6 @@ func (tm *TradeManagement) Render() fyne.CanvasObject {
 	return container.NewScroll(content)
 }
 
-// renderDisabledState shows a message when feature flag is OFF
-func (tm *TradeManagement) renderDisabledState() fyne.CanvasObject {
-	title := widget.NewLabel("Screen 9: Trade Management")
-	title.TextStyle = fyne.TextStyle{Bold: true}
-
-	message := widget.NewLabel("This feature is currently disabled.")
-	message.Wrapping = fyne.TextWrapWord
-
-	flag := tm.featureFlags.GetFlag("trade_management")
-	var details string
-	if flag != nil {
-		details = fmt.Sprintf("Feature: %s\nPhase: %d\nAvailable in version: %s",
-			flag.Description, flag.Phase, flag.SinceVersion)
-	}
-
-	detailsLabel := widget.NewLabel(details)
-	detailsLabel.Wrapping = fyne.TextWrapWord
-
-	backBtn := widget.NewButton("Back to Calendar", func() {
-		// Navigator will handle navigation back
-	})
-
-	content := container.NewVBox(
-		title,
-		widget.NewSeparator(),
-		message,
-		detailsLabel,
-		widget.NewSeparator(),
-		backBtn,
-	)
-
-	return container.NewCenter(content)
-}
-
 // createTradesTable creates a table widget with trade data
 func (tm *TradeManagement) createTradesTable(trades []models.Trade) fyne.CanvasObject {
 	if len(trades) == 0 {
@@ -130,6 +122,7 @@ func (tm *TradeManagement) createTradesTable(trades []models.Trade) fyne.CanvasO
 
 	// Header row
 	header := container.NewHBox(
+		tm.createTableCell("", 40, true),
 		tm.createTableCell("Date", 100, true),
 		tm.createTableCell("Ticker", 80, true),
 		tm.createTableCell("Sector", 120, true),
@@ -167,6 +160,15 @@ func (tm *TradeManagement) createTradeRow(trade *models.Trade) fyne.CanvasObject
 		pnlStr = "+" + pnlStr
 	}
 
+	// Selection checkbox for bulk actions
+	selectCheck := widget.NewCheck("", func(checked bool) {
+		if tm.selected == nil {
+			tm.selected = map[string]bool{}
+		}
+		tm.selected[trade.ID] = checked
+	})
+	selectCheck.Checked = tm.selected[trade.ID]
+
 	// Create cells
 	dateCell := tm.createTableCell(dateStr, 100, false)
 	tickerCell := tm.createTableCell(trade.Ticker, 80, false)
@@ -187,9 +189,15 @@ func (tm *TradeManagement) createTradeRow(trade *models.Trade) fyne.CanvasObject
 	})
 	deleteBtn.Importance = widget.DangerImportance
 
-	actionsCell := container.NewHBox(editBtn, deleteBtn)
+	historyBtn := widget.NewButton("History", func() {
+		tm.showHistory(trade)
+	})
+	historyBtn.Importance = widget.LowImportance
+
+	actionsCell := container.NewHBox(editBtn, historyBtn, deleteBtn)
 
 	row := container.NewHBox(
+		container.NewGridWrap(fyne.NewSize(40, 36), selectCheck),
 		dateCell,
 		tickerCell,
 		sectorCell,
@@ -200,6 +208,12 @@ func (tm *TradeManagement) createTradeRow(trade *models.Trade) fyne.CanvasObject
 		actionsCell,
 	)
 
+	// Contract names under the row, when the strategy and strikes give them
+	if contracts := options.Describe(*trade); contracts != "" {
+		contractsLabel := widget.NewLabel(contracts)
+		contractsLabel.TextStyle = fyne.TextStyle{Monospace: true}
+		return container.NewVBox(row, container.NewHBox(container.NewGridWrap(fyne.NewSize(40, 20)), contractsLabel))
+	}
 	return row
 }
 
@@ -251,6 +265,9 @@ func (tm *TradeManagement) editTrade(trade *models.Trade) {
 	pnlEntry := widget.NewEntry()
 	pnlEntry.SetText(fmt.Sprintf("%.2f", trade.GetPnL()))
 
+	openPnLEntry := widget.NewEntry()
+	openPnLEntry.SetText(fmt.Sprintf("%.2f", trade.GetOpenPnL()))
+
 	statusSelect := widget.NewSelect([]string{"active", "closed", "expired"}, nil)
 	statusSelect.Selected = trade.GetStatus()
 
@@ -259,6 +276,7 @@ func (tm *TradeManagement) editTrade(trade *models.Trade) {
 		Items: []*widget.FormItem{
 			{Text: "Ticker", Widget: tickerEntry},
 			{Text: "P&L ($)", Widget: pnlEntry},
+			{Text: "Open P&L ($)", Widget: openPnLEntry},
 			{Text: "Status", Widget: statusSelect},
 		},
 		OnSubmit: func() {
@@ -270,6 +288,13 @@ func (tm *TradeManagement) editTrade(trade *models.Trade) {
 			fmt.Sscanf(pnlEntry.Text, "%f", &pnl)
 			trade.ProfitLoss = &pnl
 
+			// Parse open (mark-to-market) P&L
+			var openPnL float64
+			fmt.Sscanf(openPnLEntry.Text, "%f", &openPnL)
+			if trade.OpenPnL == nil || *trade.OpenPnL != openPnL {
+				trade.MarkOpenPnL(openPnL, time.Now())
+			}
+
 			trade.Status = statusSelect.Selected
 
 			// Save trade
@@ -278,14 +303,25 @@ func (tm *TradeManagement) editTrade(trade *models.Trade) {
 				return
 			}
 
-			// Refresh display
-			tm.window.SetContent(tm.Render())
+			// Refresh display and offer undo
+			tm.refreshAfterCommand()
 		},
 		OnCancel: func() {
 			// Dialog will close automatically
 		},
 	}
 
+	// Contracts are derived from the strategy and strikes, so shown read-only
+	if legs, err := options.Legs(*trade); err == nil {
+		names := make([]string, len(legs))
+		for i, leg := range legs {
+			names[i] = leg.Name()
+		}
+		contracts := widget.NewLabel(strings.Join(names, "\n"))
+		contracts.TextStyle = fyne.TextStyle{Monospace: true}
+		form.Items = append([]*widget.FormItem{{Text: "Contracts", Widget: contracts}}, form.Items...)
+	}
+
 	// Show dialog
 	dialog.ShowForm("Edit Trade", "Save", "Cancel", form.Items, func(submitted bool) {
 		if submitted {
@@ -296,7 +332,7 @@ func (tm *TradeManagement) editTrade(trade *models.Trade) {
 
 // confirmDeleteTrade shows confirmation dialog before deleting
 func (tm *TradeManagement) confirmDeleteTrade(trade *models.Trade) {
-	message := fmt.Sprintf("Are you sure you want to delete this trade?\n\nTicker: %s\nSector: %s\nEntry: %s\n\nThis action cannot be undone.",
+	message := fmt.Sprintf("Are you sure you want to delete this trade?\n\nTicker: %s\nSector: %s\nEntry: %s\n\nYou can undo this with Ctrl+Z.",
 		trade.Ticker, trade.Sector, trade.CreatedAt.Format("2006-01-02"))
 
 	dialog.ShowConfirm("Delete Trade", message, func(confirmed bool) {
@@ -306,56 +342,61 @@ func (tm *TradeManagement) confirmDeleteTrade(trade *models.Trade) {
 				return
 			}
 
-			// Refresh display
-			tm.window.SetContent(tm.Render())
+			// Refresh display and offer undo
+			tm.refreshAfterCommand()
 		}
 	}, tm.window)
 }
 
-// updateTrade saves updated trade to storage
+// updateTrade validates and saves an updated trade through the undo stack
 func (tm *TradeManagement) updateTrade(trade *models.Trade) error {
-	// Load all trades
-	allTrades, err := storage.LoadAllTrades()
-	if err != nil {
-		return fmt.Errorf("failed to load trades: %w", err)
-	}
+	return tm.state.UpdateTrade(*trade)
+}
+
+// deleteTrade removes a trade from storage through the undo stack
+func (tm *TradeManagement) deleteTrade(trade *models.Trade) error {
+	return tm.state.ExecuteTradeCommand(commands.NewDeleteCommand(*trade))
+}
 
-	// Find and update the trade
-	found := false
-	for i := range allTrades {
-		if allTrades[i].ID == trade.ID {
-			allTrades[i] = *trade
-			found = true
-			break
+// setSelectedStatus sets the status of every selected trade in one undoable step
+func (tm *TradeManagement) setSelectedStatus(status string) error {
+	ids := []string{}
+	for id, selected := range tm.selected {
+		if selected {
+			ids = append(ids, id)
 		}
 	}
-
-	if !found {
-		return fmt.Errorf("trade not found: %s", trade.ID)
+	if len(ids) == 0 {
+		return fmt.Errorf("no trades selected")
 	}
+	sort.Strings(ids)
 
-	// Save all trades
-	return storage.SaveAllTrades(allTrades)
+	if err := tm.state.ExecuteTradeCommand(commands.NewBulkStatusCommand(ids, status)); err != nil {
+		return err
+	}
+	tm.selected = map[string]bool{}
+	return nil
 }
 
-// deleteTrade removes a trade from storage
-func (tm *TradeManagement) deleteTrade(trade *models.Trade) error {
-	// Load all trades
-	allTrades, err := storage.LoadAllTrades()
-	if err != nil {
-		return fmt.Errorf("failed to load trades: %w", err)
-	}
+// refreshAfterCommand re-renders the list and offers to undo the last command
+func (tm *TradeManagement) refreshAfterCommand() {
+	tm.window.SetContent(tm.Render())
 
-	// Filter out the deleted trade
-	filteredTrades := []models.Trade{}
-	for _, t := range allTrades {
-		if t.ID != trade.ID {
-			filteredTrades = append(filteredTrades, t)
+	name := tm.state.Commands.UndoName()
+	components.ShowToast(tm.window, "✓ "+name, "Undo", func() {
+		cmd, err := tm.state.UndoTradeCommand()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("undo failed: %w", err), tm.window)
+			return
 		}
-	}
+		tm.window.SetContent(tm.Render())
+		components.ShowToast(tm.window, "↶ Undid "+cmd.Name(), "", nil)
+	})
+}
 
-	// Save filtered trades
-	return storage.SaveAllTrades(filteredTrades)
+// showHistory shows the audit history of a trade
+func (tm *TradeManagement) showHistory(trade *models.Trade) {
+	showTradeHistory(tm.window, trade.ID, fmt.Sprintf("%s (%s)", trade.Ticker, trade.CreatedAt.Format("2006-01-02")))
 }
 
 // Validate validates the screen state (not used for read-only screen)
@@ -1,50 +1,41 @@
 package screens
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
 	"testing"
 	"time"
 
 	"fyne.io/fyne/v2/test"
 	"tf-engine/internal/appcore"
-	"tf-engine/internal/config"
+	"tf-engine/internal/audit"
 	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
 	"tf-engine/internal/storage"
 )
 
-// setupTestDataDir creates a temporary test data directory
+// setupTestDataDir points storage at a temporary data directory
 func setupTestDataDir(t *testing.T) func() {
-	// Create temp test directory
-	testDir := filepath.Join("testdata", fmt.Sprintf("test_%d", time.Now().UnixNano()))
-	os.MkdirAll(testDir, 0755)
-
-	// Ensure data directory exists for tests
-	os.MkdirAll("data", 0755)
-	os.MkdirAll("data/backups", 0755)
+	old := paths.Current()
+	saved := storage.TakeSnapshot()
+	paths.Set(paths.Under(t.TempDir(), paths.ModeFlag))
+	if _, err := storage.InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
 
 	// Return cleanup function
 	return func() {
-		os.RemoveAll("data/trades_in_progress.json")
-		os.RemoveAll("data/trades.json")
-		os.RemoveAll("data/backups")
-		os.RemoveAll(testDir)
+		paths.Set(old)
+		saved.Restore()
 	}
 }
 
-func TestTradeManagement_RenderWithFeatureFlagEnabled(t *testing.T) {
+func TestTradeManagement_Render(t *testing.T) {
 	// Arrange
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Version: "1.0.0",
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true, Phase: 2},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Act
 	content := screen.Render()
@@ -55,34 +46,6 @@ func TestTradeManagement_RenderWithFeatureFlagEnabled(t *testing.T) {
 	}
 }
 
-func TestTradeManagement_RenderWithFeatureFlagDisabled(t *testing.T) {
-	// Arrange
-	state := appcore.NewAppState()
-	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Version: "1.0.0",
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {
-				Enabled:      false,
-				Phase:        2,
-				Description:  "Screen 9: Edit/delete trades",
-				SinceVersion: "2.1.0",
-			},
-		},
-	}
-
-	screen := NewTradeManagement(state, window, flags)
-
-	// Act
-	content := screen.Render()
-
-	// Assert
-	if content == nil {
-		t.Fatal("Render returned nil even when disabled")
-	}
-	// Should show disabled state message
-}
-
 func TestTradeManagement_GetFilteredTrades_All(t *testing.T) {
 	// Arrange
 	cleanup := setupTestDataDir(t)
@@ -98,13 +61,8 @@ func TestTradeManagement_GetFilteredTrades_All(t *testing.T) {
 
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 	screen.filterStatus = "all"
 
 	// Act
@@ -130,13 +88,8 @@ func TestTradeManagement_GetFilteredTrades_ActiveOnly(t *testing.T) {
 
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 	screen.filterStatus = "active"
 
 	// Act
@@ -169,13 +122,8 @@ func TestTradeManagement_GetFilteredTrades_ClosedOnly(t *testing.T) {
 
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 	screen.filterStatus = "closed"
 
 	// Act
@@ -209,13 +157,8 @@ func TestTradeManagement_UpdateTrade(t *testing.T) {
 
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Modify first trade
 	pnlUpdated := 250.0
@@ -266,13 +209,8 @@ func TestTradeManagement_UpdateTrade_NotFound(t *testing.T) {
 
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Try to update non-existent trade
 	nonExistentTrade := &models.Trade{
@@ -303,13 +241,8 @@ func TestTradeManagement_DeleteTrade(t *testing.T) {
 
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Act
 	err := screen.deleteTrade(&trades[1]) // Delete MSFT
@@ -337,13 +270,8 @@ func TestTradeManagement_CreateTradesTable_Empty(t *testing.T) {
 	// Arrange
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Act
 	table := screen.createTradesTable([]models.Trade{})
@@ -358,13 +286,8 @@ func TestTradeManagement_CreateTradesTable_WithTrades(t *testing.T) {
 	// Arrange
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	pnl := 150.0
 	trades := []models.Trade{
@@ -393,13 +316,8 @@ func TestTradeManagement_Validate(t *testing.T) {
 	// Arrange
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Act
 	valid := screen.Validate()
@@ -414,13 +332,8 @@ func TestTradeManagement_GetName(t *testing.T) {
 	// Arrange
 	state := appcore.NewAppState()
 	window := test.NewWindow(nil)
-	flags := &config.FeatureFlags{
-		Flags: map[string]config.FeatureFlag{
-			"trade_management": {Enabled: true},
-		},
-	}
 
-	screen := NewTradeManagement(state, window, flags)
+	screen := NewTradeManagement(state, window)
 
 	// Act
 	name := screen.GetName()
@@ -431,3 +344,128 @@ func TestTradeManagement_GetName(t *testing.T) {
 		t.Errorf("Expected name '%s', got '%s'", expected, name)
 	}
 }
+
+func TestTradeManagement_EditsAreJournaled(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	trade := models.Trade{ID: "J1", Ticker: "AAPL", Status: "active"}
+	if err := storage.SaveCompletedTrade(&trade); err != nil {
+		t.Fatalf("SaveCompletedTrade failed: %v", err)
+	}
+
+	screen := NewTradeManagement(appcore.NewAppState(), test.NewWindow(nil))
+
+	// Close the trade with a P&L
+	closed := trade
+	pnl := 340.0
+	closed.ProfitLoss = &pnl
+	closed.Status = "closed"
+	if err := screen.updateTrade(&closed); err != nil {
+		t.Fatalf("updateTrade failed: %v", err)
+	}
+	if err := screen.deleteTrade(&closed); err != nil {
+		t.Fatalf("deleteTrade failed: %v", err)
+	}
+
+	events, err := storage.LoadAuditJournal()
+	if err != nil {
+		t.Fatalf("LoadAuditJournal failed: %v", err)
+	}
+	if err := audit.Verify(events); err != nil {
+		t.Errorf("Journal should verify: %v", err)
+	}
+
+	history := audit.History(events, "J1")
+	if len(history) != 3 {
+		t.Fatalf("Expected created, closed and deleted events, got %d", len(history))
+	}
+	wantTypes := []audit.EventType{audit.EventCreated, audit.EventClosed, audit.EventDeleted}
+	for i, e := range history {
+		if e.Type != wantTypes[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, wantTypes[i], e.Type)
+		}
+		if e.Actor == "" {
+			t.Errorf("Event %d should record an actor", i)
+		}
+	}
+
+	// The close records the P&L change
+	found := false
+	for _, c := range history[1].Changes {
+		if c.Field == "profit_loss" && len(c.Before) == 0 && string(c.After) == "340" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Close event should record profit_loss absent → 340, got %+v", history[1].Changes)
+	}
+
+	// Replaying the journal matches the trade file (empty after delete)
+	rebuilt, _ := audit.Rebuild(events)
+	if len(rebuilt) != 0 {
+		t.Errorf("Expected no trades after replay, got %d", len(rebuilt))
+	}
+}
+
+func TestTradeManagement_UndoDeleteAndBulkStatus(t *testing.T) {
+	cleanup := setupTestDataDir(t)
+	defer cleanup()
+
+	for _, tr := range []models.Trade{
+		{ID: "U1", Ticker: "AAPL", Status: "active"},
+		{ID: "U2", Ticker: "MSFT", Status: "active"},
+	} {
+		trade := tr
+		if err := storage.SaveCompletedTrade(&trade); err != nil {
+			t.Fatalf("SaveCompletedTrade failed: %v", err)
+		}
+	}
+
+	state := appcore.NewAppState()
+	screen := NewTradeManagement(state, test.NewWindow(nil))
+
+	// Delete then undo restores the trade
+	if err := screen.deleteTrade(&models.Trade{ID: "U1", Ticker: "AAPL"}); err != nil {
+		t.Fatalf("deleteTrade failed: %v", err)
+	}
+	if _, err := state.UndoTradeCommand(); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	trades, _ := storage.LoadAllTrades()
+	if len(trades) != 2 || trades[0].ID != "U1" {
+		t.Fatalf("Undo should restore U1 in place, got %+v", trades)
+	}
+
+	// Bulk status on the selected trades
+	screen.selected["U1"] = true
+	screen.selected["U2"] = true
+	if err := screen.setSelectedStatus("expired"); err != nil {
+		t.Fatalf("setSelectedStatus failed: %v", err)
+	}
+	trades, _ = storage.LoadAllTrades()
+	for _, tr := range trades {
+		if tr.Status != "expired" {
+			t.Errorf("%s: expected expired, got %s", tr.ID, tr.Status)
+		}
+	}
+	if err := screen.setSelectedStatus("closed"); err == nil {
+		t.Error("Expected error with nothing selected")
+	}
+
+	if _, err := state.UndoTradeCommand(); err != nil {
+		t.Fatalf("Undo bulk failed: %v", err)
+	}
+	trades, _ = storage.LoadAllTrades()
+	for _, tr := range trades {
+		if tr.Status != "active" {
+			t.Errorf("%s: expected active after undo, got %s", tr.ID, tr.Status)
+		}
+	}
+
+	// Every step, including the undos, is journaled and the chain holds
+	events, _ := storage.LoadAuditJournal()
+	if err := audit.Verify(events); err != nil {
+		t.Errorf("Journal should verify: %v", err)
+	}
+}
@@ -2,8 +2,12 @@ package vimium
 
 import (
 	"fyne.io/fyne/v2"
+
+	"tf-engine/internal/logging"
 )
 
+var logger = logging.For(logging.Vimium)
+
 // ShortcutHandler manages keyboard shortcuts for Vimium mode
 type ShortcutHandler struct {
 	enabled      bool
@@ -58,17 +62,15 @@ func (sh *ShortcutHandler) HandleKeyboard(key *fyne.KeyEvent) bool {
 		return false
 	}
 
-	println("DEBUG: Key pressed:", key.Name)
+	logger.Debug("Key pressed", "key", key.Name)
 
 	switch key.Name {
 	case fyne.KeyJ: // Scroll down
-		println("DEBUG: J key detected")
 		if sh.onScrollDown != nil {
 			sh.onScrollDown()
 			return true
 		}
 	case fyne.KeyK: // Scroll up
-		println("DEBUG: K key detected")
 		if sh.onScrollUp != nil {
 			sh.onScrollUp()
 			return true
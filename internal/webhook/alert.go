@@ -0,0 +1,157 @@
+// Package webhook turns strategy alerts (TradingView-style JSON) into trade
+// drafts. A draft only fills in the sector, direction, ticker and strategy:
+// the user still reviews it, sits out the cooldown and passes the checklist.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+// Source identifies drafts created from webhook alerts
+const Source = "tradingview"
+
+// Alert is the JSON body of an alert. With TradingView, a message like
+//
+//	{"ticker": "{{ticker}}", "strategy": "Alt10",
+//	 "direction": "{{strategy.order.action}}", "price": {{close}}}
+//
+// produces one. Sector is only needed for tickers no policy sector lists.
+type Alert struct {
+	Ticker    string `json:"ticker"`
+	Strategy  string `json:"strategy"`
+	Direction string `json:"direction"`
+	Price     number `json:"price"`
+	N         number `json:"n"`
+	ATR       number `json:"atr"`
+	Sector    string `json:"sector"`
+}
+
+// RejectedError explains why an alert can't become a draft
+type RejectedError string
+
+func (e RejectedError) Error() string { return string(e) }
+
+func rejectf(format string, args ...interface{}) error {
+	return RejectedError(fmt.Sprintf(format, args...))
+}
+
+// number accepts a JSON number or a numeric string, since alert templates
+// often quote their placeholders
+type number float64
+
+func (n *number) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "" || text == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("not a number: %s", data)
+	}
+	*n = number(value)
+	return nil
+}
+
+// ParseAlert decodes an alert body
+func ParseAlert(data []byte) (Alert, error) {
+	var alert Alert
+	if err := json.Unmarshal(data, &alert); err != nil {
+		return Alert{}, fmt.Errorf("invalid alert JSON: %w", err)
+	}
+	return alert, nil
+}
+
+// NormalizeTicker uppercases a ticker and drops an exchange prefix, so
+// "nasdaq:nvda" becomes "NVDA"
+func NormalizeTicker(ticker string) string {
+	ticker = strings.TrimSpace(ticker)
+	if i := strings.LastIndex(ticker, ":"); i >= 0 {
+		ticker = ticker[i+1:]
+	}
+	return strings.ToUpper(ticker)
+}
+
+// NormalizeDirection maps order actions and sentiment words to "bullish" or
+// "bearish"
+func NormalizeDirection(direction string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case "bullish", "buy", "long":
+		return "bullish", true
+	case "bearish", "sell", "short":
+		return "bearish", true
+	}
+	return "", false
+}
+
+// Draft maps the alert to a draft through the policy: the sector comes from
+// Sector or from the sector that lists the ticker. Unknown strategies and
+// blocked sectors are rejected. The draft starts on ticker entry so the user
+// confirms the strategy and starts the cooldown.
+func (a Alert) Draft(policy *models.Policy, now time.Time) (*models.Draft, error) {
+	if policy == nil {
+		return nil, fmt.Errorf("no policy loaded")
+	}
+
+	ticker := NormalizeTicker(a.Ticker)
+	if ticker == "" || strings.ContainsAny(ticker, " \t") {
+		return nil, rejectf("invalid ticker %q", a.Ticker)
+	}
+	direction, ok := NormalizeDirection(a.Direction)
+	if !ok {
+		return nil, rejectf("invalid direction %q (want buy/long/bullish or sell/short/bearish)", a.Direction)
+	}
+	strategy, ok := policy.FindStrategy(strings.TrimSpace(a.Strategy))
+	if !ok {
+		return nil, rejectf("unknown strategy %q", a.Strategy)
+	}
+
+	var sector *models.Sector
+	if a.Sector != "" {
+		if sector = policy.FindSector(strings.TrimSpace(a.Sector)); sector == nil {
+			return nil, rejectf("unknown sector %q", a.Sector)
+		}
+	} else if sector = policy.SectorForTicker(ticker); sector == nil {
+		return nil, rejectf("no policy sector lists %s; add \"sector\" to the alert", ticker)
+	}
+	if sector.Blocked {
+		return nil, rejectf("sector %s is blocked by the policy", sector.Name)
+	}
+
+	trade := models.Trade{
+		CreatedAt: now,
+		Sector:    sector.Name,
+		Direction: direction,
+		Ticker:    ticker,
+		Strategy:  strategy,
+		Signal: &models.Signal{
+			Source:     Source,
+			Price:      float64(a.Price),
+			N:          float64(a.N),
+			ATR:        float64(a.ATR),
+			ReceivedAt: now,
+		},
+	}
+	if suitability, ok := sector.StrategySuitability[strategy]; ok {
+		trade.StrategySuitability = suitability.Rating
+	}
+
+	return &models.Draft{
+		Trade:   trade,
+		Step:    "ticker_entry",
+		History: []string{"sector_selection", "screener_launch"},
+	}, nil
+}
+
+// sameSignal reports whether draft is already waiting on the same setup
+func sameSignal(draft models.Draft, trade models.Trade) bool {
+	return draft.Trade.Ticker == trade.Ticker &&
+		draft.Trade.Strategy == trade.Strategy &&
+		draft.Trade.Direction == trade.Direction
+}
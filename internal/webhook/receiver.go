@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/logging"
+	"tf-engine/internal/models"
+	"tf-engine/internal/storage"
+)
+
+var logger = logging.For(logging.App)
+
+// TokenFile is the name of the file holding the webhook token in the config
+// directory
+const TokenFile = "webhook.token"
+
+// Path is where alerts are posted
+const Path = "/webhook/tradingview"
+
+// Options configure the receiver
+type Options struct {
+	Token string
+	// Do runs fn where the app state may be used (the UI goroutine);
+	// defaults to calling fn directly
+	Do func(fn func())
+	// OnDraft is called (inside Do) after an alert created a draft, e.g. to
+	// notify the user
+	OnDraft func(draft models.Draft)
+}
+
+// Receiver creates drafts from alerts posted to Path
+type Receiver struct {
+	state *appcore.AppState
+	opts  Options
+	now   func() time.Time
+}
+
+// New creates the webhook handler
+func New(state *appcore.AppState, opts Options) *Receiver {
+	if opts.Do == nil {
+		opts.Do = func(fn func()) { fn() }
+	}
+	return &Receiver{state: state, opts: opts, now: time.Now}
+}
+
+// Response is the JSON answer to an accepted alert
+type Response struct {
+	DraftID   string `json:"draft_id"`
+	Ticker    string `json:"ticker"`
+	Sector    string `json:"sector"`
+	Strategy  string `json:"strategy"`
+	Direction string `json:"direction"`
+	Duplicate bool   `json:"duplicate"` // A draft for the same setup already existed
+}
+
+// ServeHTTP implements http.Handler
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Path {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found: %s", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("alerts must be POSTed"))
+		return
+	}
+	if !rc.authorized(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("missing or wrong token"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 64<<10))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	alert, err := ParseAlert(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var resp Response
+	rc.opts.Do(func() { resp, err = rc.receive(alert) })
+
+	var rejected RejectedError
+	switch {
+	case errors.As(err, &rejected):
+		logger.Warn("Alert rejected", "ticker", alert.Ticker, "strategy", alert.Strategy, "reason", err.Error())
+		writeError(w, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, storage.ErrReadOnly):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		logger.Error("Alert not saved", "err", err)
+		writeError(w, http.StatusInternalServerError, err)
+	case resp.Duplicate:
+		writeJSON(w, http.StatusOK, resp)
+	default:
+		writeJSON(w, http.StatusCreated, resp)
+	}
+}
+
+// receive saves a draft for the alert unless one for the same setup is
+// already in progress
+func (rc *Receiver) receive(alert Alert) (Response, error) {
+	draft, err := alert.Draft(rc.state.Policy, rc.now())
+	if err != nil {
+		return Response{}, err
+	}
+
+	drafts, err := storage.LoadDrafts()
+	if err != nil {
+		return Response{}, err
+	}
+	for _, existing := range drafts {
+		if sameSignal(existing, draft.Trade) {
+			return response(existing, true), nil
+		}
+	}
+
+	if err := storage.SaveDraft(draft); err != nil {
+		return Response{}, err
+	}
+	logger.Info("Draft created from alert",
+		"draft", draft.ID(), "ticker", draft.Trade.Ticker, "strategy", draft.Trade.Strategy, "sector", draft.Trade.Sector)
+	if rc.opts.OnDraft != nil {
+		rc.opts.OnDraft(*draft)
+	}
+	return response(*draft, false), nil
+}
+
+func response(draft models.Draft, duplicate bool) Response {
+	return Response{
+		DraftID:   draft.ID(),
+		Ticker:    draft.Trade.Ticker,
+		Sector:    draft.Trade.Sector,
+		Strategy:  draft.Trade.Strategy,
+		Direction: draft.Trade.Direction,
+		Duplicate: duplicate,
+	}
+}
+
+// authorized accepts the token as ?token= (TradingView can't set headers)
+// or as a bearer token
+func (rc *Receiver) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+	return rc.opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(rc.opts.Token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tf-engine/internal/appcore"
+	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
+	"tf-engine/internal/storage"
+)
+
+const testToken = "hook-token"
+
+func setupTestReceiver(t *testing.T) (*httptest.Server, *[]models.Draft) {
+	t.Helper()
+	old := paths.Current()
+	saved := storage.TakeSnapshot()
+	paths.Set(paths.Under(t.TempDir(), paths.ModeFlag))
+	if _, err := storage.InitProfiles(); err != nil {
+		t.Fatalf("InitProfiles failed: %v", err)
+	}
+	t.Cleanup(func() {
+		paths.Set(old)
+		saved.Restore()
+	})
+
+	state := appcore.NewAppState()
+	state.UseSafeMode()
+	state.Policy.FindSector("Technology").Tickers = []string{"NVDA", "MSFT"}
+
+	notified := []models.Draft{}
+	server := httptest.NewServer(New(state, Options{
+		Token:   testToken,
+		OnDraft: func(draft models.Draft) { notified = append(notified, draft) },
+	}))
+	t.Cleanup(server.Close)
+	return server, &notified
+}
+
+func post(t *testing.T, server *httptest.Server, fixture string) (*http.Response, []byte) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.Client().Post(server.URL+Path+"?token="+testToken, "text/plain", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	buf := &bytes.Buffer{}
+	buf.ReadFrom(resp.Body)
+	return resp, buf.Bytes()
+}
+
+func TestReceive_Fixtures(t *testing.T) {
+	for fixture, want := range map[string]int{
+		"long_by_ticker.json":    http.StatusCreated,
+		"short_with_sector.json": http.StatusCreated,
+		"unknown_strategy.json":  http.StatusUnprocessableEntity,
+		"blocked_sector.json":    http.StatusUnprocessableEntity,
+		"unmapped_ticker.json":   http.StatusUnprocessableEntity,
+		"flat_direction.json":    http.StatusUnprocessableEntity,
+	} {
+		t.Run(fixture, func(t *testing.T) {
+			server, notified := setupTestReceiver(t)
+			resp, body := post(t, server, fixture)
+			if resp.StatusCode != want {
+				t.Fatalf("Got %d, want %d: %s", resp.StatusCode, want, body)
+			}
+
+			drafts, _ := storage.LoadDrafts()
+			if want != http.StatusCreated {
+				if len(drafts) != 0 || len(*notified) != 0 {
+					t.Errorf("Rejected alert left %d drafts", len(drafts))
+				}
+				return
+			}
+			if len(drafts) != 1 || len(*notified) != 1 {
+				t.Fatalf("Expected one draft and notification, got %d and %d", len(drafts), len(*notified))
+			}
+		})
+	}
+}
+
+func TestReceive_Draft(t *testing.T) {
+	server, _ := setupTestReceiver(t)
+	_, body := post(t, server, "long_by_ticker.json")
+
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	draft, err := storage.LoadDraft(resp.DraftID)
+	if err != nil || draft == nil {
+		t.Fatalf("Draft %s not saved (%v)", resp.DraftID, err)
+	}
+
+	trade := draft.Trade
+	if trade.Ticker != "NVDA" || trade.Sector != "Technology" || trade.Strategy != "Alt10" || trade.Direction != "bullish" {
+		t.Errorf("Unexpected trade %+v", trade)
+	}
+	if trade.Signal == nil || trade.Signal.Price != 912.5 || trade.Signal.N != 24.1 || trade.Signal.Source != Source {
+		t.Errorf("Unexpected signal %+v", trade.Signal)
+	}
+	// The user still confirms the strategy, sits out the cooldown and
+	// passes the checklist
+	if draft.Step != "ticker_entry" || !trade.CooldownStartTime.IsZero() || trade.ChecklistPassed {
+		t.Errorf("Draft skipped the workflow: step %q, trade %+v", draft.Step, trade)
+	}
+}
+
+func TestReceive_Duplicate(t *testing.T) {
+	server, notified := setupTestReceiver(t)
+	post(t, server, "long_by_ticker.json")
+
+	resp, body := post(t, server, "long_by_ticker.json")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Repeat alert: got %d %s", resp.StatusCode, body)
+	}
+	if drafts, _ := storage.LoadDrafts(); len(drafts) != 1 || len(*notified) != 1 {
+		t.Errorf("Repeat alert created another draft (%d drafts)", len(drafts))
+	}
+}
+
+func TestReceive_Auth(t *testing.T) {
+	server, _ := setupTestReceiver(t)
+	body := `{"ticker": "NVDA", "strategy": "Alt10", "direction": "buy"}`
+
+	for _, url := range []string{Path, Path + "?token=wrong"} {
+		resp, err := server.Client().Post(server.URL+url, "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s: got %d, want 401", url, resp.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", server.URL+Path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Bearer token: got %d, want 201", resp.StatusCode)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := NormalizeTicker(" nyse:brk.b "); got != "BRK.B" {
+		t.Errorf("NormalizeTicker = %q", got)
+	}
+	for input, want := range map[string]string{"BUY": "bullish", "long": "bullish", "sell": "bearish", "Bearish": "bearish", "flat": ""} {
+		if got, _ := NormalizeDirection(input); got != want {
+			t.Errorf("NormalizeDirection(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	alert, err := ParseAlert([]byte(`{"ticker": "NVDA", "price": "abc"}`))
+	if err == nil {
+		t.Errorf("Expected a bad price to fail, got %+v", alert)
+	}
+	if _, err := (Alert{Ticker: "NVDA", Strategy: "Alt10", Direction: "buy"}).Draft(nil, time.Now()); err == nil {
+		t.Error("Expected an error without a policy")
+	}
+}
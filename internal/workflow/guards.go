@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+// Facts is what guards inspect
+type Facts struct {
+	Trade          *models.Trade
+	CooldownPeriod time.Duration
+	Now            time.Time
+	TradingAllowed error // Non-nil when prop firm rules block new trades
+}
+
+var errNoTrade = errors.New("no trade in progress")
+
+// Guards used by the default workflow
+var (
+	TradingAllowed = Guard{Name: "trading allowed", Check: func(f Facts) error {
+		return f.TradingAllowed
+	}}
+
+	SectorChosen = Guard{Name: "sector chosen", Check: func(f Facts) error {
+		if f.Trade == nil {
+			return errNoTrade
+		}
+		if f.Trade.Sector == "" {
+			return errors.New("select a sector first")
+		}
+		return nil
+	}}
+
+	TickerChosen = Guard{Name: "ticker chosen", Check: func(f Facts) error {
+		if f.Trade == nil {
+			return errNoTrade
+		}
+		if f.Trade.Ticker == "" || f.Trade.Strategy == "" {
+			return errors.New("enter a ticker and strategy first")
+		}
+		return nil
+	}}
+
+	CooldownComplete = Guard{Name: "cooldown complete", Check: func(f Facts) error {
+		if f.Trade == nil {
+			return errNoTrade
+		}
+		if f.Trade.CooldownStartTime.IsZero() {
+			return errors.New("cooldown not started")
+		}
+		if remaining := f.CooldownPeriod - f.Now.Sub(f.Trade.CooldownStartTime); remaining > 0 {
+			return fmt.Errorf("cooldown has %s left", remaining.Round(time.Second))
+		}
+		return nil
+	}}
+
+	ChecklistValid = Guard{Name: "checklist valid", Check: func(f Facts) error {
+		if f.Trade == nil {
+			return errNoTrade
+		}
+		if !f.Trade.ChecklistPassed {
+			return errors.New("complete every required checklist item")
+		}
+		return nil
+	}}
+
+	PositionSized = Guard{Name: "position sized", Check: func(f Facts) error {
+		if f.Trade == nil {
+			return errNoTrade
+		}
+		if f.Trade.MaxLoss <= 0 {
+			return errors.New("size the position first")
+		}
+		return nil
+	}}
+
+	HeatOK = Guard{Name: "heat OK", Check: func(f Facts) error {
+		if f.Trade == nil {
+			return errNoTrade
+		}
+		if !f.Trade.HeatCheckPassed {
+			return errors.New("trade would exceed heat limits")
+		}
+		return nil
+	}}
+)
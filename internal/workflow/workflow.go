@@ -0,0 +1,254 @@
+// Package workflow defines trade entry as a state machine: named states, the
+// legal transitions between them, the guards each transition must pass and
+// the side effects (auto-save) run when it happens. Screens outside the
+// workflow (calendar, trade management, analytics, settings) are modal
+// destinations that can be opened from anywhere and never advance a trade.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+)
+
+// State is a screen the navigator can be on
+type State string
+
+// Workflow steps, in order, plus the dashboard they start from
+const (
+	Dashboard       State = "dashboard"
+	SectorSelection State = "sector_selection"
+	ScreenerLaunch  State = "screener_launch"
+	TickerEntry     State = "ticker_entry"
+	Checklist       State = "checklist"
+	PositionSizing  State = "position_sizing"
+	HeatCheck       State = "heat_check"
+	TradeEntry      State = "trade_entry"
+)
+
+// Modal destinations outside the workflow
+const (
+	Calendar              State = "calendar"
+	TradeManagement       State = "trade_management"
+	Analytics             State = "analytics"
+	ConsolidatedAnalytics State = "consolidated_analytics"
+	Settings              State = "settings"
+	Diagnostics           State = "diagnostics"
+)
+
+// Steps lists the workflow states in order. Trade entry is the last one:
+// nothing follows it.
+var Steps = []State{
+	SectorSelection, ScreenerLaunch, TickerEntry, Checklist,
+	PositionSizing, HeatCheck, TradeEntry,
+}
+
+// Modals lists the destinations outside the workflow
+var Modals = []State{Calendar, TradeManagement, Analytics, ConsolidatedAnalytics, Settings, Diagnostics}
+
+// ErrIllegal is returned for a transition the machine doesn't allow
+var ErrIllegal = errors.New("illegal transition")
+
+// GuardError reports the guard that blocked a transition
+type GuardError struct {
+	Guard string
+	Err   error
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Guard, e.Err)
+}
+
+func (e *GuardError) Unwrap() error {
+	return e.Err
+}
+
+// Guard must pass for a transition to happen. Check returns why not.
+type Guard struct {
+	Name  string
+	Check func(Facts) error
+}
+
+// Transition is a legal move between two states
+type Transition struct {
+	From, To State
+	Guards   []Guard
+}
+
+// Move is a transition being made, with the Back history the navigator will
+// have afterwards (passed through to effects)
+type Move struct {
+	From, To State
+	History  []State
+}
+
+// Effect runs once a move's guards pass and before the new state is entered.
+// An error cancels the move.
+type Effect func(Move) error
+
+// Machine holds the legal transitions and the effects run on every move
+type Machine struct {
+	transitions map[State]map[State]Transition
+	effects     []Effect
+}
+
+// NewMachine creates a machine with no transitions
+func NewMachine() *Machine {
+	return &Machine{transitions: map[State]map[State]Transition{}}
+}
+
+// Add makes from → to legal, guarded by guards
+func (m *Machine) Add(from, to State, guards ...Guard) {
+	if m.transitions[from] == nil {
+		m.transitions[from] = map[State]Transition{}
+	}
+	m.transitions[from][to] = Transition{From: from, To: to, Guards: guards}
+}
+
+// Remove drops every transition to and from s, making it unreachable
+func (m *Machine) Remove(s State) {
+	delete(m.transitions, s)
+	for _, to := range m.transitions {
+		delete(to, s)
+	}
+}
+
+// OnMove registers an effect run on every allowed move
+func (m *Machine) OnMove(effect Effect) {
+	m.effects = append(m.effects, effect)
+}
+
+// Transition returns the transition from → to, if it is legal
+func (m *Machine) Transition(from, to State) (Transition, bool) {
+	t, ok := m.transitions[from][to]
+	return t, ok
+}
+
+// Check reports whether from → to is legal and every guard passes
+func (m *Machine) Check(from, to State, facts Facts) error {
+	t, ok := m.Transition(from, to)
+	if !ok {
+		return fmt.Errorf("%w: %s → %s", ErrIllegal, from, to)
+	}
+	for _, g := range t.Guards {
+		if err := g.Check(facts); err != nil {
+			return &GuardError{Guard: g.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// Fire checks a move and runs the effects. The caller enters the new state
+// only if it returns nil.
+func (m *Machine) Fire(move Move, facts Facts) error {
+	if err := m.Check(move.From, move.To, facts); err != nil {
+		return err
+	}
+	for _, effect := range m.effects {
+		if err := effect(move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Next returns the workflow step after s. Reports false after trade entry
+// and for states outside the workflow.
+func Next(s State) (State, bool) {
+	i := StepIndex(s)
+	if i < 0 || i+1 >= len(Steps) {
+		return "", false
+	}
+	return Steps[i+1], true
+}
+
+// StepIndex returns the position of s in Steps, or -1
+func StepIndex(s State) int {
+	for i, step := range Steps {
+		if step == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsStep reports whether s is a workflow step
+func IsStep(s State) bool {
+	return StepIndex(s) >= 0
+}
+
+// IsModal reports whether s is a destination outside the workflow
+func IsModal(s State) bool {
+	for _, modal := range Modals {
+		if modal == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Default builds the trade entry workflow:
+//   - each step moves forward only to the next one, through its guards
+//   - each step moves back to any earlier step, or cancels to the dashboard
+//   - entering a step from the dashboard or a modal (start, resume, return)
+//     needs every guard on the way to that step
+//   - modals open from anywhere and close to the dashboard
+func Default() *Machine {
+	m := NewMachine()
+
+	// Guards for entering each step from the one before it
+	forward := map[State][]Guard{
+		SectorSelection: {TradingAllowed},
+		ScreenerLaunch:  {SectorChosen, TradingAllowed},
+		TickerEntry:     {SectorChosen, TradingAllowed},
+		Checklist:       {TickerChosen, TradingAllowed},
+		PositionSizing:  {CooldownComplete, ChecklistValid, TradingAllowed},
+		HeatCheck:       {PositionSized, TradingAllowed},
+		TradeEntry:      {HeatOK, TradingAllowed},
+	}
+
+	prev := Dashboard
+	for i, step := range Steps {
+		m.Add(prev, step, forward[step]...)
+		m.Add(step, Dashboard)
+		for _, earlier := range Steps[:i] {
+			m.Add(step, earlier)
+		}
+
+		// Entering directly needs every guard up to this step
+		reach := pathGuards(forward, i)
+		m.Add(Dashboard, step, reach...)
+		for _, modal := range Modals {
+			m.Add(modal, step, reach...)
+		}
+		prev = step
+	}
+
+	sources := append(append([]State{Dashboard}, Steps...), Modals...)
+	for _, from := range sources {
+		for _, modal := range Modals {
+			if from != modal {
+				m.Add(from, modal)
+			}
+		}
+	}
+	for _, modal := range Modals {
+		m.Add(modal, Dashboard)
+	}
+
+	return m
+}
+
+// pathGuards collects the guards on the way to Steps[i], without repeats
+func pathGuards(forward map[State][]Guard, i int) []Guard {
+	guards := []Guard{}
+	seen := map[string]bool{}
+	for _, step := range Steps[:i+1] {
+		for _, g := range forward[step] {
+			if !seen[g.Name] {
+				seen[g.Name] = true
+				guards = append(guards, g)
+			}
+		}
+	}
+	return guards
+}
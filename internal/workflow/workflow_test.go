@@ -0,0 +1,245 @@
+package workflow
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"tf-engine/internal/models"
+)
+
+var allStates = append(append([]State{Dashboard}, Steps...), Modals...)
+
+// legalTransitions is the whole transition table, written out by hand: each
+// legal move and the guards it needs. Every other pair must be illegal.
+func legalTransitions() map[[2]State]string {
+	const (
+		start    = "trading allowed"
+		sector   = "sector chosen, trading allowed"
+		ticker   = "sector chosen, trading allowed, ticker chosen"
+		checks   = "sector chosen, trading allowed, ticker chosen, cooldown complete, checklist valid"
+		sized    = checks + ", position sized"
+		heat     = sized + ", heat OK"
+		noGuards = ""
+	)
+	reach := map[State]string{
+		SectorSelection: start, ScreenerLaunch: sector, TickerEntry: sector,
+		Checklist: ticker, PositionSizing: checks, HeatCheck: sized, TradeEntry: heat,
+	}
+
+	legal := map[[2]State]string{
+		// Forward, one step at a time
+		{SectorSelection, ScreenerLaunch}: "sector chosen, trading allowed",
+		{ScreenerLaunch, TickerEntry}:     "sector chosen, trading allowed",
+		{TickerEntry, Checklist}:          "ticker chosen, trading allowed",
+		{Checklist, PositionSizing}:       "cooldown complete, checklist valid, trading allowed",
+		{PositionSizing, HeatCheck}:       "position sized, trading allowed",
+		{HeatCheck, TradeEntry}:           "heat OK, trading allowed",
+	}
+
+	for i, step := range Steps {
+		// Start or resume from the dashboard, return from a modal
+		legal[[2]State{Dashboard, step}] = reach[step]
+		for _, modal := range Modals {
+			legal[[2]State{modal, step}] = reach[step]
+		}
+		// Back to any earlier step, cancel to the dashboard
+		for _, earlier := range Steps[:i] {
+			legal[[2]State{step, earlier}] = noGuards
+		}
+		legal[[2]State{step, Dashboard}] = noGuards
+	}
+
+	// Modals open from anywhere and close to the dashboard
+	for _, modal := range Modals {
+		for _, from := range allStates {
+			if from != modal {
+				legal[[2]State{from, modal}] = noGuards
+			}
+		}
+		legal[[2]State{modal, Dashboard}] = noGuards
+	}
+	return legal
+}
+
+// guardNames returns the sorted guard names of a transition
+func guardNames(guards []Guard) string {
+	names := []string{}
+	for _, g := range guards {
+		names = append(names, g.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func sortedNames(list string) string {
+	if list == "" {
+		return ""
+	}
+	names := strings.Split(list, ", ")
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func TestDefault_TransitionTable(t *testing.T) {
+	m := Default()
+	legal := legalTransitions()
+
+	for _, from := range allStates {
+		for _, to := range allStates {
+			want, isLegal := legal[[2]State{from, to}]
+			tr, ok := m.Transition(from, to)
+
+			if ok != isLegal {
+				t.Errorf("%s → %s: legal=%v, want %v", from, to, ok, isLegal)
+				continue
+			}
+			if !ok {
+				err := m.Check(from, to, Facts{})
+				if !errors.Is(err, ErrIllegal) {
+					t.Errorf("%s → %s: expected ErrIllegal, got %v", from, to, err)
+				}
+				continue
+			}
+			if got := guardNames(tr.Guards); got != sortedNames(want) {
+				t.Errorf("%s → %s: guards %q, want %q", from, to, got, sortedNames(want))
+			}
+		}
+	}
+}
+
+func TestDefault_NothingFollowsTradeEntry(t *testing.T) {
+	if next, ok := Next(TradeEntry); ok {
+		t.Errorf("Trade entry should be the last step, got next %s", next)
+	}
+	for _, modal := range Modals {
+		if next, ok := Next(modal); ok {
+			t.Errorf("%s is outside the workflow, got next %s", modal, next)
+		}
+	}
+	if next, _ := Next(Checklist); next != PositionSizing {
+		t.Errorf("Expected position sizing after checklist, got %s", next)
+	}
+}
+
+func TestDefault_Guards(t *testing.T) {
+	now := time.Now()
+	ready := func() *models.Trade {
+		return &models.Trade{
+			Sector: "Healthcare", Ticker: "UNH", Strategy: "Alt10",
+			CooldownStartTime: now.Add(-10 * time.Minute),
+			ChecklistPassed:   true, MaxLoss: 500, HeatCheckPassed: true,
+		}
+	}
+	tests := []struct {
+		name      string
+		from, to  State
+		change    func(*models.Trade, *Facts)
+		wantGuard string // "" = allowed
+	}{
+		{"start", Dashboard, SectorSelection, nil, ""},
+		{"start blocked by prop firm", Dashboard, SectorSelection,
+			func(_ *models.Trade, f *Facts) { f.TradingAllowed = errors.New("limit breached") }, "trading allowed"},
+		{"sector chosen", SectorSelection, ScreenerLaunch, nil, ""},
+		{"no sector", SectorSelection, ScreenerLaunch, func(tr *models.Trade, _ *Facts) { tr.Sector = "" }, "sector chosen"},
+		{"no trade", SectorSelection, ScreenerLaunch, func(_ *models.Trade, f *Facts) { f.Trade = nil }, "sector chosen"},
+		{"screener to ticker", ScreenerLaunch, TickerEntry, nil, ""},
+		{"ticker chosen", TickerEntry, Checklist, nil, ""},
+		{"no strategy", TickerEntry, Checklist, func(tr *models.Trade, _ *Facts) { tr.Strategy = "" }, "ticker chosen"},
+		{"checklist done", Checklist, PositionSizing, nil, ""},
+		{"cooldown running", Checklist, PositionSizing,
+			func(tr *models.Trade, _ *Facts) { tr.CooldownStartTime = now.Add(-time.Minute) }, "cooldown complete"},
+		{"cooldown not started", Checklist, PositionSizing,
+			func(tr *models.Trade, _ *Facts) { tr.CooldownStartTime = time.Time{} }, "cooldown complete"},
+		{"checklist incomplete", Checklist, PositionSizing,
+			func(tr *models.Trade, _ *Facts) { tr.ChecklistPassed = false }, "checklist valid"},
+		{"sized", PositionSizing, HeatCheck, nil, ""},
+		{"not sized", PositionSizing, HeatCheck, func(tr *models.Trade, _ *Facts) { tr.MaxLoss = 0 }, "position sized"},
+		{"heat ok", HeatCheck, TradeEntry, nil, ""},
+		{"too hot", HeatCheck, TradeEntry, func(tr *models.Trade, _ *Facts) { tr.HeatCheckPassed = false }, "heat OK"},
+		{"resume at sizing", Dashboard, PositionSizing, nil, ""},
+		{"resume can't skip cooldown", Dashboard, PositionSizing,
+			func(tr *models.Trade, _ *Facts) { tr.CooldownStartTime = now }, "cooldown complete"},
+		{"return from calendar can't skip heat", Calendar, TradeEntry,
+			func(tr *models.Trade, _ *Facts) { tr.HeatCheckPassed = false }, "heat OK"},
+		{"back needs no guards", TradeEntry, SectorSelection, func(_ *models.Trade, f *Facts) { f.Trade = nil }, ""},
+		{"cancel needs no guards", Checklist, Dashboard, func(_ *models.Trade, f *Facts) { f.Trade = nil }, ""},
+	}
+
+	m := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Facts{Trade: ready(), CooldownPeriod: 5 * time.Minute, Now: now}
+			if tt.change != nil {
+				tt.change(f.Trade, &f)
+			}
+
+			err := m.Check(tt.from, tt.to, f)
+			if tt.wantGuard == "" {
+				if err != nil {
+					t.Errorf("Expected allowed, got %v", err)
+				}
+				return
+			}
+
+			var guardErr *GuardError
+			if !errors.As(err, &guardErr) || guardErr.Guard != tt.wantGuard {
+				t.Errorf("Expected %q to block, got %v", tt.wantGuard, err)
+			}
+		})
+	}
+}
+
+func TestRemove_MakesStateUnreachable(t *testing.T) {
+	m := Default()
+	m.Remove(Analytics)
+
+	for _, s := range allStates {
+		if _, ok := m.Transition(s, Analytics); ok {
+			t.Errorf("%s → analytics should be removed", s)
+		}
+		if _, ok := m.Transition(Analytics, s); ok {
+			t.Errorf("analytics → %s should be removed", s)
+		}
+	}
+	if _, ok := m.Transition(Dashboard, Calendar); !ok {
+		t.Error("Other modals should stay reachable")
+	}
+}
+
+func TestFire_RunsEffectsOnlyWhenAllowed(t *testing.T) {
+	m := Default()
+	moves := []Move{}
+	m.OnMove(func(mv Move) error {
+		moves = append(moves, mv)
+		return nil
+	})
+
+	f := Facts{Trade: &models.Trade{}, CooldownPeriod: time.Minute, Now: time.Now()}
+
+	if err := m.Fire(Move{From: SectorSelection, To: ScreenerLaunch}, f); err == nil {
+		t.Error("Expected the sector guard to block")
+	}
+	if err := m.Fire(Move{From: SectorSelection, To: TradeEntry}, f); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Skipping ahead should be illegal, got %v", err)
+	}
+	if len(moves) != 0 {
+		t.Fatalf("Effects must not run for blocked moves, got %+v", moves)
+	}
+
+	history := []State{Dashboard}
+	if err := m.Fire(Move{From: Dashboard, To: SectorSelection, History: history}, f); err != nil {
+		t.Fatal(err)
+	}
+	if len(moves) != 1 || moves[0].To != SectorSelection || len(moves[0].History) != 1 {
+		t.Errorf("Expected one effect run with the history, got %+v", moves)
+	}
+
+	// A failing effect cancels the move
+	m.OnMove(func(Move) error { return errors.New("disk full") })
+	if err := m.Fire(Move{From: SectorSelection, To: Dashboard}, f); err == nil {
+		t.Error("Expected the effect error")
+	}
+}
@@ -1,21 +1,33 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
 
+	"tf-engine/internal/api"
 	"tf-engine/internal/appcore"
+	"tf-engine/internal/cli"
 	"tf-engine/internal/config"
+	"tf-engine/internal/instance"
 	"tf-engine/internal/logging"
 	"tf-engine/internal/models"
+	"tf-engine/internal/paths"
 	"tf-engine/internal/storage"
 	"tf-engine/internal/ui"
+	"tf-engine/internal/webhook"
+	"tf-engine/internal/workflow"
 )
 
 const (
@@ -24,125 +36,266 @@ const (
 	AppVersion = "1.0.0"
 )
 
+var logger = logging.For(logging.App)
+
 func main() {
 	// Set up panic recovery
 	defer func() {
 		if r := recover(); r != nil {
-			if logging.ErrorLogger != nil {
-				logging.LogPanic(r)
-				logging.ErrorLogger.Printf("Application crashed: %v", r)
-			}
+			logging.LogPanic(r)
 			fmt.Fprintf(os.Stderr, "FATAL: Application crashed: %v\n", r)
 			os.Exit(1)
 		}
 	}()
 
+	// Resolve data, config and log directories before anything touches disk
+	dataDir := flag.String("data-dir", "", "directory for trades, settings, config and logs (overrides "+paths.EnvDataDir+")")
+	portable := flag.Bool("portable", false, "keep all data next to the executable")
+	flagOverrides := config.FlagOverrides{}
+	flag.Var(flagOverrides, "flag", "turn a feature flag on (name) or off (name=false); repeatable")
+	logLevel := flag.String("log-level", os.Getenv(logging.EnvLevel), "log level, optionally per subsystem, e.g. info,storage=debug (overrides "+logging.EnvLevel+")")
+	logFormat := flag.String("log-format", os.Getenv(logging.EnvFormat), "log output: text or json (overrides "+logging.EnvFormat+")")
+	flag.Parse()
+
+	dirs, err := paths.Resolve(paths.Options{DataDir: *dataDir, Portable: *portable})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: Failed to resolve data directory: %v\n", err)
+		os.Exit(1)
+	}
+	paths.Set(dirs)
+	if err := paths.EnsureDirs(); err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize logging FIRST (so we can see what's happening)
-	if err := logging.InitializeLogging(); err != nil {
+	levels, err := logging.ParseLevels(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: %v\n", err)
+		os.Exit(2)
+	}
+	logOpts := logging.Options{Levels: levels, Format: *logFormat}
+	headless := cli.IsCommand(flag.Args())
+	if headless {
+		logOpts.Console = io.Discard // Keep command output clean for scripts
+	}
+	if err := logging.InitializeLogging(logOpts); err != nil {
 		fmt.Fprintf(os.Stderr, "FATAL: Failed to initialize logging: %v\n", err)
 		os.Exit(1)
 	}
+	if headless {
+		os.Exit(runCommand(flag.Args()))
+	}
 	defer logging.CloseLogging()
 
 	// Log startup info
 	logging.LogStartup()
-	logging.InfoLogger.Printf("Starting %s version %s", AppName, AppVersion)
+	logger.Info("Starting", "app", AppName, "version", AppVersion)
+	logger.Info("Directories", "mode", dirs.Mode, "data", dirs.Data, "config", dirs.Config, "logs", dirs.Log)
 
 	// Create required directories
 	if err := createRequiredDirectories(); err != nil {
-		logging.ErrorLogger.Printf("Failed to create required directories: %v", err)
+		logger.Error("Failed to create required directories", "err", err)
 		// Continue anyway - app can still run
 	}
 
 	// Clean up old logs (ignore errors)
 	logging.CleanupOldLogs()
 
+	// Claim the data directory. A second instance either hands over to the
+	// first or opens the data read-only.
+	guard, err := instance.Acquire(paths.Data())
+	secondInstance := errors.Is(err, instance.ErrAlreadyRunning)
+	if secondInstance {
+		logger.Info("Another instance owns the data directory, storage is read-only")
+		storage.SetReadOnly(true)
+	} else if err != nil {
+		logger.Error("Failed to claim data directory", "err", err)
+	}
+	defer guard.Release()
+
 	// Initialize application state
-	logging.InfoLogger.Println("Initializing application state...")
+	logger.Info("Initializing application state...")
 	state := appcore.NewAppState()
+	state.AppName, state.AppVersion = AppName, AppVersion
 
-	// Load policy file
-	logging.InfoLogger.Println("Loading policy configuration...")
-	policyPath := findPolicyFile()
-	if err := state.LoadPolicy(policyPath); err != nil {
-		logging.ErrorLogger.Printf("Failed to load policy: %v", err)
-		logging.ErrorLogger.Println("Activating safe mode with minimal policy")
-		state.UseSafeMode()
-	} else {
-		logging.InfoLogger.Printf("Policy loaded successfully from %s", policyPath)
-	}
-
-	// Load feature flags
-	logging.InfoLogger.Println("Loading feature flags...")
-	featureFlags, err := config.LoadFeatureFlags("feature.flags.json")
+	// Select the active account profile (points storage at its files)
+	profile, err := storage.InitProfiles()
 	if err != nil {
-		logging.ErrorLogger.Printf("Failed to load feature flags: %v", err)
-		logging.InfoLogger.Println("Continuing with default feature flags (all Phase 2 features OFF)")
-		// Create default feature flags (all disabled)
-		featureFlags = &config.FeatureFlags{
-			Version: "1.0.0",
-			Flags:   make(map[string]config.FeatureFlag),
-		}
-	} else {
-		logging.InfoLogger.Println("Feature flags loaded successfully")
+		logger.Error("Failed to load profiles", "err", err)
 	}
-	state.FeatureFlags = featureFlags
+	state.Profile = profile
+	logger.Info("Active profile", "name", profile.Name, "id", profile.ID)
 
-	// Load user settings
-	logging.InfoLogger.Println("Loading user settings...")
-	settings, err := storage.LoadSettings()
-	if err != nil {
-		logging.ErrorLogger.Printf("Failed to load settings: %v", err)
-		logging.InfoLogger.Println("Using default settings")
-		settings = models.DefaultSettings()
-	} else {
-		logging.InfoLogger.Printf("Settings loaded: $%.0f equity, %.2f%% risk",
-			settings.AccountEquity, settings.RiskPerTrade*100)
-	}
-	state.Settings = settings
+	// Load policy file (shared, unless the profile overrides it)
+	state.LoadProfilePolicy(findConfigFile("policy.v1.json"))
 
-	// Load existing trades
-	logging.InfoLogger.Println("Loading existing trades...")
-	trades, err := storage.LoadAllTrades()
+	// Load feature flags
+	logger.Info("Loading feature flags...")
+	featureFlags, err := config.ResolveFeatureFlags(config.LayerOptions{
+		File:     findConfigFile("feature.flags.json"),
+		UserFile: paths.Config(config.UserFlagsFile),
+		CLI:      flagOverrides,
+	})
 	if err != nil {
-		logging.ErrorLogger.Printf("Failed to load trades: %v", err)
-		// Continue with empty trade list
-		state.AllTrades = []models.Trade{}
-	} else {
-		state.AllTrades = trades
-		logging.InfoLogger.Printf("Loaded %d existing trades", len(trades))
+		logger.Error("Some feature flag overrides were ignored", "err", err)
 	}
-
-	// Check for in-progress trade
-	inProgressTrade, err := storage.LoadInProgressTrade()
-	if err == nil && inProgressTrade != nil {
-		logging.InfoLogger.Printf("Found in-progress trade: %s", inProgressTrade.Ticker)
-		state.CurrentTrade = inProgressTrade
+	for _, name := range featureFlags.Names() {
+		source := featureFlags.Source(name)
+		logger.Info("Feature flag", "name", name, "enabled", featureFlags.IsEnabled(name),
+			"layer", source.Layer, "blocked", source.Blocked)
 	}
+	state.FeatureFlags = featureFlags
 
 	// Create Fyne application
-	logging.InfoLogger.Println("Creating Fyne application...")
+	logger.Info("Creating Fyne application...")
 	fyneApp := app.NewWithID(AppID)
 
 	// Create main window
-	logging.InfoLogger.Println("Creating main window...")
+	logger.Info("Creating main window...")
 	window := fyneApp.NewWindow(AppName)
 	window.Resize(fyne.NewSize(1024, 768))
 	window.CenterOnScreen()
 
+	// A later launch asks this window to come to the front
+	if guard != nil {
+		guard.OnFocus(func() {
+			logger.Info("Focus requested by another instance")
+			fyne.Do(window.RequestFocus)
+		})
+	}
+
+	// Load data and build the UI, after unlocking if the data is encrypted
+	var apiServer, webhookServer *http.Server
+	start := func() {
+		loadProfileData(state)
+		navigator := buildUI(fyneApp, window, state)
+		if !secondInstance {
+			apiServer = startLocalAPI(state, navigator)
+			webhookServer = startWebhook(fyneApp, state, navigator)
+		}
+	}
+	launch := func() {
+		if storage.Locked() {
+			logger.Info("Data is encrypted, waiting for passphrase...")
+			showUnlockPrompt(window, start)
+		} else {
+			start()
+		}
+	}
+	if secondInstance {
+		showAlreadyRunningPrompt(fyneApp, window, launch)
+	} else {
+		launch()
+	}
+
+	// Show window and run
+	logger.Info("Application initialized successfully")
+	logger.Info("Showing main window...")
+	window.ShowAndRun()
+
+	// Cleanup on exit
+	logger.Info("Application shutting down...")
+	api.Shutdown(apiServer)
+	api.Shutdown(webhookServer)
+}
+
+// startLocalAPI serves the local API if Settings turn it on. Requests use
+// the state on the UI goroutine; after a write, screens showing the trade
+// history are refreshed.
+func startLocalAPI(state *appcore.AppState, navigator *ui.Navigator) *http.Server {
+	if state.Settings == nil || !state.Settings.API.Enabled {
+		return nil
+	}
+	token, err := api.LoadOrCreateToken(paths.Config(api.TokenFile))
+	if err != nil {
+		logger.Error("Local API disabled", "err", err)
+		return nil
+	}
+	server := api.New(state, api.Options{
+		Token: token,
+		Do:    fyne.DoAndWait,
+		OnChange: func() {
+			switch navigator.Current() {
+			case workflow.Dashboard, workflow.TradeManagement, workflow.Calendar, workflow.Analytics, workflow.ConsolidatedAnalytics:
+				navigator.RefreshCurrentScreen()
+			}
+		},
+	})
+	srv, err := server.Start(state.Settings.API.ListenPort())
+	if err != nil {
+		logger.Error("Local API disabled", "err", err)
+		return nil
+	}
+	return srv
+}
+
+// startWebhook listens for strategy alerts if Settings turn it on. Each new
+// draft is announced with a notification and shows up on the dashboard.
+func startWebhook(fyneApp fyne.App, state *appcore.AppState, navigator *ui.Navigator) *http.Server {
+	if state.Settings == nil || !state.Settings.Webhook.Enabled {
+		return nil
+	}
+	token, err := api.LoadOrCreateToken(paths.Config(webhook.TokenFile))
+	if err != nil {
+		logger.Error("Alert webhook disabled", "err", err)
+		return nil
+	}
+	receiver := webhook.New(state, webhook.Options{
+		Token: token,
+		Do:    fyne.DoAndWait,
+		OnDraft: func(draft models.Draft) {
+			fyneApp.SendNotification(&fyne.Notification{
+				Title: "New Trade Draft",
+				Content: fmt.Sprintf("%s %s (%s) from a %s alert. Resume it from the dashboard; the cooldown and checklist still apply.",
+					draft.Trade.Ticker, draft.Trade.Direction, draft.Trade.Strategy, draft.Trade.Signal.Source),
+			})
+			if navigator.Current() == workflow.Dashboard {
+				navigator.RefreshCurrentScreen()
+			}
+		},
+	})
+	srv, err := api.Listen("Alert webhook", receiver, state.Settings.Webhook.ListenPort())
+	if err != nil {
+		logger.Error("Alert webhook disabled", "err", err)
+		return nil
+	}
+	return srv
+}
+
+// loadProfileData loads the active profile's settings, trades and ledger and
+// reports the drafts in progress
+func loadProfileData(state *appcore.AppState) {
+	logger.Info("Loading profile data...")
+	if err := state.LoadProfileData(); err != nil {
+		logger.Error("Failed to load profile data, continuing with defaults for anything that failed", "err", err)
+	}
+	logger.Info("Settings loaded",
+		"account_equity", state.Settings.AccountEquity, "risk_per_trade", state.Settings.RiskPerTrade)
+	logger.Info("Trades loaded", "count", len(state.AllTrades))
+	if state.Ledger != nil {
+		logger.Info("Ledger loaded", "entries", len(state.Ledger.Entries), "equity", state.Ledger.Equity())
+	}
+	if drafts, err := storage.LoadDrafts(); err == nil && len(drafts) > 0 {
+		logger.Info("Trades in progress", "count", len(drafts))
+	}
+}
+
+// buildUI creates the navigator, shortcuts and first screen
+func buildUI(fyneApp fyne.App, window fyne.Window, state *appcore.AppState) *ui.Navigator {
 	// Create theme with window reference
 	tfTheme := ui.NewTFEngineTheme(window)
 	fyneApp.Settings().SetTheme(tfTheme)
 
 	// Create navigator
-	logging.InfoLogger.Println("Initializing navigator...")
+	logger.Info("Initializing navigator...")
 	navigator := ui.NewNavigator(state, window)
 
 	// Wire up theme toggle callback
 	navigator.SetThemeToggleCallback(func() {
 		newMode := tfTheme.ToggleMode()
 		navigator.UpdateThemeButton(newMode)
-		logging.InfoLogger.Printf("Theme switched to: %s mode", newMode)
+		logger.Info("Theme switched", "mode", newMode)
 	})
 
 	// Set up keyboard event handler for Vim mode (Phase 2 feature)
@@ -168,82 +321,204 @@ func main() {
 			vimManager.HandleKeyboard(key)
 		})
 
-		logging.InfoLogger.Println("Vim mode keyboard shortcuts initialized")
+		logger.Info("Vim mode keyboard shortcuts initialized")
 	}
 
 	// Show welcome screen on first launch (if feature enabled)
 	if shouldShowWelcome() {
-		logging.InfoLogger.Println("Showing welcome screen")
+		logger.Info("Showing welcome screen")
 		showWelcomeScreen(window, navigator)
 	}
 
 	// Start at dashboard
-	logging.InfoLogger.Println("Navigating to dashboard...")
+	logger.Info("Navigating to dashboard...")
 	navigator.NavigateToDashboard()
 
-	// Show window and run
-	logging.InfoLogger.Println("Application initialized successfully")
-	logging.InfoLogger.Println("Showing main window...")
-	window.ShowAndRun()
+	// Offer to continue the most recent trade in progress
+	offerResumeDraft(window, navigator)
+	return navigator
+}
 
-	// Cleanup on exit
-	logging.InfoLogger.Println("Application shutting down...")
+// offerResumeDraft asks whether to continue the most recently updated draft
+// on the step it was left on. Its cooldown continues from the saved start.
+func offerResumeDraft(window fyne.Window, navigator *ui.Navigator) {
+	if storage.ReadOnly() {
+		return // Resuming would need to save the draft
+	}
+
+	drafts, err := storage.LoadDrafts()
+	if err != nil {
+		logger.Error("Failed to load drafts", "err", err)
+		return
+	}
+	if len(drafts) == 0 {
+		return
+	}
+
+	draft := drafts[0]
+	message := fmt.Sprintf("Resume %s at %s?", draft.Title(), ui.StepTitle(draft.Step))
+	if others := len(drafts) - 1; others > 0 {
+		message += fmt.Sprintf("\n\n%d other trade(s) in progress are listed on the dashboard.", others)
+	}
+
+	confirm := dialog.NewConfirm("Trade in Progress", message, func(resume bool) {
+		if !resume {
+			return
+		}
+		logger.Info("Resuming draft", "draft", draft.ID(), "step", draft.Step)
+		if err := navigator.ResumeDraft(draft.ID()); err != nil {
+			logger.Error("Failed to resume draft", "draft", draft.ID(), "err", err)
+			dialog.ShowError(err, window)
+		}
+	}, window)
+	confirm.SetConfirmText("Resume")
+	confirm.SetDismissText("Not Now")
+	confirm.Show()
+}
+
+// showUnlockPrompt asks for the passphrase of an encrypted data directory and
+// calls onUnlocked once it's correct
+func showUnlockPrompt(window fyne.Window, onUnlocked func()) {
+	title := widget.NewLabel("🔒 Trade data is encrypted")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+
+	passEntry := widget.NewPasswordEntry()
+	passEntry.SetPlaceHolder("Passphrase")
+	status := widget.NewLabel("")
+
+	unlock := func() {
+		status.SetText("Unlocking...")
+		if err := storage.Unlock(passEntry.Text); err != nil {
+			logger.Info("Unlock failed", "err", err)
+			status.SetText("⚠️ " + err.Error())
+			passEntry.SetText("")
+			return
+		}
+		logger.Info("Data unlocked")
+		onUnlocked()
+	}
+	passEntry.OnSubmitted = func(string) { unlock() }
+
+	unlockBtn := widget.NewButton("Unlock", unlock)
+	unlockBtn.Importance = widget.HighImportance
+	quitBtn := widget.NewButton("Quit", func() { window.Close() })
+
+	form := container.NewVBox(
+		title,
+		widget.NewLabel("Enter the passphrase to open your trades, settings and backups."),
+		passEntry,
+		container.NewHBox(quitBtn, unlockBtn),
+		status,
+	)
+	window.SetContent(container.NewCenter(container.NewGridWrap(fyne.NewSize(420, 220), form)))
+	window.Canvas().Focus(passEntry)
+}
+
+// showAlreadyRunningPrompt lets a second launch switch to the running
+// instance or continue read-only
+func showAlreadyRunningPrompt(fyneApp fyne.App, window fyne.Window, openReadOnly func()) {
+	title := widget.NewLabel("TF-Engine is already running")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	status := widget.NewLabel("")
+
+	switchBtn := widget.NewButton("Switch to Running Window", func() {
+		if err := instance.RequestFocus(paths.Data()); err != nil {
+			logger.Error("Failed to focus running instance", "err", err)
+			status.SetText("⚠️ " + err.Error())
+			return
+		}
+		logger.Info("Focused running instance, exiting")
+		fyneApp.Quit()
+	})
+	switchBtn.Importance = widget.HighImportance
+
+	readOnlyBtn := widget.NewButton("Open Read-Only", func() {
+		logger.Info("Opening read-only")
+		openReadOnly()
+	})
+
+	form := container.NewVBox(
+		title,
+		widget.NewLabel("Another window is using this data directory. Switch to it,\nor open a read-only view where nothing can be saved."),
+		container.NewHBox(readOnlyBtn, switchBtn),
+		status,
+	)
+	window.SetContent(container.NewCenter(container.NewGridWrap(fyne.NewSize(460, 200), form)))
 }
 
 // createRequiredDirectories creates all directories the app needs
 func createRequiredDirectories() error {
 	dirs := []string{
-		"data",
-		"data/ui",
-		"data/backups",
-		"logs",
+		paths.Data(),
+		paths.Data("ui"),
+		paths.Data("backups"),
+		paths.Log(),
 	}
 
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
-		logging.DebugLogger.Printf("Created directory: %s", dir)
+		logger.Debug("Created directory", "path", dir)
 	}
 
 	return nil
 }
 
-// findPolicyFile locates the policy file in various possible locations
-func findPolicyFile() string {
-	// Check multiple locations
+// runCommand runs a CLI subcommand instead of the GUI. While the GUI has the
+// data directory the command can read but not change trades.
+func runCommand(args []string) int {
+	defer logging.CloseLogging()
+	logger.Info("Running command", "args", args, "version", AppVersion)
+
+	guard, err := instance.Acquire(paths.Data())
+	if errors.Is(err, instance.ErrAlreadyRunning) {
+		storage.SetReadOnly(true)
+	} else if err != nil {
+		logger.Error("Failed to claim data directory", "err", err)
+	}
+	defer guard.Release()
+
+	return cli.Run(args, cli.Options{
+		Stdout:         os.Stdout,
+		Stderr:         os.Stderr,
+		FindConfigFile: findConfigFile,
+	})
+}
+
+// findConfigFile locates a shipped config file (policy, feature flags) in the
+// config directory, the data directory, or next to the executable
+func findConfigFile(name string) string {
 	locations := []string{
-		"data/policy.v1.json",    // Development location
-		"policy.v1.json",         // Installed location (same as exe)
-		"../data/policy.v1.json", // One level up
-		"./data/policy.v1.json",  // Explicit current dir
+		paths.Config(name),
+		paths.Data(name),
 	}
 
 	// Also check relative to executable
 	if exePath, err := os.Executable(); err == nil {
 		exeDir := filepath.Dir(exePath)
 		locations = append(locations,
-			filepath.Join(exeDir, "policy.v1.json"),
-			filepath.Join(exeDir, "data", "policy.v1.json"),
+			filepath.Join(exeDir, name),
+			filepath.Join(exeDir, "data", name),
 		)
 	}
 
 	for _, loc := range locations {
 		if _, err := os.Stat(loc); err == nil {
-			logging.DebugLogger.Printf("Found policy file at: %s", loc)
+			logger.Debug("Found config file", "name", name, "path", loc)
 			return loc
 		}
 	}
 
-	// Default to first location
-	logging.DebugLogger.Printf("Policy file not found, using default path: data/policy.v1.json")
-	return "data/policy.v1.json"
+	// Default to the config directory
+	logger.Debug("Config file not found, using default path", "name", name, "path", locations[0])
+	return locations[0]
 }
 
 // shouldShowWelcome checks if welcome screen should be shown
 func shouldShowWelcome() bool {
 	// Check for marker file
-	welcomePath := filepath.Join("data", "ui", ".welcome_shown")
+	welcomePath := paths.Data("ui", ".welcome_shown")
 	if _, err := os.Stat(welcomePath); err == nil {
 		return false // Already shown
 	}
@@ -276,7 +551,7 @@ Click OK to begin!`
 	dialog.ShowInformation("Welcome to TF-Engine 2.0", welcomeContent, window)
 
 	// Mark welcome as shown
-	welcomePath := filepath.Join("data", "ui", ".welcome_shown")
+	welcomePath := paths.Data("ui", ".welcome_shown")
 	os.MkdirAll(filepath.Dir(welcomePath), 0755)
 	os.WriteFile(welcomePath, []byte("shown"), 0644)
 }